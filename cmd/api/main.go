@@ -41,6 +41,26 @@ func Run() {
 	services.InitEmailQueue()
 	services.StartEmailWorker()
 
+	// Start the worker that reconciles pending gateway payments
+	userContextService := services.NewUserContextService(database.DB)
+	userPaymentService := services.NewUserPaymentService(database.DB, userContextService)
+	ipaymuService := services.NewIpaymuService(database.DB, userContextService)
+	tsmService := services.NewTsmService(database.DB, userContextService, userPaymentService, services.NewTsmLogService(database.DB))
+	paymentProviders := services.NewDefaultPaymentProviderRegistry(database.DB, ipaymuService, tsmService)
+	services.StartPaymentReconciliationWorker(services.NewOrderPaymentService(database.DB, userContextService, paymentProviders))
+
+	// Start the worker that delivers outbound webhooks
+	services.StartWebhookWorker(services.NewWebhookService(database.DB, userContextService))
+
+	// Start the worker that emails low stock alerts
+	services.StartStockAlertWorker(services.NewStockAlertService(database.DB, userContextService))
+
+	// Start the worker that emails near-expiry stock lots
+	services.StartStockLotExpiryWorker(services.NewStockLotService(database.DB, userContextService, services.NewStockMovementService(database.DB)))
+
+	// Start the worker that releases timed-out stock reservations
+	services.StartStockReservationWorker(services.NewStockService(database.DB, userContextService, services.NewStockMovementService(database.DB)))
+
 	e := echo.New()
 
 	// Middleware
@@ -38,17 +38,50 @@ func PerformMigration() {
 		&models.UserPayment{},
 		&models.UserIpaymu{},
 		&models.UserTsm{},
+		&models.UserQris{},
 		&models.StockMovement{},
 		&models.ProductVariant{},
 		&models.OrderItemAddOn{},
 		&models.ProductAddOn{},
 		&models.TsmLog{},
+		&models.PaymentCallbackLog{},
 		&models.OrderPayment{},
 		&models.OrderPaymentItem{},
+		&models.OrderPaymentRefund{},
+		&models.OutletTable{},
+		&models.SelfOrder{},
+		&models.SelfOrderItem{},
+		&models.MarketplaceChannel{},
+		&models.MarketplaceItemMapping{},
+		&models.MarketplaceOrder{},
+		&models.OutletPaymentMethod{},
+		&models.OrderPaymentProof{},
+		&models.PaymentLink{},
+		&models.WebhookEndpoint{},
+		&models.WebhookEvent{},
+		&models.WebhookDelivery{},
+		&models.Customer{},
+		&models.CustomerWalletTransaction{},
+		&models.CustomerWalletTopUp{},
+		&models.StockAlert{},
+		&models.StockTransfer{},
+		&models.StockTransferItem{},
+		&models.StockCount{},
+		&models.StockCountItem{},
+		&models.StockCountEntry{},
+		&models.StockLot{},
+		&models.ProductUnit{},
+		&models.StockAdjustment{},
+		&models.StockReservation{},
+		&models.ProductionRun{},
 	)
 	if err != nil {
 		log.Fatalf("Failed to auto-migrate database: %v", err)
 	}
+
+	// Backfill order payment statuses for payments created before the status column existed
+	database.DB.Exec("UPDATE order_payments SET status = ? WHERE is_paid = ? AND status = ?", models.OrderPaymentStatusPaid, true, models.OrderPaymentStatusPending)
+	database.DB.Exec("UPDATE order_payments SET status = ? WHERE expired_at IS NOT NULL AND status = ?", models.OrderPaymentStatusExpired, models.OrderPaymentStatusPending)
 	log.Println("Database migration completed.")
 }
 
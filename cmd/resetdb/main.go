@@ -41,12 +41,41 @@ func Run() {
 		&models.UserPayment{},
 		&models.UserIpaymu{},
 		&models.UserTsm{},
+		&models.UserQris{},
 		&models.StockMovement{},
 		&models.ProductVariant{},
 		&models.OrderItemAddOn{},
 		&models.ProductAddOn{},
 		&models.TsmLog{},
+		&models.PaymentCallbackLog{},
 		&models.OrderPayment{},
+		&models.OrderPaymentRefund{},
+		&models.OutletTable{},
+		&models.SelfOrder{},
+		&models.SelfOrderItem{},
+		&models.MarketplaceChannel{},
+		&models.MarketplaceItemMapping{},
+		&models.MarketplaceOrder{},
+		&models.OutletPaymentMethod{},
+		&models.OrderPaymentProof{},
+		&models.PaymentLink{},
+		&models.WebhookEndpoint{},
+		&models.WebhookEvent{},
+		&models.WebhookDelivery{},
+		&models.Customer{},
+		&models.CustomerWalletTransaction{},
+		&models.CustomerWalletTopUp{},
+		&models.StockAlert{},
+		&models.StockTransfer{},
+		&models.StockTransferItem{},
+		&models.StockCount{},
+		&models.StockCountItem{},
+		&models.StockCountEntry{},
+		&models.StockLot{},
+		&models.ProductUnit{},
+		&models.StockAdjustment{},
+		&models.StockReservation{},
+		&models.ProductionRun{},
 	)
 	if err != nil {
 		log.Fatalf("Failed to drop tables: %v", err)
@@ -44,6 +44,14 @@ func seedPaymentMethods(db *gorm.DB) {
 		{Issuer: "iPaymu", Name: "Bank Transfer", Type: "bank_transfer", IsActive: true, PaymentMethod: "va", PaymentChannel: "mandiri"},
 		{Issuer: "TSM", Name: "Credit Card", Type: "credit_card", IsActive: true, PaymentMethod: "edc", PaymentChannel: "linkpayment"},
 		{Issuer: "iPaymu", Name: "QRIS", Type: "qris", IsActive: true, PaymentMethod: "qris", PaymentChannel: "qris"},
+		{Issuer: "QRIS static", Name: "QRIS Static", Type: "qris", IsActive: true, PaymentMethod: "qris", PaymentChannel: "static"},
+		{Issuer: "Manual transfer", Name: "Manual Bank Transfer", Type: "bank_transfer", IsActive: true, PaymentMethod: "transfer", PaymentChannel: "manual"},
+		{Issuer: "iPaymu", Name: "iPaymu Payment Page", Type: "online", IsActive: true, PaymentMethod: "hosted", PaymentChannel: "hosted"},
+		{Issuer: "Wallet", Name: "Customer Wallet", Type: "wallet", IsActive: true, PaymentMethod: "wallet", PaymentChannel: "store_credit"},
+	}
+	if os.Getenv("PAYMENT_FAKE_PROVIDER_ENABLED") == "true" {
+		// Sandbox gateway for testing payment flows without a real provider
+		paymentMethods = append(paymentMethods, models.PaymentMethod{Issuer: "Fake", Name: "Fake Gateway", Type: "fake", IsActive: true, PaymentMethod: "fake", PaymentChannel: "fake"})
 	}
 
 	for _, pm := range paymentMethods {
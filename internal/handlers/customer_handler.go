@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/services"
+)
+
+type CustomerHandler struct {
+	CustomerService       *services.CustomerService
+	CustomerWalletService *services.CustomerWalletService
+	UserContextService    *services.UserContextService
+}
+
+func NewCustomerHandler(customerService *services.CustomerService, customerWalletService *services.CustomerWalletService, userContextService *services.UserContextService) *CustomerHandler {
+	return &CustomerHandler{CustomerService: customerService, CustomerWalletService: customerWalletService, UserContextService: userContextService}
+}
+
+func (h *CustomerHandler) GetCustomers(c echo.Context) error {
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	customers, err := h.CustomerService.GetCustomers(c.QueryParam("search"), userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "customers_retrieved_successfully", customers)
+}
+
+func (h *CustomerHandler) GetCustomer(c echo.Context) error {
+	customerUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	customer, err := h.CustomerService.GetCustomer(customerUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "customer_retrieved_successfully", customer)
+}
+
+func (h *CustomerHandler) CreateCustomer(c echo.Context) error {
+	req, ok := c.Get("validated_data").(*dtos.CustomerRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	customer, err := h.CustomerService.CreateCustomer(req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "customer_created_successfully", customer)
+}
+
+func (h *CustomerHandler) UpdateCustomer(c echo.Context) error {
+	customerUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.CustomerRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	customer, err := h.CustomerService.UpdateCustomer(customerUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "customer_updated_successfully", customer)
+}
+
+func (h *CustomerHandler) GetWalletTransactions(c echo.Context) error {
+	customerUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	transactions, err := h.CustomerWalletService.GetWalletTransactions(customerUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "wallet_transactions_retrieved_successfully", transactions)
+}
+
+func (h *CustomerHandler) AdjustWallet(c echo.Context) error {
+	customerUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.AdjustCustomerWalletRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	transaction, err := h.CustomerWalletService.AdjustWallet(customerUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "wallet_adjusted_successfully", transaction)
+}
+
+func (h *CustomerHandler) TopUpWallet(c echo.Context) error {
+	customerUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.TopUpCustomerWalletRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	topUp, err := h.CustomerWalletService.TopUpWallet(customerUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "wallet_top_up_created_successfully", topUp)
+}
@@ -9,12 +9,13 @@ import (
 )
 
 type IpaymuHandler struct {
-	Service            *services.IpaymuService
-	UserContextService *services.UserContextService
+	Service             *services.IpaymuService
+	UserContextService  *services.UserContextService
+	OrderPaymentService *services.OrderPaymentService
 }
 
-func NewIpaymuHandler(service *services.IpaymuService, userContextService *services.UserContextService) *IpaymuHandler {
-	return &IpaymuHandler{Service: service, UserContextService: userContextService}
+func NewIpaymuHandler(service *services.IpaymuService, userContextService *services.UserContextService, orderPaymentService *services.OrderPaymentService) *IpaymuHandler {
+	return &IpaymuHandler{Service: service, UserContextService: userContextService, OrderPaymentService: orderPaymentService}
 }
 
 func (h *IpaymuHandler) CreateDirectPayment(c echo.Context) error {
@@ -47,9 +48,15 @@ func (h *IpaymuHandler) IpaymuNotify(c echo.Context) error {
 
 	// fmt.Println("Debug Signature:", req) // Debugging output
 
-	err := h.Service.NotifyDirectPayment(req.TrxID, req.Status, req.SettlementStatus)
+	rawBody, _ := c.Get("raw_body").([]byte)
+	err := h.OrderPaymentService.HandleProviderCallback("iPaymu", services.PaymentCallback{
+		Payload:  req,
+		RawBody:  rawBody,
+		Headers:  c.Request().Header,
+		SourceIP: c.RealIP(),
+	})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
+		return c.JSON(MapErrorToStatusCode(err), map[string]interface{}{"error": err.Error()})
 	}
 	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Success"})
 }
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/services"
+)
+
+type ManualTransferHandler struct {
+	ManualTransferService *services.ManualTransferService
+	UserContextService    *services.UserContextService
+}
+
+func NewManualTransferHandler(manualTransferService *services.ManualTransferService, userContextService *services.UserContextService) *ManualTransferHandler {
+	return &ManualTransferHandler{ManualTransferService: manualTransferService, UserContextService: userContextService}
+}
+
+// AttachProof accepts either a multipart upload with the image in the "proof" field, or a JSON
+// body with a link to the proof.
+func (h *ManualTransferHandler) AttachProof(c echo.Context) error {
+	orderPaymentUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	var proof *dtos.OrderPaymentProofResponse
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		fileHeader, err := c.FormFile("proof")
+		if err != nil {
+			return JSONError(c, http.StatusBadRequest, "proof_file_required")
+		}
+		note := c.FormValue("note")
+		if len(note) > 500 {
+			return JSONError(c, http.StatusBadRequest, "proof_note_too_long")
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return JSONError(c, http.StatusBadRequest, "proof_file_required")
+		}
+		defer file.Close()
+
+		proof, err = h.ManualTransferService.UploadProof(orderPaymentUuid, file, note, userID)
+		if err != nil {
+			return JSONError(c, MapErrorToStatusCode(err), err.Error())
+		}
+	} else {
+		var req dtos.AttachPaymentProofRequest
+		if err := c.Bind(&req); err != nil {
+			return JSONError(c, http.StatusBadRequest, "invalid_request_body")
+		}
+		if err := validator.New().Struct(&req); err != nil {
+			if ve, ok := err.(validator.ValidationErrors); ok {
+				return JSONError(c, http.StatusBadRequest, ve)
+			}
+			return JSONError(c, http.StatusBadRequest, "invalid_request_body")
+		}
+
+		proof, err = h.ManualTransferService.AttachProofURL(orderPaymentUuid, &req, userID)
+		if err != nil {
+			return JSONError(c, MapErrorToStatusCode(err), err.Error())
+		}
+	}
+
+	return JSONSuccess(c, http.StatusCreated, "payment_proof_attached_successfully", proof)
+}
+
+func (h *ManualTransferHandler) GetProofs(c echo.Context) error {
+	orderPaymentUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	proofs, err := h.ManualTransferService.GetProofs(orderPaymentUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "payment_proofs_retrieved_successfully", proofs)
+}
+
+func (h *ManualTransferHandler) GetProofFile(c echo.Context) error {
+	proofUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	path, contentType, err := h.ManualTransferService.GetProofFile(proofUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+	return c.File(path)
+}
+
+func (h *ManualTransferHandler) ConfirmTransfer(c echo.Context) error {
+	orderPaymentUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.ConfirmTransferRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	orderPayment, err := h.ManualTransferService.ConfirmTransfer(orderPaymentUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "transfer_confirmed_successfully", orderPayment)
+}
+
+func (h *ManualTransferHandler) RejectTransfer(c echo.Context) error {
+	orderPaymentUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.RejectTransferRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	orderPayment, err := h.ManualTransferService.RejectTransfer(orderPaymentUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "transfer_rejected_successfully", orderPayment)
+}
+
+func (h *ManualTransferHandler) GetPendingTransfers(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	transfers, err := h.ManualTransferService.GetPendingTransfers(outletUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "pending_transfers_retrieved_successfully", transfers)
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/services"
+)
+
+type MarketplaceHandler struct {
+	MarketplaceService *services.MarketplaceService
+	UserContextService *services.UserContextService
+}
+
+func NewMarketplaceHandler(marketplaceService *services.MarketplaceService, userContextService *services.UserContextService) *MarketplaceHandler {
+	return &MarketplaceHandler{MarketplaceService: marketplaceService, UserContextService: userContextService}
+}
+
+func (h *MarketplaceHandler) CreateChannel(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.CreateMarketplaceChannelRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	channel, err := h.MarketplaceService.CreateChannel(outletUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "marketplace_channel_created_successfully", channel)
+}
+
+func (h *MarketplaceHandler) GetChannelsByOutlet(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	channels, err := h.MarketplaceService.GetChannelsByOutlet(outletUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "marketplace_channels_retrieved_successfully", channels)
+}
+
+func (h *MarketplaceHandler) CreateItemMapping(c echo.Context) error {
+	channelUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.CreateMarketplaceItemMappingRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	mapping, err := h.MarketplaceService.CreateItemMapping(channelUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "marketplace_item_mapping_created_successfully", mapping)
+}
+
+func (h *MarketplaceHandler) GetItemMappings(c echo.Context) error {
+	channelUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	mappings, err := h.MarketplaceService.GetItemMappings(channelUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "marketplace_item_mappings_retrieved_successfully", mappings)
+}
+
+func (h *MarketplaceHandler) DeleteItemMapping(c echo.Context) error {
+	mappingUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	if err := h.MarketplaceService.DeleteItemMapping(mappingUuid, userID); err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "marketplace_item_mapping_deleted_successfully", nil)
+}
+
+// Webhook receives order events from a marketplace. The body is verified against the
+// X-Signature header using the channel's webhook secret.
+func (h *MarketplaceHandler) Webhook(c echo.Context) error {
+	channelUuid, err := uuid.Parse(c.Param("channel_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.MarketplaceWebhookRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+	rawBody, _ := c.Get("raw_body").([]byte)
+
+	marketplaceOrder, err := h.MarketplaceService.HandleWebhook(channelUuid, req, rawBody, c.Request().Header.Get("X-Signature"))
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "marketplace_webhook_processed_successfully", marketplaceOrder)
+}
+
+func (h *MarketplaceHandler) GetMarketplaceOrdersByOutlet(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	status := c.QueryParam("status")
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	marketplaceOrders, err := h.MarketplaceService.GetMarketplaceOrdersByOutlet(outletUuid, userID, status)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "marketplace_orders_retrieved_successfully", marketplaceOrders)
+}
+
+func (h *MarketplaceHandler) UpdateMarketplaceOrderStatus(c echo.Context) error {
+	marketplaceOrderUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.UpdateMarketplaceOrderStatusRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	marketplaceOrder, err := h.MarketplaceService.UpdateMarketplaceOrderStatus(marketplaceOrderUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "marketplace_order_status_updated_successfully", marketplaceOrder)
+}
@@ -60,6 +60,27 @@ func (h *OrderHandler) GetOrderByUuid(c echo.Context) error {
 	}
 	return JSONSuccess(c, http.StatusOK, "order_retrieved_successfully", order)
 }
+
+// CancelOrder cancels a pending, unpaid order and gives its stock back.
+func (h *OrderHandler) CancelOrder(c echo.Context) error {
+	orderUuidParam := c.Param("uuid")
+	orderUuid, err := uuid.Parse(orderUuidParam)
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_order_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	order, err := h.OrderService.CancelOrder(orderUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+
+	return JSONSuccess(c, http.StatusOK, "order_cancelled_successfully", order)
+}
 func (h *OrderHandler) GetOrdersByOutlet(c echo.Context) error {
 	outletUuidParam := c.Param("outlet_uuid")
 	outletUuid, err := uuid.Parse(outletUuidParam)
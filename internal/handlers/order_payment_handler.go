@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/msyaifudin/pos/internal/services"
 	"github.com/msyaifudin/pos/internal/models/dtos"
@@ -39,3 +40,98 @@ func (h *OrderPaymentHandler) CreateOrderPayment(c echo.Context) error {
 
 	return JSONSuccess(c, http.StatusCreated, "order_payment_created_successfully", orderPayment)
 }
+
+func (h *OrderPaymentHandler) CheckPaymentStatus(c echo.Context) error {
+	orderPaymentUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	orderPayment, err := h.OrderPaymentService.CheckPaymentStatus(orderPaymentUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "order_payment_status_checked_successfully", orderPayment)
+}
+
+func (h *OrderPaymentHandler) CancelOrderPayment(c echo.Context) error {
+	orderPaymentUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	orderPayment, err := h.OrderPaymentService.CancelOrderPayment(orderPaymentUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "order_payment_cancelled_successfully", orderPayment)
+}
+
+func (h *OrderPaymentHandler) RetryOrderPayment(c echo.Context) error {
+	orderPaymentUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.RetryOrderPaymentRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	orderPayment, err := h.OrderPaymentService.RetryOrderPayment(orderPaymentUuid, *req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "order_payment_created_successfully", orderPayment)
+}
+
+// FakeCallback simulates a gateway notification for payments made with the fake provider.
+func (h *OrderPaymentHandler) FakeCallback(c echo.Context) error {
+	req, ok := c.Get("validated_data").(*dtos.FakePaymentCallbackRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	if err := h.OrderPaymentService.HandleProviderCallback("Fake", services.PaymentCallback{
+		Payload:  req,
+		Headers:  c.Request().Header,
+		SourceIP: c.RealIP(),
+	}); err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+
+	return JSONSuccess(c, http.StatusOK, "callback_processed_successfully", nil)
+}
+
+func (h *OrderPaymentHandler) ConfirmOrderPayment(c echo.Context) error {
+	orderPaymentUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	orderPayment, err := h.OrderPaymentService.ConfirmOrderPayment(orderPaymentUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "order_payment_confirmed_successfully", orderPayment)
+}
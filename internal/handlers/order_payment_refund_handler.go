@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/services"
+)
+
+type OrderPaymentRefundHandler struct {
+	OrderPaymentRefundService *services.OrderPaymentRefundService
+	UserContextService        *services.UserContextService
+}
+
+func NewOrderPaymentRefundHandler(orderPaymentRefundService *services.OrderPaymentRefundService, userContextService *services.UserContextService) *OrderPaymentRefundHandler {
+	return &OrderPaymentRefundHandler{OrderPaymentRefundService: orderPaymentRefundService, UserContextService: userContextService}
+}
+
+func (h *OrderPaymentRefundHandler) CreateRefund(c echo.Context) error {
+	orderPaymentUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.CreateOrderPaymentRefundRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	refund, err := h.OrderPaymentRefundService.CreateRefund(orderPaymentUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "order_payment_refund_created_successfully", refund)
+}
+
+func (h *OrderPaymentRefundHandler) GetRefunds(c echo.Context) error {
+	orderPaymentUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	refunds, err := h.OrderPaymentRefundService.GetRefunds(orderPaymentUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "order_payment_refunds_retrieved_successfully", refunds)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/services"
+)
+
+type PaymentLinkHandler struct {
+	PaymentLinkService *services.PaymentLinkService
+	UserContextService *services.UserContextService
+}
+
+func NewPaymentLinkHandler(paymentLinkService *services.PaymentLinkService, userContextService *services.UserContextService) *PaymentLinkHandler {
+	return &PaymentLinkHandler{PaymentLinkService: paymentLinkService, UserContextService: userContextService}
+}
+
+func (h *PaymentLinkHandler) CreatePaymentLink(c echo.Context) error {
+	orderUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.CreatePaymentLinkRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	link, err := h.PaymentLinkService.CreatePaymentLink(orderUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "payment_link_created_successfully", link)
+}
+
+func (h *PaymentLinkHandler) GetPaymentLinks(c echo.Context) error {
+	orderUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	links, err := h.PaymentLinkService.GetPaymentLinks(orderUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "payment_links_retrieved_successfully", links)
+}
+
+func (h *PaymentLinkHandler) SendPaymentLink(c echo.Context) error {
+	linkUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.SendPaymentLinkRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	link, err := h.PaymentLinkService.SendPaymentLink(linkUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "payment_link_sent_successfully", link)
+}
+
+func (h *PaymentLinkHandler) CancelPaymentLink(c echo.Context) error {
+	linkUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	link, err := h.PaymentLinkService.CancelPaymentLink(linkUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "payment_link_cancelled_successfully", link)
+}
+
+// OpenPaymentLink sends the customer of a shared link on to iPaymu's payment page.
+func (h *PaymentLinkHandler) OpenPaymentLink(c echo.Context) error {
+	paymentURL, err := h.PaymentLinkService.ResolvePaymentLink(c.Param("token"))
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return c.Redirect(http.StatusFound, paymentURL)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/services"
+)
+
+type PaymentMethodHandler struct {
+	PaymentMethodService *services.PaymentMethodService
+	UserContextService   *services.UserContextService
+}
+
+func NewPaymentMethodHandler(paymentMethodService *services.PaymentMethodService, userContextService *services.UserContextService) *PaymentMethodHandler {
+	return &PaymentMethodHandler{PaymentMethodService: paymentMethodService, UserContextService: userContextService}
+}
+
+func (h *PaymentMethodHandler) GetAllPaymentMethods(c echo.Context) error {
+	paymentMethods, err := h.PaymentMethodService.GetAllPaymentMethods()
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "payment_methods_retrieved_successfully", paymentMethods)
+}
+
+func (h *PaymentMethodHandler) CreatePaymentMethod(c echo.Context) error {
+	req, ok := c.Get("validated_data").(*dtos.PaymentMethodRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	paymentMethod, err := h.PaymentMethodService.CreatePaymentMethod(req)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "payment_method_created_successfully", paymentMethod)
+}
+
+func (h *PaymentMethodHandler) UpdatePaymentMethod(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_payment_method_id")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.PaymentMethodRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	paymentMethod, err := h.PaymentMethodService.UpdatePaymentMethod(uint(id), req)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "payment_method_updated_successfully", paymentMethod)
+}
+
+func (h *PaymentMethodHandler) DeactivatePaymentMethod(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_payment_method_id")
+	}
+
+	if err := h.PaymentMethodService.DeactivatePaymentMethod(uint(id)); err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "payment_method_deactivated_successfully", nil)
+}
+
+func (h *PaymentMethodHandler) GetOutletPaymentMethods(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	paymentMethods, err := h.PaymentMethodService.GetOutletPaymentMethods(outletUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "outlet_payment_methods_retrieved_successfully", paymentMethods)
+}
+
+func (h *PaymentMethodHandler) SetOutletPaymentMethods(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.SetOutletPaymentMethodsRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	paymentMethods, err := h.PaymentMethodService.SetOutletPaymentMethods(outletUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "outlet_payment_methods_updated_successfully", paymentMethods)
+}
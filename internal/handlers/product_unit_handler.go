@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/services"
+)
+
+type ProductUnitHandler struct {
+	ProductUnitService *services.ProductUnitService
+	UserContextService *services.UserContextService
+}
+
+func NewProductUnitHandler(productUnitService *services.ProductUnitService, userContextService *services.UserContextService) *ProductUnitHandler {
+	return &ProductUnitHandler{ProductUnitService: productUnitService, UserContextService: userContextService}
+}
+
+func (h *ProductUnitHandler) CreateProductUnit(c echo.Context) error {
+	productUuid, err := uuid.Parse(c.Param("product_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_product_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.ProductUnitRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	resp, err := h.ProductUnitService.CreateProductUnit(productUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "product_unit_created_successfully", resp)
+}
+
+func (h *ProductUnitHandler) GetProductUnits(c echo.Context) error {
+	productUuid, err := uuid.Parse(c.Param("product_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_product_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	resp, err := h.ProductUnitService.GetProductUnits(productUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "product_units_retrieved_successfully", resp)
+}
+
+func (h *ProductUnitHandler) UpdateProductUnit(c echo.Context) error {
+	unitUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.ProductUnitRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	resp, err := h.ProductUnitService.UpdateProductUnit(unitUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "product_unit_updated_successfully", resp)
+}
+
+func (h *ProductUnitHandler) DeleteProductUnit(c echo.Context) error {
+	unitUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	if err := h.ProductUnitService.DeleteProductUnit(unitUuid, userID); err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusNoContent, "product_unit_deleted_successfully", nil)
+}
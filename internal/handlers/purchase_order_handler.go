@@ -91,12 +91,17 @@ func (h *PurchaseOrderHandler) ReceivePurchaseOrder(c echo.Context) error {
 		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
 	}
 
+	req, ok := c.Get("validated_data").(*dtos.ReceivePurchaseOrderRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
 	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
 	if err != nil {
 		return JSONError(c, http.StatusUnauthorized, err.Error())
 	}
 
-	po, err := h.PurchaseOrderService.ReceivePurchaseOrder(parsedUuid, userID)
+	po, err := h.PurchaseOrderService.ReceivePurchaseOrder(parsedUuid, *req, userID)
 	if err != nil {
 		return JSONError(c, MapErrorToStatusCode(err), err.Error())
 	}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/services"
+	"github.com/msyaifudin/pos/pkg/qris"
+)
+
+type QrisHandler struct {
+	QrisService        *services.QrisService
+	UserContextService *services.UserContextService
+}
+
+func NewQrisHandler(qrisService *services.QrisService, userContextService *services.UserContextService) *QrisHandler {
+	return &QrisHandler{QrisService: qrisService, UserContextService: userContextService}
+}
+
+func (h *QrisHandler) RegisterQris(c echo.Context) error {
+	req, ok := c.Get("validated_data").(*dtos.QrisRegisterRequest)
+	if !ok {
+		return JSONError(c, http.StatusBadRequest, "invalid_request_body")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, "unauthorized")
+	}
+
+	ownerID, err := h.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return JSONError(c, http.StatusForbidden, "forbidden")
+	}
+
+	userQris, err := h.QrisService.RegisterQris(ownerID, *req)
+	if err != nil {
+		if errors.Is(err, qris.ErrInvalidPayload) || errors.Is(err, qris.ErrInvalidChecksum) || errors.Is(err, qris.ErrInvalidAmount) {
+			return JSONError(c, http.StatusBadRequest, err.Error())
+		}
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+
+	return JSONSuccess(c, http.StatusOK, "qris_registered_successfully", userQris)
+}
+
+func (h *QrisHandler) GetQris(c echo.Context) error {
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, "unauthorized")
+	}
+
+	ownerID, err := h.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return JSONError(c, http.StatusForbidden, "forbidden")
+	}
+
+	userQris, err := h.QrisService.GetUserQris(ownerID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+
+	return JSONSuccess(c, http.StatusOK, "qris_retrieved_successfully", userQris)
+}
@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
 	"github.com/msyaifudin/pos/internal/services"
 )
 
@@ -101,3 +107,180 @@ func (h *ReportHandler) GetStockReport(c echo.Context) error {
 
 	return JSONSuccess(c, http.StatusOK, "stock_report_generated_successfully", report)
 }
+
+// GetPaymentReconciliationReport returns the report as JSON, or as a CSV download with ?format=csv.
+func (h *ReportHandler) GetPaymentReconciliationReport(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	startDate, err := time.Parse("2006-01-02", c.QueryParam("start_date"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_start_date_format")
+	}
+	endDate, err := time.Parse("2006-01-02", c.QueryParam("end_date"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_end_date_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+	ownerID, err := h.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+
+	report, err := h.ReportService.PaymentReconciliationReport(outletUuid, startDate, endDate, ownerID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+
+	if c.QueryParam("format") == "csv" {
+		content, err := paymentReconciliationCSV(report)
+		if err != nil {
+			return JSONError(c, http.StatusInternalServerError, "failed_to_generate_csv")
+		}
+		filename := fmt.Sprintf("payment-reconciliation-%s-%s.csv", report.StartDate, report.EndDate)
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+		return c.Blob(http.StatusOK, "text/csv", content)
+	}
+
+	return JSONSuccess(c, http.StatusOK, "payment_reconciliation_report_generated_successfully", report)
+}
+
+// GetGrossMarginReport groups the margin by ?group_by=product (default), category or outlet, optionally
+// for one ?outlet_uuid.
+func (h *ReportHandler) GetGrossMarginReport(c echo.Context) error {
+	startDate, err := time.Parse("2006-01-02", c.QueryParam("start_date"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_start_date_format")
+	}
+	endDate, err := time.Parse("2006-01-02", c.QueryParam("end_date"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_end_date_format")
+	}
+
+	groupBy := c.QueryParam("group_by")
+	if groupBy == "" {
+		groupBy = "product"
+	}
+	if groupBy != "product" && groupBy != "category" && groupBy != "outlet" {
+		return JSONError(c, http.StatusBadRequest, "invalid_group_by_parameter")
+	}
+
+	outletUuid, err := optionalOutletUuid(c)
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+	ownerID, err := h.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+
+	report, err := h.ReportService.GrossMarginReport(startDate, endDate, groupBy, outletUuid, ownerID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+
+	return JSONSuccess(c, http.StatusOK, "gross_margin_report_generated_successfully", report)
+}
+
+// GetWasteReport groups the stock written off by ?group_by=reason (default), product or outlet,
+// optionally for one ?outlet_uuid.
+func (h *ReportHandler) GetWasteReport(c echo.Context) error {
+	startDate, err := time.Parse("2006-01-02", c.QueryParam("start_date"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_start_date_format")
+	}
+	endDate, err := time.Parse("2006-01-02", c.QueryParam("end_date"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_end_date_format")
+	}
+
+	groupBy := c.QueryParam("group_by")
+	if groupBy == "" {
+		groupBy = "reason"
+	}
+	if groupBy != "reason" && groupBy != "product" && groupBy != "outlet" {
+		return JSONError(c, http.StatusBadRequest, "invalid_waste_group_by_parameter")
+	}
+
+	outletUuid, err := optionalOutletUuid(c)
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+	ownerID, err := h.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+
+	report, err := h.ReportService.WasteReport(startDate, endDate, groupBy, outletUuid, ownerID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+
+	return JSONSuccess(c, http.StatusOK, "waste_report_generated_successfully", report)
+}
+
+func paymentReconciliationCSV(report *dtos.PaymentReconciliationReport) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	formatAmount := func(amount float64) string {
+		return strconv.FormatFloat(amount, 'f', 2, 64)
+	}
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format("2006-01-02 15:04:05")
+	}
+
+	writer.Write([]string{"order_payment_uuid", "order_uuid", "payment_name", "reference_id", "paid_at", "amount_paid", "gateway_amount", "fee", "fee_direction", "expected_net", "settled_amount", "settled_at", "mismatches"})
+	for _, row := range report.Payments {
+		writer.Write([]string{
+			row.OrderPaymentUuid,
+			row.OrderUuid,
+			row.PaymentName,
+			row.ReferenceID,
+			formatTime(row.PaidAt),
+			formatAmount(row.AmountPaid),
+			formatAmount(row.GatewayAmount),
+			formatAmount(row.Fee),
+			row.FeeDirection,
+			formatAmount(row.ExpectedNet),
+			formatAmount(row.SettledAmount),
+			formatTime(row.SettledAt),
+			strings.Join(row.Mismatches, ";"),
+		})
+	}
+
+	summary := report.Summary
+	writer.Write([]string{})
+	writer.Write([]string{"payment_count", "gross_amount", "gateway_fees", "net_settled", "unsettled_count", "unsettled_amount", "mismatch_count"})
+	writer.Write([]string{
+		strconv.Itoa(summary.PaymentCount),
+		formatAmount(summary.GrossAmount),
+		formatAmount(summary.GatewayFees),
+		formatAmount(summary.NetSettled),
+		strconv.Itoa(summary.UnsettledCount),
+		formatAmount(summary.UnsettledAmount),
+		strconv.Itoa(summary.MismatchCount),
+	})
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
@@ -56,14 +56,20 @@ func JSONError(c echo.Context, statusCode int, messageKey interface{}, redirectU
 // MapErrorToStatusCode maps common error messages to HTTP status codes.
 func MapErrorToStatusCode(err error) int {
 	switch err.Error() {
-	case "user not found", "outlet not found", "product not found", "supplier not found", "recipe not found", "stock not found", "order not found", "purchase order not found":
+	case "user not found", "outlet not found", "product not found", "supplier not found", "recipe not found", "stock not found", "order not found", "purchase order not found", "self order not found", "outlet table not found", "marketplace channel not found", "marketplace order not found", "marketplace item mapping not found", "payment provider not found", "order payment not found", "ipaymu transaction not found", "user tsm data not found", "user qris data not found", "payment method not found", "proof of payment not found", "payment link not found", "webhook endpoint not found", "webhook delivery not found", "customer not found", "wallet payment not found", "stock alert not found", "stock transfer not found", "stock transfer item not found", "stock count not found", "stock count entry not found", "stock lot not found", "purchase order item not found", "product unit not found", "stock adjustment not found", "photo not found", "product variant not found":
 		return http.StatusNotFound
-	case "invalid credentials", "unauthorized", "user not verified":
+	case "invalid credentials", "unauthorized", "user not verified", "invalid table token", "invalid signature", "invalid callback token", "callback token has expired":
 		return http.StatusUnauthorized
-	case "username already exists", "invalid input", "validation error", "ipaymu VA already registered":
+	case "username already exists", "invalid input", "validation error", "ipaymu VA already registered", "payment operation not supported by provider", "payment not confirmed by ipaymu", "payment amount mismatch", "callback merchant does not match", "refund amount exceeds the refundable amount", "refund not confirmed by ipaymu", "qris payload must be static", "payment method does not allow manual confirmation", "unknown payment issuer", "duplicate payment method", "payment method not found or not active", "payment method not enabled for this outlet", "order payment is not a manual transfer", "order payment is not a TSM payment", "proof of payment is too large", "proof of payment must be a jpeg, png or webp image", "proof of payment required", "payment method does not support payment links", "recipient email is required", "payment reference mismatch", "customer is required for wallet payments", "wallet top-ups cannot be paid from the wallet", "insufficient wallet balance", "par level must not be below the reorder point", "source and destination outlets must be different", "received quantity cannot exceed the sent quantity", "discrepancy note is required when less is received than sent", "invalid stock transfer direction", "stock count has no counted items", "write-off quantity exceeds the lot quantity", "unit name is the product's base unit", "only corrections can add stock", "photo is too large", "photo must be a jpeg, png or webp image":
 		return http.StatusBadRequest
-	case "forbidden":
+	case "forbidden", "notification source not allowed":
 		return http.StatusForbidden
+	case "self order has already been handled", "self order has not been accepted yet", "external item is already mapped", "only pending payments can be cancelled", "order payment cannot be retried", "only paid payments can be refunded", "a refund of this payment is still pending", "only pending payments can be confirmed", "only pending transfers accept a proof of payment", "only pending transfers can be reviewed", "order has no outstanding balance", "payment link is no longer active", "payment link has already been paid", "webhook endpoint is inactive", "stock alert is already resolved", "only draft stock transfers can be edited", "only draft stock transfers can be sent", "only sent stock transfers can be received", "stock transfer can no longer be cancelled", "insufficient stock for transfer", "outlet already has an open stock count", "stock count is not open for counting", "only submitted stock counts can be approved", "stock count can no longer be cancelled", "variances are hidden until the blind count is submitted", "stock lot is empty", "product unit already exists", "only pending stock adjustments can be reviewed", "adjustment exceeds the available stock", "write-off exceeds the available stock", "stock cannot be set below the reserved quantity", "order is cancelled", "only pending orders can be cancelled", "order has payments and cannot be cancelled":
+		return http.StatusConflict
+	case "payment link has expired":
+		return http.StatusGone
+	case "too many self orders for this table, please wait", "email rate limited":
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
 	}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/services"
+)
+
+type SelfOrderHandler struct {
+	SelfOrderService   *services.SelfOrderService
+	UserContextService *services.UserContextService
+}
+
+func NewSelfOrderHandler(selfOrderService *services.SelfOrderService, userContextService *services.UserContextService) *SelfOrderHandler {
+	return &SelfOrderHandler{SelfOrderService: selfOrderService, UserContextService: userContextService}
+}
+
+func (h *SelfOrderHandler) CreateTable(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.CreateOutletTableRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	table, err := h.SelfOrderService.CreateTable(outletUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "outlet_table_created_successfully", table)
+}
+
+func (h *SelfOrderHandler) GetTablesByOutlet(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	tables, err := h.SelfOrderService.GetTablesByOutlet(outletUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "outlet_tables_retrieved_successfully", tables)
+}
+
+func (h *SelfOrderHandler) RotateTableToken(c echo.Context) error {
+	tableUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	table, err := h.SelfOrderService.RotateTableToken(tableUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "outlet_table_token_rotated_successfully", table)
+}
+
+// GetPublicMenu is called by customers scanning a table QR code; no login is required.
+func (h *SelfOrderHandler) GetPublicMenu(c echo.Context) error {
+	token := c.QueryParam("token")
+	if token == "" {
+		return JSONError(c, http.StatusUnauthorized, "invalid_table_token")
+	}
+
+	menu, err := h.SelfOrderService.GetPublicMenu(token)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "public_menu_retrieved_successfully", menu)
+}
+
+func (h *SelfOrderHandler) CreateSelfOrder(c echo.Context) error {
+	req, ok := c.Get("validated_data").(*dtos.CreateSelfOrderRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	selfOrder, err := h.SelfOrderService.CreateSelfOrder(req)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "self_order_created_successfully", selfOrder)
+}
+
+func (h *SelfOrderHandler) GetPublicSelfOrder(c echo.Context) error {
+	selfOrderUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	token := c.QueryParam("token")
+	if token == "" {
+		return JSONError(c, http.StatusUnauthorized, "invalid_table_token")
+	}
+
+	selfOrder, err := h.SelfOrderService.GetPublicSelfOrder(selfOrderUuid, token)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "self_order_retrieved_successfully", selfOrder)
+}
+
+func (h *SelfOrderHandler) PaySelfOrder(c echo.Context) error {
+	selfOrderUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.PaySelfOrderRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	payment, err := h.SelfOrderService.PaySelfOrder(selfOrderUuid, req)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "order_payment_created_successfully", payment)
+}
+
+func (h *SelfOrderHandler) GetSelfOrdersByOutlet(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	status := c.QueryParam("status")
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	selfOrders, err := h.SelfOrderService.GetSelfOrdersByOutlet(outletUuid, userID, status)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "self_orders_retrieved_successfully", selfOrders)
+}
+
+func (h *SelfOrderHandler) AcceptSelfOrder(c echo.Context) error {
+	selfOrderUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	selfOrder, err := h.SelfOrderService.AcceptSelfOrder(selfOrderUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "self_order_accepted_successfully", selfOrder)
+}
+
+func (h *SelfOrderHandler) RejectSelfOrder(c echo.Context) error {
+	selfOrderUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.RejectSelfOrderRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	selfOrder, err := h.SelfOrderService.RejectSelfOrder(selfOrderUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "self_order_rejected_successfully", selfOrder)
+}
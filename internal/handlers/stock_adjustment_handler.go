@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/services"
+)
+
+type StockAdjustmentHandler struct {
+	StockAdjustmentService *services.StockAdjustmentService
+	UserContextService     *services.UserContextService
+}
+
+func NewStockAdjustmentHandler(stockAdjustmentService *services.StockAdjustmentService, userContextService *services.UserContextService) *StockAdjustmentHandler {
+	return &StockAdjustmentHandler{StockAdjustmentService: stockAdjustmentService, UserContextService: userContextService}
+}
+
+// CreateAdjustment answers with the adjustment's status, which is pending when it waits for approval.
+func (h *StockAdjustmentHandler) CreateAdjustment(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.StockAdjustmentRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	adjustment, err := h.StockAdjustmentService.CreateAdjustment(outletUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "stock_adjustment_created_successfully", adjustment)
+}
+
+// GetAdjustmentsByOutlet can be filtered with ?status= and ?reason=.
+func (h *StockAdjustmentHandler) GetAdjustmentsByOutlet(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	adjustments, err := h.StockAdjustmentService.GetAdjustmentsByOutlet(outletUuid, c.QueryParam("status"), c.QueryParam("reason"), userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_adjustments_retrieved_successfully", adjustments)
+}
+
+func (h *StockAdjustmentHandler) GetAdjustment(c echo.Context) error {
+	adjustmentUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	adjustment, err := h.StockAdjustmentService.GetAdjustment(adjustmentUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_adjustment_retrieved_successfully", adjustment)
+}
+
+func (h *StockAdjustmentHandler) ApproveAdjustment(c echo.Context) error {
+	adjustmentUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.ReviewStockAdjustmentRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	adjustment, err := h.StockAdjustmentService.ApproveAdjustment(adjustmentUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_adjustment_approved_successfully", adjustment)
+}
+
+func (h *StockAdjustmentHandler) RejectAdjustment(c echo.Context) error {
+	adjustmentUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.ReviewStockAdjustmentRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	adjustment, err := h.StockAdjustmentService.RejectAdjustment(adjustmentUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_adjustment_rejected_successfully", adjustment)
+}
+
+// UploadPhoto takes a multipart upload with the image in the "photo" field.
+func (h *StockAdjustmentHandler) UploadPhoto(c echo.Context) error {
+	adjustmentUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	fileHeader, err := c.FormFile("photo")
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "photo_file_required")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "photo_file_required")
+	}
+	defer file.Close()
+
+	adjustment, err := h.StockAdjustmentService.UploadPhoto(adjustmentUuid, file, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_adjustment_photo_uploaded_successfully", adjustment)
+}
+
+func (h *StockAdjustmentHandler) GetPhoto(c echo.Context) error {
+	adjustmentUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	path, contentType, err := h.StockAdjustmentService.GetPhotoFile(adjustmentUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+	return c.File(path)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/services"
+)
+
+type StockAlertHandler struct {
+	StockAlertService  *services.StockAlertService
+	UserContextService *services.UserContextService
+}
+
+func NewStockAlertHandler(stockAlertService *services.StockAlertService, userContextService *services.UserContextService) *StockAlertHandler {
+	return &StockAlertHandler{StockAlertService: stockAlertService, UserContextService: userContextService}
+}
+
+func (h *StockAlertHandler) SetReorderPoint(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.SetReorderPointRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	stock, err := h.StockAlertService.SetReorderPoint(*req, outletUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "reorder_point_updated_successfully", stock)
+}
+
+// GetLowStock is the low stock dashboard: every stock at or below its reorder point with the
+// quantity to order.
+func (h *StockAlertHandler) GetLowStock(c echo.Context) error {
+	outletUuid, err := optionalOutletUuid(c)
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	stocks, err := h.StockAlertService.GetLowStock(outletUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "low_stock_retrieved_successfully", stocks)
+}
+
+func (h *StockAlertHandler) GetAlerts(c echo.Context) error {
+	outletUuid, err := optionalOutletUuid(c)
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	alerts, err := h.StockAlertService.GetAlerts(c.QueryParam("status"), outletUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_alerts_retrieved_successfully", alerts)
+}
+
+func (h *StockAlertHandler) AcknowledgeAlert(c echo.Context) error {
+	alertUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	alert, err := h.StockAlertService.AcknowledgeAlert(alertUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_alert_acknowledged_successfully", alert)
+}
+
+// optionalOutletUuid reads the outlet_uuid query parameter, which may be left out.
+func optionalOutletUuid(c echo.Context) (*uuid.UUID, error) {
+	value := c.QueryParam("outlet_uuid")
+	if value == "" {
+		return nil, nil
+	}
+	outletUuid, err := uuid.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return &outletUuid, nil
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/services"
+)
+
+type StockCountHandler struct {
+	StockCountService  *services.StockCountService
+	UserContextService *services.UserContextService
+}
+
+func NewStockCountHandler(stockCountService *services.StockCountService, userContextService *services.UserContextService) *StockCountHandler {
+	return &StockCountHandler{StockCountService: stockCountService, UserContextService: userContextService}
+}
+
+func (h *StockCountHandler) StartCount(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.StartStockCountRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	count, err := h.StockCountService.StartCount(outletUuid, *req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "stock_count_started_successfully", count)
+}
+
+func (h *StockCountHandler) GetCountsByOutlet(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	counts, err := h.StockCountService.GetCountsByOutlet(outletUuid, c.QueryParam("status"), userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_counts_retrieved_successfully", counts)
+}
+
+func (h *StockCountHandler) GetCount(c echo.Context) error {
+	countUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	count, err := h.StockCountService.GetCount(countUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_count_retrieved_successfully", count)
+}
+
+func (h *StockCountHandler) AddEntries(c echo.Context) error {
+	countUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.AddStockCountEntriesRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	count, err := h.StockCountService.AddEntries(countUuid, *req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_count_recorded_successfully", count)
+}
+
+func (h *StockCountHandler) DeleteEntry(c echo.Context) error {
+	countUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+	entryUuid, err := uuid.Parse(c.Param("entry_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	count, err := h.StockCountService.DeleteEntry(countUuid, entryUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_count_entry_deleted_successfully", count)
+}
+
+func (h *StockCountHandler) SubmitCount(c echo.Context) error {
+	countUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	count, err := h.StockCountService.SubmitCount(countUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_count_submitted_successfully", count)
+}
+
+func (h *StockCountHandler) ApproveCount(c echo.Context) error {
+	countUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	count, err := h.StockCountService.ApproveCount(countUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_count_approved_successfully", count)
+}
+
+func (h *StockCountHandler) CancelCount(c echo.Context) error {
+	countUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	count, err := h.StockCountService.CancelCount(countUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_count_cancelled_successfully", count)
+}
+
+// GetVarianceReport returns the variance report as JSON, or as a CSV download with ?format=csv.
+func (h *StockCountHandler) GetVarianceReport(c echo.Context) error {
+	countUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	report, err := h.StockCountService.GetVarianceReport(countUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+
+	if c.QueryParam("format") == "csv" {
+		content, err := stockCountVarianceCSV(report)
+		if err != nil {
+			return JSONError(c, http.StatusInternalServerError, "failed_to_generate_csv")
+		}
+		filename := fmt.Sprintf("stock-count-variance-%s.csv", report.StockCountUuid)
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+		return c.Blob(http.StatusOK, "text/csv", content)
+	}
+
+	return JSONSuccess(c, http.StatusOK, "stock_count_variance_report_generated_successfully", report)
+}
+
+func stockCountVarianceCSV(report *dtos.StockCountVarianceReport) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	formatAmount := func(amount float64) string {
+		return strconv.FormatFloat(amount, 'f', 2, 64)
+	}
+	formatQuantity := func(quantity float64) string {
+		return strconv.FormatFloat(quantity, 'f', -1, 64)
+	}
+
+	writer.Write([]string{"product_name", "variant_name", "sku", "expected_quantity", "counted_quantity", "variance", "unit_price", "variance_value"})
+	for _, row := range report.Rows {
+		counted := ""
+		if row.CountedQuantity != nil {
+			counted = formatQuantity(*row.CountedQuantity)
+		}
+		writer.Write([]string{
+			row.ProductName,
+			row.VariantName,
+			row.Sku,
+			formatQuantity(row.ExpectedQuantity),
+			counted,
+			formatQuantity(row.Variance),
+			formatAmount(row.UnitPrice),
+			formatAmount(row.VarianceValue),
+		})
+	}
+
+	summary := report.Summary
+	writer.Write([]string{})
+	writer.Write([]string{"item_count", "counted_count", "variance_count", "shortage_value", "surplus_value", "net_variance_value"})
+	writer.Write([]string{
+		strconv.Itoa(summary.ItemCount),
+		strconv.Itoa(summary.CountedCount),
+		strconv.Itoa(summary.VarianceCount),
+		formatAmount(summary.ShortageValue),
+		formatAmount(summary.SurplusValue),
+		formatAmount(summary.NetVarianceValue),
+	})
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -66,3 +67,80 @@ func (h *StockHandler) UpdateStock(c echo.Context) error {
 	return JSONSuccess(c, http.StatusOK, "stock_updated_successfully", stock)
 }
 
+
+// GetStockMovements lists the stock movement ledger of an outlet, filtered by ?product_uuid,
+// ?product_variant_uuid, ?movement_type, ?reference_type, ?start_date and ?end_date.
+func (h *StockHandler) GetStockMovements(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	filter := dtos.StockMovementFilter{
+		MovementType:  c.QueryParam("movement_type"),
+		ReferenceType: c.QueryParam("reference_type"),
+	}
+	if productUuidStr := c.QueryParam("product_uuid"); productUuidStr != "" {
+		productUuid, err := uuid.Parse(productUuidStr)
+		if err != nil {
+			return JSONError(c, http.StatusBadRequest, "invalid_product_uuid_format")
+		}
+		filter.ProductUuid = &productUuid
+	}
+	if variantUuidStr := c.QueryParam("product_variant_uuid"); variantUuidStr != "" {
+		variantUuid, err := uuid.Parse(variantUuidStr)
+		if err != nil {
+			return JSONError(c, http.StatusBadRequest, "invalid_product_variant_uuid_format")
+		}
+		filter.ProductVariantUuid = &variantUuid
+	}
+	if startDateStr := c.QueryParam("start_date"); startDateStr != "" {
+		startDate, err := time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			return JSONError(c, http.StatusBadRequest, "invalid_start_date_format")
+		}
+		filter.StartDate = &startDate
+	}
+	if endDateStr := c.QueryParam("end_date"); endDateStr != "" {
+		endDate, err := time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			return JSONError(c, http.StatusBadRequest, "invalid_end_date_format")
+		}
+		filter.EndDate = &endDate
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	movements, err := h.StockService.GetStockMovements(outletUuid, filter, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_movements_retrieved_successfully", movements)
+}
+
+// GetStockAsOf returns the stock of an outlet at the end of ?date, computed from the movement ledger.
+func (h *StockHandler) GetStockAsOf(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	date, err := time.Parse("2006-01-02", c.QueryParam("date"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_date_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	stock, err := h.StockService.GetStockAsOf(outletUuid, date, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_as_of_retrieved_successfully", stock)
+}
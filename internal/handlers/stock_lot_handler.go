@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/services"
+)
+
+type StockLotHandler struct {
+	StockLotService    *services.StockLotService
+	UserContextService *services.UserContextService
+}
+
+func NewStockLotHandler(stockLotService *services.StockLotService, userContextService *services.UserContextService) *StockLotHandler {
+	return &StockLotHandler{StockLotService: stockLotService, UserContextService: userContextService}
+}
+
+func (h *StockLotHandler) GetLotsByOutlet(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	lots, err := h.StockLotService.GetLotsByOutlet(outletUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_lots_retrieved_successfully", lots)
+}
+
+// GetExpiringLots lists the lots expiring within ?days= days (default 7), expired lots included.
+func (h *StockLotHandler) GetExpiringLots(c echo.Context) error {
+	days := 7
+	if value := c.QueryParam("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return JSONError(c, http.StatusBadRequest, "invalid_days_parameter")
+		}
+		days = parsed
+	}
+
+	outletUuid, err := optionalOutletUuid(c)
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	lots, err := h.StockLotService.GetExpiringLots(days, outletUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "expiring_stock_lots_retrieved_successfully", lots)
+}
+
+func (h *StockLotHandler) WriteOffLot(c echo.Context) error {
+	lotUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.WriteOffStockLotRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	lot, err := h.StockLotService.WriteOffLot(lotUuid, *req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_lot_written_off_successfully", lot)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/services"
+)
+
+type StockTransferHandler struct {
+	StockTransferService *services.StockTransferService
+	UserContextService   *services.UserContextService
+}
+
+func NewStockTransferHandler(stockTransferService *services.StockTransferService, userContextService *services.UserContextService) *StockTransferHandler {
+	return &StockTransferHandler{StockTransferService: stockTransferService, UserContextService: userContextService}
+}
+
+func (h *StockTransferHandler) CreateTransfer(c echo.Context) error {
+	req, ok := c.Get("validated_data").(*dtos.StockTransferRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	transfer, err := h.StockTransferService.CreateTransfer(*req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "stock_transfer_created_successfully", transfer)
+}
+
+func (h *StockTransferHandler) UpdateTransfer(c echo.Context) error {
+	transferUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.StockTransferRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	transfer, err := h.StockTransferService.UpdateTransfer(transferUuid, *req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_transfer_updated_successfully", transfer)
+}
+
+func (h *StockTransferHandler) GetTransfer(c echo.Context) error {
+	transferUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	transfer, err := h.StockTransferService.GetTransfer(transferUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_transfer_retrieved_successfully", transfer)
+}
+
+// GetTransfersByOutlet lists an outlet's transfers. The direction query parameter is "outgoing"
+// or "incoming"; without it both are listed.
+func (h *StockTransferHandler) GetTransfersByOutlet(c echo.Context) error {
+	outletUuid, err := uuid.Parse(c.Param("outlet_uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_outlet_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	transfers, err := h.StockTransferService.GetTransfersByOutlet(outletUuid, c.QueryParam("direction"), c.QueryParam("status"), userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_transfers_retrieved_successfully", transfers)
+}
+
+func (h *StockTransferHandler) SendTransfer(c echo.Context) error {
+	transferUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	transfer, err := h.StockTransferService.SendTransfer(transferUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_transfer_sent_successfully", transfer)
+}
+
+func (h *StockTransferHandler) ReceiveTransfer(c echo.Context) error {
+	transferUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.ReceiveStockTransferRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	transfer, err := h.StockTransferService.ReceiveTransfer(transferUuid, *req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_transfer_received_successfully", transfer)
+}
+
+func (h *StockTransferHandler) CancelTransfer(c echo.Context) error {
+	transferUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	transfer, err := h.StockTransferService.CancelTransfer(transferUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "stock_transfer_cancelled_successfully", transfer)
+}
@@ -9,16 +9,18 @@ import (
 )
 
 type TsmHandler struct {
-	TsmService         *services.TsmService
-	UserContextService *services.UserContextService
-	UserPaymentService *services.UserPaymentService
+	TsmService          *services.TsmService
+	UserContextService  *services.UserContextService
+	UserPaymentService  *services.UserPaymentService
+	OrderPaymentService *services.OrderPaymentService
 }
 
-func NewTsmHandler(tsmService *services.TsmService, userContextService *services.UserContextService, userPaymentService *services.UserPaymentService) *TsmHandler {
+func NewTsmHandler(tsmService *services.TsmService, userContextService *services.UserContextService, userPaymentService *services.UserPaymentService, orderPaymentService *services.OrderPaymentService) *TsmHandler {
 	return &TsmHandler{
-		TsmService:         tsmService,
-		UserContextService: userContextService,
-		UserPaymentService: userPaymentService,
+		TsmService:          tsmService,
+		UserContextService:  userContextService,
+		UserPaymentService:  userPaymentService,
+		OrderPaymentService: orderPaymentService,
 	}
 }
 
@@ -75,8 +77,14 @@ func (h *TsmHandler) Callback(c echo.Context) error {
 		return JSONError(c, http.StatusBadRequest, "invalid_request_body")
 	}
 
-	if err := h.TsmService.HandleCallback(req); err != nil {
-		return JSONError(c, http.StatusInternalServerError, err.Error())
+	rawBody, _ := c.Get("raw_body").([]byte)
+	if err := h.OrderPaymentService.HandleProviderCallback("TSM", services.PaymentCallback{
+		Payload:  &req,
+		RawBody:  rawBody,
+		Headers:  c.Request().Header,
+		SourceIP: c.RealIP(),
+	}); err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
 	}
 
 	return JSONSuccess(c, http.StatusOK, "callback_processed_successfully", nil)
@@ -67,6 +67,11 @@ func (h *UserPaymentHandler) ActivateUserPayment(c echo.Context) error {
 				"message": "TSM registration required",
 				"route":   "/tsm/register",
 			})
+		} else if errors.Is(err, services.ErrQrisRegistrationRequired) {
+			return c.JSON(http.StatusPreconditionRequired, map[string]string{
+				"message": "QRIS registration required",
+				"route":   "/qris/register",
+			})
 		}
 		return JSONError(c, MapErrorToStatusCode(err), err.Error())
 	}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/services"
+)
+
+type WebhookHandler struct {
+	WebhookService     *services.WebhookService
+	UserContextService *services.UserContextService
+}
+
+func NewWebhookHandler(webhookService *services.WebhookService, userContextService *services.UserContextService) *WebhookHandler {
+	return &WebhookHandler{WebhookService: webhookService, UserContextService: userContextService}
+}
+
+func (h *WebhookHandler) CreateEndpoint(c echo.Context) error {
+	req, ok := c.Get("validated_data").(*dtos.WebhookEndpointRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	endpoint, err := h.WebhookService.CreateEndpoint(req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusCreated, "webhook_endpoint_created_successfully", endpoint)
+}
+
+func (h *WebhookHandler) GetEndpoints(c echo.Context) error {
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	endpoints, err := h.WebhookService.GetEndpoints(userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "webhook_endpoints_retrieved_successfully", endpoints)
+}
+
+func (h *WebhookHandler) UpdateEndpoint(c echo.Context) error {
+	endpointUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	req, ok := c.Get("validated_data").(*dtos.WebhookEndpointRequest)
+	if !ok {
+		return JSONError(c, http.StatusInternalServerError, "failed_to_get_validated_request")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	endpoint, err := h.WebhookService.UpdateEndpoint(endpointUuid, req, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "webhook_endpoint_updated_successfully", endpoint)
+}
+
+func (h *WebhookHandler) DeleteEndpoint(c echo.Context) error {
+	endpointUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	if err := h.WebhookService.DeleteEndpoint(endpointUuid, userID); err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "webhook_endpoint_deleted_successfully", nil)
+}
+
+// PingEndpoint sends a signed test event to the endpoint right away and returns the delivery result.
+func (h *WebhookHandler) PingEndpoint(c echo.Context) error {
+	endpointUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	delivery, err := h.WebhookService.PingEndpoint(endpointUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "webhook_ping_sent_successfully", delivery)
+}
+
+func (h *WebhookHandler) GetDeliveries(c echo.Context) error {
+	endpointUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	deliveries, err := h.WebhookService.GetDeliveries(endpointUuid, c.QueryParam("status"), userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "webhook_deliveries_retrieved_successfully", deliveries)
+}
+
+func (h *WebhookHandler) RedeliverDelivery(c echo.Context) error {
+	deliveryUuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		return JSONError(c, http.StatusBadRequest, "invalid_uuid_format")
+	}
+
+	userID, err := h.UserContextService.GetUserIDFromEchoContext(c)
+	if err != nil {
+		return JSONError(c, http.StatusUnauthorized, err.Error())
+	}
+
+	delivery, err := h.WebhookService.RedeliverDelivery(deliveryUuid, userID)
+	if err != nil {
+		return JSONError(c, MapErrorToStatusCode(err), err.Error())
+	}
+	return JSONSuccess(c, http.StatusOK, "webhook_redelivered_successfully", delivery)
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PreserveRawBody keeps the exact request body in the context so webhook handlers can
+// verify signatures after ValidationMiddleware has bound the JSON.
+func PreserveRawBody(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var bodyBytes []byte
+		if c.Request().Body != nil {
+			bodyBytes, _ = ioutil.ReadAll(c.Request().Body)
+			// Reset body so the next handlers can still read it
+			c.Request().Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
+		}
+		c.Set("raw_body", bodyBytes)
+		return next(c)
+	}
+}
@@ -0,0 +1,13 @@
+package models
+
+// Customer is a regular of the owner's outlets. Customers can keep a prepaid wallet balance and
+// receive refunds as store credit.
+type Customer struct {
+	BaseModel
+	Name          string  `gorm:"type:varchar(255);not null" json:"name"`
+	Phone         string  `gorm:"type:varchar(50);index" json:"phone"`
+	Email         string  `gorm:"type:varchar(255)" json:"email"`
+	WalletBalance float64 `gorm:"not null;default:0;check:wallet_balance >= 0" json:"wallet_balance"` // Always the sum of the wallet ledger
+	UserID        uint    `gorm:"not null;index" json:"user_id"`
+	User          User    `json:"user"`
+}
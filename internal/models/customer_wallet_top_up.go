@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Customer wallet top-up statuses
+const (
+	CustomerWalletTopUpStatusPending  = "pending"
+	CustomerWalletTopUpStatusCredited = "credited"
+)
+
+// CustomerWalletTopUp is a wallet top-up sold as a one line order, so it can be paid with any
+// payment method. The wallet is credited once the order is fully paid.
+type CustomerWalletTopUp struct {
+	BaseModel
+	CustomerID uint       `gorm:"not null;index" json:"customer_id"`
+	Customer   Customer   `json:"customer"`
+	OrderID    uint       `gorm:"not null;uniqueIndex" json:"order_id"`
+	Order      Order      `json:"order"`
+	Amount     float64    `gorm:"not null" json:"amount"`
+	Status     string     `gorm:"type:varchar(20);default:pending;index" json:"status"`
+	CreditedAt *time.Time `json:"credited_at"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+}
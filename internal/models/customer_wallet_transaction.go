@@ -0,0 +1,25 @@
+package models
+
+// Customer wallet transaction types
+const (
+	CustomerWalletTransactionTopUp        = "top_up"
+	CustomerWalletTransactionSpend        = "spend"
+	CustomerWalletTransactionRefundCredit = "refund_credit"
+	CustomerWalletTransactionAdjustment   = "adjustment"
+)
+
+// CustomerWalletTransaction is an entry of a customer's wallet ledger. Entries are never changed or
+// removed; mistakes are corrected with an adjustment.
+type CustomerWalletTransaction struct {
+	BaseModel
+	CustomerID     uint          `gorm:"not null;index" json:"customer_id"`
+	Customer       Customer      `json:"customer"`
+	Type           string        `gorm:"type:varchar(20);not null;index" json:"type"`
+	Amount         float64       `gorm:"not null" json:"amount"` // Positive credits the wallet, negative debits it
+	BalanceAfter   float64       `gorm:"not null" json:"balance_after"`
+	Reason         string        `gorm:"type:varchar(255)" json:"reason"`
+	OrderID        *uint         `gorm:"index" json:"order_id,omitempty"` // Top-up order
+	Order          *Order        `json:"order,omitempty"`
+	OrderPaymentID *uint         `gorm:"index" json:"order_payment_id,omitempty"` // Payment spent from or refunded to the wallet
+	OrderPayment   *OrderPayment `json:"order_payment,omitempty"`
+}
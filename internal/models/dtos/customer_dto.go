@@ -0,0 +1,56 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CustomerRequest struct {
+	Name  string `json:"name" validate:"required,max=255"`
+	Phone string `json:"phone" validate:"max=50"`
+	Email string `json:"email" validate:"omitempty,email,max=255"`
+}
+
+type CustomerResponse struct {
+	Uuid          uuid.UUID `json:"uuid"`
+	Name          string    `json:"name"`
+	Phone         string    `json:"phone"`
+	Email         string    `json:"email"`
+	WalletBalance float64   `json:"wallet_balance"`
+	CreatedAt     string    `json:"created_at"`
+}
+
+// AdjustCustomerWalletRequest corrects a wallet balance. A negative Amount debits the wallet.
+type AdjustCustomerWalletRequest struct {
+	Amount float64 `json:"amount" validate:"required"`
+	Reason string  `json:"reason" validate:"required,max=255"`
+}
+
+// TopUpCustomerWalletRequest sells a wallet top-up at an outlet, paid with any payment method.
+type TopUpCustomerWalletRequest struct {
+	OutletUuid      uuid.UUID `json:"outlet_uuid" validate:"required"`
+	Amount          float64   `json:"amount" validate:"required,gt=0"`
+	PaymentMethodID uint      `json:"payment_method_id" validate:"required"`
+}
+
+type CustomerWalletTransactionResponse struct {
+	Uuid             uuid.UUID  `json:"uuid"`
+	Type             string     `json:"type"`
+	Amount           float64    `json:"amount"`
+	BalanceAfter     float64    `json:"balance_after"`
+	Reason           string     `json:"reason"`
+	OrderUuid        *uuid.UUID `json:"order_uuid,omitempty"`
+	OrderPaymentUuid *uuid.UUID `json:"order_payment_uuid,omitempty"`
+	CreatedAt        string     `json:"created_at"`
+}
+
+type CustomerWalletTopUpResponse struct {
+	Uuid         uuid.UUID             `json:"uuid"`
+	CustomerUuid uuid.UUID             `json:"customer_uuid"`
+	OrderUuid    uuid.UUID             `json:"order_uuid"`
+	Amount       float64               `json:"amount"`
+	Status       string                `json:"status"`
+	CreditedAt   *time.Time            `json:"credited_at"`
+	Payment      *OrderPaymentResponse `json:"payment"`
+}
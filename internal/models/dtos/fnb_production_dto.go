@@ -8,6 +8,7 @@ type FNBProductionRequest struct {
 }
 
 type FNBProductionResponse struct {
+	ProductionRunUuid  uuid.UUID `json:"production_run_uuid"`
 	FNBMainProductUuid uuid.UUID `json:"fnb_main_product_uuid"`
 	ProductName        string    `json:"product_name"`
 	QuantityProduced   float64   `json:"quantity_produced"`
@@ -0,0 +1,73 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateMarketplaceChannelRequest struct {
+	Provider          string  `json:"provider" validate:"required"`
+	Name              string  `json:"name" validate:"required"`
+	CommissionPercent float64 `json:"commission_percent" validate:"gte=0,lte=100"`
+	StatusCallbackURL string  `json:"status_callback_url" validate:"omitempty,url"`
+}
+
+type MarketplaceChannelResponse struct {
+	Uuid              uuid.UUID `json:"uuid"`
+	OutletUuid        uuid.UUID `json:"outlet_uuid"`
+	Provider          string    `json:"provider"`
+	Name              string    `json:"name"`
+	CommissionPercent float64   `json:"commission_percent"`
+	StatusCallbackURL string    `json:"status_callback_url,omitempty"`
+	IsActive          bool      `json:"is_active"`
+	WebhookURL        string    `json:"webhook_url"`
+	WebhookSecret     string    `json:"webhook_secret,omitempty"` // Only returned when the channel is created
+}
+
+type CreateMarketplaceItemMappingRequest struct {
+	ExternalItemID     string    `json:"external_item_id" validate:"required"`
+	ProductUuid        uuid.UUID `json:"product_uuid,omitempty"`
+	ProductVariantUuid uuid.UUID `json:"product_variant_uuid,omitempty"`
+}
+
+type MarketplaceItemMappingResponse struct {
+	Uuid               uuid.UUID `json:"uuid"`
+	ExternalItemID     string    `json:"external_item_id"`
+	ProductUuid        uuid.UUID `json:"product_uuid,omitempty"`
+	ProductVariantUuid uuid.UUID `json:"product_variant_uuid,omitempty"`
+	Name               string    `json:"name"`
+}
+
+// MarketplaceWebhookRequest is the normalized payload marketplaces (or their relay) post to the webhook.
+type MarketplaceWebhookRequest struct {
+	Event           string                          `json:"event" validate:"required,oneof=order.created order.cancelled"`
+	ExternalOrderID string                          `json:"external_order_id" validate:"required"`
+	CustomerName    string                          `json:"customer_name"`
+	Items           []MarketplaceWebhookItemRequest `json:"items" validate:"required_if=Event order.created,dive"`
+}
+
+type MarketplaceWebhookItemRequest struct {
+	ExternalItemID string  `json:"external_item_id" validate:"required"`
+	Quantity       int     `json:"quantity" validate:"required,gt=0"`
+	Price          float64 `json:"price" validate:"gte=0"` // Unit price charged on the marketplace
+}
+
+type UpdateMarketplaceOrderStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=accepted preparing ready completed cancelled"`
+}
+
+type MarketplaceOrderResponse struct {
+	Uuid              uuid.UUID `json:"uuid"`
+	ChannelUuid       uuid.UUID `json:"channel_uuid"`
+	Provider          string    `json:"provider"`
+	ExternalOrderID   string    `json:"external_order_id"`
+	OrderUuid         uuid.UUID `json:"order_uuid"`
+	Status            string    `json:"status"`
+	CustomerName      string    `json:"customer_name"`
+	GrossAmount       float64   `json:"gross_amount"`
+	CommissionPercent float64   `json:"commission_percent"`
+	CommissionAmount  float64   `json:"commission_amount"`
+	NetAmount         float64   `json:"net_amount"`
+	CreatedAt         time.Time `json:"created_at"`
+}
@@ -49,6 +49,7 @@ type OrderPaymentDetailResponse struct {
 	PaymentMethod   string     `json:"payment_method"`
 	PaymentChannel  string     `json:"payment_channel"`
 	IsPaid          bool       `json:"is_paid"` // This might be derived or from a new field in OrderPayment model
+	Status          string     `json:"status"`
 	ReferenceID     string     `json:"reference_id"`
 	CreatedAt       string     `json:"created_at"`
 	PaidAt          *time.Time `json:"paid_at"` // Use pointer for nullable timestamp
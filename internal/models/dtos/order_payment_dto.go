@@ -7,12 +7,13 @@ import (
 )
 
 type CreateOrderPaymentRequest struct {
-	OrderUuid       uuid.UUID `json:"order_uuid" validate:"required"`
-	PaymentMethodID uint      `json:"payment_method_id" validate:"required"`
-	OrderItemIDs    []uint    `json:"order_item_ids" validate:"required,min=1"`
-	CustomerName    string    `json:"customer_name"`
-	CustomerEmail   string    `json:"customer_email"`
-	CustomerPhone   string    `json:"customer_phone"`
+	OrderUuid       uuid.UUID  `json:"order_uuid" validate:"required"`
+	PaymentMethodID uint       `json:"payment_method_id" validate:"required"`
+	OrderItemIDs    []uint     `json:"order_item_ids" validate:"required,min=1"`
+	CustomerName    string     `json:"customer_name"`
+	CustomerEmail   string     `json:"customer_email"`
+	CustomerPhone   string     `json:"customer_phone"`
+	CustomerUuid    *uuid.UUID `json:"customer_uuid"` // Customer whose wallet pays, for wallet payment methods
 }
 
 type OrderPaymentResponse struct {
@@ -21,6 +22,8 @@ type OrderPaymentResponse struct {
 	PaymentMethodID uint       `json:"payment_method_id"`
 	PaymentName     string     `json:"payment_name"`
 	AmountPaid      float64    `json:"amount_paid"`
+	RefundedAmount  float64    `json:"refunded_amount"`
+	SurchargeAmount float64    `json:"surcharge_amount"`
 	CustomerName    string     `json:"customer_name"`
 	CustomerEmail   string     `json:"customer_email"`
 	CustomerPhone   string     `json:"customer_phone"`
@@ -29,4 +32,108 @@ type OrderPaymentResponse struct {
 	IsPaid          bool       `json:"is_paid"` // This might be derived or from a new field in OrderPayment model
 	PaidAt          *time.Time `json:"paid_at"` // Use pointer for nullable timestamp
 	Extra           interface{} `json:"extra,omitempty"`
+	Status          string     `json:"status"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	ExpiredAt       *time.Time `json:"expired_at"`
+	FailedAt        *time.Time `json:"failed_at"`
+	CancelledAt     *time.Time `json:"cancelled_at"`
+	RefundedAt      *time.Time `json:"refunded_at"`
+	ReviewNote      string     `json:"review_note,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at"`
+}
+
+// RetryOrderPaymentRequest pays the items of an unsuccessful payment again, optionally with another method.
+type RetryOrderPaymentRequest struct {
+	PaymentMethodID uint       `json:"payment_method_id" validate:"required"`
+	CustomerName    string     `json:"customer_name"`
+	CustomerEmail   string     `json:"customer_email"`
+	CustomerPhone   string     `json:"customer_phone"`
+	CustomerUuid    *uuid.UUID `json:"customer_uuid"`
+}
+
+// CreateOrderPaymentRefundRequest refunds part of a paid payment, or everything still refundable
+// when Amount is left out. With StoreCreditCustomerUuid the refund is credited to that customer's
+// wallet instead of going back through the gateway.
+type CreateOrderPaymentRefundRequest struct {
+	Amount                  float64    `json:"amount" validate:"omitempty,gt=0"`
+	Reason                  string     `json:"reason" validate:"required,max=255"`
+	StoreCreditCustomerUuid *uuid.UUID `json:"store_credit_customer_uuid"`
+}
+
+type OrderPaymentRefundResponse struct {
+	Uuid             uuid.UUID   `json:"uuid"`
+	OrderPaymentUuid uuid.UUID   `json:"order_payment_uuid"`
+	Amount           float64     `json:"amount"`
+	Reason           string      `json:"reason"`
+	Status           string      `json:"status"`
+	ReferenceID      string      `json:"reference_id"`
+	FailureReason    string      `json:"failure_reason,omitempty"`
+	Extra            interface{} `json:"extra,omitempty"`
+	RefundedAt       *time.Time  `json:"refunded_at"`
+	FailedAt         *time.Time  `json:"failed_at"`
+	CreatedAt        string      `json:"created_at"`
+}
+
+// FakePaymentCallbackRequest simulates a gateway notification for the fake payment provider.
+type FakePaymentCallbackRequest struct {
+	ReferenceID string `json:"reference_id" validate:"required"`
+	Status      string `json:"status" validate:"required,oneof=paid failed"`
+}
+
+// AttachPaymentProofRequest attaches a link to a proof of payment. Images are uploaded as
+// multipart form data instead.
+type AttachPaymentProofRequest struct {
+	ProofURL string `json:"proof_url" validate:"required,url,max=500"`
+	Note     string `json:"note" validate:"max=500"`
+}
+
+type OrderPaymentProofResponse struct {
+	Uuid             uuid.UUID `json:"uuid"`
+	OrderPaymentUuid uuid.UUID `json:"order_payment_uuid"`
+	ContentType      string    `json:"content_type,omitempty"`
+	URL              string    `json:"url"` // The attached link, or the download endpoint of an uploaded file
+	Note             string    `json:"note,omitempty"`
+	CreatedAt        string    `json:"created_at"`
+}
+
+type ConfirmTransferRequest struct {
+	Note string `json:"note" validate:"max=500"`
+}
+
+type RejectTransferRequest struct {
+	Note string `json:"note" validate:"required,max=500"`
+}
+
+// PendingTransferResponse is a manual transfer waiting for a manager's review.
+type PendingTransferResponse struct {
+	OrderPaymentResponse
+	Proofs []OrderPaymentProofResponse `json:"proofs"`
+}
+
+// CreatePaymentLinkRequest creates a payment link for everything still unpaid on an order.
+type CreatePaymentLinkRequest struct {
+	PaymentMethodID uint   `json:"payment_method_id"` // An iPaymu hosted payment method; the first active one when left out
+	CustomerName    string `json:"customer_name" validate:"required,max=255"`
+	CustomerEmail   string `json:"customer_email" validate:"required,email,max=255"`
+	CustomerPhone   string `json:"customer_phone" validate:"required,max=20"`
+	ExpiresInHours  int    `json:"expires_in_hours" validate:"omitempty,min=1,max=168"`
+	SendEmail       bool   `json:"send_email"`
+}
+
+// SendPaymentLinkRequest emails a payment link, to the customer of its payment when Email is left out.
+type SendPaymentLinkRequest struct {
+	Email string `json:"email" validate:"omitempty,email,max=255"`
+}
+
+type PaymentLinkResponse struct {
+	Uuid             uuid.UUID  `json:"uuid"`
+	OrderUuid        uuid.UUID  `json:"order_uuid"`
+	OrderPaymentUuid uuid.UUID  `json:"order_payment_uuid"`
+	URL              string     `json:"url"`
+	Amount           float64    `json:"amount"`
+	Status           string     `json:"status"` // active, paid, expired or cancelled
+	ExpiresAt        time.Time  `json:"expires_at"`
+	SentTo           string     `json:"sent_to,omitempty"`
+	SentAt           *time.Time `json:"sent_at"`
+	CreatedAt        string     `json:"created_at"`
 }
@@ -3,21 +3,24 @@ package dtos
 import "github.com/google/uuid"
 
 type OutletCreateRequest struct {
-	Name    string `json:"name" validate:"required"`
-	Address string `json:"address" validate:"required"`
-	Type    string `json:"type" validate:"required"`
+	Name         string `json:"name" validate:"required"`
+	Address      string `json:"address" validate:"required"`
+	Type         string `json:"type" validate:"required"`
+	ReserveStock bool   `json:"reserve_stock"`
 }
 
 type OutletUpdateRequest struct {
-	Name    string `json:"name" validate:"required"`
-	Address string `json:"address" validate:"required"`
-	Type    string `json:"type" validate:"required"`
+	Name         string `json:"name" validate:"required"`
+	Address      string `json:"address" validate:"required"`
+	Type         string `json:"type" validate:"required"`
+	ReserveStock *bool  `json:"reserve_stock"` // Left out keeps the current setting
 }
 
 type OutletResponse struct {
-	ID      uint      `json:"id"`
-	Uuid    uuid.UUID `json:"uuid"`
-	Name    string    `json:"name"`
-	Address string    `json:"address"`
-	Type    string    `json:"type"`
+	ID           uint      `json:"id"`
+	Uuid         uuid.UUID `json:"uuid"`
+	Name         string    `json:"name"`
+	Address      string    `json:"address"`
+	Type         string    `json:"type"`
+	ReserveStock bool      `json:"reserve_stock"`
 }
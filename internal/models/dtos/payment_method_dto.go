@@ -1,14 +1,45 @@
 package dtos
 
 type PaymentMethodRequest struct {
-	Name     string `json:"name"`
-	Type     string `json:"type"`
-	IsActive *bool  `json:"is_active"`
+	Name           string `json:"name" validate:"required,max=255"`
+	Type           string `json:"type" validate:"required,max=255"`
+	PaymentMethod  string `json:"payment_method" validate:"max=255"`
+	PaymentChannel string `json:"payment_channel" validate:"max=255"`
+	Issuer         string `json:"issuer" validate:"required,max=255"`
+	IsActive       *bool  `json:"is_active"`
 }
 
 type PaymentMethodResponse struct {
-	ID       uint   `json:"id"`
-	Name     string `json:"name"`
-	Type     string `json:"type"`
-	IsActive bool   `json:"is_active"`
+	ID             uint   `json:"id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	PaymentMethod  string `json:"payment_method"`
+	PaymentChannel string `json:"payment_channel"`
+	Issuer         string `json:"issuer"`
+	IsActive       bool   `json:"is_active"`
+}
+
+type OutletPaymentMethodRequest struct {
+	PaymentMethodID uint    `json:"payment_method_id" validate:"required"`
+	DisplayOrder    int     `json:"display_order" validate:"gte=0"`
+	SurchargeType   string  `json:"surcharge_type" validate:"omitempty,oneof=fixed percentage"`
+	SurchargeValue  float64 `json:"surcharge_value" validate:"gte=0"`
+}
+
+// SetOutletPaymentMethodsRequest replaces the payment methods enabled for an outlet. An empty list
+// lets the outlet accept every active payment method again.
+type SetOutletPaymentMethodsRequest struct {
+	PaymentMethods []OutletPaymentMethodRequest `json:"payment_methods" validate:"dive"`
+}
+
+type OutletPaymentMethodResponse struct {
+	PaymentMethodID uint    `json:"payment_method_id"`
+	Name            string  `json:"name"`
+	Type            string  `json:"type"`
+	PaymentMethod   string  `json:"payment_method"`
+	PaymentChannel  string  `json:"payment_channel"`
+	Issuer          string  `json:"issuer"`
+	DisplayOrder    int     `json:"display_order"`
+	SurchargeType   string  `json:"surcharge_type,omitempty"`
+	SurchargeValue  float64 `json:"surcharge_value"`
 }
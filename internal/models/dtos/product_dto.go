@@ -3,9 +3,10 @@ package dtos
 import "github.com/google/uuid"
 
 type ProductVariantCreateRequest struct {
-	Name  string  `json:"name" validate:"required"`
-	SKU   string  `json:"sku" validate:"required"`
-	Price float64 `json:"price" validate:"required"`
+	Name      string  `json:"name" validate:"required"`
+	SKU       string  `json:"sku" validate:"required"`
+	Price     float64 `json:"price" validate:"required"`
+	CostPrice float64 `json:"cost_price" validate:"gte=0"`
 }
 
 type ProductVariantUpdateRequest struct {
@@ -13,20 +14,26 @@ type ProductVariantUpdateRequest struct {
 	Name  string  `json:"name" validate:"required"`
 	SKU   string  `json:"sku" validate:"required"`
 	Price float64 `json:"price" validate:"required"`
+	// CostPrice overrides the moving-average cost. Leave it out to keep the current cost.
+	CostPrice *float64 `json:"cost_price,omitempty" validate:"omitempty,gte=0"`
 }
 
 type ProductVariantResponse struct {
-	ID    uint      `json:"id"`
-	Uuid  uuid.UUID `json:"uuid"`
-	Name  string    `json:"name"`
-	SKU   string    `json:"sku"`
-	Price float64   `json:"price"`
+	ID        uint      `json:"id"`
+	Uuid      uuid.UUID `json:"uuid"`
+	Name      string    `json:"name"`
+	SKU       string    `json:"sku"`
+	Price     float64   `json:"price"`
+	CostPrice float64   `json:"cost_price"`
 }
 
 type ProductCreateRequest struct {
 	Name        string                        `json:"name" validate:"required"`
 	Description string                        `json:"description,omitempty"`
 	Price       float64                       `json:"price"`
+	CostPrice   float64                       `json:"cost_price" validate:"gte=0"`
+	Category    string                        `json:"category,omitempty" validate:"omitempty,max=100"`
+	BaseUnit    string                        `json:"base_unit,omitempty" validate:"omitempty,max=20"` // Defaults to pcs
 	SKU         string                        `json:"sku,omitempty"`
 	Type        string                        `json:"type" validate:"required,oneof=retail_item fnb_main_product fnb_component add_on"`
 	Variants    []ProductVariantCreateRequest `json:"variants,omitempty"`
@@ -36,6 +43,9 @@ type ProductUpdateRequest struct {
 	Name        string                        `json:"name" validate:"required"`
 	Description string                        `json:"description,omitempty"`
 	Price       float64                       `json:"price"`
+	CostPrice   *float64                      `json:"cost_price,omitempty" validate:"omitempty,gte=0"` // Leave out to keep the moving-average cost
+	Category    string                        `json:"category,omitempty" validate:"omitempty,max=100"`
+	BaseUnit    string                        `json:"base_unit,omitempty" validate:"omitempty,max=20"` // Defaults to pcs
 	SKU         string                        `json:"sku,omitempty"`
 	Type        string                        `json:"type" validate:"required,oneof=retail_item fnb_main_product fnb_component add_on"`
 	Variants    []ProductVariantUpdateRequest `json:"variants,omitempty"`
@@ -47,6 +57,9 @@ type ProductResponse struct {
 	Name        string                   `json:"name"`
 	Description string                   `json:"description,omitempty"`
 	Price       float64                  `json:"price"`
+	CostPrice   float64                  `json:"cost_price"`
+	Category    string                   `json:"category,omitempty"`
+	BaseUnit    string                   `json:"base_unit"`
 	SKU         string                   `json:"sku,omitempty"`
 	Type        string                   `json:"type"`
 	Variants    []ProductVariantResponse `json:"variants,omitempty"`
@@ -57,18 +70,24 @@ type ProductDetailResponse struct {
 	Name        string                   `json:"name"`
 	Description string                   `json:"description,omitempty"`
 	Price       float64                  `json:"price"`
+	CostPrice   float64                  `json:"cost_price"`
+	RecipeCost  *float64                 `json:"recipe_cost,omitempty"` // Cost rolled up from the recipe components, for fnb_main_product
+	Category    string                   `json:"category,omitempty"`
+	BaseUnit    string                   `json:"base_unit"`
 	SKU         string                   `json:"sku,omitempty"`
 	Type        string                   `json:"type"`
 	Variants    []ProductVariantResponse `json:"variants,omitempty"`
 	Recipes     []RecipeResponse         `json:"recipes,omitempty"`
 	AddOns      []ProductAddOnResponse   `json:"add_ons,omitempty"`
+	Units       []ProductUnitResponse    `json:"units,omitempty"`
 }
 
 type ProductOutletResponse struct {
-	ProductUuid uuid.UUID `json:"product_uuid"`
-	ProductName string    `json:"product_name"`
-	ProductSku  string    `json:"product_sku"`
-	Price       float64   `json:"price"`
-	Type        string    `json:"type"`
-	Quantity    float64   `json:"quantity"` // Stock quantity at the outlet
+	ProductUuid       uuid.UUID `json:"product_uuid"`
+	ProductName       string    `json:"product_name"`
+	ProductSku        string    `json:"product_sku"`
+	Price             float64   `json:"price"`
+	Type              string    `json:"type"`
+	Quantity          float64   `json:"quantity"`           // Stock quantity at the outlet
+	AvailableQuantity float64   `json:"available_quantity"` // Quantity not reserved by unpaid orders
 }
@@ -0,0 +1,15 @@
+package dtos
+
+import "github.com/google/uuid"
+
+type ProductUnitRequest struct {
+	Name             string  `json:"name" validate:"required,max=20"`
+	ConversionFactor float64 `json:"conversion_factor" validate:"required,gt=0"` // Base units in one of this unit
+}
+
+type ProductUnitResponse struct {
+	Uuid             uuid.UUID `json:"uuid"`
+	Name             string    `json:"name"`
+	ConversionFactor float64   `json:"conversion_factor"`
+	BaseUnit         string    `json:"base_unit"`
+}
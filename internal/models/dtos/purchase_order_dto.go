@@ -12,7 +12,8 @@ type PurchaseItemRequest struct {
 	ProductUuid        uuid.UUID `json:"product_uuid,omitempty"`
 	ProductVariantUuid uuid.UUID `json:"product_variant_uuid,omitempty"`
 	Quantity           int       `json:"quantity" validate:"required,gt=0"`
-	Price              float64   `json:"price" validate:"required"`
+	Price              float64   `json:"price" validate:"required"`                  // Price of one unit
+	Unit               string    `json:"unit,omitempty" validate:"omitempty,max=20"` // Defaults to the product's base unit
 }
 
 type PurchaseOrderResponse struct {
@@ -26,3 +27,15 @@ type PurchaseOrderResponse struct {
 	TotalAmount  float64   `json:"total_amount"`
 	Status       string    `json:"status"`
 }
+
+// ReceivePurchaseOrderRequest carries the lot details of the received items. Items left out are
+// received without a lot number or expiry date.
+type ReceivePurchaseOrderRequest struct {
+	Items []ReceivePurchaseOrderItemRequest `json:"items" validate:"omitempty,dive"`
+}
+
+type ReceivePurchaseOrderItemRequest struct {
+	PurchaseOrderItemUuid uuid.UUID `json:"purchase_order_item_uuid" validate:"required"`
+	LotNumber             string    `json:"lot_number" validate:"omitempty,max=100"`
+	ExpiryDate            string    `json:"expiry_date" validate:"omitempty,datetime=2006-01-02"`
+}
@@ -0,0 +1,12 @@
+package dtos
+
+type QrisRegisterRequest struct {
+	Payload string `json:"payload" validate:"required"`
+}
+
+type UserQrisResponse struct {
+	Payload      string `json:"payload"`
+	MerchantName string `json:"merchant_name"`
+	MerchantCity string `json:"merchant_city"`
+	NMID         string `json:"nmid"`
+}
@@ -6,16 +6,20 @@ type CreateRecipeRequest struct {
 	MainProductUuid uuid.UUID `json:"main_product_uuid" validate:"required"`
 	ComponentUuid   uuid.UUID `json:"component_uuid" validate:"required"`
 	Quantity        float64   `json:"quantity" validate:"required"`
+	Unit            string    `json:"unit,omitempty" validate:"omitempty,max=20"` // Defaults to the component's base unit
 }
 
 type UpdateRecipeRequest struct {
 	MainProductUuid uuid.UUID `json:"main_product_uuid" validate:"required"`
 	ComponentUuid   uuid.UUID `json:"component_uuid" validate:"required"`
 	Quantity        float64   `json:"quantity" validate:"required"`
+	Unit            string    `json:"unit,omitempty" validate:"omitempty,max=20"` // Defaults to the component's base unit
 }
 
 type RecipeResponse struct {
 	Uuid          uuid.UUID `json:"uuid"`
 	ComponentName string    `json:"component_name"`
 	Quantity      float64   `json:"quantity"`
+	Unit          string    `json:"unit"`
+	BaseQuantity  float64   `json:"base_quantity"` // Quantity in the component's base unit
 }
@@ -1,5 +1,7 @@
 package dtos
 
+import "time"
+
 type StockReportResponse struct {
 	ProductName string  `json:"product_name"`
 	ProductSku  string  `json:"product_sku,omitempty"`
@@ -7,3 +9,89 @@ type StockReportResponse struct {
 	VariantSku  string  `json:"variant_sku,omitempty"`
 	Quantity    float64 `json:"quantity"`
 }
+
+// PaymentReconciliationRow compares one gateway payment with what the gateway logged and settled.
+type PaymentReconciliationRow struct {
+	OrderPaymentUuid string     `json:"order_payment_uuid"`
+	OrderUuid        string     `json:"order_uuid"`
+	PaymentName      string     `json:"payment_name"`
+	ReferenceID      string     `json:"reference_id"`
+	PaidAt           *time.Time `json:"paid_at"`
+	AmountPaid       float64    `json:"amount_paid"`
+	GatewayAmount    float64    `json:"gateway_amount"`
+	Fee              float64    `json:"fee"`
+	FeeDirection     string     `json:"fee_direction"`
+	ExpectedNet      float64    `json:"expected_net"`
+	SettledAmount    float64    `json:"settled_amount"`
+	SettledAt        *time.Time `json:"settled_at"`
+	Settled          bool       `json:"settled"`
+	Mismatches       []string   `json:"mismatches"`
+}
+
+type PaymentReconciliationSummary struct {
+	PaymentCount    int     `json:"payment_count"`
+	GrossAmount     float64 `json:"gross_amount"`
+	GatewayFees     float64 `json:"gateway_fees"`
+	NetSettled      float64 `json:"net_settled"`
+	UnsettledCount  int     `json:"unsettled_count"`
+	UnsettledAmount float64 `json:"unsettled_amount"`
+	MismatchCount   int     `json:"mismatch_count"`
+}
+
+type PaymentReconciliationReport struct {
+	OutletUuid string                       `json:"outlet_uuid"`
+	StartDate  string                       `json:"start_date"`
+	EndDate    string                       `json:"end_date"`
+	Summary    PaymentReconciliationSummary `json:"summary"`
+	Payments   []PaymentReconciliationRow   `json:"payments"`
+}
+
+type GrossMarginRow struct {
+	Uuid          string  `json:"uuid,omitempty"` // Product or outlet uuid, empty when grouped by category
+	Name          string  `json:"name"`
+	Quantity      float64 `json:"quantity"`
+	Revenue       float64 `json:"revenue"`
+	Cost          float64 `json:"cost"`
+	GrossMargin   float64 `json:"gross_margin"`
+	MarginPercent float64 `json:"margin_percent"`
+}
+
+type GrossMarginSummary struct {
+	Quantity      float64 `json:"quantity"`
+	Revenue       float64 `json:"revenue"`
+	Cost          float64 `json:"cost"`
+	GrossMargin   float64 `json:"gross_margin"`
+	MarginPercent float64 `json:"margin_percent"`
+}
+
+type GrossMarginReport struct {
+	GroupBy    string             `json:"group_by"`
+	OutletUuid string             `json:"outlet_uuid,omitempty"`
+	StartDate  string             `json:"start_date"`
+	EndDate    string             `json:"end_date"`
+	Summary    GrossMarginSummary `json:"summary"`
+	Rows       []GrossMarginRow   `json:"rows"`
+}
+
+type WasteRow struct {
+	Key      string  `json:"key"` // Reason code, or product or outlet uuid
+	Name     string  `json:"name"`
+	Count    int     `json:"count"` // Number of adjustments
+	Quantity float64 `json:"quantity"`
+	Value    float64 `json:"value"` // At the cost when the stock was written off
+}
+
+type WasteSummary struct {
+	Count    int     `json:"count"`
+	Quantity float64 `json:"quantity"`
+	Value    float64 `json:"value"`
+}
+
+type WasteReport struct {
+	GroupBy    string       `json:"group_by"`
+	OutletUuid string       `json:"outlet_uuid,omitempty"`
+	StartDate  string       `json:"start_date"`
+	EndDate    string       `json:"end_date"`
+	Summary    WasteSummary `json:"summary"`
+	Rows       []WasteRow   `json:"rows"`
+}
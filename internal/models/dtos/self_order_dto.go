@@ -0,0 +1,86 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateOutletTableRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type OutletTableResponse struct {
+	Uuid     uuid.UUID `json:"uuid"`
+	Name     string    `json:"name"`
+	IsActive bool      `json:"is_active"`
+	Token    string    `json:"token"`
+	OrderURL string    `json:"order_url,omitempty"` // URL to encode in the printed QR code
+}
+
+type PublicMenuAddOnResponse struct {
+	AddOnUuid uuid.UUID `json:"add_on_uuid"` // Use as add_on_uuid when submitting a self order
+	Name      string    `json:"name"`
+	Price     float64   `json:"price"`
+}
+
+type PublicMenuItemResponse struct {
+	ProductUuid uuid.UUID                 `json:"product_uuid"`
+	ProductName string                    `json:"product_name"`
+	Price       float64                   `json:"price"`
+	Type        string                    `json:"type"`
+	Available   float64                   `json:"available"`
+	AddOns      []PublicMenuAddOnResponse `json:"add_ons,omitempty"`
+}
+
+type PublicMenuResponse struct {
+	OutletUuid uuid.UUID                `json:"outlet_uuid"`
+	OutletName string                   `json:"outlet_name"`
+	TableName  string                   `json:"table_name"`
+	Items      []PublicMenuItemResponse `json:"items"`
+}
+
+type CreateSelfOrderRequest struct {
+	Token         string             `json:"token" validate:"required"`
+	CustomerName  string             `json:"customer_name" validate:"required"`
+	CustomerPhone string             `json:"customer_phone"`
+	Note          string             `json:"note"`
+	Items         []OrderItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+type RejectSelfOrderRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+type PaySelfOrderRequest struct {
+	Token           string `json:"token" validate:"required"`
+	PaymentMethodID uint   `json:"payment_method_id" validate:"required"`
+	CustomerName    string `json:"customer_name" validate:"required"`
+	CustomerEmail   string `json:"customer_email" validate:"required,email"`
+	CustomerPhone   string `json:"customer_phone" validate:"required"`
+}
+
+type SelfOrderItemResponse struct {
+	ProductUuid        uuid.UUID               `json:"product_uuid,omitempty"`
+	ProductVariantUuid uuid.UUID               `json:"product_variant_uuid,omitempty"`
+	ProductName        string                  `json:"product_name"`
+	Quantity           int                     `json:"quantity"`
+	Price              float64                 `json:"price"`
+	AddOns             []OrderItemAddonRequest `json:"add_ons,omitempty"`
+}
+
+type SelfOrderResponse struct {
+	Uuid            uuid.UUID               `json:"uuid"`
+	OutletUuid      uuid.UUID               `json:"outlet_uuid"`
+	TableName       string                  `json:"table_name"`
+	Status          string                  `json:"status"`
+	CustomerName    string                  `json:"customer_name"`
+	CustomerPhone   string                  `json:"customer_phone,omitempty"`
+	Note            string                  `json:"note,omitempty"`
+	EstimatedTotal  float64                 `json:"estimated_total"`
+	OrderUuid       *uuid.UUID              `json:"order_uuid,omitempty"`
+	RejectionReason string                  `json:"rejection_reason,omitempty"`
+	CreatedAt       time.Time               `json:"created_at"`
+	HandledAt       *time.Time              `json:"handled_at,omitempty"`
+	Items           []SelfOrderItemResponse `json:"items"`
+}
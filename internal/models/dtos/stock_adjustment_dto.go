@@ -0,0 +1,47 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockAdjustmentRequest changes the stock of a product by a quantity in its base unit. Only a
+// correction may add stock.
+type StockAdjustmentRequest struct {
+	ProductUuid        uuid.UUID `json:"product_uuid,omitempty"`
+	ProductVariantUuid uuid.UUID `json:"product_variant_uuid,omitempty"`
+	QuantityChange     float64   `json:"quantity_change" validate:"required,ne=0"`
+	Reason             string    `json:"reason" validate:"required,oneof=waste spoilage breakage theft staff_meal sample correction"`
+	Note               string    `json:"note" validate:"omitempty,max=500"`
+	PhotoURL           string    `json:"photo_url" validate:"omitempty,url,max=500"`
+}
+
+type ReviewStockAdjustmentRequest struct {
+	Note string `json:"note" validate:"omitempty,max=500"`
+}
+
+type StockAdjustmentResponse struct {
+	Uuid               uuid.UUID  `json:"uuid"`
+	OutletUuid         uuid.UUID  `json:"outlet_uuid"`
+	OutletName         string     `json:"outlet_name"`
+	ProductUuid        uuid.UUID  `json:"product_uuid"`
+	ProductName        string     `json:"product_name"`
+	ProductVariantUuid *uuid.UUID `json:"product_variant_uuid,omitempty"`
+	VariantName        string     `json:"variant_name,omitempty"`
+	Sku                string     `json:"sku,omitempty"`
+	QuantityChange     float64    `json:"quantity_change"`
+	UnitCost           float64    `json:"unit_cost"`
+	Value              float64    `json:"value"` // Quantity change valued at the unit cost
+	Reason             string     `json:"reason"`
+	Note               string     `json:"note"`
+	PhotoURL           string     `json:"photo_url,omitempty"`
+	HasPhoto           bool       `json:"has_photo"` // An uploaded photo, served from /stock-adjustments/:uuid/photo
+	Status             string     `json:"status"`
+	AdjustedBy         string     `json:"adjusted_by"`
+	AppliedAt          *time.Time `json:"applied_at"`
+	ReviewedBy         string     `json:"reviewed_by,omitempty"`
+	ReviewedAt         *time.Time `json:"reviewed_at"`
+	ReviewNote         string     `json:"review_note,omitempty"`
+	CreatedAt          string     `json:"created_at"`
+}
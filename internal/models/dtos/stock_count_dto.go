@@ -0,0 +1,92 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StartStockCountRequest struct {
+	BlindCount bool   `json:"blind_count"`
+	Note       string `json:"note" validate:"omitempty,max=1000"`
+}
+
+// AddStockCountEntriesRequest adds counted quantities to a count. Counting the same product again
+// adds to what was counted before.
+type AddStockCountEntriesRequest struct {
+	Items []StockCountEntryRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+type StockCountEntryRequest struct {
+	ProductUuid        uuid.UUID `json:"product_uuid,omitempty"`
+	ProductVariantUuid uuid.UUID `json:"product_variant_uuid,omitempty"`
+	Quantity           *float64  `json:"quantity" validate:"required,gte=0"`
+}
+
+type StockCountResponse struct {
+	Uuid         uuid.UUID                `json:"uuid"`
+	OutletUuid   uuid.UUID                `json:"outlet_uuid"`
+	OutletName   string                   `json:"outlet_name"`
+	Status       string                   `json:"status"`
+	BlindCount   bool                     `json:"blind_count"`
+	Note         string                   `json:"note"`
+	ItemCount    int                      `json:"item_count"`
+	CountedCount int                      `json:"counted_count"`
+	SubmittedAt  *time.Time               `json:"submitted_at"`
+	ApprovedAt   *time.Time               `json:"approved_at"`
+	CancelledAt  *time.Time               `json:"cancelled_at"`
+	Items        []StockCountItemResponse `json:"items,omitempty"`
+	CreatedAt    string                   `json:"created_at"`
+}
+
+// StockCountItemResponse leaves the expected quantity and variance out while a blind count is
+// still being counted.
+type StockCountItemResponse struct {
+	Uuid               uuid.UUID                 `json:"uuid"`
+	ProductUuid        uuid.UUID                 `json:"product_uuid"`
+	ProductName        string                    `json:"product_name"`
+	ProductVariantUuid *uuid.UUID                `json:"product_variant_uuid,omitempty"`
+	VariantName        string                    `json:"variant_name,omitempty"`
+	Sku                string                    `json:"sku,omitempty"`
+	ExpectedQuantity   *float64                  `json:"expected_quantity,omitempty"`
+	CountedQuantity    *float64                  `json:"counted_quantity"`
+	Variance           *float64                  `json:"variance,omitempty"`
+	Entries            []StockCountEntryResponse `json:"entries"`
+}
+
+type StockCountEntryResponse struct {
+	Uuid      uuid.UUID `json:"uuid"`
+	Quantity  float64   `json:"quantity"`
+	CountedBy string    `json:"counted_by"`
+	CountedAt string    `json:"counted_at"`
+}
+
+// StockCountVarianceReport compares the counted quantities with the snapshot taken when the count
+// started. Variances are valued at the unit price snapshotted with each item.
+type StockCountVarianceReport struct {
+	StockCountUuid uuid.UUID                 `json:"stock_count_uuid"`
+	OutletName     string                    `json:"outlet_name"`
+	Status         string                    `json:"status"`
+	Rows           []StockCountVarianceRow   `json:"rows"`
+	Summary        StockCountVarianceSummary `json:"summary"`
+}
+
+type StockCountVarianceRow struct {
+	ProductName      string   `json:"product_name"`
+	VariantName      string   `json:"variant_name,omitempty"`
+	Sku              string   `json:"sku,omitempty"`
+	ExpectedQuantity float64  `json:"expected_quantity"`
+	CountedQuantity  *float64 `json:"counted_quantity"` // Empty for items nobody counted; they are not adjusted
+	Variance         float64  `json:"variance"`
+	UnitPrice        float64  `json:"unit_price"`
+	VarianceValue    float64  `json:"variance_value"`
+}
+
+type StockCountVarianceSummary struct {
+	ItemCount        int     `json:"item_count"`
+	CountedCount     int     `json:"counted_count"`
+	VarianceCount    int     `json:"variance_count"`
+	ShortageValue    float64 `json:"shortage_value"`
+	SurplusValue     float64 `json:"surplus_value"`
+	NetVarianceValue float64 `json:"net_variance_value"`
+}
@@ -1,6 +1,10 @@
 package dtos
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type UpdateStockRequest struct {
 	ProductUuid        uuid.UUID `json:"product_uuid,omitempty"`
@@ -27,14 +31,54 @@ type StockResponse struct {
 }
 
 type StockDetailResponse struct {
-	Uuid        uuid.UUID                `json:"uuid"`
-	Name        string                   `json:"name"`
-	Description string                   `json:"description,omitempty"`
-	Price       float64                  `json:"price"`
-	SKU         string                   `json:"sku,omitempty"`
-	Type        string                   `json:"type"`
-	Variants    []ProductVariantResponse `json:"variants,omitempty"`
-	Recipes     []RecipeResponse         `json:"recipes,omitempty"`
-	AddOns      []ProductAddOnResponse   `json:"add_ons,omitempty"`
-	Quantity    float64                  `json:"quantity"`
+	Uuid              uuid.UUID                `json:"uuid"`
+	Name              string                   `json:"name"`
+	Description       string                   `json:"description,omitempty"`
+	Price             float64                  `json:"price"`
+	SKU               string                   `json:"sku,omitempty"`
+	Type              string                   `json:"type"`
+	Variants          []ProductVariantResponse `json:"variants,omitempty"`
+	Recipes           []RecipeResponse         `json:"recipes,omitempty"`
+	AddOns            []ProductAddOnResponse   `json:"add_ons,omitempty"`
+	Quantity          float64                  `json:"quantity"`
+	ReservedQuantity  float64                  `json:"reserved_quantity"`
+	AvailableQuantity float64                  `json:"available_quantity"`
+}
+
+// SetReorderPointRequest sets the reorder point and par level of a stock. Leaving ReorderPoint out
+// turns low stock alerts off for the stock.
+type SetReorderPointRequest struct {
+	ProductUuid        uuid.UUID `json:"product_uuid,omitempty"`
+	ProductVariantUuid uuid.UUID `json:"product_variant_uuid,omitempty"`
+	ReorderPoint       *float64  `json:"reorder_point" validate:"omitempty,gte=0"`
+	ParLevel           *float64  `json:"par_level" validate:"omitempty,gte=0"`
+}
+
+// StockLevelResponse is a stock with its reorder settings, as shown on the low stock dashboard.
+type StockLevelResponse struct {
+	StockUuid              uuid.UUID  `json:"stock_uuid"`
+	OutletUuid             uuid.UUID  `json:"outlet_uuid"`
+	OutletName             string     `json:"outlet_name"`
+	ProductUuid            uuid.UUID  `json:"product_uuid"`
+	ProductName            string     `json:"product_name"`
+	ProductVariantUuid     *uuid.UUID `json:"product_variant_uuid,omitempty"`
+	VariantName            string     `json:"variant_name,omitempty"`
+	Sku                    string     `json:"sku,omitempty"`
+	Quantity               float64    `json:"quantity"`
+	ReorderPoint           *float64   `json:"reorder_point"`
+	ParLevel               *float64   `json:"par_level"`
+	SuggestedOrderQuantity float64    `json:"suggested_order_quantity"` // Restocks up to the par level, or the reorder point without one
+}
+
+type StockAlertResponse struct {
+	Uuid           uuid.UUID          `json:"uuid"`
+	Stock          StockLevelResponse `json:"stock"`
+	Quantity       float64            `json:"quantity"` // Stock quantity when the alert was raised
+	ReorderPoint   float64            `json:"reorder_point"`
+	ParLevel       *float64           `json:"par_level"`
+	Status         string             `json:"status"`
+	NotifiedAt     *time.Time         `json:"notified_at"`
+	AcknowledgedAt *time.Time         `json:"acknowledged_at"`
+	ResolvedAt     *time.Time         `json:"resolved_at"`
+	CreatedAt      string             `json:"created_at"`
 }
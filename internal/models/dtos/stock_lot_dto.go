@@ -0,0 +1,33 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WriteOffStockLotRequest writes off part of a lot, or what is left of it when Quantity is left out.
+type WriteOffStockLotRequest struct {
+	Quantity *float64 `json:"quantity" validate:"omitempty,gt=0"`
+	Reason   string   `json:"reason" validate:"required,max=255"`
+}
+
+type StockLotResponse struct {
+	Uuid               uuid.UUID  `json:"uuid"`
+	OutletUuid         uuid.UUID  `json:"outlet_uuid"`
+	OutletName         string     `json:"outlet_name"`
+	ProductUuid        uuid.UUID  `json:"product_uuid"`
+	ProductName        string     `json:"product_name"`
+	ProductVariantUuid *uuid.UUID `json:"product_variant_uuid,omitempty"`
+	VariantName        string     `json:"variant_name,omitempty"`
+	Sku                string     `json:"sku,omitempty"`
+	LotNumber          string     `json:"lot_number"`
+	ReceivedAt         time.Time  `json:"received_at"`
+	ExpiryDate         *string    `json:"expiry_date"`
+	DaysToExpiry       *int       `json:"days_to_expiry"` // Negative once the lot has expired
+	Expired            bool       `json:"expired"`
+	UnitCost           float64    `json:"unit_cost"`
+	InitialQuantity    float64    `json:"initial_quantity"`
+	Quantity           float64    `json:"quantity"`
+	WrittenOffQuantity float64    `json:"written_off_quantity"`
+}
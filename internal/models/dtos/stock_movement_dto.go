@@ -0,0 +1,49 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockMovementFilter narrows the stock movement ledger of an outlet. Zero values do not filter.
+type StockMovementFilter struct {
+	ProductUuid        *uuid.UUID
+	ProductVariantUuid *uuid.UUID
+	MovementType       string
+	ReferenceType      string
+	StartDate          *time.Time
+	EndDate            *time.Time // Inclusive
+}
+
+type StockMovementResponse struct {
+	ID                 uint       `json:"id"`
+	ProductUuid        *uuid.UUID `json:"product_uuid,omitempty"`
+	ProductName        string     `json:"product_name"`
+	ProductVariantUuid *uuid.UUID `json:"product_variant_uuid,omitempty"`
+	VariantName        string     `json:"variant_name,omitempty"`
+	QuantityChange     float64    `json:"quantity_change"`
+	BalanceAfter       float64    `json:"balance_after"`
+	MovementType       string     `json:"movement_type"`
+	ReferenceType      string     `json:"reference_type,omitempty"`
+	ReferenceUuid      *uuid.UUID `json:"reference_uuid,omitempty"`
+	Description        string     `json:"description"`
+	PerformedBy        string     `json:"performed_by,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+type StockAsOfItem struct {
+	ProductUuid        uuid.UUID  `json:"product_uuid"`
+	ProductName        string     `json:"product_name"`
+	ProductSku         string     `json:"product_sku"`
+	ProductVariantUuid *uuid.UUID `json:"product_variant_uuid,omitempty"`
+	VariantName        string     `json:"variant_name,omitempty"`
+	VariantSku         string     `json:"variant_sku,omitempty"`
+	Quantity           float64    `json:"quantity"`
+}
+
+type StockAsOfResponse struct {
+	OutletUuid uuid.UUID       `json:"outlet_uuid"`
+	Date       string          `json:"date"`
+	Items      []StockAsOfItem `json:"items"`
+}
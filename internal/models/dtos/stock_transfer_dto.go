@@ -0,0 +1,59 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StockTransferRequest struct {
+	SourceOutletUuid      uuid.UUID                  `json:"source_outlet_uuid" validate:"required"`
+	DestinationOutletUuid uuid.UUID                  `json:"destination_outlet_uuid" validate:"required"`
+	Note                  string                     `json:"note" validate:"omitempty,max=1000"`
+	Items                 []StockTransferItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+type StockTransferItemRequest struct {
+	ProductUuid        uuid.UUID `json:"product_uuid,omitempty"`
+	ProductVariantUuid uuid.UUID `json:"product_variant_uuid,omitempty"`
+	Quantity           float64   `json:"quantity" validate:"required,gt=0"`
+}
+
+// ReceiveStockTransferRequest records what arrived at the destination outlet. Items left out are
+// received in full.
+type ReceiveStockTransferRequest struct {
+	Items []ReceiveStockTransferItemRequest `json:"items" validate:"omitempty,dive"`
+}
+
+type ReceiveStockTransferItemRequest struct {
+	ItemUuid         uuid.UUID `json:"item_uuid" validate:"required"`
+	ReceivedQuantity *float64  `json:"received_quantity" validate:"required,gte=0"`
+	DiscrepancyNote  string    `json:"discrepancy_note" validate:"omitempty,max=1000"`
+}
+
+type StockTransferResponse struct {
+	Uuid                  uuid.UUID                   `json:"uuid"`
+	SourceOutletUuid      uuid.UUID                   `json:"source_outlet_uuid"`
+	SourceOutletName      string                      `json:"source_outlet_name"`
+	DestinationOutletUuid uuid.UUID                   `json:"destination_outlet_uuid"`
+	DestinationOutletName string                      `json:"destination_outlet_name"`
+	Status                string                      `json:"status"`
+	Note                  string                      `json:"note"`
+	SentAt                *time.Time                  `json:"sent_at"`
+	ReceivedAt            *time.Time                  `json:"received_at"`
+	CancelledAt           *time.Time                  `json:"cancelled_at"`
+	Items                 []StockTransferItemResponse `json:"items"`
+	CreatedAt             string                      `json:"created_at"`
+}
+
+type StockTransferItemResponse struct {
+	Uuid               uuid.UUID  `json:"uuid"`
+	ProductUuid        uuid.UUID  `json:"product_uuid"`
+	ProductName        string     `json:"product_name"`
+	ProductVariantUuid *uuid.UUID `json:"product_variant_uuid,omitempty"`
+	VariantName        string     `json:"variant_name,omitempty"`
+	Quantity           float64    `json:"quantity"`
+	ReceivedQuantity   *float64   `json:"received_quantity"`
+	Discrepancy        float64    `json:"discrepancy"` // Received minus sent; negative when stock went missing in transit
+	DiscrepancyNote    string     `json:"discrepancy_note"`
+}
@@ -0,0 +1,41 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookEndpointRequest struct {
+	URL         string   `json:"url" validate:"required,url,max=500"`
+	EventTypes  []string `json:"event_types" validate:"required,min=1,dive,oneof=order.created order.completed payment.paid payment.refunded payment.orphaned stock.low purchase_order.received"`
+	Description string   `json:"description" validate:"max=255"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+type WebhookEndpointResponse struct {
+	Uuid        uuid.UUID `json:"uuid"`
+	URL         string    `json:"url"`
+	EventTypes  []string  `json:"event_types"`
+	Description string    `json:"description"`
+	IsActive    bool      `json:"is_active"`
+	Secret      string    `json:"secret,omitempty"` // Only returned when the endpoint is created
+	CreatedAt   string    `json:"created_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	Uuid           uuid.UUID   `json:"uuid"`
+	EndpointUuid   uuid.UUID   `json:"endpoint_uuid"`
+	EventUuid      uuid.UUID   `json:"event_uuid"`
+	EventType      string      `json:"event_type"`
+	Status         string      `json:"status"`
+	Attempts       int         `json:"attempts"`
+	NextAttemptAt  *time.Time  `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time  `json:"last_attempt_at"`
+	ResponseStatus int         `json:"response_status"`
+	ResponseBody   string      `json:"response_body,omitempty"`
+	Error          string      `json:"error,omitempty"`
+	DeliveredAt    *time.Time  `json:"delivered_at"`
+	Payload        interface{} `json:"payload"`
+	CreatedAt      string      `json:"created_at"`
+}
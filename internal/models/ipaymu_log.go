@@ -11,7 +11,11 @@ type IpaymuLog struct {
 	ServiceName     string     `json:"service_name"`   // Nama service yang melakukan pembayaran (misal: billing, order, dsb)
 	ServiceRefID    string     `json:"service_ref_id"` // ID referensi dari service terkait (misal: billing_id, order_id, dsb)
 	ReferenceIpaymu string     `json:"reference_ipaymu"`
+	SessionID       string     `gorm:"index" json:"session_id"`       // Session halaman pembayaran iPaymu; transaksi baru diketahui saat notifikasi
 	Amount          float64    `json:"amount"`                        // Nominal pembayaran
+	Fee             float64    `json:"fee"`                           // Biaya gateway
+	FeeDirection    string     `json:"fee_direction"`                 // Penanggung biaya (BUYER atau MERCHANT)
+	SettledAmount   float64    `json:"settled_amount"`                // Nominal yang diterima merchant saat settlement
 	Status          string     `gorm:"default:pending" json:"status"` // Status pembayaran (misal: pending, paid, failed)
 	PaymentMethod   string     `json:"payment_method"`                // Metode pembayaran (misal: va, qris, dsb)
 	PaymentChannel  string     `json:"payment_channel"`               // Channel pembayaran (misal: bca, mandiri, dsb)
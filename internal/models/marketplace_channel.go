@@ -0,0 +1,16 @@
+package models
+
+// MarketplaceChannel connects an outlet to a food delivery marketplace (e.g. GoFood, GrabFood, ShopeeFood).
+type MarketplaceChannel struct {
+	BaseModel
+	OutletID          uint    `gorm:"not null;index" json:"outlet_id"`
+	Outlet            Outlet  `json:"outlet"`
+	Provider          string  `gorm:"type:varchar(50);not null" json:"provider"`
+	Name              string  `gorm:"type:varchar(255);not null" json:"name"`
+	WebhookSecret     string  `gorm:"type:varchar(255);not null" json:"-"` // Shared secret used to sign inbound webhooks
+	CommissionPercent float64 `gorm:"default:0" json:"commission_percent"`
+	StatusCallbackURL string  `gorm:"type:varchar(500)" json:"status_callback_url"` // Where order status updates are pushed back to
+	IsActive          bool    `gorm:"default:true" json:"is_active"`
+	UserID            uint    `gorm:"not null" json:"user_id"`
+	User              User    `json:"user"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// MarketplaceItemMapping maps an item ID on the marketplace menu to a product or variant.
+type MarketplaceItemMapping struct {
+	BaseModel
+	MarketplaceChannelID uint               `gorm:"not null;uniqueIndex:idx_marketplace_item_mapping" json:"marketplace_channel_id"`
+	MarketplaceChannel   MarketplaceChannel `json:"marketplace_channel"`
+	ExternalItemID       string             `gorm:"type:varchar(255);not null;uniqueIndex:idx_marketplace_item_mapping" json:"external_item_id"`
+	ProductID            *uint              `gorm:"index" json:"product_id,omitempty"`
+	Product              *Product           `json:"product,omitempty"`
+	ProductVariantID     *uint              `gorm:"index" json:"product_variant_id,omitempty"`
+	ProductVariant       *ProductVariant    `json:"product_variant,omitempty"`
+	UserID               uint               `gorm:"not null" json:"user_id"`
+	User                 User               `json:"user"`
+}
@@ -0,0 +1,30 @@
+package models
+
+// Marketplace order statuses, pushed back to the marketplace when they change.
+const (
+	MarketplaceOrderStatusReceived  = "received"
+	MarketplaceOrderStatusAccepted  = "accepted"
+	MarketplaceOrderStatusPreparing = "preparing"
+	MarketplaceOrderStatusReady     = "ready"
+	MarketplaceOrderStatusCompleted = "completed"
+	MarketplaceOrderStatusCancelled = "cancelled"
+)
+
+// MarketplaceOrder links an order received from a marketplace to the POS order created for it.
+type MarketplaceOrder struct {
+	BaseModel
+	MarketplaceChannelID uint               `gorm:"not null;uniqueIndex:idx_marketplace_external_order" json:"marketplace_channel_id"`
+	MarketplaceChannel   MarketplaceChannel `json:"marketplace_channel"`
+	ExternalOrderID      string             `gorm:"type:varchar(255);not null;uniqueIndex:idx_marketplace_external_order" json:"external_order_id"`
+	OrderID              uint               `gorm:"not null;index" json:"order_id"`
+	Order                Order              `json:"order"`
+	Status               string             `gorm:"type:varchar(50);not null" json:"status"`
+	CustomerName         string             `gorm:"type:varchar(255)" json:"customer_name"`
+	GrossAmount          float64            `gorm:"default:0" json:"gross_amount"`
+	CommissionPercent    float64            `gorm:"default:0" json:"commission_percent"` // Channel commission at the time the order was received
+	CommissionAmount     float64            `gorm:"default:0" json:"commission_amount"`
+	NetAmount            float64            `gorm:"default:0" json:"net_amount"`
+	RawPayload           string             `gorm:"type:jsonb" json:"raw_payload,omitempty"`
+	UserID               uint               `gorm:"not null" json:"user_id"`
+	User                 User               `json:"user"`
+}
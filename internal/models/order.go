@@ -9,6 +9,7 @@ type Order struct {
 	TotalAmount   float64     `gorm:"not null" json:"total_amount"`
 	Status        string      `gorm:"not null" json:"status"` // e.g., "pending", "completed", "cancelled"
 	PaidAmount    float64     `gorm:"default:0" json:"paid_amount"`
+	StockReserved bool        `gorm:"not null;default:false" json:"stock_reserved"` // Items reserve stock until the order completes
 	OrderItems    []OrderItem `json:"order_items"`
 	OrderPayments []OrderPayment `json:"order_payments"`
 }
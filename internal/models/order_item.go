@@ -9,7 +9,8 @@ type OrderItem struct {
 	ProductVariantID  *uint              `gorm:"index" json:"product_variant_id,omitempty"`
 	ProductVariant    *ProductVariant    `json:"product_variant,omitempty"`
 	Quantity          float64            `gorm:"not null" json:"quantity"`
-	Price             float64            `gorm:"not null" json:"price"` // Price at the time of order
+	Price             float64            `gorm:"not null" json:"price"`                // Price at the time of order
+	CostPrice         float64            `gorm:"not null;default:0" json:"cost_price"` // Unit cost at the time of order
 	ProductName       string             `gorm:"type:varchar(255)" json:"product_name"`
 	AddOns            []OrderItemAddOn   `gorm:"foreignKey:OrderItemID" json:"add_ons,omitempty"`
 	OrderPaymentItems []OrderPaymentItem `json:"order_payment_items"`
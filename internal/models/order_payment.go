@@ -2,6 +2,19 @@ package models
 
 import "time"
 
+// Order payment statuses
+const (
+	OrderPaymentStatusPending   = "pending"
+	OrderPaymentStatusPaid      = "paid"
+	OrderPaymentStatusFailed    = "failed"
+	OrderPaymentStatusExpired   = "expired"
+	OrderPaymentStatusCancelled = "cancelled"
+	OrderPaymentStatusRefunded  = "refunded"
+	// OrderPaymentStatusOrphaned marks money a gateway collected after the payment was cancelled,
+	// failed or expired. It is not credited to the order and is waiting to be refunded.
+	OrderPaymentStatusOrphaned = "orphaned"
+)
+
 type OrderPayment struct {
 	BaseModel
 	OrderID           uint               `gorm:"not null" json:"order_id"`
@@ -10,12 +23,24 @@ type OrderPayment struct {
 	PaymentMethod     PaymentMethod      `json:"payment_method"`
 	OrderPaymentItems []OrderPaymentItem `json:"order_payment_items"`
 	AmountPaid        float64            `gorm:"not null;column:amount_paid;default:0" json:"amount_paid"`
+	RefundedAmount    float64            `gorm:"not null;default:0" json:"refunded_amount"`
+	SurchargeAmount   float64            `gorm:"not null;default:0" json:"surcharge_amount"` // Part of AmountPaid charged for the payment method, not credited to the order
 	ReferenceID       string             `gorm:"type:varchar(255)" json:"reference_id"`
 	IsPaid            bool               `json:"is_paid"`
+	Status            string             `gorm:"type:varchar(20);default:pending;index" json:"status"`
 	PaidAt            *time.Time         `json:"paid_at"`
+	FailedAt          *time.Time         `json:"failed_at"`
+	CancelledAt       *time.Time         `json:"cancelled_at"`
+	RefundedAt        *time.Time         `json:"refunded_at"`
 	CustomerName      string             `gorm:"type:varchar(255)" json:"customer_name"`
 	CustomerEmail     string             `gorm:"type:varchar(255)" json:"customer_email"`
 	CustomerPhone     string             `gorm:"type:varchar(255)" json:"customer_phone"`
 	ChangeAmount      float64            `gorm:"default:0" json:"change_amount"`
 	Extra             string             `gorm:"type:jsonb" json:"extra,omitempty"`
+	ExpiresAt         *time.Time         `json:"expires_at"`      // Gateway deadline for a pending payment
+	ExpiredAt         *time.Time         `json:"expired_at"`      // Set once a pending payment is given up on
+	LastCheckedAt     *time.Time         `json:"last_checked_at"` // Last status check with the gateway
+	ReviewNote        string             `gorm:"type:varchar(500)" json:"review_note"` // Note left when a manual transfer is confirmed or rejected
+	ReviewedBy        *uint              `json:"reviewed_by"`
+	ReviewedAt        *time.Time         `json:"reviewed_at"`
 }
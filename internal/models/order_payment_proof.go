@@ -0,0 +1,13 @@
+package models
+
+// OrderPaymentProof is a proof of payment attached to a manual transfer, either an uploaded image
+// or a link to one.
+type OrderPaymentProof struct {
+	BaseModel
+	OrderPaymentID uint         `gorm:"not null;index" json:"order_payment_id"`
+	OrderPayment   OrderPayment `json:"order_payment"`
+	FileName       string       `gorm:"type:varchar(255)" json:"-"` // Stored file inside PAYMENT_PROOF_DIR, empty for links
+	ContentType    string       `gorm:"type:varchar(100)" json:"content_type"`
+	URL            string       `gorm:"type:varchar(500)" json:"url"` // Attached link, empty for uploads
+	Note           string       `gorm:"type:varchar(500)" json:"note"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Order payment refund statuses
+const (
+	OrderPaymentRefundStatusPending   = "pending"
+	OrderPaymentRefundStatusSucceeded = "succeeded"
+	OrderPaymentRefundStatusFailed    = "failed"
+)
+
+// OrderPaymentRefund is a full or partial refund of a paid order payment. Gateway refunds may stay
+// pending until the gateway confirms them.
+type OrderPaymentRefund struct {
+	BaseModel
+	OrderPaymentID uint         `gorm:"not null;index" json:"order_payment_id"`
+	OrderPayment   OrderPayment `json:"order_payment"`
+	Amount         float64      `gorm:"not null" json:"amount"`
+	Reason         string       `gorm:"type:varchar(255)" json:"reason"`
+	Status         string       `gorm:"type:varchar(20);default:pending;index" json:"status"`
+	ReferenceID    string       `gorm:"type:varchar(255)" json:"reference_id"` // Refund ID on the gateway side
+	Extra          string       `gorm:"type:jsonb" json:"extra,omitempty"`
+	FailureReason  string       `gorm:"type:text" json:"failure_reason,omitempty"`
+	RefundedAt     *time.Time   `json:"refunded_at"`
+	FailedAt       *time.Time   `json:"failed_at"`
+}
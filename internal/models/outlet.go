@@ -4,8 +4,11 @@ type Outlet struct {
 	BaseModel
 	Name    string `gorm:"not null" json:"name"`
 	Address string `json:"address"`
-	Contact string `json:"address"`
+	Contact string `json:"contact"`
 	Type    string `gorm:"not null" json:"type"` // e.g., "retail", "fnb"
-	UserID  uint   `gorm:"not null" json:"user_id"`
-	User    User   `json:"user"`
+	// ReserveStock makes orders at the outlet reserve their stock until they complete, instead of
+	// deducting it as items are added.
+	ReserveStock bool `gorm:"not null;default:false" json:"reserve_stock"`
+	UserID       uint `gorm:"not null" json:"user_id"`
+	User         User `json:"user"`
 }
@@ -0,0 +1,22 @@
+package models
+
+// Surcharge types of an outlet payment method
+const (
+	SurchargeTypeFixed      = "fixed"
+	SurchargeTypePercentage = "percentage"
+)
+
+// OutletPaymentMethod enables a payment method at an outlet. Customers paying with it are charged
+// the surcharge on top of the items.
+type OutletPaymentMethod struct {
+	BaseModel
+	OutletID        uint          `gorm:"not null;uniqueIndex:idx_outlet_payment_method" json:"outlet_id"`
+	Outlet          Outlet        `json:"outlet"`
+	PaymentMethodID uint          `gorm:"not null;uniqueIndex:idx_outlet_payment_method" json:"payment_method_id"`
+	PaymentMethod   PaymentMethod `json:"payment_method"`
+	DisplayOrder    int           `gorm:"default:0" json:"display_order"`
+	SurchargeType   string        `gorm:"type:varchar(20)" json:"surcharge_type"` // fixed or percentage, empty for none
+	SurchargeValue  float64       `gorm:"default:0" json:"surcharge_value"`
+	UserID          uint          `gorm:"not null" json:"user_id"`
+	User            User          `json:"user"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// OutletTable is a dine-in table whose QR code lets customers order by themselves.
+type OutletTable struct {
+	BaseModel
+	OutletID     uint   `gorm:"not null;index" json:"outlet_id"`
+	Outlet       Outlet `json:"outlet"`
+	Name         string `gorm:"type:varchar(100);not null" json:"name"`  // e.g., "Table 12", "Terrace A"
+	TokenVersion int    `gorm:"not null;default:1" json:"token_version"` // Bump to invalidate printed QR codes
+	IsActive     bool   `gorm:"default:true" json:"is_active"`
+	UserID       uint   `gorm:"not null" json:"user_id"`
+	User         User   `json:"user"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Payment callback outcomes
+const (
+	PaymentCallbackStatusAccepted  = "accepted"
+	PaymentCallbackStatusDuplicate = "duplicate"
+	PaymentCallbackStatusRejected  = "rejected"
+)
+
+// PaymentCallbackLog keeps every inbound gateway notification for audit, including rejected ones.
+type PaymentCallbackLog struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Issuer       string    `gorm:"type:varchar(50);index" json:"issuer"`
+	ReferenceID  string    `gorm:"type:varchar(255);index" json:"reference_id"`
+	ServiceRefID string    `gorm:"type:varchar(255);index" json:"service_ref_id"`
+	SourceIP     string    `gorm:"type:varchar(100)" json:"source_ip"`
+	Payload      string    `gorm:"type:text" json:"payload"`
+	Status       string    `gorm:"type:varchar(20);index" json:"status"`
+	Reason       string    `gorm:"type:text" json:"reason"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// PaymentLink is a shareable link that lets a customer pay an order's outstanding balance on
+// iPaymu's payment page. The link's state follows its order payment.
+type PaymentLink struct {
+	BaseModel
+	Token          string       `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	OrderID        uint         `gorm:"not null;index" json:"order_id"`
+	Order          Order        `json:"order"`
+	OrderPaymentID uint         `gorm:"not null;index" json:"order_payment_id"`
+	OrderPayment   OrderPayment `json:"order_payment"`
+	Amount         float64      `gorm:"not null" json:"amount"`
+	ExpiresAt      time.Time    `gorm:"not null" json:"expires_at"`
+	SentTo         string       `gorm:"type:varchar(255)" json:"sent_to"` // Last address the link was emailed to
+	SentAt         *time.Time   `json:"sent_at"`
+	UserID         uint         `gorm:"not null;index" json:"user_id"` // Owner of the order
+}
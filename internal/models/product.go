@@ -3,16 +3,23 @@ package models
 // AllowedProductTypes defines the list of types that a product can have.
 var AllowedProductTypes = []string{"retail_item", "fnb_main_product", "fnb_component", "add_on"}
 
+// DefaultBaseUnit is the unit stock of a product is kept in unless another is set.
+const DefaultBaseUnit = "pcs"
+
 type Product struct {
 	BaseModel
 	Name        string           `gorm:"not null" json:"name"`
 	Description string           `json:"description,omitempty"`
 	Price       float64          `gorm:"not null" json:"price"`
+	CostPrice   float64          `gorm:"not null;default:0" json:"cost_price"` // Moving-average cost, updated when purchase orders are received
+	Category    string           `gorm:"type:varchar(100);index" json:"category,omitempty"`
 	SKU         string           `gorm:"uniqueIndex:idx_user_sku" json:"sku,omitempty"`
-	Type        string           `gorm:"not null" json:"type"` // e.g., "retail_item", "fnb_main_product", "fnb_component"
+	Type        string           `gorm:"not null" json:"type"`                                     // e.g., "retail_item", "fnb_main_product", "fnb_component"
+	BaseUnit    string           `gorm:"type:varchar(20);not null;default:'pcs'" json:"base_unit"` // Unit stock is kept in
 	UserID      uint             `gorm:"uniqueIndex:idx_user_sku;not null" json:"user_id"`
 	User        User             `json:"user"`
 	Variants    []ProductVariant `json:"variants,omitempty"`
 	Recipes     []Recipe         `gorm:"foreignKey:MainProductID" json:"recipes,omitempty"`
 	AddOns      []ProductAddOn   `gorm:"foreignKey:ProductID" json:"add_ons,omitempty"`
+	Units       []ProductUnit    `json:"units,omitempty"`
 }
@@ -0,0 +1,13 @@
+package models
+
+// ProductUnit is an alternate unit a product is bought or used in, e.g. a carton of 12 litres or a
+// millilitre of a product stocked in litres.
+type ProductUnit struct {
+	BaseModel
+	ProductID        uint    `gorm:"not null;uniqueIndex:idx_product_unit_name" json:"product_id"`
+	Product          Product `json:"product"`
+	Name             string  `gorm:"type:varchar(20);not null;uniqueIndex:idx_product_unit_name" json:"name"`
+	ConversionFactor float64 `gorm:"not null" json:"conversion_factor"` // Base units in one of this unit
+	UserID           uint    `gorm:"not null" json:"user_id"`
+	User             User    `json:"user"`
+}
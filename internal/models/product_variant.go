@@ -7,5 +7,6 @@ type ProductVariant struct {
 	Name      string  `gorm:"not null" json:"name"` // e.g., "Red / L"
 	SKU       string  `gorm:"uniqueIndex:idx_user_variant_sku;not null" json:"sku"`
 	Price     float64 `gorm:"not null" json:"price"`
+	CostPrice float64 `gorm:"not null;default:0" json:"cost_price"` // Moving-average cost, updated when purchase orders are received
 	UserID    uint    `gorm:"uniqueIndex:idx_user_variant_sku;not null" json:"user_id"`
 }
@@ -0,0 +1,17 @@
+package models
+
+// StockMovementProductionUsage is the movement type of ingredients used up by a production run.
+// The finished product comes in as StockMovementProduction.
+const StockMovementProductionUsage = "ProductionUsage"
+
+// ProductionRun is one batch of an F&B main product made from its recipe at an outlet. The
+// ingredient and finished product movements of the batch all reference it.
+type ProductionRun struct {
+	BaseModel
+	OutletID  uint    `gorm:"not null;index" json:"outlet_id"`
+	Outlet    Outlet  `json:"outlet"`
+	ProductID uint    `gorm:"not null;index" json:"product_id"`
+	Product   Product `json:"product"`
+	Quantity  float64 `gorm:"not null" json:"quantity"`
+	UserID    uint    `gorm:"not null;index" json:"user_id"`
+}
@@ -12,6 +12,8 @@ type PurchaseOrderItem struct {
 	Product           *Product        `json:"product,omitempty"`
 	ProductVariantID  *uint           `gorm:"index" json:"product_variant_id,omitempty"`
 	ProductVariant    *ProductVariant `json:"product_variant,omitempty"`
-	Quantity          float64         `gorm:"not null" json:"quantity"`
-	Price             float64         `gorm:"not null" json:"price"` // Price at the time of PO
+	Quantity          float64         `gorm:"not null" json:"quantity"`                    // In Unit
+	Price             float64         `gorm:"not null" json:"price"`                       // Price of one Unit at the time of PO
+	Unit              string          `gorm:"type:varchar(20)" json:"unit,omitempty"`      // Empty for the base unit
+	ConversionFactor  float64         `gorm:"not null;default:1" json:"conversion_factor"` // Base units in one Unit at the time of PO
 }
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Self order statuses. Customers submit orders as pending confirmation,
+// a cashier then accepts (turning it into an Order) or rejects them.
+const (
+	SelfOrderStatusPendingConfirmation = "pending_confirmation"
+	SelfOrderStatusAccepted            = "accepted"
+	SelfOrderStatusRejected            = "rejected"
+)
+
+// SelfOrder is an order submitted by a customer from a table QR code.
+type SelfOrder struct {
+	BaseModel
+	OutletID        uint            `gorm:"not null;index" json:"outlet_id"`
+	Outlet          Outlet          `json:"outlet"`
+	OutletTableID   uint            `gorm:"not null;index" json:"outlet_table_id"`
+	OutletTable     OutletTable     `json:"outlet_table"`
+	Status          string          `gorm:"type:varchar(50);not null;index" json:"status"`
+	CustomerName    string          `gorm:"type:varchar(255)" json:"customer_name"`
+	CustomerPhone   string          `gorm:"type:varchar(255)" json:"customer_phone"`
+	Note            string          `gorm:"type:text" json:"note"`
+	EstimatedTotal  float64         `gorm:"default:0" json:"estimated_total"`
+	OrderID         *uint           `gorm:"index" json:"order_id,omitempty"` // Set once a cashier accepts the self order
+	Order           *Order          `json:"order,omitempty"`
+	HandledByID     *uint           `json:"handled_by_id,omitempty"`
+	HandledAt       *time.Time      `json:"handled_at,omitempty"`
+	RejectionReason string          `gorm:"type:text" json:"rejection_reason,omitempty"`
+	Items           []SelfOrderItem `json:"items"`
+	UserID          uint            `gorm:"not null" json:"user_id"`
+	User            User            `json:"user"`
+}
@@ -0,0 +1,14 @@
+package models
+
+type SelfOrderItem struct {
+	BaseModel
+	SelfOrderID      uint            `gorm:"not null;index" json:"self_order_id"`
+	ProductID        *uint           `gorm:"index" json:"product_id,omitempty"`
+	Product          *Product        `json:"product,omitempty"`
+	ProductVariantID *uint           `gorm:"index" json:"product_variant_id,omitempty"`
+	ProductVariant   *ProductVariant `json:"product_variant,omitempty"`
+	ProductName      string          `gorm:"type:varchar(255)" json:"product_name"`
+	Quantity         float64         `gorm:"not null" json:"quantity"`
+	Price            float64         `gorm:"not null" json:"price"`               // Menu price when the customer submitted the order
+	AddOns           string          `gorm:"type:jsonb" json:"add_ons,omitempty"` // []dtos.OrderItemAddonRequest as submitted
+}
@@ -9,6 +9,9 @@ type Stock struct {
 	ProductVariantID *uint           `gorm:"index" json:"product_variant_id,omitempty"`
 	ProductVariant   *ProductVariant `json:"product_variant,omitempty"`
 	Quantity         float64         `gorm:"not null" json:"quantity"`
+	ReservedQuantity float64         `gorm:"not null;default:0" json:"reserved_quantity"`
+	ReorderPoint     *float64        `json:"reorder_point"` // Low stock alerts are raised at or below this quantity
+	ParLevel         *float64        `json:"par_level"`     // Quantity the stock is restocked up to
 	UserID           uint            `gorm:"not null" json:"user_id"`
 	User             User            `json:"user"`
 }
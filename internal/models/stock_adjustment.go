@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Stock adjustment reasons. Every reason but a correction takes stock out.
+const (
+	StockAdjustmentReasonWaste      = "waste"
+	StockAdjustmentReasonSpoilage   = "spoilage"
+	StockAdjustmentReasonBreakage   = "breakage"
+	StockAdjustmentReasonTheft      = "theft"
+	StockAdjustmentReasonStaffMeal  = "staff_meal"
+	StockAdjustmentReasonSample     = "sample"
+	StockAdjustmentReasonCorrection = "correction"
+)
+
+// Stock adjustment statuses. Adjustments above the approval threshold wait as pending until a
+// manager approves them; the others are applied right away.
+const (
+	StockAdjustmentStatusPending  = "pending"
+	StockAdjustmentStatusApplied  = "applied"
+	StockAdjustmentStatusRejected = "rejected"
+)
+
+// StockMovementAdjustment is the movement type of direct stock updates and stock adjustments.
+const StockMovementAdjustment = "Adjustment"
+
+// StockAdjustment is a reason-coded change to the stock of a product at an outlet.
+type StockAdjustment struct {
+	BaseModel
+	OutletID         uint            `gorm:"not null;index" json:"outlet_id"`
+	Outlet           Outlet          `json:"outlet"`
+	ProductID        *uint           `gorm:"index" json:"product_id,omitempty"`
+	Product          *Product        `json:"product,omitempty"`
+	ProductVariantID *uint           `gorm:"index" json:"product_variant_id,omitempty"`
+	ProductVariant   *ProductVariant `json:"product_variant,omitempty"`
+	QuantityChange   float64         `gorm:"not null" json:"quantity_change"` // In the base unit, negative takes stock out
+	UnitCost         float64         `gorm:"not null;default:0" json:"unit_cost"`
+	Reason           string          `gorm:"type:varchar(20);not null;index" json:"reason"`
+	Note             string          `gorm:"type:varchar(500)" json:"note"`
+	PhotoURL         string          `gorm:"type:varchar(500)" json:"photo_url"`
+	PhotoFileName    string          `gorm:"type:varchar(255)" json:"-"` // Stored file inside STOCK_ADJUSTMENT_PHOTO_DIR
+	PhotoContentType string          `gorm:"type:varchar(100)" json:"photo_content_type"`
+	Status           string          `gorm:"type:varchar(20);not null;index" json:"status"`
+	AdjustedBy       uint            `gorm:"not null" json:"adjusted_by"`
+	Adjuster         User            `gorm:"foreignKey:AdjustedBy" json:"adjuster"`
+	AppliedAt        *time.Time      `json:"applied_at"`
+	ReviewedBy       *uint           `json:"reviewed_by"`
+	Reviewer         *User           `gorm:"foreignKey:ReviewedBy" json:"reviewer,omitempty"`
+	ReviewedAt       *time.Time      `json:"reviewed_at"`
+	ReviewNote       string          `gorm:"type:varchar(500)" json:"review_note"`
+	UserID           uint            `gorm:"not null;index" json:"user_id"`
+	User             User            `json:"user"`
+}
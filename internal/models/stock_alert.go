@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Stock alert statuses. An alert stays open or acknowledged until the stock is restocked above
+// its reorder point.
+const (
+	StockAlertStatusOpen         = "open"
+	StockAlertStatusAcknowledged = "acknowledged"
+	StockAlertStatusResolved     = "resolved"
+)
+
+// StockAlert is raised when a stock falls to or below its reorder point.
+type StockAlert struct {
+	BaseModel
+	StockID        uint       `gorm:"not null;index" json:"stock_id"`
+	Stock          Stock      `json:"stock"`
+	OutletID       uint       `gorm:"not null;index" json:"outlet_id"`
+	Quantity       float64    `gorm:"not null" json:"quantity"` // Stock quantity when the alert was raised
+	ReorderPoint   float64    `gorm:"not null" json:"reorder_point"`
+	ParLevel       *float64   `json:"par_level"`
+	Status         string     `gorm:"type:varchar(20);default:open;index" json:"status"`
+	NotifiedAt     *time.Time `gorm:"index" json:"notified_at"` // Set once the alert email is handled
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	AcknowledgedBy *uint      `json:"acknowledged_by"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	UserID         uint       `gorm:"not null;index" json:"user_id"`
+}
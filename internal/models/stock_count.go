@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Stock count statuses. Counting accepts counted quantities; a submitted count waits for a
+// manager to approve it, which posts the variances to stock.
+const (
+	StockCountStatusCounting  = "counting"
+	StockCountStatusSubmitted = "submitted"
+	StockCountStatusApproved  = "approved"
+	StockCountStatusCancelled = "cancelled"
+)
+
+// StockMovementStockCount is the movement type of adjustments posted by an approved stock count.
+const StockMovementStockCount = "StockCount"
+
+// StockCount is a stock opname session at an outlet. The expected quantities are snapshotted when
+// the count starts.
+type StockCount struct {
+	BaseModel
+	OutletID    uint             `gorm:"not null;index" json:"outlet_id"`
+	Outlet      Outlet           `json:"outlet"`
+	Status      string           `gorm:"type:varchar(20);default:counting;index" json:"status"`
+	BlindCount  bool             `gorm:"default:false" json:"blind_count"` // Hides expected quantities from counters until the count is submitted
+	Note        string           `gorm:"type:text" json:"note"`
+	SubmittedAt *time.Time       `json:"submitted_at"`
+	SubmittedBy *uint            `json:"submitted_by"`
+	ApprovedAt  *time.Time       `json:"approved_at"`
+	ApprovedBy  *uint            `json:"approved_by"`
+	CancelledAt *time.Time       `json:"cancelled_at"`
+	Items       []StockCountItem `json:"items"`
+	UserID      uint             `gorm:"not null;index" json:"user_id"`
+	User        User             `json:"user"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// StockCountEntry is one counter's count of an item. The item's counted quantity is the sum of its
+// entries, so several people can count the same product in different places.
+type StockCountEntry struct {
+	BaseModel
+	StockCountID     uint    `gorm:"not null;index" json:"stock_count_id"`
+	StockCountItemID uint    `gorm:"not null;index" json:"stock_count_item_id"`
+	Quantity         float64 `gorm:"not null" json:"quantity"`
+	CountedBy        uint    `gorm:"not null" json:"counted_by"`
+	Counter          User    `gorm:"foreignKey:CountedBy" json:"counter"`
+}
@@ -0,0 +1,15 @@
+package models
+
+type StockCountItem struct {
+	BaseModel
+	StockCountID     uint              `gorm:"not null;index" json:"stock_count_id"`
+	StockID          *uint             `gorm:"index" json:"stock_id"` // Empty for products found during the count without a stock record
+	ProductID        *uint             `gorm:"index" json:"product_id,omitempty"`
+	Product          *Product          `json:"product,omitempty"`
+	ProductVariantID *uint             `gorm:"index" json:"product_variant_id,omitempty"`
+	ProductVariant   *ProductVariant   `json:"product_variant,omitempty"`
+	ExpectedQuantity float64           `gorm:"not null" json:"expected_quantity"` // Stock quantity when the count started
+	CountedQuantity  *float64          `json:"counted_quantity"`                  // Sum of the count entries, empty until counted
+	UnitPrice        float64           `gorm:"not null" json:"unit_price"`        // Values the variance
+	Entries          []StockCountEntry `json:"entries"`
+}
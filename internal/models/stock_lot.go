@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// StockMovementWriteOff is the movement type of stock written off from a lot.
+const StockMovementWriteOff = "WriteOff"
+
+// StockLot is a batch of a stock received together, with its own expiry date and cost. Lots are
+// consumed first-expired-first-out whenever the stock goes down; stock not covered by a lot, such
+// as stock entered before lots existed, is consumed last.
+type StockLot struct {
+	BaseModel
+	StockID            uint            `gorm:"not null;index" json:"stock_id"`
+	Stock              Stock           `json:"stock"`
+	OutletID           uint            `gorm:"not null;index" json:"outlet_id"`
+	ProductID          *uint           `gorm:"index" json:"product_id,omitempty"`
+	Product            *Product        `json:"product,omitempty"`
+	ProductVariantID   *uint           `gorm:"index" json:"product_variant_id,omitempty"`
+	ProductVariant     *ProductVariant `json:"product_variant,omitempty"`
+	PurchaseOrderID    *uint           `gorm:"index" json:"purchase_order_id"`
+	LotNumber          string          `gorm:"type:varchar(100)" json:"lot_number"`
+	ReceivedAt         time.Time       `gorm:"not null" json:"received_at"`
+	ExpiryDate         *time.Time      `gorm:"type:date;index" json:"expiry_date"`
+	UnitCost           float64         `gorm:"not null;default:0" json:"unit_cost"`
+	InitialQuantity    float64         `gorm:"not null" json:"initial_quantity"`
+	Quantity           float64         `gorm:"not null" json:"quantity"` // Quantity left in the lot
+	WrittenOffQuantity float64         `gorm:"not null;default:0" json:"written_off_quantity"`
+	ExpiryNotifiedAt   *time.Time      `json:"expiry_notified_at"` // Set once the near-expiry email is handled
+	UserID             uint            `gorm:"not null;index" json:"user_id"`
+}
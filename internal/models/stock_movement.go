@@ -6,6 +6,25 @@ import (
 	"gorm.io/gorm"
 )
 
+// Stock movement types not declared next to their feature.
+const (
+	StockMovementOrder         = "Order"
+	StockMovementReturn        = "Return"
+	StockMovementProduction    = "Production"
+	StockMovementPurchaseOrder = "PurchaseOrder"
+)
+
+// Stock movement reference types name the table a movement's ReferenceID points into.
+const (
+	StockReferenceOrder           = "order"
+	StockReferencePurchaseOrder   = "purchase_order"
+	StockReferenceStockTransfer   = "stock_transfer"
+	StockReferenceStockCount      = "stock_count"
+	StockReferenceStockLot        = "stock_lot"
+	StockReferenceStockAdjustment = "stock_adjustment"
+	StockReferenceProductionRun   = "production_run"
+)
+
 // StockMovement represents a record of stock changes
 type StockMovement struct {
 	ID               uint           `gorm:"primaryKey" json:"id"`
@@ -13,12 +32,16 @@ type StockMovement struct {
 	Product          *Product       `gorm:"foreignKey:ProductID"`
 	ProductVariantID *uint          `gorm:"index"`
 	ProductVariant   *ProductVariant `gorm:"foreignKey:ProductVariantID"`
-	OutletID         uint           `gorm:"not null"`
+	OutletID         uint           `gorm:"not null;index:idx_stock_movement_outlet_created"`
 	Outlet           Outlet         `gorm:"foreignKey:OutletID"`
-	QuantityChange   int            `gorm:"not null"` // Positive for increase, negative for decrease
+	QuantityChange   float64        `gorm:"not null"` // Positive for increase, negative for decrease
+	BalanceAfter     float64        `gorm:"not null;default:0"` // Stock on hand at the outlet once the change was applied
 	MovementType     string         `gorm:"type:varchar(50);not null"` // e.g., "Order", "PurchaseOrder", "Adjustment"
-	ReferenceID      *uint          // Optional: ID of the Order, PurchaseOrder, or other reference
+	ReferenceType    string         `gorm:"type:varchar(50);index:idx_stock_movement_reference"` // e.g., "order", "purchase_order"
+	ReferenceID      *uint          `gorm:"index:idx_stock_movement_reference"` // Optional: ID of the Order, PurchaseOrder, or other reference
 	Description      *string        // Optional: A brief description for manual adjustments
-	CreatedAt        time.Time      `json:"created_at"`
+	CreatedBy        *uint          `json:"created_by,omitempty"` // User who performed the movement, set from the transaction context
+	Creator          *User          `gorm:"foreignKey:CreatedBy"`
+	CreatedAt        time.Time      `gorm:"index:idx_stock_movement_outlet_created" json:"created_at"`
 	DeletedAt        gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
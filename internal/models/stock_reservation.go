@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Stock reservation statuses. An active reservation holds stock for an unpaid order; it is
+// converted into a deduction when the order completes, or released when the order is cancelled or
+// left unpaid past the reservation timeout.
+const (
+	StockReservationStatusActive    = "active"
+	StockReservationStatusConverted = "converted"
+	StockReservationStatusReleased  = "released"
+)
+
+// StockMovementShortfall puts back stock an order was sold but that was no longer on hand when the
+// order was paid, so its sale does not take stock below zero.
+const StockMovementShortfall = "Shortfall"
+
+// StockReservation is the stock of one product or variant held for an order at its outlet.
+type StockReservation struct {
+	BaseModel
+	OutletID         uint            `gorm:"not null;index" json:"outlet_id"`
+	Outlet           Outlet          `json:"outlet"`
+	OrderID          uint            `gorm:"not null;index" json:"order_id"`
+	Order            Order           `json:"order"`
+	ProductID        *uint           `gorm:"index" json:"product_id,omitempty"`
+	Product          *Product        `json:"product,omitempty"`
+	ProductVariantID *uint           `gorm:"index" json:"product_variant_id,omitempty"`
+	ProductVariant   *ProductVariant `json:"product_variant,omitempty"`
+	Quantity         float64         `gorm:"not null" json:"quantity"`
+	Status           string          `gorm:"type:varchar(20);not null;index:idx_stock_reservation_status_expiry" json:"status"`
+	ExpiresAt        time.Time       `gorm:"not null;index:idx_stock_reservation_status_expiry" json:"expires_at"`
+	ConvertedAt      *time.Time      `json:"converted_at"`
+	ReleasedAt       *time.Time      `json:"released_at"`
+	UserID           uint            `gorm:"not null;index" json:"user_id"`
+	User             User            `json:"user"`
+}
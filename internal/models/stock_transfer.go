@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Stock transfer statuses. A draft can still be edited; sending deducts the stock at the source
+// outlet and keeps it in transit until the destination outlet receives it.
+const (
+	StockTransferStatusDraft     = "draft"
+	StockTransferStatusSent      = "sent"
+	StockTransferStatusReceived  = "received"
+	StockTransferStatusCancelled = "cancelled"
+)
+
+// Stock movement types written by stock transfers. ReferenceID points at the transfer.
+const (
+	StockMovementTransferOut    = "TransferOut"
+	StockMovementTransferIn     = "TransferIn"
+	StockMovementTransferReturn = "TransferReturn"
+)
+
+// StockTransfer moves stock from one of the owner's outlets to another.
+type StockTransfer struct {
+	BaseModel
+	SourceOutletID      uint                `gorm:"not null;index" json:"source_outlet_id"`
+	SourceOutlet        Outlet              `gorm:"foreignKey:SourceOutletID" json:"source_outlet"`
+	DestinationOutletID uint                `gorm:"not null;index" json:"destination_outlet_id"`
+	DestinationOutlet   Outlet              `gorm:"foreignKey:DestinationOutletID" json:"destination_outlet"`
+	Status              string              `gorm:"type:varchar(20);default:draft;index" json:"status"`
+	Note                string              `gorm:"type:text" json:"note"`
+	SentAt              *time.Time          `json:"sent_at"`
+	SentBy              *uint               `json:"sent_by"`
+	ReceivedAt          *time.Time          `json:"received_at"`
+	ReceivedBy          *uint               `json:"received_by"`
+	CancelledAt         *time.Time          `json:"cancelled_at"`
+	Items               []StockTransferItem `json:"items"`
+	UserID              uint                `gorm:"not null;index" json:"user_id"`
+	User                User                `json:"user"`
+}
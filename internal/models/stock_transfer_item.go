@@ -0,0 +1,13 @@
+package models
+
+type StockTransferItem struct {
+	BaseModel
+	StockTransferID  uint            `gorm:"not null;index" json:"stock_transfer_id"`
+	ProductID        *uint           `gorm:"index" json:"product_id,omitempty"`
+	Product          *Product        `json:"product,omitempty"`
+	ProductVariantID *uint           `gorm:"index" json:"product_variant_id,omitempty"`
+	ProductVariant   *ProductVariant `json:"product_variant,omitempty"`
+	Quantity         float64         `gorm:"not null" json:"quantity"`          // Quantity sent from the source outlet
+	ReceivedQuantity *float64        `json:"received_quantity"`                 // Quantity counted in at the destination outlet
+	DiscrepancyNote  string          `gorm:"type:text" json:"discrepancy_note"` // Why the received quantity differs from the sent one
+}
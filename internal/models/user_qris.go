@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserQris holds the static QRIS an owner received from their bank. Dynamic codes for each
+// payment are generated from it.
+type UserQris struct {
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	UserID       uint           `gorm:"not null"`
+	User         User           `gorm:"foreignKey:UserID"`
+	Payload      string         `gorm:"type:text;not null" json:"payload"`
+	MerchantName string         `gorm:"type:varchar(255)" json:"merchant_name"`
+	MerchantCity string         `gorm:"type:varchar(255)" json:"merchant_city"`
+	NMID         string         `gorm:"type:varchar(255)" json:"nmid"` // National Merchant ID
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
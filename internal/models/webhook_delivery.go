@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusSucceeded = "succeeded"
+	WebhookDeliveryStatusFailed    = "failed"
+)
+
+// WebhookDelivery is one event sent to one endpoint, with the outcome of its latest attempt.
+type WebhookDelivery struct {
+	BaseModel
+	WebhookEndpointID uint            `gorm:"not null;index" json:"webhook_endpoint_id"`
+	WebhookEndpoint   WebhookEndpoint `json:"webhook_endpoint"`
+	WebhookEventID    uint            `gorm:"not null;index" json:"webhook_event_id"`
+	WebhookEvent      WebhookEvent    `json:"webhook_event"`
+	EventType         string          `gorm:"type:varchar(50);not null" json:"event_type"`
+	Status            string          `gorm:"type:varchar(20);default:pending;index" json:"status"`
+	Attempts          int             `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt     *time.Time      `gorm:"index" json:"next_attempt_at"`
+	LastAttemptAt     *time.Time      `json:"last_attempt_at"`
+	ResponseStatus    int             `json:"response_status"`
+	ResponseBody      string          `gorm:"type:text" json:"response_body"` // Truncated response of the latest attempt
+	Error             string          `gorm:"type:varchar(500)" json:"error"`
+	DeliveredAt       *time.Time      `json:"delivered_at"`
+}
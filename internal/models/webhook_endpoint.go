@@ -0,0 +1,25 @@
+package models
+
+// Webhook event types owners can subscribe to
+const (
+	WebhookEventOrderCreated          = "order.created"
+	WebhookEventOrderCompleted        = "order.completed"
+	WebhookEventPaymentPaid           = "payment.paid"
+	WebhookEventPaymentRefunded       = "payment.refunded"
+	WebhookEventPaymentOrphaned       = "payment.orphaned"
+	WebhookEventStockLow              = "stock.low"
+	WebhookEventPurchaseOrderReceived = "purchase_order.received"
+	WebhookEventPing                  = "ping"
+)
+
+// WebhookEndpoint is a URL of a partner integration that receives the owner's events.
+type WebhookEndpoint struct {
+	BaseModel
+	URL         string `gorm:"type:varchar(500);not null" json:"url"`
+	Secret      string `gorm:"type:varchar(255);not null" json:"-"`           // Signs every delivery
+	EventTypes  string `gorm:"type:varchar(500);not null" json:"event_types"` // Comma separated subscribed event types
+	Description string `gorm:"type:varchar(255)" json:"description"`
+	IsActive    bool   `gorm:"default:true" json:"is_active"`
+	UserID      uint   `gorm:"not null;index" json:"user_id"`
+	User        User   `json:"user"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// WebhookEvent is an event waiting to be fanned out to the owner's webhook endpoints. It is written in
+// the same transaction as the change it describes, so rolled back changes never notify anyone.
+type WebhookEvent struct {
+	BaseModel
+	EventType    string     `gorm:"type:varchar(50);not null" json:"event_type"`
+	Payload      string     `gorm:"type:jsonb;not null" json:"payload"`
+	DispatchedAt *time.Time `gorm:"index" json:"dispatched_at"` // Set once deliveries were created for every subscribed endpoint
+	UserID       uint       `gorm:"not null;index" json:"user_id"`
+}
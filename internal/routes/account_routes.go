@@ -17,13 +17,16 @@ func RegisterAccountRoutes(e *echo.Echo, db *gorm.DB) {
 	authHandler := handlers.NewAuthHandler(authService, userContextService)
 
 	ipaymuService := services.NewIpaymuService(db, userContextService)
-	ipaymuHandler := handlers.NewIpaymuHandler(ipaymuService, userContextService)
 
 	userPaymentService := services.NewUserPaymentService(db, userContextService) // Assuming this is needed for tsmService
 	tsmLogService := services.NewTsmLogService(db)
-	orderPaymentService := services.NewOrderPaymentService(db, userContextService, ipaymuService, nil)
-	tsmService := services.NewTsmService(db, userContextService, userPaymentService, tsmLogService, orderPaymentService)
-	tsmHandler := handlers.NewTsmHandler(tsmService, userContextService, userPaymentService)
+	tsmService := services.NewTsmService(db, userContextService, userPaymentService, tsmLogService)
+	paymentProviders := services.NewDefaultPaymentProviderRegistry(db, ipaymuService, tsmService)
+	orderPaymentService := services.NewOrderPaymentService(db, userContextService, paymentProviders)
+	ipaymuHandler := handlers.NewIpaymuHandler(ipaymuService, userContextService, orderPaymentService)
+	tsmHandler := handlers.NewTsmHandler(tsmService, userContextService, userPaymentService, orderPaymentService)
+	qrisService := services.NewQrisService(db, userContextService)
+	qrisHandler := handlers.NewQrisHandler(qrisService, userContextService)
 
 	selfAuthGroup := e.Group("")
 	selfAuthGroup.Use(internalmw.SelfAuthorize())
@@ -40,5 +43,9 @@ func RegisterAccountRoutes(e *echo.Echo, db *gorm.DB) {
 
 		tsmGroup := selfAuthGroup.Group("/tsm")
 		tsmGroup.POST("/register", tsmHandler.RegisterTsm, WithValidation(&dtos.TsmRegisterRequest{}, validators.ValidateTsmRegister))
+
+		qrisGroup := selfAuthGroup.Group("/qris")
+		qrisGroup.POST("/register", qrisHandler.RegisterQris, WithValidation(&dtos.QrisRegisterRequest{}, validators.ValidateQrisRegister))
+		qrisGroup.GET("", qrisHandler.GetQris)
 	}
 }
@@ -31,9 +31,21 @@ func RegisterApiRoutes(e *echo.Echo, db *gorm.DB) {
 	outletService := services.NewOutletService(db, userContextService)
 	outletHandler := handlers.NewOutletHandler(outletService, userContextService)
 	stockHandler := handlers.NewStockHandler(stockService, userContextService)
+	stockAlertService := services.NewStockAlertService(db, userContextService)
+	stockAlertHandler := handlers.NewStockAlertHandler(stockAlertService, userContextService)
+	stockTransferService := services.NewStockTransferService(db, userContextService, stockMovementService)
+	stockTransferHandler := handlers.NewStockTransferHandler(stockTransferService, userContextService)
+	stockCountService := services.NewStockCountService(db, userContextService, stockMovementService)
+	stockCountHandler := handlers.NewStockCountHandler(stockCountService, userContextService)
+	stockAdjustmentService := services.NewStockAdjustmentService(db, userContextService, stockMovementService)
+	stockAdjustmentHandler := handlers.NewStockAdjustmentHandler(stockAdjustmentService, userContextService)
+	stockLotService := services.NewStockLotService(db, userContextService, stockMovementService)
+	stockLotHandler := handlers.NewStockLotHandler(stockLotService, userContextService)
 
 	productAddOnService := services.NewProductAddOnService(db, userContextService)
 	productAddOnHandler := handlers.NewProductAddOnHandler(productAddOnService, userContextService)
+	productUnitService := services.NewProductUnitService(db, userContextService)
+	productUnitHandler := handlers.NewProductUnitHandler(productUnitService, userContextService)
 
 	ipaymuService := services.NewIpaymuService(db, userContextService) // Assuming this is needed for orderService
 	orderService := services.NewOrderService(db, stockService, ipaymuService, userContextService)
@@ -49,13 +61,33 @@ func RegisterApiRoutes(e *echo.Echo, db *gorm.DB) {
 	poHandler := handlers.NewPurchaseOrderHandler(poService, userContextService)
 
 	tsmLogService := services.NewTsmLogService(db)
-	orderPaymentService := services.NewOrderPaymentService(db, userContextService, ipaymuService, nil)
-	tsmService := services.NewTsmService(db, userContextService, userPaymentService, tsmLogService, orderPaymentService)
-	orderPaymentService.TsmService = tsmService
-	ipaymuService.SetOrderPaymentService(orderPaymentService)
+	tsmService := services.NewTsmService(db, userContextService, userPaymentService, tsmLogService)
+	paymentProviders := services.NewDefaultPaymentProviderRegistry(db, ipaymuService, tsmService)
+	orderPaymentService := services.NewOrderPaymentService(db, userContextService, paymentProviders)
 
-	tsmHandler := handlers.NewTsmHandler(tsmService, userContextService, userPaymentService)
+	tsmHandler := handlers.NewTsmHandler(tsmService, userContextService, userPaymentService, orderPaymentService)
 	orderPaymentHandler := handlers.NewOrderPaymentHandler(orderPaymentService, userContextService)
+	orderPaymentRefundService := services.NewOrderPaymentRefundService(db, userContextService, paymentProviders)
+	orderPaymentRefundHandler := handlers.NewOrderPaymentRefundHandler(orderPaymentRefundService, userContextService)
+	paymentMethodService := services.NewPaymentMethodService(db, userContextService, paymentProviders)
+	paymentMethodHandler := handlers.NewPaymentMethodHandler(paymentMethodService, userContextService)
+	manualTransferService := services.NewManualTransferService(db, userContextService, orderPaymentService)
+	manualTransferHandler := handlers.NewManualTransferHandler(manualTransferService, userContextService)
+	paymentLinkService := services.NewPaymentLinkService(db, userContextService, orderPaymentService)
+	paymentLinkHandler := handlers.NewPaymentLinkHandler(paymentLinkService, userContextService)
+
+	customerService := services.NewCustomerService(db, userContextService)
+	customerWalletService := services.NewCustomerWalletService(db, userContextService, orderPaymentService)
+	customerHandler := handlers.NewCustomerHandler(customerService, customerWalletService, userContextService)
+
+	webhookService := services.NewWebhookService(db, userContextService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService, userContextService)
+
+	selfOrderService := services.NewSelfOrderService(db, userContextService, productService, orderService, orderPaymentService)
+	selfOrderHandler := handlers.NewSelfOrderHandler(selfOrderService, userContextService)
+
+	marketplaceService := services.NewMarketplaceService(db, userContextService, stockService)
+	marketplaceHandler := handlers.NewMarketplaceHandler(marketplaceService, userContextService)
 
 	authorizedGroup := e.Group("")
 	{
@@ -74,6 +106,17 @@ func RegisterApiRoutes(e *echo.Echo, db *gorm.DB) {
 		userPaymentGroup.POST("/deactivate", userPaymentHandler.DeactivateUserPayment, internalmw.Authorize("user_payments", "deactivate"))
 		userPaymentGroup.GET("", userPaymentHandler.ListAllPaymentMethodsWithUserStatus, internalmw.Authorize("user_payments", "read"))
 
+		// Payment method administration
+		paymentMethodGroup := authorizedGroup.Group("/payment-methods", internalmw.Authorize("payment_methods", "manage"))
+		paymentMethodGroup.GET("", paymentMethodHandler.GetAllPaymentMethods)
+		paymentMethodGroup.POST("", paymentMethodHandler.CreatePaymentMethod, WithValidation(&dtos.PaymentMethodRequest{}, validators.ValidatePaymentMethod))
+		paymentMethodGroup.PUT("/:id", paymentMethodHandler.UpdatePaymentMethod, WithValidation(&dtos.PaymentMethodRequest{}, validators.ValidatePaymentMethod))
+		paymentMethodGroup.DELETE("/:id", paymentMethodHandler.DeactivatePaymentMethod)
+
+		outletPaymentMethodGroup := authorizedGroup.Group("/outlets/:outlet_uuid/payment-methods", internalmw.Authorize("outlet_payment_methods", "read"))
+		outletPaymentMethodGroup.GET("", paymentMethodHandler.GetOutletPaymentMethods)
+		outletPaymentMethodGroup.PUT("", paymentMethodHandler.SetOutletPaymentMethods, internalmw.Authorize("outlet_payment_methods", "write"), WithValidation(&dtos.SetOutletPaymentMethodsRequest{}, validators.ValidateSetOutletPaymentMethods))
+
 		// Product routes
 		productGroup := authorizedGroup.Group("/products", internalmw.Authorize("products", "read"))
 		productGroup.GET("", productHandler.GetAllProducts)
@@ -90,6 +133,15 @@ func RegisterApiRoutes(e *echo.Echo, db *gorm.DB) {
 		productAddOnDeleteGroup := authorizedGroup.Group("/product-add-ons", internalmw.Authorize("products", "write"))
 		productAddOnDeleteGroup.DELETE("/:uuid", productAddOnHandler.DeleteProductAddOn)
 
+		// Product unit routes
+		productUnitGroup := authorizedGroup.Group("/products/:product_uuid/units", internalmw.Authorize("products", "read"))
+		productUnitGroup.GET("", productUnitHandler.GetProductUnits)
+		productUnitGroup.POST("", productUnitHandler.CreateProductUnit, internalmw.Authorize("products", "write"), WithValidation(&dtos.ProductUnitRequest{}, validators.ValidateProductUnit))
+
+		productUnitItemGroup := authorizedGroup.Group("/product-units", internalmw.Authorize("products", "write"))
+		productUnitItemGroup.PUT("/:uuid", productUnitHandler.UpdateProductUnit, WithValidation(&dtos.ProductUnitRequest{}, validators.ValidateProductUnit))
+		productUnitItemGroup.DELETE("/:uuid", productUnitHandler.DeleteProductUnit)
+
 		outletProductGroup := authorizedGroup.Group("/outlets/:outlet_uuid/products", internalmw.Authorize("products", "read"))
 		outletProductGroup.GET("", productHandler.GetProductsByOutlet)
 
@@ -116,6 +168,58 @@ func RegisterApiRoutes(e *echo.Echo, db *gorm.DB) {
 		stockGroup.GET("", stockHandler.GetOutletStocks)
 		stockGroup.PUT("", stockHandler.UpdateStock, internalmw.Authorize("stocks", "write"), WithValidation(&dtos.UpdateStockRequest{}, validators.ValidateUpdateStock))
 		stockGroup.POST("/produce-fnb", productHandler.ProduceFNBProduct, internalmw.Authorize("stocks", "write"), WithValidation(&dtos.FNBProductionRequest{}, validators.ValidateFNBProductionRequest))
+		stockGroup.PUT("/reorder-point", stockAlertHandler.SetReorderPoint, internalmw.Authorize("stocks", "write"), WithValidation(&dtos.SetReorderPointRequest{}, validators.ValidateSetReorderPoint))
+		stockGroup.GET("/lots", stockLotHandler.GetLotsByOutlet)
+
+		// Stock movement ledger routes
+		stockMovementGroup := authorizedGroup.Group("/outlets/:outlet_uuid/stock-movements", internalmw.Authorize("stocks", "read"))
+		stockMovementGroup.GET("", stockHandler.GetStockMovements)
+		stockMovementGroup.GET("/as-of", stockHandler.GetStockAsOf)
+
+		// Low stock dashboard and alerts
+		authorizedGroup.GET("/low-stock", stockAlertHandler.GetLowStock, internalmw.Authorize("stocks", "read"))
+		stockAlertGroup := authorizedGroup.Group("/stock-alerts", internalmw.Authorize("stocks", "read"))
+		stockAlertGroup.GET("", stockAlertHandler.GetAlerts)
+		stockAlertGroup.POST("/:uuid/acknowledge", stockAlertHandler.AcknowledgeAlert, internalmw.Authorize("stocks", "write"))
+
+		// Stock lot (batch and expiry) routes
+		stockLotGroup := authorizedGroup.Group("/stock-lots", internalmw.Authorize("stocks", "read"))
+		stockLotGroup.GET("/expiring", stockLotHandler.GetExpiringLots)
+		stockLotGroup.POST("/:uuid/write-off", stockLotHandler.WriteOffLot, internalmw.Authorize("stocks", "write"), WithValidation(&dtos.WriteOffStockLotRequest{}, validators.ValidateWriteOffStockLot))
+
+		// Stock transfer routes
+		stockTransferGroup := authorizedGroup.Group("/stock-transfers", internalmw.Authorize("stock_transfers", "read"))
+		stockTransferGroup.POST("", stockTransferHandler.CreateTransfer, internalmw.Authorize("stock_transfers", "write"), WithValidation(&dtos.StockTransferRequest{}, validators.ValidateStockTransfer))
+		stockTransferGroup.GET("/:uuid", stockTransferHandler.GetTransfer)
+		stockTransferGroup.PUT("/:uuid", stockTransferHandler.UpdateTransfer, internalmw.Authorize("stock_transfers", "write"), WithValidation(&dtos.StockTransferRequest{}, validators.ValidateStockTransfer))
+		stockTransferGroup.POST("/:uuid/send", stockTransferHandler.SendTransfer, internalmw.Authorize("stock_transfers", "write"))
+		stockTransferGroup.POST("/:uuid/receive", stockTransferHandler.ReceiveTransfer, internalmw.Authorize("stock_transfers", "write"), WithValidation(&dtos.ReceiveStockTransferRequest{}, validators.ValidateReceiveStockTransfer))
+		stockTransferGroup.POST("/:uuid/cancel", stockTransferHandler.CancelTransfer, internalmw.Authorize("stock_transfers", "write"))
+		authorizedGroup.GET("/outlets/:outlet_uuid/stock-transfers", stockTransferHandler.GetTransfersByOutlet, internalmw.Authorize("stock_transfers", "read"))
+
+		// Stock count (opname) routes
+		outletStockCountGroup := authorizedGroup.Group("/outlets/:outlet_uuid/stock-counts", internalmw.Authorize("stock_counts", "read"))
+		outletStockCountGroup.GET("", stockCountHandler.GetCountsByOutlet)
+		outletStockCountGroup.POST("", stockCountHandler.StartCount, internalmw.Authorize("stock_counts", "write"), WithValidation(&dtos.StartStockCountRequest{}, validators.ValidateStartStockCount))
+		stockCountGroup := authorizedGroup.Group("/stock-counts", internalmw.Authorize("stock_counts", "read"))
+		stockCountGroup.GET("/:uuid", stockCountHandler.GetCount)
+		stockCountGroup.GET("/:uuid/variance", stockCountHandler.GetVarianceReport)
+		stockCountGroup.POST("/:uuid/entries", stockCountHandler.AddEntries, internalmw.Authorize("stock_counts", "write"), WithValidation(&dtos.AddStockCountEntriesRequest{}, validators.ValidateAddStockCountEntries))
+		stockCountGroup.DELETE("/:uuid/entries/:entry_uuid", stockCountHandler.DeleteEntry, internalmw.Authorize("stock_counts", "write"))
+		stockCountGroup.POST("/:uuid/submit", stockCountHandler.SubmitCount, internalmw.Authorize("stock_counts", "write"))
+		stockCountGroup.POST("/:uuid/approve", stockCountHandler.ApproveCount, internalmw.Authorize("stock_counts", "approve"))
+		stockCountGroup.POST("/:uuid/cancel", stockCountHandler.CancelCount, internalmw.Authorize("stock_counts", "write"))
+
+		// Stock adjustment (waste) routes
+		outletStockAdjustmentGroup := authorizedGroup.Group("/outlets/:outlet_uuid/stock-adjustments", internalmw.Authorize("stock_adjustments", "read"))
+		outletStockAdjustmentGroup.GET("", stockAdjustmentHandler.GetAdjustmentsByOutlet)
+		outletStockAdjustmentGroup.POST("", stockAdjustmentHandler.CreateAdjustment, internalmw.Authorize("stock_adjustments", "write"), WithValidation(&dtos.StockAdjustmentRequest{}, validators.ValidateStockAdjustment))
+		stockAdjustmentGroup := authorizedGroup.Group("/stock-adjustments", internalmw.Authorize("stock_adjustments", "read"))
+		stockAdjustmentGroup.GET("/:uuid", stockAdjustmentHandler.GetAdjustment)
+		stockAdjustmentGroup.GET("/:uuid/photo", stockAdjustmentHandler.GetPhoto)
+		stockAdjustmentGroup.POST("/:uuid/photo", stockAdjustmentHandler.UploadPhoto, internalmw.Authorize("stock_adjustments", "write"))
+		stockAdjustmentGroup.POST("/:uuid/approve", stockAdjustmentHandler.ApproveAdjustment, internalmw.Authorize("stock_adjustments", "approve"), WithValidation(&dtos.ReviewStockAdjustmentRequest{}, validators.ValidateReviewStockAdjustment))
+		stockAdjustmentGroup.POST("/:uuid/reject", stockAdjustmentHandler.RejectAdjustment, internalmw.Authorize("stock_adjustments", "approve"), WithValidation(&dtos.ReviewStockAdjustmentRequest{}, validators.ValidateReviewStockAdjustment))
 
 		// Order routes
 		orderGroup := authorizedGroup.Group("/orders")
@@ -124,19 +228,102 @@ func RegisterApiRoutes(e *echo.Echo, db *gorm.DB) {
 		orderGroup.PUT("/:uuid/items", orderHandler.UpdateOrderItem, internalmw.Authorize("orders", "write"), WithValidation(&dtos.UpdateOrderItemRequest{}, validators.ValidateUpdateOrderItemRequest))
 		orderGroup.DELETE("/:uuid/items", orderHandler.DeleteOrderItem, internalmw.Authorize("orders", "write"), WithValidation(&dtos.DeleteOrderItemRequest{}, validators.ValidateDeleteOrderItemRequest))
 		orderGroup.POST("/:uuid/items", orderHandler.CreateOrderItem, internalmw.Authorize("orders", "write"), WithValidation(&dtos.CreateOrderItemRequest{}, validators.ValidateCreateOrderItemRequest))
+		orderGroup.POST("/:uuid/cancel", orderHandler.CancelOrder, internalmw.Authorize("orders", "write"))
+		orderGroup.POST("/:uuid/payment-links", paymentLinkHandler.CreatePaymentLink, internalmw.Authorize("order_payments", "write"), WithValidation(&dtos.CreatePaymentLinkRequest{}, validators.ValidateCreatePaymentLink))
+		orderGroup.GET("/:uuid/payment-links", paymentLinkHandler.GetPaymentLinks, internalmw.Authorize("order_payments", "read"))
 
 		// Order Payment routes
 		orderPaymentGroup := authorizedGroup.Group("/order-payments")
 		orderPaymentGroup.POST("", orderPaymentHandler.CreateOrderPayment, internalmw.Authorize("order_payments", "write"), WithValidation(&dtos.CreateOrderPaymentRequest{}, validators.ValidateCreateOrderPayment))
+		orderPaymentGroup.POST("/:uuid/check-status", orderPaymentHandler.CheckPaymentStatus, internalmw.Authorize("order_payments", "read"))
+		orderPaymentGroup.POST("/:uuid/cancel", orderPaymentHandler.CancelOrderPayment, internalmw.Authorize("order_payments", "write"))
+		orderPaymentGroup.POST("/:uuid/confirm", orderPaymentHandler.ConfirmOrderPayment, internalmw.Authorize("order_payments", "write"))
+		orderPaymentGroup.POST("/:uuid/retry", orderPaymentHandler.RetryOrderPayment, internalmw.Authorize("order_payments", "write"), WithValidation(&dtos.RetryOrderPaymentRequest{}, validators.ValidateRetryOrderPayment))
+		orderPaymentGroup.POST("/:uuid/refunds", orderPaymentRefundHandler.CreateRefund, internalmw.Authorize("order_payments", "refund"), WithValidation(&dtos.CreateOrderPaymentRefundRequest{}, validators.ValidateCreateOrderPaymentRefund))
+		orderPaymentGroup.GET("/:uuid/refunds", orderPaymentRefundHandler.GetRefunds, internalmw.Authorize("order_payments", "read"))
+		orderPaymentGroup.POST("/:uuid/proofs", manualTransferHandler.AttachProof, internalmw.Authorize("order_payments", "write"))
+		orderPaymentGroup.GET("/:uuid/proofs", manualTransferHandler.GetProofs, internalmw.Authorize("order_payments", "read"))
+		orderPaymentGroup.POST("/:uuid/transfer/confirm", manualTransferHandler.ConfirmTransfer, internalmw.Authorize("payment_reviews", "write"), WithValidation(&dtos.ConfirmTransferRequest{}, validators.ValidateConfirmTransfer))
+		orderPaymentGroup.POST("/:uuid/transfer/reject", manualTransferHandler.RejectTransfer, internalmw.Authorize("payment_reviews", "write"), WithValidation(&dtos.RejectTransferRequest{}, validators.ValidateRejectTransfer))
+
+		paymentLinkGroup := authorizedGroup.Group("/payment-links", internalmw.Authorize("order_payments", "write"))
+		paymentLinkGroup.POST("/:uuid/send", paymentLinkHandler.SendPaymentLink, WithValidation(&dtos.SendPaymentLinkRequest{}, validators.ValidateSendPaymentLink))
+		paymentLinkGroup.POST("/:uuid/cancel", paymentLinkHandler.CancelPaymentLink)
+
+		// Customer and wallet routes
+		customerGroup := authorizedGroup.Group("/customers", internalmw.Authorize("customers", "read"))
+		customerGroup.GET("", customerHandler.GetCustomers)
+		customerGroup.POST("", customerHandler.CreateCustomer, internalmw.Authorize("customers", "write"), WithValidation(&dtos.CustomerRequest{}, validators.ValidateCustomer))
+		customerGroup.GET("/:uuid", customerHandler.GetCustomer)
+		customerGroup.PUT("/:uuid", customerHandler.UpdateCustomer, internalmw.Authorize("customers", "write"), WithValidation(&dtos.CustomerRequest{}, validators.ValidateCustomer))
+		customerGroup.GET("/:uuid/wallet/transactions", customerHandler.GetWalletTransactions)
+		customerGroup.POST("/:uuid/wallet/top-ups", customerHandler.TopUpWallet, internalmw.Authorize("customers", "write"), WithValidation(&dtos.TopUpCustomerWalletRequest{}, validators.ValidateTopUpCustomerWallet))
+		customerGroup.POST("/:uuid/wallet/adjustments", customerHandler.AdjustWallet, internalmw.Authorize("customer_wallets", "adjust"), WithValidation(&dtos.AdjustCustomerWalletRequest{}, validators.ValidateAdjustCustomerWallet))
+
+		// Outbound webhook routes
+		webhookGroup := authorizedGroup.Group("/webhooks", internalmw.Authorize("webhooks", "read"))
+		webhookGroup.GET("", webhookHandler.GetEndpoints)
+		webhookGroup.POST("", webhookHandler.CreateEndpoint, internalmw.Authorize("webhooks", "write"), WithValidation(&dtos.WebhookEndpointRequest{}, validators.ValidateWebhookEndpoint))
+		webhookGroup.PUT("/:uuid", webhookHandler.UpdateEndpoint, internalmw.Authorize("webhooks", "write"), WithValidation(&dtos.WebhookEndpointRequest{}, validators.ValidateWebhookEndpoint))
+		webhookGroup.DELETE("/:uuid", webhookHandler.DeleteEndpoint, internalmw.Authorize("webhooks", "write"))
+		webhookGroup.POST("/:uuid/ping", webhookHandler.PingEndpoint, internalmw.Authorize("webhooks", "write"))
+		webhookGroup.GET("/:uuid/deliveries", webhookHandler.GetDeliveries)
+
+		webhookDeliveryGroup := authorizedGroup.Group("/webhook-deliveries", internalmw.Authorize("webhooks", "write"))
+		webhookDeliveryGroup.POST("/:uuid/redeliver", webhookHandler.RedeliverDelivery)
+
+		orderPaymentProofGroup := authorizedGroup.Group("/order-payment-proofs", internalmw.Authorize("order_payments", "read"))
+		orderPaymentProofGroup.GET("/:uuid/file", manualTransferHandler.GetProofFile)
+
+		outletTransferGroup := authorizedGroup.Group("/outlets/:outlet_uuid/pending-transfers", internalmw.Authorize("payment_reviews", "read"))
+		outletTransferGroup.GET("", manualTransferHandler.GetPendingTransfers)
 
 		outletOrdersGroup := authorizedGroup.Group("/outlets/:outlet_uuid/orders", internalmw.Authorize("orders", "read"))
 		outletOrdersGroup.GET("", orderHandler.GetOrdersByOutlet)
 
+		// Outlet table routes (QR self-ordering)
+		outletTableGroup := authorizedGroup.Group("/outlets/:outlet_uuid/tables", internalmw.Authorize("outlet_tables", "read"))
+		outletTableGroup.GET("", selfOrderHandler.GetTablesByOutlet)
+		outletTableGroup.POST("", selfOrderHandler.CreateTable, internalmw.Authorize("outlet_tables", "write"), WithValidation(&dtos.CreateOutletTableRequest{}, validators.ValidateCreateOutletTable))
+
+		tableGroup := authorizedGroup.Group("/outlet-tables", internalmw.Authorize("outlet_tables", "write"))
+		tableGroup.POST("/:uuid/rotate-token", selfOrderHandler.RotateTableToken)
+
+		// Self order routes
+		outletSelfOrderGroup := authorizedGroup.Group("/outlets/:outlet_uuid/self-orders", internalmw.Authorize("self_orders", "read"))
+		outletSelfOrderGroup.GET("", selfOrderHandler.GetSelfOrdersByOutlet)
+
+		selfOrderGroup := authorizedGroup.Group("/self-orders", internalmw.Authorize("self_orders", "write"))
+		selfOrderGroup.POST("/:uuid/accept", selfOrderHandler.AcceptSelfOrder)
+		selfOrderGroup.POST("/:uuid/reject", selfOrderHandler.RejectSelfOrder, WithValidation(&dtos.RejectSelfOrderRequest{}, validators.ValidateRejectSelfOrder))
+
+		// Marketplace channel routes
+		outletMarketplaceGroup := authorizedGroup.Group("/outlets/:outlet_uuid/marketplace-channels", internalmw.Authorize("marketplace", "read"))
+		outletMarketplaceGroup.GET("", marketplaceHandler.GetChannelsByOutlet)
+		outletMarketplaceGroup.POST("", marketplaceHandler.CreateChannel, internalmw.Authorize("marketplace", "write"), WithValidation(&dtos.CreateMarketplaceChannelRequest{}, validators.ValidateCreateMarketplaceChannel))
+
+		marketplaceChannelGroup := authorizedGroup.Group("/marketplace-channels", internalmw.Authorize("marketplace", "read"))
+		marketplaceChannelGroup.GET("/:uuid/item-mappings", marketplaceHandler.GetItemMappings)
+		marketplaceChannelGroup.POST("/:uuid/item-mappings", marketplaceHandler.CreateItemMapping, internalmw.Authorize("marketplace", "write"), WithValidation(&dtos.CreateMarketplaceItemMappingRequest{}, validators.ValidateCreateMarketplaceItemMapping))
+
+		marketplaceMappingGroup := authorizedGroup.Group("/marketplace-item-mappings", internalmw.Authorize("marketplace", "write"))
+		marketplaceMappingGroup.DELETE("/:uuid", marketplaceHandler.DeleteItemMapping)
+
+		// Marketplace order routes
+		outletMarketplaceOrderGroup := authorizedGroup.Group("/outlets/:outlet_uuid/marketplace-orders", internalmw.Authorize("marketplace_orders", "read"))
+		outletMarketplaceOrderGroup.GET("", marketplaceHandler.GetMarketplaceOrdersByOutlet)
+
+		marketplaceOrderGroup := authorizedGroup.Group("/marketplace-orders", internalmw.Authorize("marketplace_orders", "write"))
+		marketplaceOrderGroup.PUT("/:uuid/status", marketplaceHandler.UpdateMarketplaceOrderStatus, WithValidation(&dtos.UpdateMarketplaceOrderStatusRequest{}, validators.ValidateUpdateMarketplaceOrderStatus))
+
 		// Report routes
 		reportGroup := authorizedGroup.Group("/reports", internalmw.Authorize("reports", "read"))
 		reportGroup.GET("/outlets/:outlet_uuid/sales", reportHandler.GetSalesByOutletReport)
 		reportGroup.GET("/products/:product_uuid/sales", reportHandler.GetSalesByProductReport)
 		reportGroup.GET("/outlets/:outlet_uuid/stock", reportHandler.GetStockReport)
+		reportGroup.GET("/outlets/:outlet_uuid/payment-reconciliation", reportHandler.GetPaymentReconciliationReport)
+		reportGroup.GET("/gross-margin", reportHandler.GetGrossMarginReport)
+		reportGroup.GET("/waste", reportHandler.GetWasteReport)
 
 		// Supplier routes
 		supplierGroup := authorizedGroup.Group("/suppliers", internalmw.Authorize("suppliers", "read"))
@@ -149,7 +336,7 @@ func RegisterApiRoutes(e *echo.Echo, db *gorm.DB) {
 		// Purchase Order routes
 		poGroup := authorizedGroup.Group("/purchase-orders")
 		poGroup.POST("", poHandler.CreatePurchaseOrder, internalmw.Authorize("purchase_orders", "write"), WithValidation(&dtos.CreatePurchaseOrderRequest{}, validators.ValidateCreatePurchaseOrder))
-		poGroup.PUT("/:uuid/receive", poHandler.ReceivePurchaseOrder, internalmw.Authorize("purchase_orders", "write"))
+		poGroup.PUT("/:uuid/receive", poHandler.ReceivePurchaseOrder, internalmw.Authorize("purchase_orders", "write"), WithValidation(&dtos.ReceivePurchaseOrderRequest{}, validators.ValidateReceivePurchaseOrder))
 		poGroup.GET("/:uuid", poHandler.GetPurchaseOrderByUuid, internalmw.Authorize("purchase_orders", "read"))
 
 		outletPoGroup := authorizedGroup.Group("/outlets/:outlet_uuid/purchase-orders", internalmw.Authorize("purchase_orders", "read"))
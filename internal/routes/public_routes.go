@@ -5,6 +5,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/msyaifudin/pos/internal/handlers"
+	internalmw "github.com/msyaifudin/pos/internal/middleware"
 	"github.com/msyaifudin/pos/internal/models/dtos"
 	"github.com/msyaifudin/pos/internal/services"
 	"github.com/msyaifudin/pos/internal/validators"
@@ -18,25 +19,50 @@ func RegisterPublicRoutes(e *echo.Echo, db *gorm.DB) {
 	// Dependencies for TsmService & OrderPaymentService
 	userPaymentService := services.NewUserPaymentService(db, userContextService)
 	tsmLogService := services.NewTsmLogService(db)
-	orderPaymentService := services.NewOrderPaymentService(db, userContextService, nil, nil)
-	tsmService := services.NewTsmService(db, userContextService, userPaymentService, tsmLogService, orderPaymentService)
-
-	// Initialize IpaymuService
+	tsmService := services.NewTsmService(db, userContextService, userPaymentService, tsmLogService)
 	ipaymuService := services.NewIpaymuService(db, userContextService)
 
-	// Set IpaymuService in OrderPaymentService
-	orderPaymentService.IpaymuService = ipaymuService
-	orderPaymentService.TsmService = tsmService
+	// Payment gateways are resolved by issuer, so gateway services no longer need OrderPaymentService
+	paymentProviders := services.NewDefaultPaymentProviderRegistry(db, ipaymuService, tsmService)
+	orderPaymentService := services.NewOrderPaymentService(db, userContextService, paymentProviders)
+
+	ipaymuHandler := handlers.NewIpaymuHandler(ipaymuService, userContextService, orderPaymentService)
+	tsmHandler := handlers.NewTsmHandler(tsmService, userContextService, userPaymentService, orderPaymentService)
+	orderPaymentHandler := handlers.NewOrderPaymentHandler(orderPaymentService, userContextService)
+	paymentLinkService := services.NewPaymentLinkService(db, userContextService, orderPaymentService)
+	paymentLinkHandler := handlers.NewPaymentLinkHandler(paymentLinkService, userContextService)
 
-	// Set OrderPaymentService in IpaymuService to resolve circular dependency
-	ipaymuService.SetOrderPaymentService(orderPaymentService)
+	// Dependencies for QR table self-ordering
+	stockMovementService := services.NewStockMovementService(db)
+	stockService := services.NewStockService(db, userContextService, stockMovementService)
+	productService := services.NewProductService(db, userContextService)
+	orderService := services.NewOrderService(db, stockService, ipaymuService, userContextService)
+	selfOrderService := services.NewSelfOrderService(db, userContextService, productService, orderService, orderPaymentService)
+	selfOrderHandler := handlers.NewSelfOrderHandler(selfOrderService, userContextService)
 
-	ipaymuHandler := handlers.NewIpaymuHandler(ipaymuService, userContextService)
-	tsmHandler := handlers.NewTsmHandler(tsmService, userContextService, userPaymentService)
+	marketplaceService := services.NewMarketplaceService(db, userContextService, stockService)
+	marketplaceHandler := handlers.NewMarketplaceHandler(marketplaceService, userContextService)
 
 	e.GET("", func(c echo.Context) error {
 		return c.String(http.StatusOK, "Welcome to POS API!")
 	})
-	e.POST("/api/payment/ipaymu/notify", ipaymuHandler.IpaymuNotify, WithValidation(&dtos.IpaymuNotifyRequest{}, validators.ValidateIpaymuNotify))
-	e.POST("/api/payment/tsm/callback", tsmHandler.Callback)
+	e.POST("/api/payment/ipaymu/notify", ipaymuHandler.IpaymuNotify, internalmw.PreserveRawBody, WithValidation(&dtos.IpaymuNotifyRequest{}, validators.ValidateIpaymuNotify))
+	e.POST("/api/payment/tsm/callback", tsmHandler.Callback, internalmw.PreserveRawBody)
+
+	// Simulated gateway callback, only available when the fake provider is enabled
+	if _, err := paymentProviders.Get("Fake"); err == nil {
+		e.POST("/api/payment/fake/callback", orderPaymentHandler.FakeCallback, WithValidation(&dtos.FakePaymentCallbackRequest{}, validators.ValidateFakePaymentCallback))
+	}
+
+	// Shared payment links redirect to iPaymu's payment page (authenticated by the link token)
+	e.GET("/pay/:token", paymentLinkHandler.OpenPaymentLink)
+
+	// QR table self-ordering (authenticated by the signed table token)
+	e.GET("/public/menu", selfOrderHandler.GetPublicMenu)
+	e.POST("/public/self-orders", selfOrderHandler.CreateSelfOrder, WithValidation(&dtos.CreateSelfOrderRequest{}, validators.ValidateCreateSelfOrder))
+	e.GET("/public/self-orders/:uuid", selfOrderHandler.GetPublicSelfOrder)
+	e.POST("/public/self-orders/:uuid/pay", selfOrderHandler.PaySelfOrder, WithValidation(&dtos.PaySelfOrderRequest{}, validators.ValidatePaySelfOrder))
+
+	// Marketplace order ingestion (authenticated by the X-Signature header)
+	e.POST("/api/marketplace/:channel_uuid/webhook", marketplaceHandler.Webhook, internalmw.PreserveRawBody, WithValidation(&dtos.MarketplaceWebhookRequest{}, validators.ValidateMarketplaceWebhook))
 }
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"gorm.io/gorm"
+)
+
+type CustomerService struct {
+	DB                 *gorm.DB
+	UserContextService *UserContextService
+}
+
+func NewCustomerService(db *gorm.DB, userContextService *UserContextService) *CustomerService {
+	return &CustomerService{DB: db, UserContextService: userContextService}
+}
+
+// GetCustomers lists the owner's customers, optionally only those whose name, phone or email
+// contains search.
+func (s *CustomerService) GetCustomers(search string, userID uint) ([]dtos.CustomerResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.DB.Where("user_id = ?", ownerID)
+	if search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("name ILIKE ? OR phone ILIKE ? OR email ILIKE ?", pattern, pattern, pattern)
+	}
+
+	var customers []models.Customer
+	if err := query.Order("name").Find(&customers).Error; err != nil {
+		log.Printf("Error getting customers: %v", err)
+		return nil, errors.New("failed to retrieve customers")
+	}
+
+	responses := make([]dtos.CustomerResponse, 0, len(customers))
+	for _, customer := range customers {
+		responses = append(responses, *mapCustomerToResponse(customer))
+	}
+	return responses, nil
+}
+
+func (s *CustomerService) GetCustomer(customerUuid uuid.UUID, userID uint) (*dtos.CustomerResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := findOwnedCustomer(s.DB, customerUuid, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return mapCustomerToResponse(*customer), nil
+}
+
+func (s *CustomerService) CreateCustomer(req *dtos.CustomerRequest, userID uint) (*dtos.CustomerResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	customer := models.Customer{
+		Name:   req.Name,
+		Phone:  req.Phone,
+		Email:  req.Email,
+		UserID: ownerID,
+	}
+	if err := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Create(&customer).Error; err != nil {
+		log.Printf("Error creating customer: %v", err)
+		return nil, errors.New("failed to create customer")
+	}
+	return mapCustomerToResponse(customer), nil
+}
+
+// UpdateCustomer changes a customer's details. The wallet balance only changes through the ledger.
+func (s *CustomerService) UpdateCustomer(customerUuid uuid.UUID, req *dtos.CustomerRequest, userID uint) (*dtos.CustomerResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID))
+	customer, err := findOwnedCustomer(db, customerUuid, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Model(customer).Updates(map[string]interface{}{
+		"name":  req.Name,
+		"phone": req.Phone,
+		"email": req.Email,
+	}).Error; err != nil {
+		log.Printf("Error updating customer: %v", err)
+		return nil, errors.New("failed to update customer")
+	}
+	return mapCustomerToResponse(*customer), nil
+}
+
+func findOwnedCustomer(db *gorm.DB, customerUuid uuid.UUID, ownerID uint) (*models.Customer, error) {
+	var customer models.Customer
+	if err := db.Where("uuid = ? AND user_id = ?", customerUuid, ownerID).First(&customer).Error; err != nil {
+		return nil, errors.New("customer not found")
+	}
+	return &customer, nil
+}
+
+func mapCustomerToResponse(customer models.Customer) *dtos.CustomerResponse {
+	return &dtos.CustomerResponse{
+		Uuid:          customer.Uuid,
+		Name:          customer.Name,
+		Phone:         customer.Phone,
+		Email:         customer.Email,
+		WalletBalance: customer.WalletBalance,
+		CreatedAt:     customer.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
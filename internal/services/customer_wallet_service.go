@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInsufficientWalletBalance = errors.New("insufficient wallet balance")
+
+// walletTopUpItemName is the order line of a wallet top-up.
+const walletTopUpItemName = "Wallet top-up"
+
+type CustomerWalletService struct {
+	DB                  *gorm.DB
+	UserContextService  *UserContextService
+	OrderPaymentService *OrderPaymentService
+}
+
+func NewCustomerWalletService(db *gorm.DB, userContextService *UserContextService, orderPaymentService *OrderPaymentService) *CustomerWalletService {
+	return &CustomerWalletService{DB: db, UserContextService: userContextService, OrderPaymentService: orderPaymentService}
+}
+
+func (s *CustomerWalletService) GetWalletTransactions(customerUuid uuid.UUID, userID uint) ([]dtos.CustomerWalletTransactionResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := findOwnedCustomer(s.DB, customerUuid, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions []models.CustomerWalletTransaction
+	if err := s.DB.Preload("Order").Preload("OrderPayment").
+		Where("customer_id = ?", customer.ID).
+		Order("id desc").
+		Find(&transactions).Error; err != nil {
+		log.Printf("Error getting wallet transactions: %v", err)
+		return nil, errors.New("failed to retrieve wallet transactions")
+	}
+
+	responses := make([]dtos.CustomerWalletTransactionResponse, 0, len(transactions))
+	for _, transaction := range transactions {
+		responses = append(responses, mapCustomerWalletTransactionToResponse(transaction))
+	}
+	return responses, nil
+}
+
+// AdjustWallet books a manual correction on the wallet ledger. A debit may not take the balance
+// below zero.
+func (s *CustomerWalletService) AdjustWallet(customerUuid uuid.UUID, req *dtos.AdjustCustomerWalletRequest, userID uint) (*dtos.CustomerWalletTransactionResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	customer, err := findOwnedCustomer(tx, customerUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	transaction, err := postWalletTransaction(tx, customer.ID, models.CustomerWalletTransaction{
+		Type:   models.CustomerWalletTransactionAdjustment,
+		Amount: req.Amount,
+		Reason: req.Reason,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	response := mapCustomerWalletTransactionToResponse(*transaction)
+	return &response, nil
+}
+
+// TopUpWallet sells a top-up as a one line order at the outlet and starts its payment with the
+// chosen payment method. The wallet is credited once the order is paid, right away for cash.
+func (s *CustomerWalletService) TopUpWallet(customerUuid uuid.UUID, req *dtos.TopUpCustomerWalletRequest, userID uint) (*dtos.CustomerWalletTopUpResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID))
+
+	customer, err := findOwnedCustomer(db, customerUuid, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := db.Where("uuid = ? AND user_id = ?", req.OutletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	var paymentMethod models.PaymentMethod
+	if err := db.Where("id = ? AND is_active = ?", req.PaymentMethodID, true).First(&paymentMethod).Error; err != nil {
+		return nil, errors.New("payment method not found or not active")
+	}
+	if paymentMethod.Issuer == WalletIssuer {
+		return nil, errors.New("wallet top-ups cannot be paid from the wallet")
+	}
+
+	order := models.Order{
+		OutletID:    outlet.ID,
+		UserID:      ownerID,
+		Status:      "pending",
+		TotalAmount: req.Amount,
+	}
+	orderItem := models.OrderItem{
+		Quantity:    1,
+		Price:       req.Amount,
+		ProductName: walletTopUpItemName,
+	}
+	topUp := models.CustomerWalletTopUp{
+		CustomerID: customer.ID,
+		Amount:     req.Amount,
+		Status:     models.CustomerWalletTopUpStatusPending,
+		UserID:     ownerID,
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+		orderItem.OrderID = order.ID
+		if err := tx.Create(&orderItem).Error; err != nil {
+			return err
+		}
+		topUp.OrderID = order.ID
+		return tx.Create(&topUp).Error
+	})
+	if err != nil {
+		log.Printf("Error creating wallet top-up: %v", err)
+		return nil, errors.New("failed to create wallet top-up")
+	}
+
+	payment, err := s.OrderPaymentService.CreateOrderPayment(dtos.CreateOrderPaymentRequest{
+		OrderUuid:       order.Uuid,
+		PaymentMethodID: req.PaymentMethodID,
+		OrderItemIDs:    []uint{orderItem.ID},
+		CustomerName:    customer.Name,
+		CustomerEmail:   customer.Email,
+		CustomerPhone:   customer.Phone,
+	}, userID)
+	if err != nil {
+		// Nothing was paid, so the top-up is dropped instead of leaving an unpaid order behind
+		s.discardTopUp(db, topUp, order)
+		return nil, err
+	}
+
+	if err := db.First(&topUp, topUp.ID).Error; err != nil {
+		return nil, errors.New("failed to retrieve wallet top-up")
+	}
+	return &dtos.CustomerWalletTopUpResponse{
+		Uuid:         topUp.Uuid,
+		CustomerUuid: customer.Uuid,
+		OrderUuid:    order.Uuid,
+		Amount:       topUp.Amount,
+		Status:       topUp.Status,
+		CreditedAt:   topUp.CreditedAt,
+		Payment:      payment,
+	}, nil
+}
+
+func (s *CustomerWalletService) discardTopUp(db *gorm.DB, topUp models.CustomerWalletTopUp, order models.Order) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&topUp).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("order_id = ?", order.ID).Delete(&models.OrderItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&order).Error
+	})
+	if err != nil {
+		log.Printf("Error discarding wallet top-up %s: %v", topUp.Uuid, err)
+	}
+}
+
+// postWalletTransaction appends an entry to the customer's wallet ledger and moves the balance by
+// its amount. The customer row is locked first, so concurrent spends are applied one at a time and
+// can never overdraw the wallet.
+func postWalletTransaction(tx *gorm.DB, customerID uint, transaction models.CustomerWalletTransaction) (*models.CustomerWalletTransaction, error) {
+	var customer models.Customer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, customerID).Error; err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	balance := customer.WalletBalance + transaction.Amount
+	if balance < 0 {
+		if !amountMatches(0, balance) {
+			return nil, ErrInsufficientWalletBalance
+		}
+		balance = 0
+	}
+
+	if err := tx.Model(&customer).Update("wallet_balance", balance).Error; err != nil {
+		return nil, fmt.Errorf("failed to update wallet balance: %w", err)
+	}
+
+	transaction.CustomerID = customer.ID
+	transaction.BalanceAfter = balance
+	if err := tx.Create(&transaction).Error; err != nil {
+		return nil, fmt.Errorf("failed to record wallet transaction: %w", err)
+	}
+	return &transaction, nil
+}
+
+// creditWalletTopUp credits the wallet of a top-up order once the order is paid. Orders that are
+// not top-ups are left alone.
+func creditWalletTopUp(tx *gorm.DB, orderID uint) error {
+	var topUp models.CustomerWalletTopUp
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status = ?", orderID, models.CustomerWalletTopUpStatusPending).
+		First(&topUp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load wallet top-up: %w", err)
+	}
+
+	if _, err := postWalletTransaction(tx, topUp.CustomerID, models.CustomerWalletTransaction{
+		Type:    models.CustomerWalletTransactionTopUp,
+		Amount:  topUp.Amount,
+		Reason:  walletTopUpItemName,
+		OrderID: &orderID,
+	}); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	topUp.Status = models.CustomerWalletTopUpStatusCredited
+	topUp.CreditedAt = &now
+	if err := tx.Omit(clause.Associations).Save(&topUp).Error; err != nil {
+		return fmt.Errorf("failed to update wallet top-up: %w", err)
+	}
+	return nil
+}
+
+// reverseWalletTopUp takes a refunded top-up back out of the wallet. It fails when the customer has
+// already spent the credit.
+func reverseWalletTopUp(tx *gorm.DB, orderID uint, amount float64) error {
+	var topUp models.CustomerWalletTopUp
+	err := tx.Where("order_id = ? AND status = ?", orderID, models.CustomerWalletTopUpStatusCredited).First(&topUp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load wallet top-up: %w", err)
+	}
+
+	_, err = postWalletTransaction(tx, topUp.CustomerID, models.CustomerWalletTransaction{
+		Type:    models.CustomerWalletTransactionAdjustment,
+		Amount:  -amount,
+		Reason:  "Wallet top-up refunded",
+		OrderID: &orderID,
+	})
+	return err
+}
+
+// checkWalletTopUpRefundable makes sure a refund of a top-up order can still be taken back out of
+// the wallet, before any money is sent back through the gateway.
+func checkWalletTopUpRefundable(tx *gorm.DB, orderID uint, amount float64) error {
+	var customer models.Customer
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "customers"}}).
+		Joins("JOIN customer_wallet_top_ups ON customer_wallet_top_ups.customer_id = customers.id").
+		Where("customer_wallet_top_ups.order_id = ? AND customer_wallet_top_ups.status = ?", orderID, models.CustomerWalletTopUpStatusCredited).
+		First(&customer).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load wallet top-up: %w", err)
+	}
+	if customer.WalletBalance < amount && !amountMatches(customer.WalletBalance, amount) {
+		return ErrInsufficientWalletBalance
+	}
+	return nil
+}
+
+// refundAsStoreCredit credits a refund to a customer's wallet instead of paying it back through
+// the payment's gateway.
+func refundAsStoreCredit(tx *gorm.DB, customer *models.Customer, orderPayment models.OrderPayment, amount float64, reason string) (*PaymentRefundResult, error) {
+	transaction, err := postWalletTransaction(tx, customer.ID, models.CustomerWalletTransaction{
+		Type:           models.CustomerWalletTransactionRefundCredit,
+		Amount:         amount,
+		Reason:         reason,
+		OrderPaymentID: &orderPayment.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PaymentRefundResult{
+		ReferenceID: transaction.Uuid.String(),
+		Status:      PaymentStatusRefunded,
+		Extra: map[string]interface{}{
+			"store_credit_customer_uuid": customer.Uuid,
+			"wallet_balance":             transaction.BalanceAfter,
+		},
+	}, nil
+}
+
+func mapCustomerWalletTransactionToResponse(transaction models.CustomerWalletTransaction) dtos.CustomerWalletTransactionResponse {
+	response := dtos.CustomerWalletTransactionResponse{
+		Uuid:         transaction.Uuid,
+		Type:         transaction.Type,
+		Amount:       transaction.Amount,
+		BalanceAfter: transaction.BalanceAfter,
+		Reason:       transaction.Reason,
+		CreatedAt:    transaction.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if transaction.Order != nil {
+		response.OrderUuid = &transaction.Order.Uuid
+	}
+	if transaction.OrderPayment != nil {
+		response.OrderPaymentUuid = &transaction.OrderPayment.Uuid
+	}
+	return response
+}
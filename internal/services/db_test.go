@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	testDB        *gorm.DB
+	testDBErr     error
+	testDBOnce    sync.Once
+	testDBCounter int64
+)
+
+// openTestDB connects to the Postgres database in TEST_DATABASE_URL and migrates every model once
+// per test run. Tests that need a database are skipped when it is not set. Each test creates its
+// own owner, so tests do not see each other's rows.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	testDBOnce.Do(func() {
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		if err != nil {
+			testDBErr = err
+			return
+		}
+		if err := db.Use(&database.UpdateByCallback{}); err != nil {
+			testDBErr = err
+			return
+		}
+		testDBErr = db.AutoMigrate(
+			&models.User{},
+			&models.Outlet{},
+			&models.Product{},
+			&models.Recipe{},
+			&models.Stock{},
+			&models.Order{},
+			&models.OrderItem{},
+			&models.PaymentMethod{},
+			&models.IpaymuLog{},
+			&models.UserPayment{},
+			&models.UserTsm{},
+			&models.StockMovement{},
+			&models.ProductVariant{},
+			&models.OrderItemAddOn{},
+			&models.ProductAddOn{},
+			&models.TsmLog{},
+			&models.PaymentCallbackLog{},
+			&models.OrderPayment{},
+			&models.OrderPaymentItem{},
+			&models.OrderPaymentRefund{},
+			&models.WebhookEndpoint{},
+			&models.WebhookEvent{},
+			&models.WebhookDelivery{},
+			&models.Customer{},
+			&models.CustomerWalletTransaction{},
+			&models.CustomerWalletTopUp{},
+			&models.StockAlert{},
+			&models.StockLot{},
+			&models.ProductUnit{},
+			&models.StockReservation{},
+			&models.MarketplaceChannel{},
+			&models.MarketplaceItemMapping{},
+			&models.MarketplaceOrder{},
+			&models.ProductionRun{},
+		)
+		testDB = db
+	})
+	if testDBErr != nil {
+		t.Fatalf("failed to open test database: %v", testDBErr)
+	}
+	return testDB
+}
+
+// testUserContext carries userID the way the services pass it to GORM callbacks.
+func testUserContext(userID uint) context.Context {
+	return context.WithValue(context.Background(), database.UserIDContextKey, userID)
+}
+
+// createTestOwner creates an owner with an outlet of their own.
+func createTestOwner(t *testing.T, db *gorm.DB, reserveStock bool) (models.User, models.Outlet) {
+	t.Helper()
+	n := atomic.AddInt64(&testDBCounter, 1)
+	owner := models.User{
+		Name:  "Test Owner",
+		Email: fmt.Sprintf("owner-%d-%d@example.test", time.Now().UnixNano(), n),
+		Role:  "owner",
+	}
+	if err := db.Create(&owner).Error; err != nil {
+		t.Fatalf("failed to create owner: %v", err)
+	}
+	outlet := models.Outlet{Name: "Test Outlet", Type: "retail", ReserveStock: reserveStock, UserID: owner.ID}
+	if err := db.Create(&outlet).Error; err != nil {
+		t.Fatalf("failed to create outlet: %v", err)
+	}
+	return owner, outlet
+}
+
+// createTestStock creates a retail product with the given quantity on hand at outlet.
+func createTestStock(t *testing.T, db *gorm.DB, outlet models.Outlet, name string, quantity float64) (models.Product, models.Stock) {
+	t.Helper()
+	n := atomic.AddInt64(&testDBCounter, 1)
+	product := models.Product{Name: name, Price: 10000, SKU: fmt.Sprintf("TEST-%d-%d", time.Now().UnixNano(), n), Type: "retail_item", UserID: outlet.UserID}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatalf("failed to create product: %v", err)
+	}
+	stock := models.Stock{OutletID: outlet.ID, ProductID: &product.ID, Quantity: quantity, UserID: outlet.UserID}
+	if err := db.Create(&stock).Error; err != nil {
+		t.Fatalf("failed to create stock: %v", err)
+	}
+	return product, stock
+}
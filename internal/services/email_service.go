@@ -156,6 +156,157 @@ func SendVerificationEmail(to, otp string) error {
 	return nil
 }
 
+// PaymentLinkEmail is the data of a payment link email.
+type PaymentLinkEmail struct {
+	CustomerName string
+	OutletName   string
+	Amount       string
+	URL          string
+	ExpiresAt    string
+	LogoURL      string
+}
+
+// SendPaymentLinkEmail queues an email asking the customer to pay through a payment link.
+func SendPaymentLinkEmail(to string, data PaymentLinkEmail) error {
+	if !CanSendEmail(to) {
+		log.Printf("Email to %s rate limited. Please wait before sending another email.", to)
+		return fmt.Errorf("email rate limited")
+	}
+
+	templateBytes, err := os.ReadFile("internal/templates/emails/payment_link_template.html")
+	if err != nil {
+		log.Printf("Could not read email template: %v", err)
+		return err
+	}
+
+	tmpl, err := template.New("paymentLinkTemplate").Parse(string(templateBytes))
+	if err != nil {
+		log.Printf("Could not parse email template: %v", err)
+		return err
+	}
+
+	data.LogoURL = os.Getenv("LOGO")
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		log.Printf("Could not execute email template: %v", err)
+		return err
+	}
+
+	EmailQueue <- EmailJob{
+		To:      to,
+		Subject: "Payment Request",
+		Body:    body.String(),
+	}
+	log.Printf("Payment link email for %s queued.", to)
+
+	return nil
+}
+
+// LowStockAlertEmail is the data of a low stock alert digest.
+type LowStockAlertEmail struct {
+	OwnerName string
+	Items     []LowStockAlertEmailItem
+	LogoURL   string
+}
+
+type LowStockAlertEmailItem struct {
+	OutletName             string
+	Name                   string
+	Quantity               string
+	ReorderPoint           string
+	SuggestedOrderQuantity string
+}
+
+// SendLowStockAlertEmail queues an email listing the stocks that fell to their reorder point.
+func SendLowStockAlertEmail(to string, data LowStockAlertEmail) error {
+	if !CanSendEmail(to) {
+		log.Printf("Email to %s rate limited. Please wait before sending another email.", to)
+		return fmt.Errorf("email rate limited")
+	}
+
+	templateBytes, err := os.ReadFile("internal/templates/emails/low_stock_alert_template.html")
+	if err != nil {
+		log.Printf("Could not read email template: %v", err)
+		return err
+	}
+
+	tmpl, err := template.New("lowStockAlertTemplate").Parse(string(templateBytes))
+	if err != nil {
+		log.Printf("Could not parse email template: %v", err)
+		return err
+	}
+
+	data.LogoURL = os.Getenv("LOGO")
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		log.Printf("Could not execute email template: %v", err)
+		return err
+	}
+
+	EmailQueue <- EmailJob{
+		To:      to,
+		Subject: "Low Stock Alert",
+		Body:    body.String(),
+	}
+	log.Printf("Low stock alert email for %s queued.", to)
+
+	return nil
+}
+
+// ExpiringLotsEmail is the data of a near-expiry stock lot digest.
+type ExpiringLotsEmail struct {
+	OwnerName string
+	Items     []ExpiringLotsEmailItem
+	LogoURL   string
+}
+
+type ExpiringLotsEmailItem struct {
+	OutletName string
+	Name       string
+	LotNumber  string
+	ExpiryDate string
+	Quantity   string
+}
+
+// SendExpiringLotsEmail queues an email listing the stock lots that are about to expire.
+func SendExpiringLotsEmail(to string, data ExpiringLotsEmail) error {
+	if !CanSendEmail(to) {
+		log.Printf("Email to %s rate limited. Please wait before sending another email.", to)
+		return fmt.Errorf("email rate limited")
+	}
+
+	templateBytes, err := os.ReadFile("internal/templates/emails/expiring_lots_template.html")
+	if err != nil {
+		log.Printf("Could not read email template: %v", err)
+		return err
+	}
+
+	tmpl, err := template.New("expiringLotsTemplate").Parse(string(templateBytes))
+	if err != nil {
+		log.Printf("Could not parse email template: %v", err)
+		return err
+	}
+
+	data.LogoURL = os.Getenv("LOGO")
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		log.Printf("Could not execute email template: %v", err)
+		return err
+	}
+
+	EmailQueue <- EmailJob{
+		To:      to,
+		Subject: "Stock Expiring Soon",
+		Body:    body.String(),
+	}
+	log.Printf("Expiring lots email for %s queued.", to)
+
+	return nil
+}
+
 // CanSendEmail checks if an email can be sent to a recipient based on a 1-minute cooldown
 func CanSendEmail(email string) bool {
 	ctx := context.Background()
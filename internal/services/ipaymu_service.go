@@ -9,24 +9,42 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
 	"github.com/msyaifudin/pos/pkg/elasticsearch"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// IpaymuLog statuses
+const (
+	IpaymuLogStatusPending = "pending"
+	IpaymuLogStatusPaid    = "paid"
+	IpaymuLogStatusFailed  = "failed"
+)
+
+// ipaymuStatusRefund is the iPaymu transaction status code of a refunded transaction
+const ipaymuStatusRefund = 3
+
+// ipaymuStatusSettled is the iPaymu transaction status code of a successful, settled transaction.
+// A successful transaction that is not settled yet has status 6.
+const ipaymuStatusSettled = 1
+
 type IpaymuService struct {
 	BaseURL            string
 	Va                 string
 	ApiKey             string
 	DB                 *gorm.DB
 	UserContextService *UserContextService
-	OrderPaymentService *OrderPaymentService // New dependency
 }
 
 func NewIpaymuService(db *gorm.DB, userContextService *UserContextService) *IpaymuService {
@@ -39,10 +57,6 @@ func NewIpaymuService(db *gorm.DB, userContextService *UserContextService) *Ipay
 	}
 }
 
-func (s *IpaymuService) SetOrderPaymentService(orderPaymentService *OrderPaymentService) {
-	s.OrderPaymentService = orderPaymentService
-}
-
 func (s *IpaymuService) header(body interface{}, method string) map[string]string {
 	if method == "" {
 		method = "POST"
@@ -222,12 +236,13 @@ func (s *IpaymuService) CreateDirectPayment(
 	// Ambil referenceIpaymu dan totalStr dari response
 	if data, ok := res["Data"].(map[string]interface{}); ok {
 		if ref, ok := data["TransactionId"]; ok {
-			referenceIpaymu = fmt.Sprintf("%v", ref)
+			referenceIpaymu = formatIpaymuID(ref)
 		}
 		if total, ok := data["Total"]; ok {
 			totalStr = fmt.Sprintf("%v", total)
 		}
 	}
+	feeDirection, _ := body["feeDirection"].(string)
 
 	log := models.IpaymuLog{
 		UserID:          userID,
@@ -237,6 +252,12 @@ func (s *IpaymuService) CreateDirectPayment(
 		PaymentChannel:  channel,
 		RequestAt:       time.Now(),
 		ReferenceIpaymu: referenceIpaymu,
+		FeeDirection:    feeDirection,
+	}
+	if data, ok := res["Data"].(map[string]interface{}); ok {
+		if fee, ok := data["Fee"].(float64); ok {
+			log.Fee = fee
+		}
 	}
 
 	// Convert the entire response map to a JSON string and store it
@@ -253,64 +274,487 @@ func (s *IpaymuService) CreateDirectPayment(
 	}
 	log.Amount = amountFloat
 	if s.DB != nil {
-		s.DB.Create(&log)
+		if err := s.DB.Create(&log).Error; err != nil {
+			return nil, fmt.Errorf("failed to save iPaymu log: %w", err)
+		}
 	}
 
 	return res, nil
 
 }
 
-// UpdateIpaymuLogStatus updates the IpaymuLog status and timestamps based on notification
-func (s *IpaymuService) NotifyDirectPayment(TrxId int, Status string, SettlementStatus string) error {
-	tx := s.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+// CreateRedirectPayment creates a payment on iPaymu's hosted payment page, where the buyer picks
+// the channel. iPaymu only assigns a transaction once the buyer pays, so the IpaymuLog is keyed by
+// the session id, and holds the amount without the fee, until the notification arrives.
+func (s *IpaymuService) CreateRedirectPayment(
+	userID uint,
+	ServiceName string,
+	ServiceRefID string,
+	product []string,
+	qty []int,
+	price []int,
+	name, email, phone string,
+	expiredHours int,
+) (map[string]interface{}, error) {
+	start := time.Now()
+
+	amount := 0
+	for i := range product {
+		if i < len(qty) && i < len(price) {
+			amount += qty[i] * price[i]
 		}
-	}()
+	}
+	if expiredHours <= 0 {
+		expiredHours = 24
+	}
+	cancelURL := os.Getenv("IPAYMU_CANCEL_URL")
+	if cancelURL == "" {
+		cancelURL = os.Getenv("IPAYMU_RETURN_URL")
+	}
+	body := map[string]interface{}{
+		"product":      product,
+		"qty":          qty,
+		"price":        price,
+		"buyerName":    name,
+		"buyerEmail":   email,
+		"buyerPhone":   phone,
+		"expired":      expiredHours,
+		"expiredType":  "hours",
+		"referenceId":  ServiceRefID,
+		"returnUrl":    os.Getenv("IPAYMU_RETURN_URL"),
+		"cancelUrl":    cancelURL,
+		"notifyUrl":    os.Getenv("IPAYMU_NOTIFY_URL"),
+		"feeDirection": "BUYER",
+	}
+	endPoint := "/api/v2/payment"
 
-	var log models.IpaymuLog
-	if err := tx.Where("reference_ipaymu = ?", fmt.Sprintf("%v", TrxId)).First(&log).Error; err != nil {
-		tx.Rollback()
-		return err
+	reqBodyBytes, _ := json.Marshal(body)
+	res, err := s.send(endPoint, body, "application/json", "POST")
+
+	logData := elasticsearch.APILog{
+		Method:     "POST",
+		Path:       endPoint,
+		Status:     200,
+		DurationMs: time.Since(start).Milliseconds(),
+		Extra: map[string]interface{}{
+			"request_payload": string(reqBodyBytes),
+			"service_name":    ServiceName,
+			"service_ref_id":  ServiceRefID,
+		},
 	}
+	if err != nil {
+		logData.Status = 0
+		logData.Error = err.Error()
+		elasticsearch.LogAPI("ipaymu_curl_logs", logData)
+		return nil, err
+	}
+	respBodyBytes, _ := json.Marshal(res)
+	logData.Extra["response_payload"] = string(respBodyBytes)
+	elasticsearch.LogAPI("ipaymu_curl_logs", logData)
 
-	
+	data, ok := res["Data"].(map[string]interface{})
+	if !ok {
+		message, _ := res["Message"].(string)
+		return res, fmt.Errorf("ipaymu refused the payment: %s", message)
+	}
+	sessionID, _ := data["SessionID"].(string)
+	if sessionID == "" {
+		return res, errors.New("ipaymu did not return a payment session")
+	}
 
-	dateNow := time.Now()
+	log := models.IpaymuLog{
+		UserID:        userID,
+		ServiceName:   ServiceName,
+		ServiceRefID:  ServiceRefID,
+		SessionID:     sessionID,
+		PaymentMethod: "hosted",
+		Amount:        float64(amount),
+		FeeDirection:  "BUYER",
+		RequestAt:     start,
+		ResponseData:  string(respBodyBytes),
+	}
+	if s.DB != nil {
+		if err := s.DB.Create(&log).Error; err != nil {
+			return res, fmt.Errorf("failed to save iPaymu log: %w", err)
+		}
+	}
+
+	return res, nil
+}
+
+// formatIpaymuID formats a numeric ID decoded from an iPaymu JSON response without
+// an exponent, so it matches the trx_id sent in notifications.
+func formatIpaymuID(id interface{}) string {
+	if f, ok := id.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", id)
+}
+
+// parseIpaymuTime parses the "2006-01-02 15:04:05" timestamps iPaymu returns, which are in WIB.
+func parseIpaymuTime(value string) *time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.FixedZone("WIB", 7*60*60))
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// IsNotifySourceAllowed checks the sender of a notification against IPAYMU_NOTIFY_ALLOWED_IPS
+// (comma separated). An empty list accepts any source; the transaction is still re-checked with iPaymu,
+// but settlement figures are not recorded.
+func (s *IpaymuService) IsNotifySourceAllowed(sourceIP string) bool {
+	allowed := strings.TrimSpace(os.Getenv("IPAYMU_NOTIFY_ALLOWED_IPS"))
+	if allowed == "" {
+		return true
+	}
+	for _, ip := range strings.Split(allowed, ",") {
+		if strings.TrimSpace(ip) == sourceIP {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyNotification checks a notification before anything is credited: the sender must be allowed,
+// a successful status is confirmed with iPaymu, and the confirmed amount must match the IpaymuLog.
+// It returns true only for the notification that turns the log paid, so duplicates and replays
+// never credit twice. The log row is locked for the rest of the transaction.
+func (s *IpaymuService) VerifyNotification(tx *gorm.DB, req *dtos.IpaymuNotifyRequest, sourceIP string) (*models.IpaymuLog, bool, error) {
+	if !s.IsNotifySourceAllowed(sourceIP) {
+		return nil, false, errors.New("notification source not allowed")
+	}
+
+	trxID := strconv.Itoa(req.TrxID)
+	lockedTx := tx.Clauses(clause.Locking{Strength: "UPDATE"})
+	var log models.IpaymuLog
+	err := lockedTx.Where("reference_ipaymu = ? AND service_name <> ?", trxID, "Order Payment Refund").First(&log).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) && req.SID != "" {
+		// Hosted payments only learn their transaction id from this notification
+		err = lockedTx.Where("session_id = ? AND service_name <> ?", req.SID, "Order Payment Refund").First(&log).Error
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, errors.New("ipaymu transaction not found")
+		}
+		return nil, false, err
+	}
 
+	dateNow := time.Now()
 	updated := false
+	credited := false
+	var checked map[string]interface{}
+
+	if req.Status == "berhasil" && log.Status != IpaymuLogStatusPaid {
+		res, err := s.CheckTransaction(trxID)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to confirm transaction with ipaymu: %w", err)
+		}
+		checked = res
+		status, amount, fee, err := parseIpaymuTransaction(res)
+		if err != nil {
+			return nil, false, err
+		}
+		if status != PaymentStatusPaid {
+			return nil, false, errors.New("payment not confirmed by ipaymu")
+		}
+		if log.ReferenceIpaymu == "" {
+			// A session matched by the notification's sid must be the one the transaction belongs to
+			if !ipaymuTransactionBelongsTo(res, log) {
+				return nil, false, errors.New("payment reference mismatch")
+			}
+			log.ReferenceIpaymu = trxID
+		}
+		// The log keeps the total charged to the buyer, which may or may not include the fee
+		if !amountMatches(log.Amount, amount) && !amountMatches(log.Amount, amount+fee) {
+			return nil, false, errors.New("payment amount mismatch")
+		}
 
-	if Status == "berhasil" {
+		log.Status = IpaymuLogStatusPaid
 		log.SuccessAt = &dateNow
+		if notifiedFee, err := strconv.ParseFloat(req.Fee, 64); err == nil {
+			log.Fee = notifiedFee
+		} else if fee > 0 {
+			log.Fee = fee
+		}
+		// A hosted payment is logged before the buyer picks a channel, so without the fee. Keep the
+		// total charged instead, as direct payments do.
+		if log.PaymentMethod == "hosted" {
+			if total, err := strconv.ParseFloat(req.Total, 64); err == nil && amountMatches(total, log.Amount+log.Fee) {
+				log.Amount = total
+			}
+		}
 		updated = true
+		credited = true
 	}
-	if SettlementStatus == "settled" {
-		log.SettlementAt = &dateNow
-		updated = true
+	if req.SettlementStatus == "settled" && log.SettlementAt == nil && log.Status == IpaymuLogStatusPaid {
+		settled, err := s.confirmSettlement(log.ReferenceIpaymu, checked)
+		if err != nil {
+			return nil, false, err
+		}
+		if settled {
+			log.SettlementAt = &dateNow
+			log.SettledAmount = float64(req.PaidOff)
+			updated = true
+		}
 	}
 
 	if updated {
 		if err := tx.Save(&log).Error; err != nil {
-			tx.Rollback()
-			return err
+			return nil, false, err
 		}
 	}
 
-	// Handle Order Payment specific updates by calling OrderPaymentService
-	if log.ServiceName == "Order Payment" && Status == "berhasil" {
-		// Pass the transaction to the OrderPaymentService function
-		if err := s.OrderPaymentService.UpdateOrderPaymentAndStatus(tx, log.ServiceRefID, log.Amount); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("failed to update order payment and status via OrderPaymentService: %w", err)
+	return &log, credited, nil
+}
+
+// confirmSettlement decides whether the settlement figures of a notification can be recorded. They
+// come from the unsigned notification body, so they are only taken from an allow-listed sender
+// and once iPaymu itself reports the transaction settled. checked is the transaction already
+// fetched for this notification, if any.
+func (s *IpaymuService) confirmSettlement(transactionID string, checked map[string]interface{}) (bool, error) {
+	if strings.TrimSpace(os.Getenv("IPAYMU_NOTIFY_ALLOWED_IPS")) == "" {
+		log.Printf("Ignoring settlement of iPaymu transaction %s: IPAYMU_NOTIFY_ALLOWED_IPS is not set", transactionID)
+		return false, nil
+	}
+	if checked == nil {
+		res, err := s.CheckTransaction(transactionID)
+		if err != nil {
+			return false, fmt.Errorf("failed to confirm settlement with ipaymu: %w", err)
 		}
+		checked = res
+	}
+	data, ok := checked["Data"].(map[string]interface{})
+	if !ok {
+		return false, errors.New("unexpected iPaymu transaction response")
 	}
+	statusCode, _ := data["Status"].(float64)
+	return int(statusCode) == ipaymuStatusSettled, nil
+}
 
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+// parseIpaymuTransaction maps an iPaymu transaction response to a payment status, amount and fee.
+func parseIpaymuTransaction(res map[string]interface{}) (string, float64, float64, error) {
+	data, ok := res["Data"].(map[string]interface{})
+	if !ok {
+		return "", 0, 0, errors.New("unexpected iPaymu transaction response")
+	}
+
+	status := PaymentStatusPending
+	if statusCode, ok := data["Status"].(float64); ok {
+		switch int(statusCode) {
+		case 1, 6: // berhasil, berhasil (unsettled)
+			status = PaymentStatusPaid
+		case -2: // expired
+			status = PaymentStatusExpired
+		case 2, 4, 5: // cancelled, error, failed
+			status = PaymentStatusFailed
+		case ipaymuStatusRefund:
+			status = PaymentStatusRefunded
+		}
 	}
 
-	return nil
+	amount, _ := data["Amount"].(float64)
+	fee, _ := data["Fee"].(float64)
+	return status, amount, fee, nil
+}
+
+// ipaymuTransactionBelongsTo reports whether a checked transaction was created for the hosted
+// payment session of log.
+func ipaymuTransactionBelongsTo(res map[string]interface{}, log models.IpaymuLog) bool {
+	data, ok := res["Data"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	if sessionID, ok := data["SessionId"].(string); ok && sessionID != "" {
+		return sessionID == log.SessionID
+	}
+	referenceID, _ := data["ReferenceId"].(string)
+	return referenceID != "" && referenceID == log.ServiceRefID
+}
+
+// amountMatches compares currency amounts, ignoring floating point noise.
+func amountMatches(expected, actual float64) bool {
+	return math.Abs(expected-actual) < 0.01
+}
+
+// CheckTransaction asks iPaymu for the current state of a transaction
+func (s *IpaymuService) CheckTransaction(transactionID string) (map[string]interface{}, error) {
+	start := time.Now()
+	endPoint := "/api/v2/transaction"
+	body := map[string]interface{}{
+		"transactionId": transactionID,
+	}
+
+	reqBodyBytes, _ := json.Marshal(body)
+	res, err := s.send(endPoint, body, "application/json", "POST")
+
+	logData := elasticsearch.APILog{
+		Method:     "POST",
+		Path:       endPoint,
+		Status:     200,
+		DurationMs: time.Since(start).Milliseconds(),
+		Extra: map[string]interface{}{
+			"request_payload": string(reqBodyBytes),
+			"service_name":    "Check Transaction",
+			"service_ref_id":  transactionID,
+		},
+	}
+	if err != nil {
+		logData.Status = 0
+		logData.Error = err.Error()
+		elasticsearch.LogAPI("ipaymu_curl_logs", logData)
+		return nil, err
+	}
+	respBodyBytes, _ := json.Marshal(res)
+	logData.Extra["response_payload"] = string(respBodyBytes)
+	elasticsearch.LogAPI("ipaymu_curl_logs", logData)
+
+	return res, nil
+}
+
+// FindSessionTransaction looks up the transaction iPaymu created for the hosted payment session of
+// log, for when its notification never arrived. It returns an empty id while the buyer has not paid.
+func (s *IpaymuService) FindSessionTransaction(log models.IpaymuLog) (string, error) {
+	start := time.Now()
+	endPoint := os.Getenv("IPAYMU_HISTORY_PATH")
+	if endPoint == "" {
+		endPoint = "/api/v2/history"
+	}
+	body := map[string]interface{}{
+		"referenceId": log.ServiceRefID,
+	}
+
+	reqBodyBytes, _ := json.Marshal(body)
+	res, err := s.send(endPoint, body, "application/json", "POST")
+
+	logData := elasticsearch.APILog{
+		Method:     "POST",
+		Path:       endPoint,
+		Status:     200,
+		DurationMs: time.Since(start).Milliseconds(),
+		Extra: map[string]interface{}{
+			"request_payload": string(reqBodyBytes),
+			"service_name":    "Transaction History",
+			"service_ref_id":  log.ServiceRefID,
+		},
+	}
+	if err != nil {
+		logData.Status = 0
+		logData.Error = err.Error()
+		elasticsearch.LogAPI("ipaymu_curl_logs", logData)
+		return "", err
+	}
+	respBodyBytes, _ := json.Marshal(res)
+	logData.Extra["response_payload"] = string(respBodyBytes)
+	elasticsearch.LogAPI("ipaymu_curl_logs", logData)
+
+	var transactions []interface{}
+	switch data := res["Data"].(type) {
+	case map[string]interface{}:
+		transactions, _ = data["Transaction"].([]interface{})
+	case []interface{}:
+		transactions = data
+	}
+	for _, item := range transactions {
+		transaction, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id, ok := transaction["TransactionId"]; ok && ipaymuTransactionBelongsTo(map[string]interface{}{"Data": transaction}, log) {
+			return formatIpaymuID(id), nil
+		}
+	}
+	return "", nil
+}
+
+// RefundTransaction asks iPaymu to refund part or all of a paid transaction. The request and response
+// are kept in IpaymuLog under the "Order Payment Refund" service, referenced by the order payment uuid.
+func (s *IpaymuService) RefundTransaction(userID uint, refundRefID string, transactionID string, amount float64, reason string) (map[string]interface{}, error) {
+	start := time.Now()
+	endPoint := os.Getenv("IPAYMU_REFUND_PATH")
+	if endPoint == "" {
+		endPoint = "/api/v2/transaction/refund"
+	}
+	body := map[string]interface{}{
+		"transactionId": transactionID,
+		"amount":        amount,
+		"reason":        reason,
+	}
+
+	reqBodyBytes, _ := json.Marshal(body)
+	res, err := s.send(endPoint, body, "application/json", "POST")
+
+	logData := elasticsearch.APILog{
+		Method:     "POST",
+		Path:       endPoint,
+		Status:     200,
+		DurationMs: time.Since(start).Milliseconds(),
+		Extra: map[string]interface{}{
+			"request_payload": string(reqBodyBytes),
+			"service_name":    "Order Payment Refund",
+			"service_ref_id":  refundRefID,
+		},
+	}
+	refundLog := models.IpaymuLog{
+		UserID:          userID,
+		ServiceName:     "Order Payment Refund",
+		ServiceRefID:    refundRefID,
+		ReferenceIpaymu: transactionID,
+		Amount:          amount,
+		RequestAt:       start,
+		ResponseData:    "{}",
+	}
+
+	if err != nil {
+		logData.Status = 0
+		logData.Error = err.Error()
+		elasticsearch.LogAPI("ipaymu_curl_logs", logData)
+		refundLog.Status = IpaymuLogStatusFailed
+		s.DB.Create(&refundLog)
+		return nil, err
+	}
+	respBodyBytes, _ := json.Marshal(res)
+	logData.Extra["response_payload"] = string(respBodyBytes)
+	elasticsearch.LogAPI("ipaymu_curl_logs", logData)
+
+	refundLog.ResponseData = string(respBodyBytes)
+	if statusCode, ok := res["Status"].(float64); !ok || int(statusCode) != http.StatusOK {
+		refundLog.Status = IpaymuLogStatusFailed
+		s.DB.Create(&refundLog)
+		message, _ := res["Message"].(string)
+		return res, fmt.Errorf("ipaymu refused the refund: %s", message)
+	}
+	s.DB.Create(&refundLog)
+
+	return res, nil
+}
+
+// VerifyRefundNotification confirms a refund notification with iPaymu before it is trusted.
+func (s *IpaymuService) VerifyRefundNotification(tx *gorm.DB, req *dtos.IpaymuNotifyRequest, sourceIP string) (*models.IpaymuLog, error) {
+	if !s.IsNotifySourceAllowed(sourceIP) {
+		return nil, errors.New("notification source not allowed")
+	}
+
+	var log models.IpaymuLog
+	if err := tx.Where("reference_ipaymu = ? AND service_name <> ?", strconv.Itoa(req.TrxID), "Order Payment Refund").First(&log).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("ipaymu transaction not found")
+		}
+		return nil, err
+	}
+
+	res, err := s.CheckTransaction(log.ReferenceIpaymu)
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm transaction with ipaymu: %w", err)
+	}
+	status, _, _, err := parseIpaymuTransaction(res)
+	if err != nil {
+		return nil, err
+	}
+	if status != PaymentStatusRefunded {
+		return nil, errors.New("refund not confirmed by ipaymu")
+	}
+	return &log, nil
 }
 
 // Register melakukan pendaftaran user ke Ipaymu
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxPaymentProofSize is the largest proof of payment image accepted, in bytes.
+const maxPaymentProofSize = 5 << 20
+
+// paymentProofExtensions are the image types accepted as proof of payment.
+var paymentProofExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+type ManualTransferService struct {
+	DB                  *gorm.DB
+	UserContextService  *UserContextService
+	OrderPaymentService *OrderPaymentService
+}
+
+func NewManualTransferService(db *gorm.DB, userContextService *UserContextService, orderPaymentService *OrderPaymentService) *ManualTransferService {
+	return &ManualTransferService{DB: db, UserContextService: userContextService, OrderPaymentService: orderPaymentService}
+}
+
+// paymentProofDir is where uploaded proofs are stored. They are only served through the API.
+func paymentProofDir() string {
+	if dir := os.Getenv("PAYMENT_PROOF_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join("storage", "payment-proofs")
+}
+
+// UploadProof stores an uploaded proof of payment image for a pending manual transfer.
+func (s *ManualTransferService) UploadProof(orderPaymentUuid uuid.UUID, file io.Reader, note string, userID uint) (*dtos.OrderPaymentProofResponse, error) {
+	data, err := io.ReadAll(io.LimitReader(file, maxPaymentProofSize+1))
+	if err != nil {
+		return nil, errors.New("failed to read proof of payment")
+	}
+	if len(data) > maxPaymentProofSize {
+		return nil, errors.New("proof of payment is too large")
+	}
+	contentType := http.DetectContentType(data)
+	extension, ok := paymentProofExtensions[contentType]
+	if !ok {
+		return nil, errors.New("proof of payment must be a jpeg, png or webp image")
+	}
+
+	proof := models.OrderPaymentProof{
+		FileName:    uuid.New().String() + extension,
+		ContentType: contentType,
+		Note:        note,
+	}
+
+	dir := paymentProofDir()
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		log.Printf("Error creating payment proof directory: %v", err)
+		return nil, errors.New("failed to store proof of payment")
+	}
+	path := filepath.Join(dir, proof.FileName)
+	if err := os.WriteFile(path, data, 0o640); err != nil {
+		log.Printf("Error writing payment proof: %v", err)
+		return nil, errors.New("failed to store proof of payment")
+	}
+
+	response, err := s.attachProof(orderPaymentUuid, &proof, userID)
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return response, nil
+}
+
+// AttachProofURL attaches a link to a proof of payment hosted elsewhere.
+func (s *ManualTransferService) AttachProofURL(orderPaymentUuid uuid.UUID, req *dtos.AttachPaymentProofRequest, userID uint) (*dtos.OrderPaymentProofResponse, error) {
+	proof := models.OrderPaymentProof{
+		URL:  req.ProofURL,
+		Note: req.Note,
+	}
+	return s.attachProof(orderPaymentUuid, &proof, userID)
+}
+
+func (s *ManualTransferService) attachProof(orderPaymentUuid uuid.UUID, proof *models.OrderPaymentProof, userID uint) (*dtos.OrderPaymentProofResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	orderPayment, err := findOwnedOrderPayment(s.DB, orderPaymentUuid, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if orderPayment.PaymentMethod.Issuer != ManualTransferIssuer {
+		return nil, errors.New("order payment is not a manual transfer")
+	}
+	if orderPayment.Status != models.OrderPaymentStatusPending {
+		return nil, errors.New("only pending transfers accept a proof of payment")
+	}
+
+	proof.OrderPaymentID = orderPayment.ID
+	if err := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Create(proof).Error; err != nil {
+		return nil, errors.New("failed to attach proof of payment")
+	}
+	return mapOrderPaymentProofToResponse(*proof, orderPayment.Uuid), nil
+}
+
+func (s *ManualTransferService) GetProofs(orderPaymentUuid uuid.UUID, userID uint) ([]dtos.OrderPaymentProofResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	orderPayment, err := findOwnedOrderPayment(s.DB, orderPaymentUuid, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var proofs []models.OrderPaymentProof
+	if err := s.DB.Where("order_payment_id = ?", orderPayment.ID).Order("created_at").Find(&proofs).Error; err != nil {
+		return nil, errors.New("failed to retrieve proofs of payment")
+	}
+
+	responses := make([]dtos.OrderPaymentProofResponse, 0, len(proofs))
+	for _, proof := range proofs {
+		responses = append(responses, *mapOrderPaymentProofToResponse(proof, orderPayment.Uuid))
+	}
+	return responses, nil
+}
+
+// GetProofFile returns the path and content type of an uploaded proof owned by the user's owner.
+func (s *ManualTransferService) GetProofFile(proofUuid uuid.UUID, userID uint) (string, string, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	var proof models.OrderPaymentProof
+	if err := s.DB.Joins("JOIN order_payments ON order_payments.id = order_payment_proofs.order_payment_id").
+		Joins("JOIN orders ON orders.id = order_payments.order_id").
+		Where("order_payment_proofs.uuid = ? AND orders.user_id = ?", proofUuid, ownerID).
+		First(&proof).Error; err != nil || proof.FileName == "" {
+		return "", "", errors.New("proof of payment not found")
+	}
+	return filepath.Join(paymentProofDir(), proof.FileName), proof.ContentType, nil
+}
+
+// ConfirmTransfer credits a pending manual transfer once a manager has checked its proof of payment.
+func (s *ManualTransferService) ConfirmTransfer(orderPaymentUuid uuid.UUID, req *dtos.ConfirmTransferRequest, userID uint) (*dtos.OrderPaymentResponse, error) {
+	return s.reviewTransfer(orderPaymentUuid, req.Note, true, userID)
+}
+
+// RejectTransfer fails a pending manual transfer, freeing its items for another payment.
+func (s *ManualTransferService) RejectTransfer(orderPaymentUuid uuid.UUID, req *dtos.RejectTransferRequest, userID uint) (*dtos.OrderPaymentResponse, error) {
+	return s.reviewTransfer(orderPaymentUuid, req.Note, false, userID)
+}
+
+func (s *ManualTransferService) reviewTransfer(orderPaymentUuid uuid.UUID, note string, confirm bool, userID uint) (*dtos.OrderPaymentResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	orderPayment, err := findOwnedOrderPayment(tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "order_payments"}}), orderPaymentUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if orderPayment.PaymentMethod.Issuer != ManualTransferIssuer {
+		tx.Rollback()
+		return nil, errors.New("order payment is not a manual transfer")
+	}
+	if orderPayment.Status != models.OrderPaymentStatusPending {
+		tx.Rollback()
+		return nil, errors.New("only pending transfers can be reviewed")
+	}
+
+	now := time.Now()
+	if confirm {
+		var proofCount int64
+		if err := tx.Model(&models.OrderPaymentProof{}).Where("order_payment_id = ?", orderPayment.ID).Count(&proofCount).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to check proofs of payment")
+		}
+		if proofCount == 0 {
+			tx.Rollback()
+			return nil, errors.New("proof of payment required")
+		}
+
+		if err := s.OrderPaymentService.UpdateOrderPaymentAndStatus(tx, orderPayment.Uuid.String(), orderPayment.AmountPaid); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	} else {
+		if err := tx.Model(&models.OrderPayment{}).Where("id = ?", orderPayment.ID).
+			Updates(map[string]interface{}{"status": models.OrderPaymentStatusFailed, "failed_at": now}).Error; err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to update order payment status: %w", err)
+		}
+	}
+
+	if err := tx.Model(&models.OrderPayment{}).Where("id = ?", orderPayment.ID).
+		Updates(map[string]interface{}{"review_note": note, "reviewed_by": userID, "reviewed_at": now}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to record transfer review")
+	}
+
+	reviewed, err := findOwnedOrderPayment(tx, orderPaymentUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return mapOrderPaymentToResponse(*reviewed, reviewed.Order.Uuid, reviewed.PaymentMethod.Name), nil
+}
+
+// GetPendingTransfers is the review queue of an outlet: pending manual transfers with at least one
+// proof of payment, oldest first.
+func (s *ManualTransferService) GetPendingTransfers(outletUuid uuid.UUID, userID uint) ([]dtos.PendingTransferResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	var orderPayments []models.OrderPayment
+	if err := s.DB.Preload("Order").Preload("PaymentMethod").
+		Joins("JOIN orders ON orders.id = order_payments.order_id").
+		Joins("JOIN payment_methods ON payment_methods.id = order_payments.payment_method_id").
+		Where("orders.outlet_id = ? AND orders.user_id = ? AND payment_methods.issuer = ? AND order_payments.status = ?", outlet.ID, ownerID, ManualTransferIssuer, models.OrderPaymentStatusPending).
+		Where("EXISTS (SELECT 1 FROM order_payment_proofs WHERE order_payment_proofs.order_payment_id = order_payments.id)").
+		Order("order_payments.created_at").
+		Find(&orderPayments).Error; err != nil {
+		return nil, errors.New("failed to retrieve pending transfers")
+	}
+
+	responses := make([]dtos.PendingTransferResponse, 0, len(orderPayments))
+	for _, orderPayment := range orderPayments {
+		var proofs []models.OrderPaymentProof
+		if err := s.DB.Where("order_payment_id = ?", orderPayment.ID).Order("created_at").Find(&proofs).Error; err != nil {
+			return nil, errors.New("failed to retrieve proofs of payment")
+		}
+
+		response := dtos.PendingTransferResponse{
+			OrderPaymentResponse: *mapOrderPaymentToResponse(orderPayment, orderPayment.Order.Uuid, orderPayment.PaymentMethod.Name),
+			Proofs:               make([]dtos.OrderPaymentProofResponse, 0, len(proofs)),
+		}
+		for _, proof := range proofs {
+			response.Proofs = append(response.Proofs, *mapOrderPaymentProofToResponse(proof, orderPayment.Uuid))
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+func mapOrderPaymentProofToResponse(proof models.OrderPaymentProof, orderPaymentUuid uuid.UUID) *dtos.OrderPaymentProofResponse {
+	url := proof.URL
+	if proof.FileName != "" {
+		url = "/order-payment-proofs/" + proof.Uuid.String() + "/file"
+	}
+	return &dtos.OrderPaymentProofResponse{
+		Uuid:             proof.Uuid,
+		OrderPaymentUuid: orderPaymentUuid,
+		ContentType:      proof.ContentType,
+		URL:              url,
+		Note:             proof.Note,
+		CreatedAt:        proof.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/pkg/utils"
+)
+
+// MarketplaceClient pushes order status updates back to a marketplace.
+// Providers with their own API register an implementation on MarketplaceService;
+// every other provider uses HTTPMarketplaceClient.
+type MarketplaceClient interface {
+	PushOrderStatus(channel models.MarketplaceChannel, externalOrderID string, status string) error
+}
+
+// HTTPMarketplaceClient posts status updates as signed JSON to the channel's status callback URL.
+type HTTPMarketplaceClient struct {
+	HTTPClient *http.Client
+}
+
+func NewHTTPMarketplaceClient() *HTTPMarketplaceClient {
+	return &HTTPMarketplaceClient{HTTPClient: &http.Client{Timeout: 15 * time.Second}}
+}
+
+type marketplaceStatusPayload struct {
+	ExternalOrderID string `json:"external_order_id"`
+	Status          string `json:"status"`
+	UpdatedAt       string `json:"updated_at"`
+}
+
+func (c *HTTPMarketplaceClient) PushOrderStatus(channel models.MarketplaceChannel, externalOrderID string, status string) error {
+	if channel.StatusCallbackURL == "" {
+		// Nothing to push to; the marketplace polls or the status is managed on its side
+		return nil
+	}
+
+	body, err := json.Marshal(marketplaceStatusPayload{
+		ExternalOrderID: externalOrderID,
+		Status:          status,
+		UpdatedAt:       time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, channel.StatusCallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature", utils.SignHMACSHA256(channel.WebhookSecret, body))
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		log.Printf("Error pushing marketplace order status to %s: %v", channel.StatusCallbackURL, err)
+		return errors.New("failed to push status to marketplace")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("marketplace rejected status update with status code %d", resp.StatusCode)
+	}
+	return nil
+}
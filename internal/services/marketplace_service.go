@@ -0,0 +1,572 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/pkg/utils"
+	"gorm.io/gorm"
+)
+
+type MarketplaceService struct {
+	DB                 *gorm.DB
+	UserContextService *UserContextService
+	StockService       *StockService
+	Clients            map[string]MarketplaceClient // Outbound clients by channel provider
+	DefaultClient      MarketplaceClient
+}
+
+func NewMarketplaceService(db *gorm.DB, userContextService *UserContextService, stockService *StockService) *MarketplaceService {
+	return &MarketplaceService{
+		DB:                 db,
+		UserContextService: userContextService,
+		StockService:       stockService,
+		Clients:            make(map[string]MarketplaceClient),
+		DefaultClient:      NewHTTPMarketplaceClient(),
+	}
+}
+
+// RegisterClient sets the outbound client used for channels of the given provider.
+func (s *MarketplaceService) RegisterClient(provider string, client MarketplaceClient) {
+	s.Clients[strings.ToLower(provider)] = client
+}
+
+func (s *MarketplaceService) clientFor(provider string) MarketplaceClient {
+	if client, ok := s.Clients[strings.ToLower(provider)]; ok {
+		return client
+	}
+	return s.DefaultClient
+}
+
+func (s *MarketplaceService) CreateChannel(outletUuid uuid.UUID, req *dtos.CreateMarketplaceChannelRequest, userID uint) (*dtos.MarketplaceChannelResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		log.Printf("Error generating marketplace webhook secret: %v", err)
+		return nil, errors.New("failed to create marketplace channel")
+	}
+
+	channel := models.MarketplaceChannel{
+		OutletID:          outlet.ID,
+		Outlet:            outlet,
+		Provider:          strings.ToLower(req.Provider),
+		Name:              req.Name,
+		WebhookSecret:     secret,
+		CommissionPercent: req.CommissionPercent,
+		StatusCallbackURL: req.StatusCallbackURL,
+		IsActive:          true,
+		UserID:            ownerID,
+	}
+	if err := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Omit("Outlet").Create(&channel).Error; err != nil {
+		log.Printf("Error creating marketplace channel: %v", err)
+		return nil, errors.New("failed to create marketplace channel")
+	}
+
+	response := mapMarketplaceChannelToResponse(channel)
+	response.WebhookSecret = channel.WebhookSecret
+	return response, nil
+}
+
+func (s *MarketplaceService) GetChannelsByOutlet(outletUuid uuid.UUID, userID uint) ([]dtos.MarketplaceChannelResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	var channels []models.MarketplaceChannel
+	if err := s.DB.Preload("Outlet").Where("outlet_id = ? AND user_id = ?", outlet.ID, ownerID).Find(&channels).Error; err != nil {
+		log.Printf("Error getting marketplace channels: %v", err)
+		return nil, errors.New("failed to retrieve marketplace channels")
+	}
+
+	responses := []dtos.MarketplaceChannelResponse{}
+	for _, channel := range channels {
+		responses = append(responses, *mapMarketplaceChannelToResponse(channel))
+	}
+	return responses, nil
+}
+
+func (s *MarketplaceService) CreateItemMapping(channelUuid uuid.UUID, req *dtos.CreateMarketplaceItemMappingRequest, userID uint) (*dtos.MarketplaceItemMappingResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var channel models.MarketplaceChannel
+	if err := s.DB.Where("uuid = ? AND user_id = ?", channelUuid, ownerID).First(&channel).Error; err != nil {
+		return nil, errors.New("marketplace channel not found")
+	}
+
+	mapping := models.MarketplaceItemMapping{
+		MarketplaceChannelID: channel.ID,
+		ExternalItemID:       req.ExternalItemID,
+		UserID:               ownerID,
+	}
+	if req.ProductVariantUuid != uuid.Nil {
+		var variant models.ProductVariant
+		if err := s.DB.Where("uuid = ? AND user_id = ?", req.ProductVariantUuid, ownerID).First(&variant).Error; err != nil {
+			return nil, errors.New("product variant not found")
+		}
+		mapping.ProductVariantID = &variant.ID
+		mapping.ProductVariant = &variant
+	} else {
+		var product models.Product
+		if err := s.DB.Where("uuid = ? AND user_id = ?", req.ProductUuid, ownerID).First(&product).Error; err != nil {
+			return nil, errors.New("product not found")
+		}
+		mapping.ProductID = &product.ID
+		mapping.Product = &product
+	}
+
+	var existing int64
+	s.DB.Model(&models.MarketplaceItemMapping{}).Where("marketplace_channel_id = ? AND external_item_id = ?", channel.ID, req.ExternalItemID).Count(&existing)
+	if existing > 0 {
+		return nil, errors.New("external item is already mapped")
+	}
+
+	if err := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Omit("Product", "ProductVariant").Create(&mapping).Error; err != nil {
+		log.Printf("Error creating marketplace item mapping: %v", err)
+		return nil, errors.New("failed to create marketplace item mapping")
+	}
+
+	return mapMarketplaceItemMappingToResponse(mapping), nil
+}
+
+func (s *MarketplaceService) GetItemMappings(channelUuid uuid.UUID, userID uint) ([]dtos.MarketplaceItemMappingResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var channel models.MarketplaceChannel
+	if err := s.DB.Where("uuid = ? AND user_id = ?", channelUuid, ownerID).First(&channel).Error; err != nil {
+		return nil, errors.New("marketplace channel not found")
+	}
+
+	var mappings []models.MarketplaceItemMapping
+	if err := s.DB.Preload("Product").Preload("ProductVariant").Where("marketplace_channel_id = ?", channel.ID).Find(&mappings).Error; err != nil {
+		log.Printf("Error getting marketplace item mappings: %v", err)
+		return nil, errors.New("failed to retrieve marketplace item mappings")
+	}
+
+	responses := []dtos.MarketplaceItemMappingResponse{}
+	for _, mapping := range mappings {
+		responses = append(responses, *mapMarketplaceItemMappingToResponse(mapping))
+	}
+	return responses, nil
+}
+
+func (s *MarketplaceService) DeleteItemMapping(mappingUuid uuid.UUID, userID uint) error {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return err
+	}
+
+	result := s.DB.Where("uuid = ? AND user_id = ?", mappingUuid, ownerID).Delete(&models.MarketplaceItemMapping{})
+	if result.Error != nil {
+		log.Printf("Error deleting marketplace item mapping: %v", result.Error)
+		return errors.New("failed to delete marketplace item mapping")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("marketplace item mapping not found")
+	}
+	return nil
+}
+
+// HandleWebhook processes an order event sent by a marketplace. The raw body must be signed
+// with the channel's webhook secret. Redelivered events are answered with the existing order.
+func (s *MarketplaceService) HandleWebhook(channelUuid uuid.UUID, req *dtos.MarketplaceWebhookRequest, rawBody []byte, signature string) (*dtos.MarketplaceOrderResponse, error) {
+	var channel models.MarketplaceChannel
+	if err := s.DB.Where("uuid = ? AND is_active = ?", channelUuid, true).First(&channel).Error; err != nil {
+		return nil, errors.New("marketplace channel not found")
+	}
+
+	if !utils.VerifyHMACSHA256(channel.WebhookSecret, rawBody, signature) {
+		return nil, errors.New("invalid signature")
+	}
+
+	switch req.Event {
+	case "order.created":
+		return s.createMarketplaceOrder(channel, req, rawBody)
+	case "order.cancelled":
+		return s.cancelMarketplaceOrderFromWebhook(channel, req)
+	default:
+		return nil, errors.New("unsupported marketplace event")
+	}
+}
+
+func (s *MarketplaceService) createMarketplaceOrder(channel models.MarketplaceChannel, req *dtos.MarketplaceWebhookRequest, rawBody []byte) (*dtos.MarketplaceOrderResponse, error) {
+	var existing models.MarketplaceOrder
+	if err := s.DB.Where("marketplace_channel_id = ? AND external_order_id = ?", channel.ID, req.ExternalOrderID).First(&existing).Error; err == nil {
+		return s.loadMarketplaceOrderResponse(existing.ID)
+	}
+
+	ownerID := channel.UserID
+	ctx := context.WithValue(context.Background(), database.UserIDContextKey, ownerID)
+
+	tx := s.DB.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order := models.Order{
+		OutletID:    channel.OutletID,
+		UserID:      ownerID,
+		Status:      "pending",
+		TotalAmount: 0,
+	}
+	if err := tx.WithContext(ctx).Create(&order).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to create order")
+	}
+
+	externalItemIDs := make([]string, 0, len(req.Items))
+	for _, item := range req.Items {
+		externalItemIDs = append(externalItemIDs, item.ExternalItemID)
+	}
+	var mappings []models.MarketplaceItemMapping
+	if err := tx.Where("marketplace_channel_id = ? AND external_item_id IN ?", channel.ID, externalItemIDs).Find(&mappings).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error finding marketplace item mappings: %v", err)
+		return nil, errors.New("failed to create order")
+	}
+	var productIDs, variantIDs []uint
+	for _, mapping := range mappings {
+		if mapping.ProductVariantID != nil {
+			variantIDs = append(variantIDs, *mapping.ProductVariantID)
+		} else if mapping.ProductID != nil {
+			productIDs = append(productIDs, *mapping.ProductID)
+		}
+	}
+	if err := lockStocks(tx, channel.OutletID, productIDs, variantIDs); err != nil {
+		tx.Rollback()
+		log.Printf("Error locking stocks for marketplace order: %v", err)
+		return nil, errors.New("failed to create order")
+	}
+
+	grossAmount := 0.0
+	for _, item := range req.Items {
+		var mapping models.MarketplaceItemMapping
+		if err := tx.Preload("Product").Preload("ProductVariant").Where("marketplace_channel_id = ? AND external_item_id = ?", channel.ID, item.ExternalItemID).First(&mapping).Error; err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("marketplace item %s is not mapped", item.ExternalItemID)
+		}
+
+		var productName string
+		var price float64
+		if mapping.ProductVariant != nil {
+			productName = mapping.ProductVariant.Name
+			price = mapping.ProductVariant.Price
+		} else if mapping.Product != nil {
+			productName = mapping.Product.Name
+			price = mapping.Product.Price
+		}
+		// The marketplace price wins; it is what the customer was charged
+		if item.Price > 0 {
+			price = item.Price
+		}
+
+		if err := s.StockService.DeductStockForSale(tx, channel.OutletID, mapping.ProductID, mapping.ProductVariantID, float64(item.Quantity), ownerID, models.StockReferenceOrder, &order.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		costPrice, err := productUnitCost(tx, mapping.ProductID, mapping.ProductVariantID)
+		if err != nil {
+			tx.Rollback()
+			log.Printf("Error getting product cost: %v", err)
+			return nil, errors.New("failed to create order item")
+		}
+
+		orderItem := models.OrderItem{
+			OrderID:          order.ID,
+			ProductID:        mapping.ProductID,
+			ProductVariantID: mapping.ProductVariantID,
+			Quantity:         float64(item.Quantity),
+			Price:            price,
+			CostPrice:        costPrice,
+			ProductName:      productName,
+		}
+		if err := tx.WithContext(ctx).Create(&orderItem).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to create order item")
+		}
+
+		grossAmount += price * float64(item.Quantity)
+	}
+
+	// Marketplace orders are paid to the marketplace, so the order is settled on arrival
+	order.TotalAmount = grossAmount
+	order.PaidAmount = grossAmount
+	order.Status = "completed"
+	if err := tx.Save(&order).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update order total")
+	}
+	recordWebhookEvent(tx, ownerID, models.WebhookEventOrderCreated, orderWebhookData(order))
+	recordWebhookEvent(tx, ownerID, models.WebhookEventOrderCompleted, orderWebhookData(order))
+
+	commissionAmount := roundCurrency(grossAmount * channel.CommissionPercent / 100)
+	marketplaceOrder := models.MarketplaceOrder{
+		MarketplaceChannelID: channel.ID,
+		ExternalOrderID:      req.ExternalOrderID,
+		OrderID:              order.ID,
+		Status:               models.MarketplaceOrderStatusReceived,
+		CustomerName:         req.CustomerName,
+		GrossAmount:          grossAmount,
+		CommissionPercent:    channel.CommissionPercent,
+		CommissionAmount:     commissionAmount,
+		NetAmount:            grossAmount - commissionAmount,
+		RawPayload:           string(rawBody),
+		UserID:               ownerID,
+	}
+	if err := tx.WithContext(ctx).Create(&marketplaceOrder).Error; err != nil {
+		tx.Rollback()
+		// A concurrent redelivery may have stored the same external order first
+		if err := s.DB.Where("marketplace_channel_id = ? AND external_order_id = ?", channel.ID, req.ExternalOrderID).First(&existing).Error; err == nil {
+			return s.loadMarketplaceOrderResponse(existing.ID)
+		}
+		log.Printf("Error creating marketplace order: %v", err)
+		return nil, errors.New("failed to create marketplace order")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to commit marketplace order transaction")
+	}
+
+	return s.loadMarketplaceOrderResponse(marketplaceOrder.ID)
+}
+
+func (s *MarketplaceService) cancelMarketplaceOrderFromWebhook(channel models.MarketplaceChannel, req *dtos.MarketplaceWebhookRequest) (*dtos.MarketplaceOrderResponse, error) {
+	var marketplaceOrder models.MarketplaceOrder
+	if err := s.DB.Where("marketplace_channel_id = ? AND external_order_id = ?", channel.ID, req.ExternalOrderID).First(&marketplaceOrder).Error; err != nil {
+		return nil, errors.New("marketplace order not found")
+	}
+
+	if marketplaceOrder.Status != models.MarketplaceOrderStatusCancelled {
+		tx := s.DB.Begin()
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+			}
+		}()
+
+		if err := s.cancelMarketplaceOrder(tx, &marketplaceOrder, channel.UserID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to commit marketplace order transaction")
+		}
+	}
+
+	return s.loadMarketplaceOrderResponse(marketplaceOrder.ID)
+}
+
+// cancelMarketplaceOrder cancels the linked order and returns its items to stock.
+func (s *MarketplaceService) cancelMarketplaceOrder(tx *gorm.DB, marketplaceOrder *models.MarketplaceOrder, userID uint) error {
+	var order models.Order
+	if err := tx.Preload("OrderItems").First(&order, marketplaceOrder.OrderID).Error; err != nil {
+		return errors.New("order not found")
+	}
+
+	var productIDs, variantIDs []uint
+	for _, item := range order.OrderItems {
+		if item.ProductVariantID != nil {
+			variantIDs = append(variantIDs, *item.ProductVariantID)
+		} else if item.ProductID != nil {
+			productIDs = append(productIDs, *item.ProductID)
+		}
+	}
+	if err := lockStocks(tx, order.OutletID, productIDs, variantIDs); err != nil {
+		log.Printf("Error locking stocks for marketplace order %d: %v", order.ID, err)
+		return errors.New("failed to cancel order")
+	}
+
+	ctx := context.WithValue(context.Background(), database.UserIDContextKey, userID)
+	for _, item := range order.OrderItems {
+		if err := s.StockService.AddStockFromSale(tx.WithContext(ctx), order.OutletID, item.ProductID, item.ProductVariantID, item.Quantity, order.UserID, models.StockReferenceOrder, &order.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.WithContext(ctx).Model(&order).Update("status", "cancelled").Error; err != nil {
+		return errors.New("failed to cancel order")
+	}
+
+	marketplaceOrder.Status = models.MarketplaceOrderStatusCancelled
+	if err := tx.WithContext(ctx).Model(marketplaceOrder).Update("status", marketplaceOrder.Status).Error; err != nil {
+		return errors.New("failed to update marketplace order status")
+	}
+	return nil
+}
+
+func (s *MarketplaceService) GetMarketplaceOrdersByOutlet(outletUuid uuid.UUID, userID uint, status string) ([]dtos.MarketplaceOrderResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	query := s.DB.Preload("MarketplaceChannel").Preload("Order").
+		Joins("JOIN marketplace_channels ON marketplace_channels.id = marketplace_orders.marketplace_channel_id").
+		Where("marketplace_channels.outlet_id = ? AND marketplace_orders.user_id = ?", outlet.ID, ownerID)
+	if status != "" {
+		query = query.Where("marketplace_orders.status = ?", status)
+	}
+
+	var marketplaceOrders []models.MarketplaceOrder
+	if err := query.Order("marketplace_orders.created_at DESC").Find(&marketplaceOrders).Error; err != nil {
+		log.Printf("Error getting marketplace orders: %v", err)
+		return nil, errors.New("failed to retrieve marketplace orders")
+	}
+
+	responses := []dtos.MarketplaceOrderResponse{}
+	for _, marketplaceOrder := range marketplaceOrders {
+		responses = append(responses, *mapMarketplaceOrderToResponse(marketplaceOrder))
+	}
+	return responses, nil
+}
+
+// UpdateMarketplaceOrderStatus changes the status of a marketplace order and pushes it to the marketplace.
+// Sending the current status again only retries the push.
+func (s *MarketplaceService) UpdateMarketplaceOrderStatus(marketplaceOrderUuid uuid.UUID, req *dtos.UpdateMarketplaceOrderStatusRequest, userID uint) (*dtos.MarketplaceOrderResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var marketplaceOrder models.MarketplaceOrder
+	if err := s.DB.Preload("MarketplaceChannel").Where("uuid = ? AND user_id = ?", marketplaceOrderUuid, ownerID).First(&marketplaceOrder).Error; err != nil {
+		return nil, errors.New("marketplace order not found")
+	}
+
+	if marketplaceOrder.Status != req.Status {
+		if marketplaceOrder.Status == models.MarketplaceOrderStatusCancelled || marketplaceOrder.Status == models.MarketplaceOrderStatusCompleted {
+			return nil, fmt.Errorf("marketplace order is already %s", marketplaceOrder.Status)
+		}
+
+		tx := s.DB.Begin()
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+			}
+		}()
+
+		if req.Status == models.MarketplaceOrderStatusCancelled {
+			if err := s.cancelMarketplaceOrder(tx, &marketplaceOrder, userID); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		} else {
+			if err := tx.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Model(&marketplaceOrder).Update("status", req.Status).Error; err != nil {
+				tx.Rollback()
+				return nil, errors.New("failed to update marketplace order status")
+			}
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to commit marketplace order transaction")
+		}
+	}
+
+	if err := s.clientFor(marketplaceOrder.MarketplaceChannel.Provider).PushOrderStatus(marketplaceOrder.MarketplaceChannel, marketplaceOrder.ExternalOrderID, req.Status); err != nil {
+		log.Printf("Error pushing status of marketplace order %s: %v", marketplaceOrder.Uuid, err)
+		return nil, errors.New("failed to push status to marketplace")
+	}
+
+	return s.loadMarketplaceOrderResponse(marketplaceOrder.ID)
+}
+
+func (s *MarketplaceService) loadMarketplaceOrderResponse(marketplaceOrderID uint) (*dtos.MarketplaceOrderResponse, error) {
+	var marketplaceOrder models.MarketplaceOrder
+	if err := s.DB.Preload("MarketplaceChannel").Preload("Order").First(&marketplaceOrder, marketplaceOrderID).Error; err != nil {
+		log.Printf("Error loading marketplace order: %v", err)
+		return nil, errors.New("failed to retrieve marketplace order")
+	}
+	return mapMarketplaceOrderToResponse(marketplaceOrder), nil
+}
+
+// roundCurrency rounds an amount to two decimals.
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func mapMarketplaceChannelToResponse(channel models.MarketplaceChannel) *dtos.MarketplaceChannelResponse {
+	return &dtos.MarketplaceChannelResponse{
+		Uuid:              channel.Uuid,
+		OutletUuid:        channel.Outlet.Uuid,
+		Provider:          channel.Provider,
+		Name:              channel.Name,
+		CommissionPercent: channel.CommissionPercent,
+		StatusCallbackURL: channel.StatusCallbackURL,
+		IsActive:          channel.IsActive,
+		WebhookURL:        fmt.Sprintf("%s/api/marketplace/%s/webhook", strings.TrimRight(os.Getenv("HOST"), "/"), channel.Uuid),
+	}
+}
+
+func mapMarketplaceItemMappingToResponse(mapping models.MarketplaceItemMapping) *dtos.MarketplaceItemMappingResponse {
+	response := &dtos.MarketplaceItemMappingResponse{
+		Uuid:           mapping.Uuid,
+		ExternalItemID: mapping.ExternalItemID,
+	}
+	if mapping.ProductVariant != nil {
+		response.ProductVariantUuid = mapping.ProductVariant.Uuid
+		response.Name = mapping.ProductVariant.Name
+	}
+	if mapping.Product != nil {
+		response.ProductUuid = mapping.Product.Uuid
+		response.Name = mapping.Product.Name
+	}
+	return response
+}
+
+func mapMarketplaceOrderToResponse(marketplaceOrder models.MarketplaceOrder) *dtos.MarketplaceOrderResponse {
+	return &dtos.MarketplaceOrderResponse{
+		Uuid:              marketplaceOrder.Uuid,
+		ChannelUuid:       marketplaceOrder.MarketplaceChannel.Uuid,
+		Provider:          marketplaceOrder.MarketplaceChannel.Provider,
+		ExternalOrderID:   marketplaceOrder.ExternalOrderID,
+		OrderUuid:         marketplaceOrder.Order.Uuid,
+		Status:            marketplaceOrder.Status,
+		CustomerName:      marketplaceOrder.CustomerName,
+		GrossAmount:       marketplaceOrder.GrossAmount,
+		CommissionPercent: marketplaceOrder.CommissionPercent,
+		CommissionAmount:  marketplaceOrder.CommissionAmount,
+		NetAmount:         marketplaceOrder.NetAmount,
+		CreatedAt:         marketplaceOrder.CreatedAt,
+	}
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// stubMarketplaceClient records the status updates pushed to it instead of calling a marketplace.
+type stubMarketplaceClient struct {
+	pushed []string
+}
+
+func (c *stubMarketplaceClient) PushOrderStatus(channel models.MarketplaceChannel, externalOrderID string, status string) error {
+	c.pushed = append(c.pushed, externalOrderID+" "+status)
+	return nil
+}
+
+// marketplaceFixture is a channel with a 20% commission whose item SKU-1 is mapped to a product with
+// 10 on hand. Status updates for the channel go to client.
+type marketplaceFixture struct {
+	db      *gorm.DB
+	owner   models.User
+	product models.Product
+	stock   models.Stock
+	channel models.MarketplaceChannel
+	service *MarketplaceService
+	client  *stubMarketplaceClient
+}
+
+const marketplaceOrderCreated = `{"event":"order.created","external_order_id":"MP-1","customer_name":"Budi","items":[{"external_item_id":"SKU-1","quantity":2,"price":15000}]}`
+
+func createMarketplaceFixture(t *testing.T) marketplaceFixture {
+	t.Helper()
+	db := openTestDB(t)
+	owner, outlet := createTestOwner(t, db, false)
+	product, stock := createTestStock(t, db, outlet, "Marketplace item", 10)
+
+	channel := models.MarketplaceChannel{OutletID: outlet.ID, Provider: "testmart", Name: "TestMart", WebhookSecret: "channel-secret", CommissionPercent: 20, IsActive: true, UserID: owner.ID}
+	if err := db.Omit(clause.Associations).Create(&channel).Error; err != nil {
+		t.Fatalf("failed to create marketplace channel: %v", err)
+	}
+	mapping := models.MarketplaceItemMapping{MarketplaceChannelID: channel.ID, ExternalItemID: "SKU-1", ProductID: &product.ID, UserID: owner.ID}
+	if err := db.Omit(clause.Associations).Create(&mapping).Error; err != nil {
+		t.Fatalf("failed to create marketplace item mapping: %v", err)
+	}
+
+	client := &stubMarketplaceClient{}
+	service := NewMarketplaceService(db, NewUserContextService(db), newTestStockService(db))
+	service.RegisterClient("TestMart", client)
+	return marketplaceFixture{db: db, owner: owner, product: product, stock: stock, channel: channel, service: service, client: client}
+}
+
+// deliver hands body to the webhook handler with the given signature, as the webhook handler would.
+func (f marketplaceFixture) deliver(t *testing.T, body string, signature string) (*dtos.MarketplaceOrderResponse, error) {
+	t.Helper()
+	var req dtos.MarketplaceWebhookRequest
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("failed to decode webhook body: %v", err)
+	}
+	return f.service.HandleWebhook(f.channel.Uuid, &req, []byte(body), signature)
+}
+
+func (f marketplaceFixture) sign(body string) string {
+	return "sha256=" + utils.SignHMACSHA256(f.channel.WebhookSecret, []byte(body))
+}
+
+// assertStock checks the stock of the fixture's product and the number of its ledger rows.
+func (f marketplaceFixture) assertStock(t *testing.T, quantity float64, movements int64) {
+	t.Helper()
+	var stock models.Stock
+	if err := f.db.First(&stock, f.stock.ID).Error; err != nil {
+		t.Fatalf("failed to reload stock: %v", err)
+	}
+	if stock.Quantity != quantity {
+		t.Fatalf("expected %v on hand, got %v", quantity, stock.Quantity)
+	}
+	var count int64
+	f.db.Model(&models.StockMovement{}).Where("product_id = ?", f.product.ID).Count(&count)
+	if count != movements {
+		t.Fatalf("expected %d stock movements, got %d", movements, count)
+	}
+}
+
+func TestMarketplaceWebhookRejectsBadSignature(t *testing.T) {
+	f := createMarketplaceFixture(t)
+
+	forged := "sha256=" + utils.SignHMACSHA256("another-secret", []byte(marketplaceOrderCreated))
+	for _, signature := range []string{"", forged} {
+		if _, err := f.deliver(t, marketplaceOrderCreated, signature); err == nil || err.Error() != "invalid signature" {
+			t.Fatalf("expected invalid signature, got %v", err)
+		}
+	}
+
+	var orders int64
+	f.db.Model(&models.MarketplaceOrder{}).Where("marketplace_channel_id = ?", f.channel.ID).Count(&orders)
+	if orders != 0 {
+		t.Fatalf("expected no marketplace order, got %d", orders)
+	}
+	f.assertStock(t, 10, 0)
+}
+
+func TestMarketplaceWebhookRedeliveryReturnsExistingOrder(t *testing.T) {
+	f := createMarketplaceFixture(t)
+
+	first, err := f.deliver(t, marketplaceOrderCreated, f.sign(marketplaceOrderCreated))
+	if err != nil {
+		t.Fatalf("failed to handle order.created: %v", err)
+	}
+	// 2 x 15000 at the marketplace price, less the channel's 20% commission
+	if first.GrossAmount != 30000 || first.CommissionPercent != 20 || first.CommissionAmount != 6000 || first.NetAmount != 24000 {
+		t.Fatalf("unexpected amounts %+v", first)
+	}
+	if first.Status != models.MarketplaceOrderStatusReceived || first.ExternalOrderID != "MP-1" {
+		t.Fatalf("unexpected marketplace order %+v", first)
+	}
+
+	again, err := f.deliver(t, marketplaceOrderCreated, f.sign(marketplaceOrderCreated))
+	if err != nil {
+		t.Fatalf("failed to handle redelivered order.created: %v", err)
+	}
+	if again.Uuid != first.Uuid || again.OrderUuid != first.OrderUuid {
+		t.Fatalf("expected the existing order %s, got %s", first.Uuid, again.Uuid)
+	}
+
+	var orders int64
+	f.db.Model(&models.MarketplaceOrder{}).Where("marketplace_channel_id = ?", f.channel.ID).Count(&orders)
+	if orders != 1 {
+		t.Fatalf("expected one marketplace order, got %d", orders)
+	}
+	f.assertStock(t, 8, 1)
+}
+
+func TestUpdateMarketplaceOrderStatusPushesToClient(t *testing.T) {
+	f := createMarketplaceFixture(t)
+
+	created, err := f.deliver(t, marketplaceOrderCreated, f.sign(marketplaceOrderCreated))
+	if err != nil {
+		t.Fatalf("failed to handle order.created: %v", err)
+	}
+
+	updated, err := f.service.UpdateMarketplaceOrderStatus(created.Uuid, &dtos.UpdateMarketplaceOrderStatusRequest{Status: models.MarketplaceOrderStatusAccepted}, f.owner.ID)
+	if err != nil {
+		t.Fatalf("failed to update marketplace order status: %v", err)
+	}
+	if updated.Status != models.MarketplaceOrderStatusAccepted {
+		t.Fatalf("expected the order to be accepted, got %s", updated.Status)
+	}
+	if len(f.client.pushed) != 1 || f.client.pushed[0] != "MP-1 accepted" {
+		t.Fatalf("expected the status to be pushed through the registered client, got %v", f.client.pushed)
+	}
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrderPaymentRefundService struct {
+	DB                 *gorm.DB
+	UserContextService *UserContextService
+	PaymentProviders   *PaymentProviderRegistry
+}
+
+func NewOrderPaymentRefundService(db *gorm.DB, userContextService *UserContextService, paymentProviders *PaymentProviderRegistry) *OrderPaymentRefundService {
+	return &OrderPaymentRefundService{DB: db, UserContextService: userContextService, PaymentProviders: paymentProviders}
+}
+
+// CreateRefund refunds part or all of a paid or orphaned order payment through its gateway. The pending
+// refund is committed before the gateway is called, and the gateway's answer is recorded in a second
+// transaction, so money sent out is never without a record and no row stays locked during the call.
+// A refund the gateway accepted but that could not be recorded stays pending until reconciliation.
+func (s *OrderPaymentRefundService) CreateRefund(orderPaymentUuid uuid.UUID, req *dtos.CreateOrderPaymentRefundRequest, userID uint) (*dtos.OrderPaymentRefundResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID))
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	orderPayment, err := findOwnedOrderPayment(tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "order_payments"}}), orderPaymentUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	orphaned := orderPayment.Status == models.OrderPaymentStatusOrphaned
+	if orderPayment.Status != models.OrderPaymentStatusPaid && !orphaned {
+		tx.Rollback()
+		return nil, errors.New("only paid payments can be refunded")
+	}
+
+	provider, err := s.PaymentProviders.Get(orderPayment.PaymentMethod.Issuer)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var storeCreditCustomer *models.Customer
+	if req.StoreCreditCustomerUuid != nil {
+		if storeCreditCustomer, err = findOwnedCustomer(tx, *req.StoreCreditCustomerUuid, ownerID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	var pendingAmount float64
+	if err := tx.Model(&models.OrderPaymentRefund{}).
+		Where("order_payment_id = ? AND status = ?", orderPayment.ID, models.OrderPaymentRefundStatusPending).
+		Select("COALESCE(SUM(amount), 0)").
+		Row().
+		Scan(&pendingAmount); err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to check pending refunds")
+	}
+	// The gateway confirms refunds per payment, so a second gateway refund could not be told apart
+	if pendingAmount > 0 && storeCreditCustomer == nil {
+		tx.Rollback()
+		return nil, errors.New("a refund of this payment is still pending")
+	}
+
+	refundable := orderPayment.AmountPaid - orderPayment.RefundedAmount - pendingAmount
+	amount := req.Amount
+	if amount == 0 {
+		amount = refundable
+	}
+	if amount <= 0 || (amount > refundable && !amountMatches(refundable, amount)) {
+		tx.Rollback()
+		return nil, errors.New("refund amount exceeds the refundable amount")
+	}
+	// A refunded wallet top-up is taken back out of the wallet, so it must not have been spent
+	if !orphaned {
+		if err := checkWalletTopUpRefundable(tx, orderPayment.OrderID, refundOrderShare(*orderPayment, amount)); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	refund := models.OrderPaymentRefund{
+		OrderPaymentID: orderPayment.ID,
+		Amount:         amount,
+		Reason:         req.Reason,
+		Status:         models.OrderPaymentRefundStatusPending,
+		Extra:          "{}",
+	}
+	if err := tx.Create(&refund).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to create refund")
+	}
+
+	// Store credit never leaves the POS, so it is granted and recorded in one transaction
+	if storeCreditCustomer != nil {
+		result, err := refundAsStoreCredit(tx, storeCreditCustomer, *orderPayment, amount, req.Reason)
+		if err == nil {
+			err = recordRefundResult(tx, &refund, result)
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := tx.Commit().Error; err != nil {
+			return nil, errors.New("failed to commit transaction")
+		}
+		return mapOrderPaymentRefundToResponse(refund, orderPayment.Uuid), nil
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+
+	// Providers that move money inside the POS, like the wallet, write in their own short
+	// transaction; gateways only read from it while they are called.
+	var result *PaymentRefundResult
+	refundErr := db.Transaction(func(providerTx *gorm.DB) error {
+		var err error
+		result, err = provider.Refund(providerTx, *orderPayment, amount, req.Reason)
+		return err
+	})
+
+	tx = db.Begin()
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&refund, refund.ID).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Refund %s of order payment %s could not be reloaded after the gateway call: %v", refund.Uuid, orderPayment.Uuid, err)
+		return nil, errors.New("failed to update refund")
+	}
+
+	if refundErr != nil {
+		if errors.Is(refundErr, ErrPaymentOperationNotSupported) {
+			// Nothing was sent, so the refund never happened
+			if err := tx.Delete(&refund).Error; err != nil {
+				tx.Rollback()
+				return nil, errors.New("failed to update refund")
+			}
+			if err := tx.Commit().Error; err != nil {
+				return nil, errors.New("failed to commit transaction")
+			}
+			return nil, refundErr
+		}
+		if refund.Status != models.OrderPaymentRefundStatusPending {
+			// A callback settled the refund while the gateway was answering
+			tx.Rollback()
+			return nil, fmt.Errorf("refund failed: %w", refundErr)
+		}
+		// Keep the failed attempt for the audit trail
+		now := time.Now()
+		refund.Status = models.OrderPaymentRefundStatusFailed
+		refund.FailureReason = refundErr.Error()
+		refund.FailedAt = &now
+		if err := tx.Save(&refund).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to update refund")
+		}
+		if err := tx.Commit().Error; err != nil {
+			return nil, errors.New("failed to commit transaction")
+		}
+		log.Printf("Refund of order payment %s failed: %v", orderPayment.Uuid, refundErr)
+		return nil, fmt.Errorf("refund failed: %w", refundErr)
+	}
+
+	if err := recordRefundResult(tx, &refund, result); err != nil {
+		tx.Rollback()
+		log.Printf("Refund %s of order payment %s was accepted by the gateway but could not be recorded: %v", refund.Uuid, orderPayment.Uuid, err)
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		log.Printf("Refund %s of order payment %s was accepted by the gateway but could not be recorded: %v", refund.Uuid, orderPayment.Uuid, err)
+		return nil, errors.New("failed to commit transaction")
+	}
+	return mapOrderPaymentRefundToResponse(refund, orderPayment.Uuid), nil
+}
+
+// recordRefundResult stores what the gateway answered for a refund, and completes it when the
+// gateway refunded it straight away. A refund a callback already completed is not completed again.
+func recordRefundResult(tx *gorm.DB, refund *models.OrderPaymentRefund, result *PaymentRefundResult) error {
+	refund.ReferenceID = result.ReferenceID
+	refund.Extra = marshalExtra(result.Extra)
+	if err := tx.Save(refund).Error; err != nil {
+		return errors.New("failed to update refund")
+	}
+	if result.Status == PaymentStatusRefunded && refund.Status == models.OrderPaymentRefundStatusPending {
+		return completeRefund(tx, refund, time.Now())
+	}
+	return nil
+}
+
+func (s *OrderPaymentRefundService) GetRefunds(orderPaymentUuid uuid.UUID, userID uint) ([]dtos.OrderPaymentRefundResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	orderPayment, err := findOwnedOrderPayment(s.DB, orderPaymentUuid, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var refunds []models.OrderPaymentRefund
+	if err := s.DB.Where("order_payment_id = ?", orderPayment.ID).Order("created_at desc").Find(&refunds).Error; err != nil {
+		return nil, errors.New("failed to retrieve refunds")
+	}
+
+	responses := make([]dtos.OrderPaymentRefundResponse, 0, len(refunds))
+	for _, refund := range refunds {
+		responses = append(responses, *mapOrderPaymentRefundToResponse(refund, orderPayment.Uuid))
+	}
+	return responses, nil
+}
+
+// completePendingRefund marks the pending refund of an order payment succeeded once its gateway
+// reports the payment refunded. Gateways report this per payment rather than per refund, so when
+// several refunds are pending there is no telling which one was confirmed and they are left for
+// review; CreateRefund only lets one gateway refund be pending at a time.
+func completePendingRefund(tx *gorm.DB, orderPaymentUuid string) error {
+	var refunds []models.OrderPaymentRefund
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "order_payment_refunds"}}).
+		Joins("JOIN order_payments ON order_payments.id = order_payment_refunds.order_payment_id").
+		Where("order_payments.uuid = ? AND order_payment_refunds.status = ?", orderPaymentUuid, models.OrderPaymentRefundStatusPending).
+		Find(&refunds).Error; err != nil {
+		return fmt.Errorf("failed to load pending refunds: %w", err)
+	}
+
+	switch len(refunds) {
+	case 0:
+		return nil
+	case 1:
+		return completeRefund(tx, &refunds[0], time.Now())
+	default:
+		log.Printf("Order payment %s has %d pending refunds; not completing them on a payment-level refund status", orderPaymentUuid, len(refunds))
+		return nil
+	}
+}
+
+// completeRefund marks a refund succeeded and takes its amount off the payment and the order.
+// The payment becomes refunded once nothing is left of it.
+func completeRefund(tx *gorm.DB, refund *models.OrderPaymentRefund, at time.Time) error {
+	refund.Status = models.OrderPaymentRefundStatusSucceeded
+	refund.RefundedAt = &at
+	if err := tx.Omit(clause.Associations).Save(refund).Error; err != nil {
+		return fmt.Errorf("failed to update refund: %w", err)
+	}
+
+	var orderPayment models.OrderPayment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&orderPayment, refund.OrderPaymentID).Error; err != nil {
+		return errors.New("order payment not found")
+	}
+	// An orphaned payment was never credited to the order, so there is nothing to take off it
+	credited := orderPayment.Status != models.OrderPaymentStatusOrphaned
+	orderPayment.RefundedAmount += refund.Amount
+	if orderPayment.RefundedAmount >= orderPayment.AmountPaid || amountMatches(orderPayment.AmountPaid, orderPayment.RefundedAmount) {
+		setOrderPaymentStatus(&orderPayment, models.OrderPaymentStatusRefunded, at)
+	}
+	if err := tx.Save(&orderPayment).Error; err != nil {
+		return fmt.Errorf("failed to update order payment: %w", err)
+	}
+
+	if credited {
+		orderShare := refundOrderShare(orderPayment, refund.Amount)
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderPayment.OrderID).Error; err != nil {
+			return errors.New("order not found")
+		}
+		order.PaidAmount -= orderShare
+		// An order that is no longer fully paid is open again
+		if order.Status == "completed" && order.PaidAmount < order.TotalAmount && !amountMatches(order.TotalAmount, order.PaidAmount) {
+			order.Status = "pending"
+		}
+		if err := tx.Model(&order).Updates(map[string]interface{}{"paid_amount": order.PaidAmount, "status": order.Status}).Error; err != nil {
+			return fmt.Errorf("failed to update order paid amount: %w", err)
+		}
+		if err := reverseWalletTopUp(tx, orderPayment.OrderID, orderShare); err != nil {
+			return err
+		}
+	}
+
+	var ownerID uint
+	if err := tx.Model(&models.Order{}).Where("id = ?", orderPayment.OrderID).Pluck("user_id", &ownerID).Error; err == nil {
+		data := paymentWebhookData(tx, orderPayment)
+		data["refund"] = map[string]interface{}{
+			"uuid":         refund.Uuid,
+			"amount":       refund.Amount,
+			"reason":       refund.Reason,
+			"reference_id": refund.ReferenceID,
+			"refunded_at":  refund.RefundedAt,
+		}
+		recordWebhookEvent(tx, ownerID, models.WebhookEventPaymentRefunded, data)
+	}
+	return nil
+}
+
+// refundOrderShare is the part of a refund that comes off the order; the rest returns the
+// payment method surcharge.
+func refundOrderShare(orderPayment models.OrderPayment, amount float64) float64 {
+	if orderPayment.SurchargeAmount > 0 && orderPayment.AmountPaid > 0 {
+		return amount * (orderPayment.AmountPaid - orderPayment.SurchargeAmount) / orderPayment.AmountPaid
+	}
+	return amount
+}
+
+func mapOrderPaymentRefundToResponse(refund models.OrderPaymentRefund, orderPaymentUuid uuid.UUID) *dtos.OrderPaymentRefundResponse {
+	var extraData interface{}
+	if refund.Extra != "" {
+		json.Unmarshal([]byte(refund.Extra), &extraData)
+	}
+
+	return &dtos.OrderPaymentRefundResponse{
+		Uuid:             refund.Uuid,
+		OrderPaymentUuid: orderPaymentUuid,
+		Amount:           refund.Amount,
+		Reason:           refund.Reason,
+		Status:           refund.Status,
+		ReferenceID:      refund.ReferenceID,
+		FailureReason:    refund.FailureReason,
+		Extra:            extraData,
+		RefundedAt:       refund.RefundedAt,
+		FailedAt:         refund.FailedAt,
+		CreatedAt:        refund.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -1,28 +1,38 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
 	"github.com/msyaifudin/pos/internal/models"
 	"github.com/msyaifudin/pos/internal/models/dtos"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderPaymentService struct {
 	DB                 *gorm.DB
 	UserContextService *UserContextService
-	IpaymuService      *IpaymuService
-	TsmService         *TsmService
+	PaymentProviders   *PaymentProviderRegistry
 }
 
-func NewOrderPaymentService(db *gorm.DB, userContextService *UserContextService, ipaymuService *IpaymuService, tsmService *TsmService) *OrderPaymentService {
-	return &OrderPaymentService{DB: db, UserContextService: userContextService, IpaymuService: ipaymuService, TsmService: tsmService}
+func NewOrderPaymentService(db *gorm.DB, userContextService *UserContextService, paymentProviders *PaymentProviderRegistry) *OrderPaymentService {
+	return &OrderPaymentService{DB: db, UserContextService: userContextService, PaymentProviders: paymentProviders}
 }
 
 func (s *OrderPaymentService) CreateOrderPayment(req dtos.CreateOrderPaymentRequest, userID uint) (*dtos.OrderPaymentResponse, error) {
+	return s.createOrderPayment(req, userID, 0)
+}
+
+// createOrderPayment creates a payment for the selected items. expiresIn asks the provider for a
+// payment window other than its default.
+func (s *OrderPaymentService) createOrderPayment(req dtos.CreateOrderPaymentRequest, userID uint, expiresIn time.Duration) (*dtos.OrderPaymentResponse, error) {
 	ownerID, err := s.UserContextService.GetOwnerID(userID)
 	if err != nil {
 		return nil, err
@@ -45,6 +55,10 @@ func (s *OrderPaymentService) CreateOrderPayment(req dtos.CreateOrderPaymentRequ
 		tx.Rollback()
 		return nil, errors.New("order is already completed")
 	}
+	if order.Status == "cancelled" {
+		tx.Rollback()
+		return nil, errors.New("order is cancelled")
+	}
 
 	var paymentMethod models.PaymentMethod
 	if err := tx.Where("id = ? AND is_active = ?", req.PaymentMethodID, true).First(&paymentMethod).Error; err != nil {
@@ -52,19 +66,10 @@ func (s *OrderPaymentService) CreateOrderPayment(req dtos.CreateOrderPaymentRequ
 		return nil, errors.New("payment method not found or not active")
 	}
 
-	if paymentMethod.Issuer == "iPaymu" {
-		if req.CustomerName == "" || req.CustomerEmail == "" || req.CustomerPhone == "" {
-			tx.Rollback()
-			return nil, errors.New("customer details are required for iPaymu payments")
-		}
-	}
-
-	if paymentMethod.Issuer == "TSM" {
-		var userPayment models.UserPayment
-		if err := tx.Where("user_id = ? AND payment_method_id = ? AND is_active = ?", ownerID, paymentMethod.ID, true).First(&userPayment).Error; err != nil {
-			tx.Rollback()
-			return nil, errors.New("user has not activated TSM payment")
-		}
+	provider, err := s.PaymentProviders.Get(paymentMethod.Issuer)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
 	}
 
 	var selectedOrderItems []models.OrderItem
@@ -83,10 +88,11 @@ func (s *OrderPaymentService) CreateOrderPayment(req dtos.CreateOrderPaymentRequ
 
 	for _, item := range selectedOrderItems {
 		var totalPaidQuantity float64
-		// Check how much of this item has been paid for in previous transactions
+		// Check how much of this item is paid, or still awaiting payment, in previous transactions.
+		// Failed, expired and cancelled payments no longer hold their items.
 		err := tx.Model(&models.OrderPaymentItem{}).
 			Joins("JOIN order_payments ON order_payments.id = order_payment_items.order_payment_id").
-			Where("order_payment_items.order_item_id = ? AND order_payments.is_paid = ?", item.ID, true).
+			Where("order_payment_items.order_item_id = ? AND order_payments.status IN ?", item.ID, []string{models.OrderPaymentStatusPending, models.OrderPaymentStatusPaid}).
 			Select("COALESCE(SUM(order_payment_items.quantity_paid), 0)").
 			Row().
 			Scan(&totalPaidQuantity)
@@ -106,7 +112,7 @@ func (s *OrderPaymentService) CreateOrderPayment(req dtos.CreateOrderPaymentRequ
 
 	if len(alreadyPaidItems) > 0 {
 		tx.Rollback()
-		return nil, fmt.Errorf("the following items have already been fully paid: %v", alreadyPaidItems)
+		return nil, fmt.Errorf("the following items have already been fully paid or are awaiting payment: %v", alreadyPaidItems)
 	}
 
 	if totalAmountToPay <= 0 {
@@ -114,12 +120,19 @@ func (s *OrderPaymentService) CreateOrderPayment(req dtos.CreateOrderPaymentRequ
 		return nil, errors.New("no amount to pay for the selected items")
 	}
 
-	now := time.Now()
+	surcharge, err := outletPaymentSurcharge(tx, order.OutletID, paymentMethod.ID, totalAmountToPay)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	orderPayment := models.OrderPayment{
 		OrderID:         order.ID,
 		PaymentMethodID: req.PaymentMethodID,
-		AmountPaid:      totalAmountToPay,
+		AmountPaid:      totalAmountToPay + surcharge,
+		SurchargeAmount: surcharge,
 		IsPaid:          false,
+		Status:          models.OrderPaymentStatusPending,
 		CustomerName:    req.CustomerName,
 		CustomerEmail:   req.CustomerEmail,
 		CustomerPhone:   req.CustomerPhone,
@@ -136,101 +149,59 @@ func (s *OrderPaymentService) CreateOrderPayment(req dtos.CreateOrderPaymentRequ
 	}
 	orderPayment.OrderPaymentItems = paymentItems
 
-	if paymentMethod.Issuer != "iPaymu" && paymentMethod.Issuer != "TSM" {
-		orderPayment.IsPaid = true
-		orderPayment.PaidAt = &now
-		order.PaidAmount += totalAmountToPay
-		if order.PaidAmount >= order.TotalAmount {
-			order.Status = "completed"
-		}
-		if err := tx.Save(&order).Error; err != nil {
-			tx.Rollback()
-			return nil, errors.New("failed to update order status")
-		}
-	}
-
 	if err := tx.Create(&orderPayment).Error; err != nil {
 		tx.Rollback()
 		return nil, errors.New("failed to create order payment")
 	}
 
-	if paymentMethod.Issuer == "iPaymu" {
-		var products []string
-		var qtys []int
-		var prices []int
-		for _, item := range selectedOrderItems {
-			products = append(products, item.ProductName)
-			qtys = append(qtys, int(item.Quantity))
-			prices = append(prices, int(item.Price))
-		}
-
-		ipaymuRes, err := s.IpaymuService.CreateDirectPayment(
-			userID, "Order Payment", orderPayment.Uuid.String(),
-			products, qtys, prices,
-			req.CustomerName, req.CustomerEmail, req.CustomerPhone,
-			paymentMethod.PaymentMethod, paymentMethod.PaymentChannel, nil,
-		)
-		if err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("iPaymu direct payment failed: %w", err)
-		}
+	result, err := provider.Initiate(tx, PaymentInitiateRequest{
+		UserID:        userID,
+		OwnerID:       ownerID,
+		Order:         order,
+		OrderPayment:  &orderPayment,
+		PaymentMethod: paymentMethod,
+		Items:         selectedOrderItems,
+		CustomerName:  req.CustomerName,
+		CustomerEmail: req.CustomerEmail,
+		CustomerPhone: req.CustomerPhone,
+		ExpiresIn:     expiresIn,
+		CustomerUuid:  req.CustomerUuid,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
-		if data, ok := ipaymuRes["Data"].(map[string]interface{}); ok {
-			if trxId, ok := data["TransactionId"].(string); ok {
-				orderPayment.ReferenceID = trxId
-			}
-			rawExtra, err := json.Marshal(data)
-			if err == nil {
-				orderPayment.Extra = string(rawExtra)
+	orderPayment.ReferenceID = result.ReferenceID
+	orderPayment.Extra = marshalExtra(result.Extra)
+	if !result.Paid {
+		orderPayment.ExpiresAt = result.ExpiresAt
+		if orderPayment.ExpiresAt == nil {
+			if expiresIn <= 0 {
+				expiresIn = pendingPaymentTTL()
 			}
-		}
-		if err := tx.Save(&orderPayment).Error; err != nil {
-			tx.Rollback()
-			return nil, errors.New("failed to update order payment with iPaymu details")
+			expiresAt := time.Now().Add(expiresIn)
+			orderPayment.ExpiresAt = &expiresAt
 		}
 	}
-
-	if paymentMethod.Issuer == "TSM" {
-		var userTsm models.UserTsm
-		if err := tx.Where("user_id = ?", ownerID).First(&userTsm).Error; err != nil {
+	if result.Paid {
+		if err := s.updateOrderAndPaymentStatus(tx, &orderPayment, orderPayment.AmountPaid); err != nil {
 			tx.Rollback()
-			return nil, errors.New("user tsm data not found")
-		}
-
-		var productNames []string
-		for _, item := range selectedOrderItems {
-			productNames = append(productNames, item.ProductName)
-		}
-
-		tsmReq := dtos.TsmGenerateApplinkRequest{
-			AppCode:      userTsm.AppCode,
-			Amount:       orderPayment.AmountPaid,
-			TrxID:        orderPayment.Uuid.String(),
-			TerminalCode: userTsm.TerminalCode,
-			MerchantCode: userTsm.MerchantCode,
-		}
-		tsmLink, err := s.TsmService.GenerateAPPLink(ownerID, tsmReq)
-		if err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("TSM generate app link failed: %w", err)
-		}
-
-		// Construct the desired extra data structure
-		extraDataMap := make(map[string]interface{})
-		if tsmLinkResponseCode, ok := tsmLink["data"]; ok {
-			extraDataMap["data"] = tsmLinkResponseCode
-		}
-
-		rawExtra, err := json.Marshal(extraDataMap["data"])
-		if err == nil {
-			orderPayment.Extra = string(rawExtra)
+			return nil, errors.New("failed to update order status")
 		}
+	} else if err := tx.Save(&orderPayment).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update order payment with provider details")
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		return nil, errors.New("failed to commit transaction")
 	}
 
+	return mapOrderPaymentToResponse(orderPayment, order.Uuid, paymentMethod.Name), nil
+}
+
+func mapOrderPaymentToResponse(orderPayment models.OrderPayment, orderUuid uuid.UUID, paymentName string) *dtos.OrderPaymentResponse {
 	var extraData interface{}
 	if orderPayment.Extra != "" {
 		json.Unmarshal([]byte(orderPayment.Extra), &extraData)
@@ -238,10 +209,12 @@ func (s *OrderPaymentService) CreateOrderPayment(req dtos.CreateOrderPaymentRequ
 
 	return &dtos.OrderPaymentResponse{
 		Uuid:            orderPayment.Uuid,
-		OrderUuid:       order.Uuid,
+		OrderUuid:       orderUuid,
 		PaymentMethodID: orderPayment.PaymentMethodID,
-		PaymentName:     paymentMethod.Name,
+		PaymentName:     paymentName,
 		AmountPaid:      orderPayment.AmountPaid,
+		RefundedAmount:  orderPayment.RefundedAmount,
+		SurchargeAmount: orderPayment.SurchargeAmount,
 		CustomerName:    orderPayment.CustomerName,
 		CustomerEmail:   orderPayment.CustomerEmail,
 		CustomerPhone:   orderPayment.CustomerPhone,
@@ -250,26 +223,33 @@ func (s *OrderPaymentService) CreateOrderPayment(req dtos.CreateOrderPaymentRequ
 		PaidAt:          orderPayment.PaidAt,
 		ChangeAmount:    orderPayment.ChangeAmount,
 		Extra:           extraData,
-	}, nil
+		Status:          orderPayment.Status,
+		ExpiresAt:       orderPayment.ExpiresAt,
+		ExpiredAt:       orderPayment.ExpiredAt,
+		FailedAt:        orderPayment.FailedAt,
+		CancelledAt:     orderPayment.CancelledAt,
+		RefundedAt:      orderPayment.RefundedAt,
+		ReviewNote:      orderPayment.ReviewNote,
+		ReviewedAt:      orderPayment.ReviewedAt,
+	}
 }
 
 // updateOrderAndPaymentStatus is a helper function to update order payment and order status
 func (s *OrderPaymentService) updateOrderAndPaymentStatus(tx *gorm.DB, orderPayment *models.OrderPayment, amountPaid float64) error {
-	now := time.Now()
-	orderPayment.IsPaid = true
-	orderPayment.PaidAt = &now
+	setOrderPaymentStatus(orderPayment, models.OrderPaymentStatusPaid, time.Now())
 
 	if err := tx.Save(orderPayment).Error; err != nil {
 		return fmt.Errorf("failed to update order payment status: %w", err)
 	}
 
 	var order models.Order
-	if err := tx.Where("id = ?", orderPayment.OrderID).First(&order).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", orderPayment.OrderID).First(&order).Error; err != nil {
 		return fmt.Errorf("order not found for order payment %s: %w", orderPayment.Uuid.String(), err)
 	}
 
-	// Update order's paid amount and status
-	order.PaidAmount += amountPaid
+	// Update order's paid amount and status. The payment method surcharge is not part of the order.
+	wasCompleted := order.Status == "completed"
+	order.PaidAmount += amountPaid - orderPayment.SurchargeAmount
 	if order.PaidAmount >= order.TotalAmount {
 		order.Status = "completed"
 	}
@@ -278,15 +258,501 @@ func (s *OrderPaymentService) updateOrderAndPaymentStatus(tx *gorm.DB, orderPaym
 		return fmt.Errorf("failed to update order paid amount and status: %w", err)
 	}
 
+	recordWebhookEvent(tx, order.UserID, models.WebhookEventPaymentPaid, paymentWebhookData(tx, *orderPayment))
+	if order.Status == "completed" && !wasCompleted {
+		if err := creditWalletTopUp(tx, order.ID); err != nil {
+			return err
+		}
+		// Stock held for the order while it was unpaid is taken out now
+		if err := convertStockReservations(tx, order.ID); err != nil {
+			return err
+		}
+		recordWebhookEvent(tx, order.UserID, models.WebhookEventOrderCompleted, orderWebhookData(order))
+	}
+
 	return nil
 }
 
-// UpdateOrderPaymentAndStatus updates the order payment and order status based on iPaymu notification
+// UpdateOrderPaymentAndStatus marks the order payment paid and credits its order. The payment row is
+// locked and an already paid payment is left untouched, so repeated callbacks never credit twice. A
+// payment that was given up on is not credited, since a retry may have paid its items already.
 func (s *OrderPaymentService) UpdateOrderPaymentAndStatus(tx *gorm.DB, serviceRefID string, amountPaid float64) error {
 	var orderPayment models.OrderPayment
-	if err := tx.Where("uuid = ?", serviceRefID).First(&orderPayment).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ?", serviceRefID).First(&orderPayment).Error; err != nil {
 		return fmt.Errorf("order payment not found for ref ID %s: %w", serviceRefID, err)
 	}
 
-	return s.updateOrderAndPaymentStatus(tx, &orderPayment, amountPaid)
+	switch orderPayment.Status {
+	case models.OrderPaymentStatusPending:
+		return s.updateOrderAndPaymentStatus(tx, &orderPayment, amountPaid)
+	case models.OrderPaymentStatusFailed, models.OrderPaymentStatusExpired, models.OrderPaymentStatusCancelled:
+		return orphanOrderPayment(tx, &orderPayment)
+	}
+	return nil
+}
+
+// HandleProviderCallback verifies a notification with the provider of the given issuer and
+// credits the order payment it refers to. Every callback is recorded in PaymentCallbackLog.
+func (s *OrderPaymentService) HandleProviderCallback(issuer string, callback PaymentCallback) error {
+	provider, err := s.PaymentProviders.Get(issuer)
+	if err != nil {
+		s.recordCallback(issuer, callback, nil, err)
+		return err
+	}
+
+	tx := s.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	result, err := provider.VerifyCallback(tx, callback)
+	if err != nil {
+		tx.Rollback()
+		s.recordCallback(issuer, callback, nil, err)
+		return err
+	}
+
+	if result.Paid && result.ServiceRefID != "" {
+		if err := s.UpdateOrderPaymentAndStatus(tx, result.ServiceRefID, result.Amount); err != nil {
+			tx.Rollback()
+			s.recordCallback(issuer, callback, result, err)
+			return fmt.Errorf("failed to update order payment and status: %w", err)
+		}
+	}
+	if result.Refunded && result.ServiceRefID != "" {
+		if err := completePendingRefund(tx, result.ServiceRefID); err != nil {
+			tx.Rollback()
+			s.recordCallback(issuer, callback, result, err)
+			return fmt.Errorf("failed to complete refunds: %w", err)
+		}
+	}
+	if result.Failed && result.ServiceRefID != "" {
+		if err := tx.Model(&models.OrderPayment{}).
+			Where("uuid = ? AND status = ?", result.ServiceRefID, models.OrderPaymentStatusPending).
+			Updates(map[string]interface{}{"status": models.OrderPaymentStatusFailed, "failed_at": time.Now()}).Error; err != nil {
+			tx.Rollback()
+			s.recordCallback(issuer, callback, result, err)
+			return fmt.Errorf("failed to update order payment status: %w", err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		s.recordCallback(issuer, callback, result, err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.recordCallback(issuer, callback, result, nil)
+	return nil
+}
+
+// recordCallback writes the audit entry outside the callback transaction so rejected
+// notifications are kept as well.
+func (s *OrderPaymentService) recordCallback(issuer string, callback PaymentCallback, result *PaymentCallbackResult, callbackErr error) {
+	entry := models.PaymentCallbackLog{
+		Issuer:   issuer,
+		SourceIP: callback.SourceIP,
+		Payload:  string(callback.RawBody),
+		Status:   models.PaymentCallbackStatusAccepted,
+	}
+	if entry.Payload == "" {
+		entry.Payload = marshalExtra(callback.Payload)
+	}
+	if result != nil {
+		entry.ReferenceID = result.ReferenceID
+		entry.ServiceRefID = result.ServiceRefID
+		if result.Duplicate {
+			entry.Status = models.PaymentCallbackStatusDuplicate
+		}
+	}
+	if callbackErr != nil {
+		entry.Status = models.PaymentCallbackStatusRejected
+		entry.Reason = callbackErr.Error()
+	}
+
+	if err := s.DB.Create(&entry).Error; err != nil {
+		log.Printf("Error recording %s payment callback: %v", issuer, err)
+	}
+}
+
+// CheckPaymentStatus asks the gateway for the status of one of the owner's order payments and applies it.
+func (s *OrderPaymentService) CheckPaymentStatus(orderPaymentUuid uuid.UUID, userID uint) (*dtos.OrderPaymentResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var orderPayment models.OrderPayment
+	if err := s.DB.Joins("JOIN orders ON orders.id = order_payments.order_id").
+		Where("order_payments.uuid = ? AND orders.user_id = ?", orderPaymentUuid, ownerID).
+		First(&orderPayment).Error; err != nil {
+		return nil, errors.New("order payment not found")
+	}
+
+	if err := s.reconcilePayment(orderPayment.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.Preload("Order").Preload("PaymentMethod").First(&orderPayment, orderPayment.ID).Error; err != nil {
+		return nil, errors.New("order payment not found")
+	}
+	return mapOrderPaymentToResponse(orderPayment, orderPayment.Order.Uuid, orderPayment.PaymentMethod.Name), nil
+}
+
+// ReconcilePendingPayments checks every open non-cash payment with its gateway. It is run by the
+// payment reconciliation worker; a failure on one payment does not stop the others.
+func (s *OrderPaymentService) ReconcilePendingPayments() error {
+	var pendingIDs []uint
+	if err := s.DB.Model(&models.OrderPayment{}).
+		Where("status = ?", models.OrderPaymentStatusPending).
+		Order("id").
+		Pluck("id", &pendingIDs).Error; err != nil {
+		return fmt.Errorf("failed to load pending payments: %w", err)
+	}
+
+	for _, id := range pendingIDs {
+		if err := s.reconcilePayment(id); err != nil {
+			log.Printf("Error reconciling order payment %d: %v", id, err)
+		}
+	}
+
+	// Payments with refunds the gateway has not confirmed yet
+	var refundingIDs []uint
+	if err := s.DB.Model(&models.OrderPaymentRefund{}).
+		Where("status = ?", models.OrderPaymentRefundStatusPending).
+		Distinct().
+		Pluck("order_payment_id", &refundingIDs).Error; err != nil {
+		return fmt.Errorf("failed to load pending refunds: %w", err)
+	}
+
+	for _, id := range refundingIDs {
+		if err := s.reconcileRefunds(id); err != nil {
+			log.Printf("Error reconciling refunds of order payment %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// reconcileRefunds completes the pending refund of a payment once its gateway reports it refunded.
+func (s *OrderPaymentService) reconcileRefunds(orderPaymentID uint) error {
+	var orderPayment models.OrderPayment
+	if err := s.DB.Preload("PaymentMethod").First(&orderPayment, orderPaymentID).Error; err != nil {
+		return errors.New("order payment not found")
+	}
+
+	provider, err := s.PaymentProviders.Get(orderPayment.PaymentMethod.Issuer)
+	if err != nil {
+		return err
+	}
+
+	// Ask the gateway before taking any row lock, as reconcilePayment does
+	result, err := provider.QueryStatus(s.DB, orderPayment)
+	if err != nil {
+		if errors.Is(err, ErrPaymentOperationNotSupported) {
+			return nil
+		}
+		return err
+	}
+	if result.Status != PaymentStatusRefunded {
+		return nil
+	}
+
+	tx := s.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := completePendingRefund(tx, orderPayment.Uuid.String()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// reconcilePayment credits a pending payment the gateway reports as paid, marks it failed or expired
+// when the gateway says so, and expires it once its deadline has passed. Only pending and paid
+// payments hold their items.
+func (s *OrderPaymentService) reconcilePayment(orderPaymentID uint) error {
+	var orderPayment models.OrderPayment
+	if err := s.DB.First(&orderPayment, orderPaymentID).Error; err != nil {
+		return errors.New("order payment not found")
+	}
+	if orderPayment.Status != models.OrderPaymentStatusPending {
+		return nil
+	}
+
+	var paymentMethod models.PaymentMethod
+	if err := s.DB.First(&paymentMethod, orderPayment.PaymentMethodID).Error; err != nil {
+		return errors.New("payment method not found")
+	}
+
+	provider, err := s.PaymentProviders.Get(paymentMethod.Issuer)
+	if err != nil {
+		return err
+	}
+
+	// Ask the gateway before taking the row lock, so a slow gateway does not hold up callbacks and
+	// cashiers working on the same payment
+	status := PaymentStatusPending
+	result, err := provider.QueryStatus(s.DB, orderPayment)
+	if err != nil && !errors.Is(err, ErrPaymentOperationNotSupported) {
+		return err
+	}
+	if result != nil {
+		status = result.Status
+	}
+
+	tx := s.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&orderPayment, orderPaymentID).Error; err != nil {
+		tx.Rollback()
+		return errors.New("order payment not found")
+	}
+	if orderPayment.Status != models.OrderPaymentStatusPending {
+		// A callback or a cashier settled the payment while the gateway was being asked
+		tx.Rollback()
+		return nil
+	}
+
+	now := time.Now()
+	switch {
+	case status == PaymentStatusPaid:
+		// Gateways may add their fee on top, but never collect less than the payment
+		if result.Amount > 0 && result.Amount < orderPayment.AmountPaid && !amountMatches(orderPayment.AmountPaid, result.Amount) {
+			tx.Rollback()
+			return errors.New("payment amount mismatch")
+		}
+		if orderPayment.ReferenceID == "" {
+			orderPayment.ReferenceID = result.ReferenceID
+		}
+		if err := s.updateOrderAndPaymentStatus(tx, &orderPayment, orderPayment.AmountPaid); err != nil {
+			tx.Rollback()
+			return err
+		}
+	case status == PaymentStatusFailed:
+		setOrderPaymentStatus(&orderPayment, models.OrderPaymentStatusFailed, now)
+	case status == PaymentStatusExpired, orderPayment.ExpiresAt != nil && now.After(*orderPayment.ExpiresAt):
+		setOrderPaymentStatus(&orderPayment, models.OrderPaymentStatusExpired, now)
+	}
+
+	orderPayment.LastCheckedAt = &now
+	if err := tx.Save(&orderPayment).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to update order payment status: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CancelOrderPayment cancels a pending payment, cancelling it at the gateway when the gateway
+// supports it, and frees its items for another payment.
+func (s *OrderPaymentService) CancelOrderPayment(orderPaymentUuid uuid.UUID, userID uint) (*dtos.OrderPaymentResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	orderPayment, err := s.cancelPendingPayment(tx, orderPaymentUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return mapOrderPaymentToResponse(*orderPayment, orderPayment.Order.Uuid, orderPayment.PaymentMethod.Name), nil
+}
+
+// ConfirmOrderPayment marks a pending payment paid on the cashier's confirmation. Only providers
+// without a gateway callback, such as a static QRIS, accept it.
+func (s *OrderPaymentService) ConfirmOrderPayment(orderPaymentUuid uuid.UUID, userID uint) (*dtos.OrderPaymentResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	orderPayment, err := findOwnedOrderPayment(tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "order_payments"}}), orderPaymentUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if orderPayment.Status != models.OrderPaymentStatusPending {
+		tx.Rollback()
+		return nil, errors.New("only pending payments can be confirmed")
+	}
+
+	provider, err := s.PaymentProviders.Get(orderPayment.PaymentMethod.Issuer)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	manualProvider, ok := provider.(ManualConfirmationProvider)
+	if !ok {
+		tx.Rollback()
+		return nil, ErrManualConfirmationNotAllowed
+	}
+	if err := manualProvider.ConfirmPayment(tx, *orderPayment); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := s.UpdateOrderPaymentAndStatus(tx, orderPayment.Uuid.String(), orderPayment.AmountPaid); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	confirmed, err := findOwnedOrderPayment(tx, orderPaymentUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return mapOrderPaymentToResponse(*confirmed, confirmed.Order.Uuid, confirmed.PaymentMethod.Name), nil
+}
+
+// RetryOrderPayment pays the items of a failed, expired or cancelled payment again, with the same or
+// another payment method. A payment that is still pending is cancelled first.
+func (s *OrderPaymentService) RetryOrderPayment(orderPaymentUuid uuid.UUID, req dtos.RetryOrderPaymentRequest, userID uint) (*dtos.OrderPaymentResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	orderPayment, err := findOwnedOrderPayment(s.DB, orderPaymentUuid, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch orderPayment.Status {
+	case models.OrderPaymentStatusPaid, models.OrderPaymentStatusRefunded, models.OrderPaymentStatusOrphaned:
+		return nil, errors.New("order payment cannot be retried")
+	case models.OrderPaymentStatusPending:
+		if _, err := s.CancelOrderPayment(orderPaymentUuid, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	var orderItemIDs []uint
+	for _, item := range orderPayment.OrderPaymentItems {
+		orderItemIDs = append(orderItemIDs, item.OrderItemID)
+	}
+
+	customerName, customerEmail, customerPhone := req.CustomerName, req.CustomerEmail, req.CustomerPhone
+	if customerName == "" && customerEmail == "" && customerPhone == "" {
+		customerName, customerEmail, customerPhone = orderPayment.CustomerName, orderPayment.CustomerEmail, orderPayment.CustomerPhone
+	}
+
+	return s.CreateOrderPayment(dtos.CreateOrderPaymentRequest{
+		OrderUuid:       orderPayment.Order.Uuid,
+		PaymentMethodID: req.PaymentMethodID,
+		OrderItemIDs:    orderItemIDs,
+		CustomerName:    customerName,
+		CustomerEmail:   customerEmail,
+		CustomerPhone:   customerPhone,
+		CustomerUuid:    req.CustomerUuid,
+	}, userID)
+}
+
+func (s *OrderPaymentService) cancelPendingPayment(tx *gorm.DB, orderPaymentUuid uuid.UUID, ownerID uint) (*models.OrderPayment, error) {
+	orderPayment, err := findOwnedOrderPayment(tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "order_payments"}}), orderPaymentUuid, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if orderPayment.Status != models.OrderPaymentStatusPending {
+		return nil, errors.New("only pending payments can be cancelled")
+	}
+
+	provider, err := s.PaymentProviders.Get(orderPayment.PaymentMethod.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	if err := provider.Cancel(tx, *orderPayment); err != nil {
+		if !errors.Is(err, ErrPaymentOperationNotSupported) {
+			return nil, fmt.Errorf("failed to cancel payment at the gateway: %w", err)
+		}
+		// The gateway cannot cancel; its payment request simply lapses and a late confirmation is kept as orphaned
+		log.Printf("Gateway %s cannot cancel order payment %s, cancelling locally", orderPayment.PaymentMethod.Issuer, orderPayment.Uuid)
+	}
+
+	setOrderPaymentStatus(orderPayment, models.OrderPaymentStatusCancelled, time.Now())
+	if err := tx.Omit(clause.Associations).Save(orderPayment).Error; err != nil {
+		return nil, errors.New("failed to cancel order payment")
+	}
+	return orderPayment, nil
+}
+
+func findOwnedOrderPayment(db *gorm.DB, orderPaymentUuid uuid.UUID, ownerID uint) (*models.OrderPayment, error) {
+	var orderPayment models.OrderPayment
+	if err := db.Preload("Order").Preload("PaymentMethod").Preload("OrderPaymentItems").
+		Joins("JOIN orders ON orders.id = order_payments.order_id").
+		Where("order_payments.uuid = ? AND orders.user_id = ?", orderPaymentUuid, ownerID).
+		First(&orderPayment).Error; err != nil {
+		return nil, errors.New("order payment not found")
+	}
+	return &orderPayment, nil
+}
+
+// orphanOrderPayment records money a gateway collected for a payment that was cancelled, failed or
+// expired. The order is not credited; the owner is told through a payment.orphaned webhook and
+// refunds the payment.
+func orphanOrderPayment(tx *gorm.DB, orderPayment *models.OrderPayment) error {
+	log.Printf("Order payment %s was collected after it was %s; keeping it for a refund", orderPayment.Uuid, orderPayment.Status)
+	setOrderPaymentStatus(orderPayment, models.OrderPaymentStatusOrphaned, time.Now())
+	if err := tx.Save(orderPayment).Error; err != nil {
+		return fmt.Errorf("failed to update order payment status: %w", err)
+	}
+
+	var ownerID uint
+	if err := tx.Model(&models.Order{}).Where("id = ?", orderPayment.OrderID).Pluck("user_id", &ownerID).Error; err != nil {
+		return fmt.Errorf("failed to find order of order payment: %w", err)
+	}
+	recordWebhookEvent(tx, ownerID, models.WebhookEventPaymentOrphaned, paymentWebhookData(tx, *orderPayment))
+	return nil
+}
+
+// setOrderPaymentStatus moves a payment to a new status and stamps the matching transition time.
+// IsPaid stays in sync for code that only looks at the boolean.
+func setOrderPaymentStatus(orderPayment *models.OrderPayment, status string, at time.Time) {
+	orderPayment.Status = status
+	orderPayment.IsPaid = status == models.OrderPaymentStatusPaid
+	switch status {
+	case models.OrderPaymentStatusPaid, models.OrderPaymentStatusOrphaned:
+		orderPayment.PaidAt = &at
+	case models.OrderPaymentStatusFailed:
+		orderPayment.FailedAt = &at
+	case models.OrderPaymentStatusExpired:
+		orderPayment.ExpiredAt = &at
+	case models.OrderPaymentStatusCancelled:
+		orderPayment.CancelledAt = &at
+	case models.OrderPaymentStatusRefunded:
+		orderPayment.RefundedAt = &at
+	}
 }
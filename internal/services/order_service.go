@@ -12,6 +12,7 @@ import (
 	"github.com/msyaifudin/pos/internal/models"
 	"github.com/msyaifudin/pos/internal/models/dtos"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderService struct {
@@ -42,22 +43,55 @@ func (s *OrderService) CreateOrder(req dtos.CreateOrderRequest, userID uint) (*d
 		return nil, errors.New("user not found")
 	}
 
-	tx := s.DB.Begin()
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
 		}
 	}()
 
+	order, err := s.createOrderWithTx(tx, outlet, req, userID, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to commit order transaction")
+	}
+
+	// Reload the order with all its relations for the comprehensive response using the main DB connection
+	if err := s.DB.Preload("User").Preload("Outlet").Preload("OrderPayments.PaymentMethod").Preload("OrderItems.Product").Preload("OrderItems.ProductVariant").Preload("OrderItems.AddOns.AddOn").First(order, order.ID).Error; err != nil {
+		log.Printf("Error preloading order relations after commit: %v", err)
+		return nil, errors.New("failed to retrieve full order details after commit")
+	}
+
+	return mapOrderToOrderResponse(*order, outlet), nil
+}
+
+// createOrderWithTx creates an order with its items and takes or reserves their stock. The caller
+// commits or rolls back tx.
+func (s *OrderService) createOrderWithTx(tx *gorm.DB, outlet models.Outlet, req dtos.CreateOrderRequest, userID uint, ownerID uint) (*models.Order, error) {
 	order := models.Order{
-		OutletID:    outlet.ID,
-		UserID:      ownerID,
-		Status:      "pending",
-		TotalAmount: 0,
+		OutletID:      outlet.ID,
+		UserID:        ownerID,
+		Status:        "pending",
+		TotalAmount:   0,
+		StockReserved: outlet.ReserveStock,
 	}
 
 	if err := tx.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Create(&order).Error; err != nil {
-		tx.Rollback()
+		return nil, errors.New("failed to create order")
+	}
+
+	var productUuids, variantUuids []uuid.UUID
+	for _, item := range req.Items {
+		productUuids = append(productUuids, item.ProductUuid)
+		variantUuids = append(variantUuids, item.ProductVariantUuid)
+	}
+	if err := s.lockOrderStocks(tx, outlet.ID, ownerID, productUuids, variantUuids, nil); err != nil {
+		log.Printf("Error locking stocks for order: %v", err)
 		return nil, errors.New("failed to create order")
 	}
 
@@ -72,7 +106,6 @@ func (s *OrderService) CreateOrder(req dtos.CreateOrderRequest, userID uint) (*d
 
 		if item.ProductVariantUuid != uuid.Nil {
 			if err := tx.Where("uuid = ? AND user_id = ?", item.ProductVariantUuid, ownerID).First(&variant).Error; err != nil {
-				tx.Rollback()
 				return nil, errors.New("product variant not found")
 			}
 			price = variant.Price
@@ -80,33 +113,36 @@ func (s *OrderService) CreateOrder(req dtos.CreateOrderRequest, userID uint) (*d
 			productName = variant.Name // Use variant name
 		} else if item.ProductUuid != uuid.Nil {
 			if err := tx.Where("uuid = ? AND user_id = ?", item.ProductUuid, ownerID).First(&product).Error; err != nil {
-				tx.Rollback()
 				return nil, errors.New("product not found")
 			}
 			price = product.Price
 			productID = &product.ID
 			productName = product.Name // Use product name
 		} else {
-			tx.Rollback()
 			return nil, errors.New("product_uuid or product_variant_uuid is required for each item")
 		}
 
-		if err := s.StockService.DeductStockForSale(tx, outlet.ID, productID, variantID, float64(item.Quantity), ownerID); err != nil {
-			tx.Rollback()
+		if err := s.takeOrderStock(tx, order, productID, variantID, float64(item.Quantity), ownerID); err != nil {
 			return nil, err
 		}
 
+		costPrice, err := productUnitCost(tx, productID, variantID)
+		if err != nil {
+			log.Printf("Error getting product cost: %v", err)
+			return nil, errors.New("failed to create order item")
+		}
+
 		orderItem := models.OrderItem{
 			OrderID:          order.ID,
 			ProductID:        productID,
 			ProductVariantID: variantID,
 			Quantity:         float64(item.Quantity),
 			Price:            price,
+			CostPrice:        costPrice,
 			ProductName:      productName,
 		}
 
 		if err := tx.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Create(&orderItem).Error; err != nil {
-			tx.Rollback()
 			return nil, errors.New("failed to create order item")
 		}
 
@@ -114,7 +150,6 @@ func (s *OrderService) CreateOrder(req dtos.CreateOrderRequest, userID uint) (*d
 		for _, addOnReq := range item.AddOns {
 			var addOnProduct models.Product
 			if err := tx.Where("uuid = ? AND user_id = ? AND type = ?", addOnReq.AddOnUuid, ownerID, "add_on").First(&addOnProduct).Error; err != nil {
-				tx.Rollback()
 				return nil, errors.New("add-on product not found or not of type add_on")
 			}
 
@@ -127,7 +162,6 @@ func (s *OrderService) CreateOrder(req dtos.CreateOrderRequest, userID uint) (*d
 			}
 
 			if err := tx.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Create(&orderItemAddOn).Error; err != nil {
-				tx.Rollback()
 				return nil, errors.New("failed to create order item add-on")
 			}
 			totalAmount += addOnProduct.Price * float64(addOnReq.Quantity)
@@ -138,22 +172,11 @@ func (s *OrderService) CreateOrder(req dtos.CreateOrderRequest, userID uint) (*d
 
 	order.TotalAmount = totalAmount
 	if err := tx.Save(&order).Error; err != nil {
-		tx.Rollback()
 		return nil, errors.New("failed to update order total")
 	}
+	recordWebhookEvent(tx, ownerID, models.WebhookEventOrderCreated, orderWebhookData(order))
 
-	if err := tx.Commit().Error; err != nil {
-		tx.Rollback()
-		return nil, errors.New("failed to commit order transaction")
-	}
-
-	// Reload the order with all its relations for the comprehensive response using the main DB connection
-	if err := s.DB.Preload("User").Preload("Outlet").Preload("OrderPayments.PaymentMethod").Preload("OrderItems.Product").Preload("OrderItems.ProductVariant").Preload("OrderItems.AddOns.AddOn").First(&order, order.ID).Error; err != nil {
-		log.Printf("Error preloading order relations after commit: %v", err)
-		return nil, errors.New("failed to retrieve full order details after commit")
-	}
-
-	return mapOrderToOrderResponse(order, outlet), nil
+	return &order, nil
 }
 
 // GetOrder retrieves an order by its Uuid.
@@ -210,7 +233,7 @@ func (s *OrderService) UpdateOrderItem(orderUuid uuid.UUID, req dtos.UpdateOrder
 		return nil, err
 	}
 
-	tx := s.DB.Begin()
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -236,22 +259,32 @@ func (s *OrderService) UpdateOrderItem(orderUuid uuid.UUID, req dtos.UpdateOrder
 			return nil, errors.New("cannot update a paid order item")
 		}
 	}
+	if order.Status == "cancelled" {
+		tx.Rollback()
+		return nil, errors.New("order is cancelled")
+	}
+
+	if err := s.lockOrderStocks(tx, order.OutletID, ownerID, []uuid.UUID{req.ProductUuid}, []uuid.UUID{req.ProductVariantUuid}, []models.OrderItem{orderItem}); err != nil {
+		tx.Rollback()
+		log.Printf("Error locking stocks for order item: %v", err)
+		return nil, errors.New("failed to update order item")
+	}
 
 	// Return stock for old item and add-ons
 	if orderItem.ProductID != nil {
-		if err := s.StockService.AddStockFromSale(tx, order.OutletID, orderItem.ProductID, nil, orderItem.Quantity, ownerID); err != nil {
+		if err := s.returnOrderStock(tx, order, orderItem.ProductID, nil, orderItem.Quantity, ownerID); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
 	} else if orderItem.ProductVariantID != nil {
-		if err := s.StockService.AddStockFromSale(tx, order.OutletID, nil, orderItem.ProductVariantID, orderItem.Quantity, ownerID); err != nil {
+		if err := s.returnOrderStock(tx, order, nil, orderItem.ProductVariantID, orderItem.Quantity, ownerID); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
 	}
 
 	for _, addOn := range orderItem.AddOns {
-		if err := s.StockService.AddStockFromSale(tx, order.OutletID, &addOn.AddOnID, nil, addOn.Quantity, ownerID); err != nil {
+		if err := s.returnOrderStock(tx, order, &addOn.AddOnID, nil, addOn.Quantity, ownerID); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
@@ -291,15 +324,23 @@ func (s *OrderService) UpdateOrderItem(orderUuid uuid.UUID, req dtos.UpdateOrder
 		return nil, errors.New("product_uuid or product_variant_uuid is required for each item")
 	}
 
-	if err := s.StockService.DeductStockForSale(tx, order.OutletID, productID, variantID, float64(req.Quantity), ownerID); err != nil {
+	if err := s.takeOrderStock(tx, order, productID, variantID, float64(req.Quantity), ownerID); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
+	costPrice, err := productUnitCost(tx, productID, variantID)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("Error getting product cost: %v", err)
+		return nil, errors.New("failed to update order item")
+	}
+
 	orderItem.ProductID = productID
 	orderItem.ProductVariantID = variantID
 	orderItem.Quantity = float64(req.Quantity)
 	orderItem.Price = price
+	orderItem.CostPrice = costPrice
 	orderItem.ProductName = productName
 
 	if err := tx.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Save(&orderItem).Error; err != nil {
@@ -355,7 +396,7 @@ func (s *OrderService) DeleteOrderItem(orderUuid uuid.UUID, req dtos.DeleteOrder
 		return nil, err
 	}
 
-	tx := s.DB.Begin()
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -387,22 +428,32 @@ func (s *OrderService) DeleteOrderItem(orderUuid uuid.UUID, req dtos.DeleteOrder
 			return nil, errors.New("cannot delete a paid order item")
 		}
 	}
+	if order.Status == "cancelled" {
+		tx.Rollback()
+		return nil, errors.New("order is cancelled")
+	}
+
+	if err := s.lockOrderStocks(tx, order.OutletID, ownerID, nil, nil, []models.OrderItem{orderItem}); err != nil {
+		tx.Rollback()
+		log.Printf("Error locking stocks for order item: %v", err)
+		return nil, errors.New("failed to delete order item")
+	}
 
 	// Return stock for the deleted item and its add-ons
 	if orderItem.ProductID != nil {
-		if err := s.StockService.AddStockFromSale(tx, order.OutletID, orderItem.ProductID, nil, orderItem.Quantity, ownerID); err != nil {
+		if err := s.returnOrderStock(tx, order, orderItem.ProductID, nil, orderItem.Quantity, ownerID); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
 	} else if orderItem.ProductVariantID != nil {
-		if err := s.StockService.AddStockFromSale(tx, order.OutletID, nil, orderItem.ProductVariantID, orderItem.Quantity, ownerID); err != nil {
+		if err := s.returnOrderStock(tx, order, nil, orderItem.ProductVariantID, orderItem.Quantity, ownerID); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
 	}
 
 	for _, addOn := range orderItem.AddOns {
-		if err := s.StockService.AddStockFromSale(tx, order.OutletID, &addOn.AddOnID, nil, addOn.Quantity, ownerID); err != nil {
+		if err := s.returnOrderStock(tx, order, &addOn.AddOnID, nil, addOn.Quantity, ownerID); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
@@ -502,7 +553,7 @@ func (s *OrderService) CreateOrderItem(orderUuid uuid.UUID, req dtos.CreateOrder
 		return nil, err
 	}
 
-	tx := s.DB.Begin()
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -520,6 +571,10 @@ func (s *OrderService) CreateOrderItem(orderUuid uuid.UUID, req dtos.CreateOrder
 		tx.Rollback()
 		return nil, errors.New("cannot add item to a paid order")
 	}
+	if order.Status == "cancelled" {
+		tx.Rollback()
+		return nil, errors.New("order is cancelled")
+	}
 
 	var product *models.Product
 	var variant *models.ProductVariant
@@ -549,17 +604,25 @@ func (s *OrderService) CreateOrderItem(orderUuid uuid.UUID, req dtos.CreateOrder
 		return nil, errors.New("product_uuid or product_variant_uuid is required for each item")
 	}
 
-	if err := s.StockService.DeductStockForSale(tx, order.OutletID, productID, variantID, float64(req.Quantity), ownerID); err != nil {
+	if err := s.takeOrderStock(tx, order, productID, variantID, float64(req.Quantity), ownerID); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
+	costPrice, err := productUnitCost(tx, productID, variantID)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("Error getting product cost: %v", err)
+		return nil, errors.New("failed to create order item")
+	}
+
 	orderItem := models.OrderItem{
 		OrderID:          order.ID,
 		ProductID:        productID,
 		ProductVariantID: variantID,
 		Quantity:         float64(req.Quantity),
 		Price:            price,
+		CostPrice:        costPrice,
 		ProductName:      productName,
 	}
 
@@ -610,6 +673,94 @@ func (s *OrderService) CreateOrderItem(orderUuid uuid.UUID, req dtos.CreateOrder
 	return mapOrderToOrderResponse(order, order.Outlet), nil
 }
 
+// CancelOrder cancels a pending order nothing has been paid on and gives back its stock: reserved
+// stock is released, deducted stock is returned.
+func (s *OrderService) CancelOrder(orderUuid uuid.UUID, userID uint) (*dtos.OrderResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ? AND user_id = ?", orderUuid, ownerID).First(&order).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("order not found")
+	}
+	if order.Status != "pending" {
+		tx.Rollback()
+		return nil, errors.New("only pending orders can be cancelled")
+	}
+
+	var payments int64
+	if err := tx.Model(&models.OrderPayment{}).
+		Where("order_id = ? AND status IN ?", order.ID, []string{models.OrderPaymentStatusPending, models.OrderPaymentStatusPaid}).
+		Count(&payments).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error checking payments of order %d: %v", order.ID, err)
+		return nil, errors.New("failed to cancel order")
+	}
+	if payments > 0 || order.PaidAmount > 0 {
+		tx.Rollback()
+		return nil, errors.New("order has payments and cannot be cancelled")
+	}
+
+	if order.StockReserved {
+		if err := releaseStockReservations(tx, order.ID); err != nil {
+			tx.Rollback()
+			log.Printf("Error releasing stock reservations of order %d: %v", order.ID, err)
+			return nil, errors.New("failed to cancel order")
+		}
+	} else {
+		var items []models.OrderItem
+		if err := tx.Where("order_id = ?", order.ID).Find(&items).Error; err != nil {
+			tx.Rollback()
+			log.Printf("Error getting items of order %d: %v", order.ID, err)
+			return nil, errors.New("failed to cancel order")
+		}
+		var productIDs, variantIDs []uint
+		for _, item := range items {
+			if item.ProductVariantID != nil {
+				variantIDs = append(variantIDs, *item.ProductVariantID)
+			} else if item.ProductID != nil {
+				productIDs = append(productIDs, *item.ProductID)
+			}
+		}
+		if err := lockStocks(tx, order.OutletID, productIDs, variantIDs); err != nil {
+			tx.Rollback()
+			log.Printf("Error locking stocks for order %d: %v", order.ID, err)
+			return nil, errors.New("failed to cancel order")
+		}
+		for _, item := range items {
+			if item.ProductVariantID != nil {
+				err = s.returnOrderStock(tx, order, nil, item.ProductVariantID, item.Quantity, ownerID)
+			} else {
+				err = s.returnOrderStock(tx, order, item.ProductID, nil, item.Quantity, ownerID)
+			}
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Model(&order).Update("status", "cancelled").Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to cancel order")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return s.GetOrderByUuid(order.Uuid, userID)
+}
+
 func (s *OrderService) recalculateOrderTotal(tx *gorm.DB, order *models.Order, ownerID uint) error {
 	var orderItems []models.OrderItem
 	if err := tx.Preload("AddOns").Where("order_id = ?", order.ID).Find(&orderItems).Error; err != nil {
@@ -631,6 +782,46 @@ func (s *OrderService) recalculateOrderTotal(tx *gorm.DB, order *models.Order, o
 	return nil
 }
 
+// takeOrderStock deducts the stock of an order item, or reserves it when the order reserves stock.
+func (s *OrderService) takeOrderStock(tx *gorm.DB, order models.Order, productID *uint, variantID *uint, quantity float64, ownerID uint) error {
+	if order.StockReserved {
+		return s.StockService.ReserveStockForSale(tx, order.OutletID, productID, variantID, quantity, ownerID, order.ID)
+	}
+	return s.StockService.DeductStockForSale(tx, order.OutletID, productID, variantID, quantity, ownerID, models.StockReferenceOrder, &order.ID)
+}
+
+// returnOrderStock gives back the stock taken by takeOrderStock.
+func (s *OrderService) returnOrderStock(tx *gorm.DB, order models.Order, productID *uint, variantID *uint, quantity float64, ownerID uint) error {
+	if order.StockReserved {
+		return s.StockService.ReleaseStockForSale(tx, order.OutletID, productID, variantID, quantity, ownerID, order.ID)
+	}
+	return s.StockService.AddStockFromSale(tx, order.OutletID, productID, variantID, quantity, ownerID, models.StockReferenceOrder, &order.ID)
+}
+
+// lockOrderStocks locks all stock an order change touches before any of it changes: the products
+// and variants named by uuid, and those of the given order items and their add-ons.
+func (s *OrderService) lockOrderStocks(tx *gorm.DB, outletID uint, ownerID uint, productUuids []uuid.UUID, variantUuids []uuid.UUID, items []models.OrderItem) error {
+	var productIDs, variantIDs []uint
+	if err := tx.Model(&models.Product{}).Where("uuid IN ? AND user_id = ?", productUuids, ownerID).Pluck("id", &productIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.ProductVariant{}).Where("uuid IN ? AND user_id = ?", variantUuids, ownerID).Pluck("id", &variantIDs).Error; err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.ProductID != nil {
+			productIDs = append(productIDs, *item.ProductID)
+		}
+		if item.ProductVariantID != nil {
+			variantIDs = append(variantIDs, *item.ProductVariantID)
+		}
+		for _, addOn := range item.AddOns {
+			productIDs = append(productIDs, addOn.AddOnID)
+		}
+	}
+	return lockStocks(tx, outletID, productIDs, variantIDs)
+}
+
 func mapOrderToSimpleOrderResponse(order models.Order) *dtos.SimpleOrderResponse {
 	return &dtos.SimpleOrderResponse{
 		Uuid:        order.Uuid,
@@ -717,6 +908,7 @@ func mapOrderToOrderResponse(order models.Order, outlet models.Outlet) *dtos.Ord
 				PaymentChannel:  payment.PaymentMethod.PaymentChannel,
 				ChangeAmount:    payment.ChangeAmount,
 				IsPaid:          payment.IsPaid,
+				Status:          payment.Status,
 				ReferenceID:     payment.ReferenceID,
 				CreatedAt:       payment.CreatedAt.Format(time.RFC3339),
 				PaidAt:          payment.PaidAt,
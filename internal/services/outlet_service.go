@@ -48,11 +48,12 @@ func (s *OutletService) GetOutletByUuid(Uuid uuid.UUID, userID uint) (*dtos.Outl
 		return nil, errors.New("failed to retrieve outlet")
 	}
 	return &dtos.OutletResponse{
-		ID:      outlet.ID,
-		Uuid:    outlet.Uuid,
-		Name:    outlet.Name,
-		Address: outlet.Address,
-		Type:    outlet.Type,
+		ID:           outlet.ID,
+		Uuid:         outlet.Uuid,
+		Name:         outlet.Name,
+		Address:      outlet.Address,
+		Type:         outlet.Type,
+		ReserveStock: outlet.ReserveStock,
 	}, nil
 }
 
@@ -62,21 +63,23 @@ func (s *OutletService) CreateOutlet(req *dtos.OutletCreateRequest, userID uint)
 		return nil, err
 	}
 	outlet := &models.Outlet{
-		Name:    req.Name,
-		Address: req.Address,
-		Type:    req.Type,
-		UserID:  ownerID,
+		Name:         req.Name,
+		Address:      req.Address,
+		Type:         req.Type,
+		ReserveStock: req.ReserveStock,
+		UserID:       ownerID,
 	}
 	if err := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Create(outlet).Error; err != nil {
 		log.Printf("Error creating outlet: %v", err)
 		return nil, errors.New("failed to create outlet")
 	}
 	return &dtos.OutletResponse{
-		ID:      outlet.ID,
-		Uuid:    outlet.Uuid,
-		Name:    outlet.Name,
-		Address: outlet.Address,
-		Type:    outlet.Type,
+		ID:           outlet.ID,
+		Uuid:         outlet.Uuid,
+		Name:         outlet.Name,
+		Address:      outlet.Address,
+		Type:         outlet.Type,
+		ReserveStock: outlet.ReserveStock,
 	}, nil
 }
 
@@ -98,17 +101,21 @@ func (s *OutletService) UpdateOutlet(Uuid uuid.UUID, req *dtos.OutletUpdateReque
 	outlet.Name = req.Name
 	outlet.Address = req.Address
 	outlet.Type = req.Type
+	if req.ReserveStock != nil {
+		outlet.ReserveStock = *req.ReserveStock
+	}
 
 	if err := s.DB.Save(&outlet).Error; err != nil {
 		log.Printf("Error updating outlet: %v", err)
 		return nil, errors.New("failed to update outlet")
 	}
 	return &dtos.OutletResponse{
-		ID:      outlet.ID,
-		Uuid:    outlet.Uuid,
-		Name:    outlet.Name,
-		Address: outlet.Address,
-		Type:    outlet.Type,
+		ID:           outlet.ID,
+		Uuid:         outlet.Uuid,
+		Name:         outlet.Name,
+		Address:      outlet.Address,
+		Type:         outlet.Type,
+		ReserveStock: outlet.ReserveStock,
 	}, nil
 }
 
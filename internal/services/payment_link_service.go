@@ -0,0 +1,363 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Payment link statuses, derived from the link's order payment
+const (
+	PaymentLinkStatusActive    = "active"
+	PaymentLinkStatusPaid      = "paid"
+	PaymentLinkStatusExpired   = "expired"
+	PaymentLinkStatusCancelled = "cancelled"
+)
+
+type PaymentLinkService struct {
+	DB                  *gorm.DB
+	UserContextService  *UserContextService
+	OrderPaymentService *OrderPaymentService
+}
+
+func NewPaymentLinkService(db *gorm.DB, userContextService *UserContextService, orderPaymentService *OrderPaymentService) *PaymentLinkService {
+	return &PaymentLinkService{DB: db, UserContextService: userContextService, OrderPaymentService: orderPaymentService}
+}
+
+// paymentLinkTTL is how long a payment link stays valid when the cashier does not choose, from
+// PAYMENT_LINK_TTL_HOURS (default 24).
+func paymentLinkTTL() time.Duration {
+	hours, err := strconv.Atoi(os.Getenv("PAYMENT_LINK_TTL_HOURS"))
+	if err != nil || hours <= 0 {
+		hours = 24
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// paymentLinkURL is the public address of a link. It redirects the customer to iPaymu's payment page.
+func paymentLinkURL(token string) string {
+	return strings.TrimRight(os.Getenv("PAYMENT_LINK_BASE_URL"), "/") + "/pay/" + token
+}
+
+// CreatePaymentLink opens an iPaymu hosted payment for everything still unpaid on an order and
+// returns a shareable link to it. The payment is credited by the regular iPaymu notification.
+func (s *PaymentLinkService) CreatePaymentLink(orderUuid uuid.UUID, req *dtos.CreatePaymentLinkRequest, userID uint) (*dtos.PaymentLinkResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var order models.Order
+	if err := s.DB.Preload("Outlet").Where("uuid = ? AND user_id = ?", orderUuid, ownerID).First(&order).Error; err != nil {
+		return nil, errors.New("order not found")
+	}
+	if order.Status == "completed" {
+		return nil, errors.New("order is already completed")
+	}
+	if order.Status == "cancelled" {
+		return nil, errors.New("order is cancelled")
+	}
+
+	paymentMethod, err := s.findLinkPaymentMethod(req.PaymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+
+	orderItemIDs, err := outstandingOrderItemIDs(s.DB, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(orderItemIDs) == 0 {
+		return nil, errors.New("order has no outstanding balance")
+	}
+
+	ttl := paymentLinkTTL()
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+
+	payment, err := s.OrderPaymentService.createOrderPayment(dtos.CreateOrderPaymentRequest{
+		OrderUuid:       order.Uuid,
+		PaymentMethodID: paymentMethod.ID,
+		OrderItemIDs:    orderItemIDs,
+		CustomerName:    req.CustomerName,
+		CustomerEmail:   req.CustomerEmail,
+		CustomerPhone:   req.CustomerPhone,
+	}, userID, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.saveLink(order, payment, ownerID, userID)
+	if err != nil {
+		// Do not leave a payment behind that nobody can reach
+		if _, cancelErr := s.OrderPaymentService.CancelOrderPayment(payment.Uuid, userID); cancelErr != nil {
+			log.Printf("Error cancelling order payment %s of a failed payment link: %v", payment.Uuid, cancelErr)
+		}
+		return nil, err
+	}
+
+	if req.SendEmail {
+		if err := s.sendLink(link, req.CustomerEmail); err != nil {
+			log.Printf("Error emailing payment link %s: %v", link.Uuid, err)
+		}
+	}
+	return mapPaymentLinkToResponse(*link), nil
+}
+
+func (s *PaymentLinkService) saveLink(order models.Order, payment *dtos.OrderPaymentResponse, ownerID uint, userID uint) (*models.PaymentLink, error) {
+	var orderPayment models.OrderPayment
+	if err := s.DB.Preload("PaymentMethod").Where("uuid = ?", payment.Uuid).First(&orderPayment).Error; err != nil {
+		return nil, errors.New("order payment not found")
+	}
+	if orderPayment.ExpiresAt == nil {
+		return nil, errors.New("failed to create payment link")
+	}
+
+	token, err := utils.GenerateSecureToken(24)
+	if err != nil {
+		return nil, errors.New("failed to create payment link")
+	}
+
+	link := models.PaymentLink{
+		Token:          token,
+		OrderID:        order.ID,
+		OrderPaymentID: orderPayment.ID,
+		Amount:         orderPayment.AmountPaid,
+		ExpiresAt:      *orderPayment.ExpiresAt,
+		UserID:         ownerID,
+	}
+	if err := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Create(&link).Error; err != nil {
+		log.Printf("Error creating payment link: %v", err)
+		return nil, errors.New("failed to create payment link")
+	}
+	link.Order = order
+	link.OrderPayment = orderPayment
+	return &link, nil
+}
+
+// findLinkPaymentMethod returns the requested payment method, or the first active one, as long as
+// it sends the customer to iPaymu's payment page.
+func (s *PaymentLinkService) findLinkPaymentMethod(paymentMethodID uint) (*models.PaymentMethod, error) {
+	query := s.DB.Where("issuer = ? AND payment_method = ? AND is_active = ?", "iPaymu", ipaymuHostedMethod, true)
+	if paymentMethodID != 0 {
+		query = query.Where("id = ?", paymentMethodID)
+	}
+
+	var paymentMethod models.PaymentMethod
+	if err := query.Order("id").First(&paymentMethod).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("payment method does not support payment links")
+		}
+		return nil, err
+	}
+	return &paymentMethod, nil
+}
+
+// outstandingOrderItemIDs lists the items of an order not yet paid or awaiting payment.
+func outstandingOrderItemIDs(tx *gorm.DB, orderID uint) ([]uint, error) {
+	heldQuantity := tx.Model(&models.OrderPaymentItem{}).
+		Select("COALESCE(SUM(order_payment_items.quantity_paid), 0)").
+		Joins("JOIN order_payments ON order_payments.id = order_payment_items.order_payment_id").
+		Where("order_payment_items.order_item_id = order_items.id AND order_payments.status IN ?", []string{models.OrderPaymentStatusPending, models.OrderPaymentStatusPaid})
+
+	var ids []uint
+	if err := tx.Model(&models.OrderItem{}).
+		Where("order_items.order_id = ? AND order_items.quantity > (?)", orderID, heldQuantity).
+		Order("order_items.id").
+		Pluck("order_items.id", &ids).Error; err != nil {
+		return nil, errors.New("failed to check outstanding order items")
+	}
+	return ids, nil
+}
+
+func (s *PaymentLinkService) GetPaymentLinks(orderUuid uuid.UUID, userID uint) ([]dtos.PaymentLinkResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var order models.Order
+	if err := s.DB.Where("uuid = ? AND user_id = ?", orderUuid, ownerID).First(&order).Error; err != nil {
+		return nil, errors.New("order not found")
+	}
+
+	var links []models.PaymentLink
+	if err := s.DB.Preload("OrderPayment").Where("order_id = ?", order.ID).Order("created_at desc").Find(&links).Error; err != nil {
+		return nil, errors.New("failed to retrieve payment links")
+	}
+
+	responses := make([]dtos.PaymentLinkResponse, 0, len(links))
+	for _, link := range links {
+		link.Order = order
+		responses = append(responses, *mapPaymentLinkToResponse(link))
+	}
+	return responses, nil
+}
+
+// SendPaymentLink emails an active link to the given address, or to the customer of its payment.
+func (s *PaymentLinkService) SendPaymentLink(linkUuid uuid.UUID, req *dtos.SendPaymentLinkRequest, userID uint) (*dtos.PaymentLinkResponse, error) {
+	link, err := s.findOwnedLink(linkUuid, userID)
+	if err != nil {
+		return nil, err
+	}
+	if paymentLinkStatus(*link) != PaymentLinkStatusActive {
+		return nil, errors.New("payment link is no longer active")
+	}
+
+	to := req.Email
+	if to == "" {
+		to = link.OrderPayment.CustomerEmail
+	}
+	if to == "" {
+		return nil, errors.New("recipient email is required")
+	}
+
+	if err := s.sendLink(link, to); err != nil {
+		return nil, err
+	}
+	return mapPaymentLinkToResponse(*link), nil
+}
+
+func (s *PaymentLinkService) sendLink(link *models.PaymentLink, to string) error {
+	if err := SendPaymentLinkEmail(to, PaymentLinkEmail{
+		CustomerName: link.OrderPayment.CustomerName,
+		OutletName:   link.Order.Outlet.Name,
+		Amount:       formatRupiah(link.Amount),
+		URL:          paymentLinkURL(link.Token),
+		ExpiresAt:    link.ExpiresAt.Format("02 Jan 2006 15:04"),
+	}); err != nil {
+		if err.Error() == "email rate limited" {
+			return err
+		}
+		return errors.New("failed to send payment link email")
+	}
+
+	now := time.Now()
+	link.SentTo = to
+	link.SentAt = &now
+	if err := s.DB.Model(link).Updates(map[string]interface{}{"sent_to": to, "sent_at": now}).Error; err != nil {
+		log.Printf("Error recording payment link %s as sent: %v", link.Uuid, err)
+	}
+	return nil
+}
+
+// CancelPaymentLink cancels the pending payment behind a link, which frees its items and
+// deactivates the link.
+func (s *PaymentLinkService) CancelPaymentLink(linkUuid uuid.UUID, userID uint) (*dtos.PaymentLinkResponse, error) {
+	link, err := s.findOwnedLink(linkUuid, userID)
+	if err != nil {
+		return nil, err
+	}
+	if link.OrderPayment.Status != models.OrderPaymentStatusPending {
+		return nil, errors.New("payment link is no longer active")
+	}
+
+	if _, err := s.OrderPaymentService.CancelOrderPayment(link.OrderPayment.Uuid, userID); err != nil {
+		return nil, err
+	}
+	if err := s.DB.First(&link.OrderPayment, link.OrderPaymentID).Error; err != nil {
+		return nil, errors.New("order payment not found")
+	}
+	return mapPaymentLinkToResponse(*link), nil
+}
+
+// ResolvePaymentLink returns the iPaymu payment page of an active link. It is used by the public
+// /pay/:token redirect.
+func (s *PaymentLinkService) ResolvePaymentLink(token string) (string, error) {
+	var link models.PaymentLink
+	if err := s.DB.Preload("OrderPayment").Where("token = ?", token).First(&link).Error; err != nil {
+		return "", errors.New("payment link not found")
+	}
+
+	switch paymentLinkStatus(link) {
+	case PaymentLinkStatusPaid:
+		return "", errors.New("payment link has already been paid")
+	case PaymentLinkStatusExpired:
+		return "", errors.New("payment link has expired")
+	case PaymentLinkStatusCancelled:
+		return "", errors.New("payment link is no longer active")
+	}
+
+	var extra struct {
+		PaymentURL string `json:"payment_url"`
+	}
+	if err := json.Unmarshal([]byte(link.OrderPayment.Extra), &extra); err != nil || extra.PaymentURL == "" {
+		return "", errors.New("payment link not found")
+	}
+	return extra.PaymentURL, nil
+}
+
+func (s *PaymentLinkService) findOwnedLink(linkUuid uuid.UUID, userID uint) (*models.PaymentLink, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var link models.PaymentLink
+	if err := s.DB.Preload("Order.Outlet").Preload("OrderPayment").
+		Where("uuid = ? AND user_id = ?", linkUuid, ownerID).
+		First(&link).Error; err != nil {
+		return nil, errors.New("payment link not found")
+	}
+	return &link, nil
+}
+
+// paymentLinkStatus derives a link's status from its order payment. A pending payment past the
+// link's deadline counts as expired before the reconciliation worker gets to it.
+func paymentLinkStatus(link models.PaymentLink) string {
+	switch link.OrderPayment.Status {
+	case models.OrderPaymentStatusPending:
+		if time.Now().After(link.ExpiresAt) {
+			return PaymentLinkStatusExpired
+		}
+		return PaymentLinkStatusActive
+	case models.OrderPaymentStatusPaid, models.OrderPaymentStatusRefunded:
+		return PaymentLinkStatusPaid
+	case models.OrderPaymentStatusExpired:
+		return PaymentLinkStatusExpired
+	default:
+		return PaymentLinkStatusCancelled
+	}
+}
+
+// formatRupiah writes a whole rupiah amount with dots between thousands, e.g. 150.000.
+func formatRupiah(amount float64) string {
+	digits := strconv.FormatInt(int64(math.Round(math.Abs(amount))), 10)
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
+
+func mapPaymentLinkToResponse(link models.PaymentLink) *dtos.PaymentLinkResponse {
+	return &dtos.PaymentLinkResponse{
+		Uuid:             link.Uuid,
+		OrderUuid:        link.Order.Uuid,
+		OrderPaymentUuid: link.OrderPayment.Uuid,
+		URL:              paymentLinkURL(link.Token),
+		Amount:           link.Amount,
+		Status:           paymentLinkStatus(link),
+		ExpiresAt:        link.ExpiresAt,
+		SentTo:           link.SentTo,
+		SentAt:           link.SentAt,
+		CreatedAt:        link.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"gorm.io/gorm"
+)
+
+type PaymentMethodService struct {
+	DB                 *gorm.DB
+	UserContextService *UserContextService
+	PaymentProviders   *PaymentProviderRegistry
+}
+
+func NewPaymentMethodService(db *gorm.DB, userContextService *UserContextService, paymentProviders *PaymentProviderRegistry) *PaymentMethodService {
+	return &PaymentMethodService{DB: db, UserContextService: userContextService, PaymentProviders: paymentProviders}
+}
+
+// GetAllPaymentMethods lists every payment method, inactive ones included.
+func (s *PaymentMethodService) GetAllPaymentMethods() ([]dtos.PaymentMethodResponse, error) {
+	var paymentMethods []models.PaymentMethod
+	if err := s.DB.Order("id").Find(&paymentMethods).Error; err != nil {
+		return nil, errors.New("failed to retrieve payment methods")
+	}
+
+	responses := make([]dtos.PaymentMethodResponse, 0, len(paymentMethods))
+	for _, paymentMethod := range paymentMethods {
+		responses = append(responses, *mapPaymentMethodToResponse(paymentMethod))
+	}
+	return responses, nil
+}
+
+// CreatePaymentMethod adds a payment method. Its issuer must be one of the registered payment
+// providers, so custom methods such as "EDC Mandiri" reuse the provider that settles them.
+func (s *PaymentMethodService) CreatePaymentMethod(req *dtos.PaymentMethodRequest) (*dtos.PaymentMethodResponse, error) {
+	if _, err := s.PaymentProviders.Get(req.Issuer); err != nil {
+		return nil, errors.New("unknown payment issuer")
+	}
+
+	paymentMethod := models.PaymentMethod{
+		Name:           req.Name,
+		Type:           req.Type,
+		PaymentMethod:  req.PaymentMethod,
+		PaymentChannel: req.PaymentChannel,
+		Issuer:         req.Issuer,
+		IsActive:       true,
+	}
+	if req.IsActive != nil {
+		paymentMethod.IsActive = *req.IsActive
+	}
+
+	if err := s.DB.Create(&paymentMethod).Error; err != nil {
+		log.Printf("Error creating payment method: %v", err)
+		return nil, errors.New("failed to create payment method")
+	}
+	// is_active has a column default, so GORM skips a false value on insert
+	if !paymentMethod.IsActive {
+		if err := s.DB.Model(&paymentMethod).Update("is_active", false).Error; err != nil {
+			return nil, errors.New("failed to create payment method")
+		}
+	}
+	return mapPaymentMethodToResponse(paymentMethod), nil
+}
+
+func (s *PaymentMethodService) UpdatePaymentMethod(id uint, req *dtos.PaymentMethodRequest) (*dtos.PaymentMethodResponse, error) {
+	var paymentMethod models.PaymentMethod
+	if err := s.DB.First(&paymentMethod, id).Error; err != nil {
+		return nil, errors.New("payment method not found")
+	}
+	if _, err := s.PaymentProviders.Get(req.Issuer); err != nil {
+		return nil, errors.New("unknown payment issuer")
+	}
+
+	paymentMethod.Name = req.Name
+	paymentMethod.Type = req.Type
+	paymentMethod.PaymentMethod = req.PaymentMethod
+	paymentMethod.PaymentChannel = req.PaymentChannel
+	paymentMethod.Issuer = req.Issuer
+	if req.IsActive != nil {
+		paymentMethod.IsActive = *req.IsActive
+	}
+	if err := s.DB.Save(&paymentMethod).Error; err != nil {
+		return nil, errors.New("failed to update payment method")
+	}
+	return mapPaymentMethodToResponse(paymentMethod), nil
+}
+
+// DeactivatePaymentMethod hides a payment method from new payments. It is not deleted because past
+// payments refer to it.
+func (s *PaymentMethodService) DeactivatePaymentMethod(id uint) error {
+	result := s.DB.Model(&models.PaymentMethod{}).Where("id = ?", id).Update("is_active", false)
+	if result.Error != nil {
+		return errors.New("failed to deactivate payment method")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("payment method not found")
+	}
+	return nil
+}
+
+// GetOutletPaymentMethods lists the active payment methods enabled for an outlet in display order.
+func (s *PaymentMethodService) GetOutletPaymentMethods(outletUuid uuid.UUID, userID uint) ([]dtos.OutletPaymentMethodResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	var outletPaymentMethods []models.OutletPaymentMethod
+	if err := s.DB.Preload("PaymentMethod").
+		Joins("JOIN payment_methods ON payment_methods.id = outlet_payment_methods.payment_method_id").
+		Where("outlet_payment_methods.outlet_id = ? AND payment_methods.is_active = ?", outlet.ID, true).
+		Order("outlet_payment_methods.display_order, outlet_payment_methods.id").
+		Find(&outletPaymentMethods).Error; err != nil {
+		return nil, errors.New("failed to retrieve outlet payment methods")
+	}
+
+	responses := make([]dtos.OutletPaymentMethodResponse, 0, len(outletPaymentMethods))
+	for _, outletPaymentMethod := range outletPaymentMethods {
+		responses = append(responses, mapOutletPaymentMethodToResponse(outletPaymentMethod))
+	}
+	return responses, nil
+}
+
+// SetOutletPaymentMethods replaces the payment methods enabled for an outlet.
+func (s *PaymentMethodService) SetOutletPaymentMethods(outletUuid uuid.UUID, req *dtos.SetOutletPaymentMethodsRequest, userID uint) ([]dtos.OutletPaymentMethodResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Where("outlet_id = ?", outlet.ID).Delete(&models.OutletPaymentMethod{}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to update outlet payment methods")
+	}
+
+	seen := make(map[uint]bool, len(req.PaymentMethods))
+	for _, item := range req.PaymentMethods {
+		if seen[item.PaymentMethodID] {
+			tx.Rollback()
+			return nil, errors.New("duplicate payment method")
+		}
+		seen[item.PaymentMethodID] = true
+
+		var paymentMethod models.PaymentMethod
+		if err := tx.Where("id = ? AND is_active = ?", item.PaymentMethodID, true).First(&paymentMethod).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("payment method not found or not active")
+		}
+
+		outletPaymentMethod := models.OutletPaymentMethod{
+			OutletID:        outlet.ID,
+			PaymentMethodID: paymentMethod.ID,
+			DisplayOrder:    item.DisplayOrder,
+			SurchargeType:   item.SurchargeType,
+			SurchargeValue:  item.SurchargeValue,
+			UserID:          ownerID,
+		}
+		if item.SurchargeType == "" {
+			outletPaymentMethod.SurchargeValue = 0
+		}
+		if err := tx.Create(&outletPaymentMethod).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to update outlet payment methods")
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return s.GetOutletPaymentMethods(outletUuid, userID)
+}
+
+// outletPaymentSurcharge checks that a payment method may be used at an outlet and returns the
+// surcharge for amount. Outlets that never configured their payment methods accept every active
+// method without a surcharge.
+func outletPaymentSurcharge(tx *gorm.DB, outletID uint, paymentMethodID uint, amount float64) (float64, error) {
+	var outletPaymentMethods []models.OutletPaymentMethod
+	if err := tx.Where("outlet_id = ?", outletID).Find(&outletPaymentMethods).Error; err != nil {
+		return 0, errors.New("failed to load outlet payment methods")
+	}
+	if len(outletPaymentMethods) == 0 {
+		return 0, nil
+	}
+
+	for _, outletPaymentMethod := range outletPaymentMethods {
+		if outletPaymentMethod.PaymentMethodID != paymentMethodID {
+			continue
+		}
+		switch outletPaymentMethod.SurchargeType {
+		case models.SurchargeTypeFixed:
+			return outletPaymentMethod.SurchargeValue, nil
+		case models.SurchargeTypePercentage:
+			return math.Round(amount*outletPaymentMethod.SurchargeValue) / 100, nil
+		default:
+			return 0, nil
+		}
+	}
+	return 0, errors.New("payment method not enabled for this outlet")
+}
+
+func mapPaymentMethodToResponse(paymentMethod models.PaymentMethod) *dtos.PaymentMethodResponse {
+	return &dtos.PaymentMethodResponse{
+		ID:             paymentMethod.ID,
+		Name:           paymentMethod.Name,
+		Type:           paymentMethod.Type,
+		PaymentMethod:  paymentMethod.PaymentMethod,
+		PaymentChannel: paymentMethod.PaymentChannel,
+		Issuer:         paymentMethod.Issuer,
+		IsActive:       paymentMethod.IsActive,
+	}
+}
+
+func mapOutletPaymentMethodToResponse(outletPaymentMethod models.OutletPaymentMethod) dtos.OutletPaymentMethodResponse {
+	return dtos.OutletPaymentMethodResponse{
+		PaymentMethodID: outletPaymentMethod.PaymentMethodID,
+		Name:            outletPaymentMethod.PaymentMethod.Name,
+		Type:            outletPaymentMethod.PaymentMethod.Type,
+		PaymentMethod:   outletPaymentMethod.PaymentMethod.PaymentMethod,
+		PaymentChannel:  outletPaymentMethod.PaymentMethod.PaymentChannel,
+		Issuer:          outletPaymentMethod.PaymentMethod.Issuer,
+		DisplayOrder:    outletPaymentMethod.DisplayOrder,
+		SurchargeType:   outletPaymentMethod.SurchargeType,
+		SurchargeValue:  outletPaymentMethod.SurchargeValue,
+	}
+}
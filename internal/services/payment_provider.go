@@ -0,0 +1,171 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPaymentOperationNotSupported = errors.New("payment operation not supported by provider")
+	ErrPaymentProviderNotFound      = errors.New("payment provider not found")
+	ErrManualConfirmationNotAllowed = errors.New("payment method does not allow manual confirmation")
+)
+
+// Payment statuses reported by providers.
+const (
+	PaymentStatusPending  = "pending"
+	PaymentStatusPaid     = "paid"
+	PaymentStatusFailed   = "failed"
+	PaymentStatusExpired  = "expired"
+	PaymentStatusRefunded = "refunded"
+)
+
+// PaymentInitiateRequest carries everything a provider needs to start collecting an order payment.
+// OrderPayment has already been created inside the transaction, so its Uuid can be used as reference.
+type PaymentInitiateRequest struct {
+	UserID        uint // The user taking the payment
+	OwnerID       uint
+	Order         models.Order
+	OrderPayment  *models.OrderPayment
+	PaymentMethod models.PaymentMethod
+	Items         []models.OrderItem
+	CustomerName  string
+	CustomerEmail string
+	CustomerPhone string
+	ExpiresIn     time.Duration // Requested payment window, zero for the provider's default
+	CustomerUuid  *uuid.UUID    // Customer paying from their wallet
+}
+
+type PaymentInitiateResult struct {
+	ReferenceID string      // Transaction ID on the provider side
+	Extra       interface{} // Provider data shown to the cashier (VA number, QR string, app link, ...)
+	Paid        bool        // The payment is settled immediately (e.g. cash)
+	ExpiresAt   *time.Time  // Gateway deadline, when the gateway reports one
+}
+
+type PaymentStatusResult struct {
+	Status      string
+	Amount      float64
+	ReferenceID string
+}
+
+type PaymentRefundResult struct {
+	ReferenceID string
+	Status      string
+	Extra       interface{}
+}
+
+// PaymentCallback is an inbound notification from a provider.
+type PaymentCallback struct {
+	Payload  interface{} // The bound request DTO
+	RawBody  []byte
+	Headers  http.Header
+	SourceIP string
+}
+
+// PaymentCallbackResult tells the order payment code what a verified callback means.
+// ServiceRefID is the order payment uuid; it is empty when the callback is not about an order payment.
+// Duplicate marks a success notification for a transaction that was already credited.
+type PaymentCallbackResult struct {
+	ReferenceID  string
+	ServiceRefID string
+	Paid         bool
+	Failed       bool
+	Refunded     bool // The gateway confirmed the pending refund of the payment
+	Amount       float64
+	Duplicate    bool
+}
+
+// PaymentProvider is implemented by every payment gateway. Operations a gateway does not offer
+// return ErrPaymentOperationNotSupported.
+type PaymentProvider interface {
+	Issuer() string
+	Initiate(tx *gorm.DB, req PaymentInitiateRequest) (*PaymentInitiateResult, error)
+	QueryStatus(tx *gorm.DB, orderPayment models.OrderPayment) (*PaymentStatusResult, error)
+	Cancel(tx *gorm.DB, orderPayment models.OrderPayment) error
+	Refund(tx *gorm.DB, orderPayment models.OrderPayment, amount float64, reason string) (*PaymentRefundResult, error)
+	VerifyCallback(tx *gorm.DB, callback PaymentCallback) (*PaymentCallbackResult, error)
+}
+
+// ManualConfirmationProvider is implemented by providers whose payments are confirmed by staff
+// instead of a gateway callback. ConfirmPayment may refuse the confirmation.
+type ManualConfirmationProvider interface {
+	ConfirmPayment(tx *gorm.DB, orderPayment models.OrderPayment) error
+}
+
+// PaymentProviderRegistry resolves payment providers by the Issuer of a payment method.
+type PaymentProviderRegistry struct {
+	providers map[string]PaymentProvider
+}
+
+func NewPaymentProviderRegistry() *PaymentProviderRegistry {
+	return &PaymentProviderRegistry{providers: make(map[string]PaymentProvider)}
+}
+
+// NewDefaultPaymentProviderRegistry registers the built-in gateways. The fake provider is only
+// available when PAYMENT_FAKE_PROVIDER_ENABLED=true.
+func NewDefaultPaymentProviderRegistry(db *gorm.DB, ipaymuService *IpaymuService, tsmService *TsmService) *PaymentProviderRegistry {
+	registry := NewPaymentProviderRegistry()
+	registry.Register(NewCashPaymentProvider())
+	registry.Register(NewIpaymuPaymentProvider(ipaymuService))
+	registry.Register(NewTsmPaymentProvider(db, tsmService))
+	registry.Register(NewQrisStaticPaymentProvider(db))
+	registry.Register(NewManualTransferPaymentProvider())
+	registry.Register(NewWalletPaymentProvider())
+	if os.Getenv("PAYMENT_FAKE_PROVIDER_ENABLED") == "true" {
+		registry.Register(NewFakePaymentProvider())
+	}
+	return registry
+}
+
+func (r *PaymentProviderRegistry) Register(provider PaymentProvider) {
+	r.providers[provider.Issuer()] = provider
+}
+
+func (r *PaymentProviderRegistry) Get(issuer string) (PaymentProvider, error) {
+	provider, ok := r.providers[issuer]
+	if !ok {
+		return nil, ErrPaymentProviderNotFound
+	}
+	return provider, nil
+}
+
+// Issuers lists the registered issuers in alphabetical order.
+func (r *PaymentProviderRegistry) Issuers() []string {
+	issuers := make([]string, 0, len(r.providers))
+	for issuer := range r.providers {
+		issuers = append(issuers, issuer)
+	}
+	sort.Strings(issuers)
+	return issuers
+}
+
+// marshalExtra stores provider data as the JSON kept in OrderPayment.Extra.
+func marshalExtra(extra interface{}) string {
+	if extra == nil {
+		return "{}"
+	}
+	rawExtra, err := json.Marshal(extra)
+	if err != nil {
+		return "{}"
+	}
+	return string(rawExtra)
+}
+
+// pendingPaymentTTL is how long a pending payment stays open when the gateway gives no deadline.
+func pendingPaymentTTL() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("PAYMENT_PENDING_TTL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 1440
+	}
+	return time.Duration(minutes) * time.Minute
+}
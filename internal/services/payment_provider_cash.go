@@ -0,0 +1,42 @@
+package services
+
+import (
+	"github.com/msyaifudin/pos/internal/models"
+	"gorm.io/gorm"
+)
+
+// CashPaymentProvider settles payments at the counter. Its issuer matches the seeded "Cash" method.
+type CashPaymentProvider struct{}
+
+func NewCashPaymentProvider() *CashPaymentProvider {
+	return &CashPaymentProvider{}
+}
+
+func (p *CashPaymentProvider) Issuer() string {
+	return "default"
+}
+
+func (p *CashPaymentProvider) Initiate(tx *gorm.DB, req PaymentInitiateRequest) (*PaymentInitiateResult, error) {
+	return &PaymentInitiateResult{Paid: true}, nil
+}
+
+func (p *CashPaymentProvider) QueryStatus(tx *gorm.DB, orderPayment models.OrderPayment) (*PaymentStatusResult, error) {
+	status := PaymentStatusPending
+	if orderPayment.IsPaid {
+		status = PaymentStatusPaid
+	}
+	return &PaymentStatusResult{Status: status, Amount: orderPayment.AmountPaid}, nil
+}
+
+func (p *CashPaymentProvider) Cancel(tx *gorm.DB, orderPayment models.OrderPayment) error {
+	return nil
+}
+
+// Refund of cash is handed back at the counter, so there is nothing to call.
+func (p *CashPaymentProvider) Refund(tx *gorm.DB, orderPayment models.OrderPayment, amount float64, reason string) (*PaymentRefundResult, error) {
+	return &PaymentRefundResult{Status: PaymentStatusRefunded}, nil
+}
+
+func (p *CashPaymentProvider) VerifyCallback(tx *gorm.DB, callback PaymentCallback) (*PaymentCallbackResult, error) {
+	return nil, ErrPaymentOperationNotSupported
+}
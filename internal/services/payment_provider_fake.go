@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"gorm.io/gorm"
+)
+
+// FakePaymentProvider simulates an online gateway for development and automated testing.
+// Payments stay pending until a callback is posted to /api/payment/fake/callback.
+type FakePaymentProvider struct{}
+
+func NewFakePaymentProvider() *FakePaymentProvider {
+	return &FakePaymentProvider{}
+}
+
+func (p *FakePaymentProvider) Issuer() string {
+	return "Fake"
+}
+
+func (p *FakePaymentProvider) Initiate(tx *gorm.DB, req PaymentInitiateRequest) (*PaymentInitiateResult, error) {
+	referenceID := "FAKE-" + req.OrderPayment.Uuid.String()
+	return &PaymentInitiateResult{
+		ReferenceID: referenceID,
+		Extra: map[string]interface{}{
+			"reference_id": referenceID,
+			"callback_url": "/api/payment/fake/callback",
+		},
+	}, nil
+}
+
+func (p *FakePaymentProvider) QueryStatus(tx *gorm.DB, orderPayment models.OrderPayment) (*PaymentStatusResult, error) {
+	status := PaymentStatusPending
+	switch orderPayment.Status {
+	case models.OrderPaymentStatusPaid:
+		status = PaymentStatusPaid
+	case models.OrderPaymentStatusFailed:
+		status = PaymentStatusFailed
+	}
+	return &PaymentStatusResult{Status: status, Amount: orderPayment.AmountPaid, ReferenceID: orderPayment.ReferenceID}, nil
+}
+
+func (p *FakePaymentProvider) Cancel(tx *gorm.DB, orderPayment models.OrderPayment) error {
+	return nil
+}
+
+func (p *FakePaymentProvider) Refund(tx *gorm.DB, orderPayment models.OrderPayment, amount float64, reason string) (*PaymentRefundResult, error) {
+	return &PaymentRefundResult{ReferenceID: "FAKE-REFUND-" + orderPayment.Uuid.String(), Status: PaymentStatusRefunded}, nil
+}
+
+func (p *FakePaymentProvider) VerifyCallback(tx *gorm.DB, callback PaymentCallback) (*PaymentCallbackResult, error) {
+	req, ok := callback.Payload.(*dtos.FakePaymentCallbackRequest)
+	if !ok {
+		return nil, errors.New("invalid fake payment callback payload")
+	}
+
+	var orderPayment models.OrderPayment
+	if err := tx.Where("reference_id = ?", req.ReferenceID).First(&orderPayment).Error; err != nil {
+		return nil, errors.New("order payment not found")
+	}
+
+	return &PaymentCallbackResult{
+		ServiceRefID: orderPayment.Uuid.String(),
+		Paid:         req.Status == PaymentStatusPaid,
+		Failed:       req.Status == PaymentStatusFailed,
+		Amount:       orderPayment.AmountPaid,
+	}, nil
+}
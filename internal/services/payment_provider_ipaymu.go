@@ -0,0 +1,233 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"gorm.io/gorm"
+)
+
+// ipaymuHostedMethod is the payment method value of iPaymu payment methods that send the buyer to
+// iPaymu's payment page instead of a channel chosen at the counter.
+const ipaymuHostedMethod = "hosted"
+
+// IpaymuPaymentProvider collects payments through iPaymu direct payments (VA, QRIS, ...) or
+// iPaymu's hosted payment page.
+type IpaymuPaymentProvider struct {
+	IpaymuService *IpaymuService
+}
+
+func NewIpaymuPaymentProvider(ipaymuService *IpaymuService) *IpaymuPaymentProvider {
+	return &IpaymuPaymentProvider{IpaymuService: ipaymuService}
+}
+
+func (p *IpaymuPaymentProvider) Issuer() string {
+	return "iPaymu"
+}
+
+func (p *IpaymuPaymentProvider) Initiate(tx *gorm.DB, req PaymentInitiateRequest) (*PaymentInitiateResult, error) {
+	if req.CustomerName == "" || req.CustomerEmail == "" || req.CustomerPhone == "" {
+		return nil, errors.New("customer details are required for iPaymu payments")
+	}
+
+	var products []string
+	var qtys []int
+	var prices []int
+	for _, item := range req.Items {
+		products = append(products, item.ProductName)
+		qtys = append(qtys, int(item.Quantity))
+		prices = append(prices, int(item.Price))
+	}
+	if req.OrderPayment.SurchargeAmount > 0 {
+		products = append(products, "Payment surcharge")
+		qtys = append(qtys, 1)
+		prices = append(prices, int(math.Round(req.OrderPayment.SurchargeAmount)))
+	}
+
+	if req.PaymentMethod.PaymentMethod == ipaymuHostedMethod {
+		return p.initiateHosted(req, products, qtys, prices)
+	}
+
+	ipaymuRes, err := p.IpaymuService.CreateDirectPayment(
+		req.UserID, "Order Payment", req.OrderPayment.Uuid.String(),
+		products, qtys, prices,
+		req.CustomerName, req.CustomerEmail, req.CustomerPhone,
+		req.PaymentMethod.PaymentMethod, req.PaymentMethod.PaymentChannel, nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("iPaymu direct payment failed: %w", err)
+	}
+
+	result := &PaymentInitiateResult{}
+	if data, ok := ipaymuRes["Data"].(map[string]interface{}); ok {
+		if trxId, ok := data["TransactionId"]; ok {
+			result.ReferenceID = formatIpaymuID(trxId)
+		}
+		if expired, ok := data["Expired"].(string); ok {
+			result.ExpiresAt = parseIpaymuTime(expired)
+		}
+		result.Extra = data
+	}
+	return result, nil
+}
+
+// initiateHosted opens a session on iPaymu's payment page. The buyer is sent to the returned Url and
+// the payment is credited by the notification sent once they pay.
+func (p *IpaymuPaymentProvider) initiateHosted(req PaymentInitiateRequest, products []string, qtys []int, prices []int) (*PaymentInitiateResult, error) {
+	expiresIn := req.ExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = 24 * time.Hour
+	}
+	expiredHours := int(math.Ceil(expiresIn.Hours()))
+
+	ipaymuRes, err := p.IpaymuService.CreateRedirectPayment(
+		req.UserID, "Order Payment", req.OrderPayment.Uuid.String(),
+		products, qtys, prices,
+		req.CustomerName, req.CustomerEmail, req.CustomerPhone,
+		expiredHours,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("iPaymu hosted payment failed: %w", err)
+	}
+
+	expiresAt := time.Now().Add(time.Duration(expiredHours) * time.Hour)
+	result := &PaymentInitiateResult{ExpiresAt: &expiresAt}
+	if data, ok := ipaymuRes["Data"].(map[string]interface{}); ok {
+		paymentURL, _ := data["Url"].(string)
+		result.Extra = map[string]interface{}{
+			"session_id":  data["SessionID"],
+			"payment_url": paymentURL,
+		}
+	}
+	return result, nil
+}
+
+// QueryStatus maps the iPaymu transaction status codes to payment statuses.
+func (p *IpaymuPaymentProvider) QueryStatus(tx *gorm.DB, orderPayment models.OrderPayment) (*PaymentStatusResult, error) {
+	transactionID := orderPayment.ReferenceID
+	if transactionID == "" {
+		// A hosted payment has no transaction until the buyer pays, and only learns it from the
+		// notification. Look it up in case the notification was lost.
+		var session models.IpaymuLog
+		if err := tx.Where("service_ref_id = ? AND session_id <> ?", orderPayment.Uuid.String(), "").
+			Order("id desc").First(&session).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("order payment has no iPaymu transaction")
+			}
+			return nil, err
+		}
+		transactionID = session.ReferenceIpaymu
+		if transactionID == "" {
+			found, err := p.IpaymuService.FindSessionTransaction(session)
+			if err != nil {
+				return nil, err
+			}
+			if found == "" {
+				// Not paid yet; it expires by its deadline
+				return &PaymentStatusResult{Status: PaymentStatusPending}, nil
+			}
+			// Later notifications and refunds find the session by its transaction
+			if err := tx.Model(&session).Update("reference_ipaymu", found).Error; err != nil {
+				return nil, err
+			}
+			transactionID = found
+		}
+	}
+
+	res, err := p.IpaymuService.CheckTransaction(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	status, amount, _, err := parseIpaymuTransaction(res)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymentStatusResult{ReferenceID: transactionID, Status: status, Amount: amount}, nil
+}
+
+func (p *IpaymuPaymentProvider) Cancel(tx *gorm.DB, orderPayment models.OrderPayment) error {
+	return ErrPaymentOperationNotSupported
+}
+
+// Refund asks iPaymu to refund the transaction. iPaymu confirms the refund later through a
+// notification, so the refund starts out pending.
+func (p *IpaymuPaymentProvider) Refund(tx *gorm.DB, orderPayment models.OrderPayment, amount float64, reason string) (*PaymentRefundResult, error) {
+	if orderPayment.ReferenceID == "" {
+		return nil, errors.New("order payment has no iPaymu transaction")
+	}
+
+	var order models.Order
+	if err := tx.Select("id", "user_id").First(&order, orderPayment.OrderID).Error; err != nil {
+		return nil, errors.New("order not found")
+	}
+
+	res, err := p.IpaymuService.RefundTransaction(order.UserID, orderPayment.Uuid.String(), orderPayment.ReferenceID, amount, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PaymentRefundResult{Status: PaymentStatusPending}
+	if data, ok := res["Data"].(map[string]interface{}); ok {
+		if refundID, ok := data["RefundId"]; ok {
+			result.ReferenceID = formatIpaymuID(refundID)
+		}
+		result.Extra = data
+	}
+	return result, nil
+}
+
+func (p *IpaymuPaymentProvider) VerifyCallback(tx *gorm.DB, callback PaymentCallback) (*PaymentCallbackResult, error) {
+	req, ok := callback.Payload.(*dtos.IpaymuNotifyRequest)
+	if !ok {
+		return nil, errors.New("invalid iPaymu notification payload")
+	}
+
+	if req.StatusCode == ipaymuStatusRefund {
+		ipaymuLog, err := p.IpaymuService.VerifyRefundNotification(tx, req, callback.SourceIP)
+		if err != nil {
+			return nil, err
+		}
+		result := &PaymentCallbackResult{ReferenceID: ipaymuLog.ReferenceIpaymu}
+		if ipaymuLog.ServiceName == "Order Payment" {
+			result.ServiceRefID = ipaymuLog.ServiceRefID
+			result.Refunded = true
+		}
+		return result, nil
+	}
+
+	ipaymuLog, credited, err := p.IpaymuService.VerifyNotification(tx, req, callback.SourceIP)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PaymentCallbackResult{
+		ReferenceID: ipaymuLog.ReferenceIpaymu,
+		Duplicate:   req.Status == "berhasil" && !credited,
+	}
+	// Only notifications for order payments credit anything
+	if ipaymuLog.ServiceName != "Order Payment" {
+		return result, nil
+	}
+
+	result.ServiceRefID = ipaymuLog.ServiceRefID
+	result.Paid = credited
+	result.Amount = ipaymuLog.Amount
+	if ipaymuLog.FeeDirection == "BUYER" {
+		// The log holds the total charged; iPaymu's fee on top of it is not part of the payment
+		result.Amount -= ipaymuLog.Fee
+	}
+	if credited && ipaymuLog.SessionID != "" {
+		// Keep the transaction on the payment so it can be checked and refunded later
+		if err := tx.Model(&models.OrderPayment{}).
+			Where("uuid = ? AND reference_id = ?", ipaymuLog.ServiceRefID, "").
+			Update("reference_id", ipaymuLog.ReferenceIpaymu).Error; err != nil {
+			return nil, fmt.Errorf("failed to record iPaymu transaction: %w", err)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/msyaifudin/pos/internal/models"
+	"gorm.io/gorm"
+)
+
+// ManualTransferIssuer is the issuer of payment methods paid by a bank transfer that staff confirm
+// against a proof of payment.
+const ManualTransferIssuer = "Manual transfer"
+
+// ManualTransferPaymentProvider keeps a payment pending until a manager reviews its proof of
+// payment through ManualTransferService. Cashiers cannot confirm it directly.
+type ManualTransferPaymentProvider struct{}
+
+func NewManualTransferPaymentProvider() *ManualTransferPaymentProvider {
+	return &ManualTransferPaymentProvider{}
+}
+
+func (p *ManualTransferPaymentProvider) Issuer() string {
+	return ManualTransferIssuer
+}
+
+func (p *ManualTransferPaymentProvider) Initiate(tx *gorm.DB, req PaymentInitiateRequest) (*PaymentInitiateResult, error) {
+	// Transfers take longer than gateway payments, so they get their own deadline
+	hours, err := strconv.Atoi(os.Getenv("MANUAL_TRANSFER_TTL_HOURS"))
+	if err != nil || hours <= 0 {
+		hours = 72
+	}
+	expiresAt := time.Now().Add(time.Duration(hours) * time.Hour)
+
+	return &PaymentInitiateResult{
+		Extra: map[string]interface{}{
+			"bank":            req.PaymentMethod.PaymentChannel,
+			"transfer_amount": req.OrderPayment.AmountPaid,
+			"proof_required":  true,
+		},
+		ExpiresAt: &expiresAt,
+	}, nil
+}
+
+func (p *ManualTransferPaymentProvider) QueryStatus(tx *gorm.DB, orderPayment models.OrderPayment) (*PaymentStatusResult, error) {
+	status := PaymentStatusPending
+	switch orderPayment.Status {
+	case models.OrderPaymentStatusPaid:
+		status = PaymentStatusPaid
+	case models.OrderPaymentStatusFailed:
+		status = PaymentStatusFailed
+	}
+	return &PaymentStatusResult{Status: status, Amount: orderPayment.AmountPaid}, nil
+}
+
+func (p *ManualTransferPaymentProvider) Cancel(tx *gorm.DB, orderPayment models.OrderPayment) error {
+	return nil
+}
+
+// Refund is transferred back to the customer by the merchant, so there is nothing to call.
+func (p *ManualTransferPaymentProvider) Refund(tx *gorm.DB, orderPayment models.OrderPayment, amount float64, reason string) (*PaymentRefundResult, error) {
+	return &PaymentRefundResult{Status: PaymentStatusRefunded}, nil
+}
+
+func (p *ManualTransferPaymentProvider) VerifyCallback(tx *gorm.DB, callback PaymentCallback) (*PaymentCallbackResult, error) {
+	return nil, ErrPaymentOperationNotSupported
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/pkg/qris"
+	"gorm.io/gorm"
+)
+
+// qrisImageSize is the width and height in pixels of the generated QR code.
+const qrisImageSize = 512
+
+// QrisStaticPaymentProvider generates a dynamic QRIS from the owner's static QRIS. The money goes
+// straight to the merchant's bank, so there is no callback: the cashier confirms the payment.
+type QrisStaticPaymentProvider struct {
+	DB *gorm.DB
+}
+
+func NewQrisStaticPaymentProvider(db *gorm.DB) *QrisStaticPaymentProvider {
+	return &QrisStaticPaymentProvider{DB: db}
+}
+
+func (p *QrisStaticPaymentProvider) Issuer() string {
+	return "QRIS static"
+}
+
+func (p *QrisStaticPaymentProvider) Initiate(tx *gorm.DB, req PaymentInitiateRequest) (*PaymentInitiateResult, error) {
+	var userQris models.UserQris
+	if err := tx.Where("user_id = ?", req.OwnerID).First(&userQris).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user qris data not found")
+		}
+		return nil, err
+	}
+
+	payload, err := qris.ToDynamic(userQris.Payload, req.OrderPayment.AmountPaid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate qris: %w", err)
+	}
+	image, err := qris.PNG(payload, qrisImageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate qris image: %w", err)
+	}
+
+	return &PaymentInitiateResult{
+		ReferenceID: "QRIS-" + req.OrderPayment.Uuid.String(),
+		Extra: map[string]interface{}{
+			"qris_payload":  payload,
+			"qris_image":    "data:image/png;base64," + base64.StdEncoding.EncodeToString(image),
+			"merchant_name": userQris.MerchantName,
+			"nmid":          userQris.NMID,
+			"confirmation":  "manual",
+		},
+	}, nil
+}
+
+// QueryStatus has nobody to ask; the payment stays pending until it is confirmed or expires.
+func (p *QrisStaticPaymentProvider) QueryStatus(tx *gorm.DB, orderPayment models.OrderPayment) (*PaymentStatusResult, error) {
+	status := PaymentStatusPending
+	if orderPayment.Status == models.OrderPaymentStatusPaid {
+		status = PaymentStatusPaid
+	}
+	return &PaymentStatusResult{Status: status, Amount: orderPayment.AmountPaid, ReferenceID: orderPayment.ReferenceID}, nil
+}
+
+func (p *QrisStaticPaymentProvider) Cancel(tx *gorm.DB, orderPayment models.OrderPayment) error {
+	return nil
+}
+
+// Refund is transferred back by the merchant from their bank account, so there is nothing to call.
+func (p *QrisStaticPaymentProvider) Refund(tx *gorm.DB, orderPayment models.OrderPayment, amount float64, reason string) (*PaymentRefundResult, error) {
+	return &PaymentRefundResult{Status: PaymentStatusRefunded}, nil
+}
+
+func (p *QrisStaticPaymentProvider) VerifyCallback(tx *gorm.DB, callback PaymentCallback) (*PaymentCallbackResult, error) {
+	return nil, ErrPaymentOperationNotSupported
+}
+
+// ConfirmPayment accepts the cashier's word that the amount arrived in the merchant's account.
+func (p *QrisStaticPaymentProvider) ConfirmPayment(tx *gorm.DB, orderPayment models.OrderPayment) error {
+	return nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"gorm.io/gorm"
+)
+
+// TsmIssuer is the issuer of payment methods collected on a TSM EDC.
+const TsmIssuer = "TSM"
+
+// TsmPaymentProvider collects card payments on a TSM EDC through an app link.
+type TsmPaymentProvider struct {
+	DB         *gorm.DB
+	TsmService *TsmService
+}
+
+func NewTsmPaymentProvider(db *gorm.DB, tsmService *TsmService) *TsmPaymentProvider {
+	return &TsmPaymentProvider{DB: db, TsmService: tsmService}
+}
+
+func (p *TsmPaymentProvider) Issuer() string {
+	return TsmIssuer
+}
+
+func (p *TsmPaymentProvider) Initiate(tx *gorm.DB, req PaymentInitiateRequest) (*PaymentInitiateResult, error) {
+	var userPayment models.UserPayment
+	if err := tx.Where("user_id = ? AND payment_method_id = ? AND is_active = ?", req.OwnerID, req.PaymentMethod.ID, true).First(&userPayment).Error; err != nil {
+		return nil, errors.New("user has not activated TSM payment")
+	}
+
+	var userTsm models.UserTsm
+	if err := tx.Where("user_id = ?", req.OwnerID).First(&userTsm).Error; err != nil {
+		return nil, errors.New("user tsm data not found")
+	}
+
+	tsmReq := dtos.TsmGenerateApplinkRequest{
+		AppCode:      userTsm.AppCode,
+		Amount:       req.OrderPayment.AmountPaid,
+		TrxID:        req.OrderPayment.Uuid.String(),
+		TerminalCode: userTsm.TerminalCode,
+		MerchantCode: userTsm.MerchantCode,
+	}
+	tsmLink, err := p.TsmService.GenerateAPPLink(req.OwnerID, tsmReq)
+	if err != nil {
+		return nil, fmt.Errorf("TSM generate app link failed: %w", err)
+	}
+
+	return &PaymentInitiateResult{Extra: tsmLink["data"]}, nil
+}
+
+// QueryStatus reads the verified callback recorded in TsmLog; TSM offers no inquiry API for app link payments.
+func (p *TsmPaymentProvider) QueryStatus(tx *gorm.DB, orderPayment models.OrderPayment) (*PaymentStatusResult, error) {
+	result := &PaymentStatusResult{ReferenceID: orderPayment.Uuid.String(), Status: PaymentStatusPending}
+
+	var tsmLog models.TsmLog
+	if err := tx.Where("service_ref_id = ? AND callback_at IS NOT NULL", orderPayment.Uuid.String()).First(&tsmLog).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return result, nil
+		}
+		return nil, err
+	}
+
+	var callback dtos.TsmCallbackRequest
+	if err := json.Unmarshal([]byte(tsmLog.Callback), &callback); err != nil {
+		return result, nil
+	}
+	switch callback.Status {
+	case "PAID":
+		result.Status = PaymentStatusPaid
+	case "":
+	default:
+		result.Status = PaymentStatusFailed
+	}
+	result.Amount = callback.Amount
+	return result, nil
+}
+
+func (p *TsmPaymentProvider) Cancel(tx *gorm.DB, orderPayment models.OrderPayment) error {
+	return ErrPaymentOperationNotSupported
+}
+
+func (p *TsmPaymentProvider) Refund(tx *gorm.DB, orderPayment models.OrderPayment, amount float64, reason string) (*PaymentRefundResult, error) {
+	return nil, ErrPaymentOperationNotSupported
+}
+
+func (p *TsmPaymentProvider) VerifyCallback(tx *gorm.DB, callback PaymentCallback) (*PaymentCallbackResult, error) {
+	req, ok := callback.Payload.(*dtos.TsmCallbackRequest)
+	if !ok {
+		return nil, errors.New("invalid TSM callback payload")
+	}
+
+	orderPayment, alreadyPaid, err := p.TsmService.VerifyCallback(tx, *req, callback.RawBody, callback.Headers.Get("Token"))
+	if err != nil {
+		return nil, err
+	}
+
+	paid := req.Status == "PAID"
+	return &PaymentCallbackResult{
+		ReferenceID:  req.PartnerTrxID,
+		ServiceRefID: orderPayment.Uuid.String(),
+		Paid:         paid && !alreadyPaid,
+		Failed:       !paid && req.Status != "",
+		Amount:       orderPayment.AmountPaid,
+		Duplicate:    paid && alreadyPaid,
+	}, nil
+}
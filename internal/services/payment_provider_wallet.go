@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/msyaifudin/pos/internal/models"
+	"gorm.io/gorm"
+)
+
+// WalletIssuer is the issuer of payment methods paid from a customer's wallet balance.
+const WalletIssuer = "Wallet"
+
+// WalletPaymentProvider pays orders from a customer's wallet. The balance is debited while the
+// payment is created, so wallet payments are settled immediately; refunds go back to the wallet.
+type WalletPaymentProvider struct{}
+
+func NewWalletPaymentProvider() *WalletPaymentProvider {
+	return &WalletPaymentProvider{}
+}
+
+func (p *WalletPaymentProvider) Issuer() string {
+	return WalletIssuer
+}
+
+func (p *WalletPaymentProvider) Initiate(tx *gorm.DB, req PaymentInitiateRequest) (*PaymentInitiateResult, error) {
+	if req.CustomerUuid == nil {
+		return nil, errors.New("customer is required for wallet payments")
+	}
+
+	var topUps int64
+	if err := tx.Model(&models.CustomerWalletTopUp{}).Where("order_id = ?", req.Order.ID).Count(&topUps).Error; err != nil {
+		return nil, err
+	}
+	if topUps > 0 {
+		return nil, errors.New("wallet top-ups cannot be paid from the wallet")
+	}
+
+	customer, err := findOwnedCustomer(tx, *req.CustomerUuid, req.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := postWalletTransaction(tx, customer.ID, models.CustomerWalletTransaction{
+		Type:           models.CustomerWalletTransactionSpend,
+		Amount:         -req.OrderPayment.AmountPaid,
+		Reason:         "Order payment",
+		OrderPaymentID: &req.OrderPayment.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if req.OrderPayment.CustomerName == "" && req.OrderPayment.CustomerEmail == "" && req.OrderPayment.CustomerPhone == "" {
+		req.OrderPayment.CustomerName = customer.Name
+		req.OrderPayment.CustomerEmail = customer.Email
+		req.OrderPayment.CustomerPhone = customer.Phone
+	}
+
+	return &PaymentInitiateResult{
+		ReferenceID: transaction.Uuid.String(),
+		Extra: map[string]interface{}{
+			"customer_uuid":  customer.Uuid,
+			"wallet_balance": transaction.BalanceAfter,
+		},
+		Paid: true,
+	}, nil
+}
+
+func (p *WalletPaymentProvider) QueryStatus(tx *gorm.DB, orderPayment models.OrderPayment) (*PaymentStatusResult, error) {
+	status := PaymentStatusPending
+	if orderPayment.IsPaid {
+		status = PaymentStatusPaid
+	}
+	return &PaymentStatusResult{Status: status, Amount: orderPayment.AmountPaid}, nil
+}
+
+func (p *WalletPaymentProvider) Cancel(tx *gorm.DB, orderPayment models.OrderPayment) error {
+	return nil
+}
+
+// Refund credits the refunded amount back to the wallet the payment was taken from.
+func (p *WalletPaymentProvider) Refund(tx *gorm.DB, orderPayment models.OrderPayment, amount float64, reason string) (*PaymentRefundResult, error) {
+	var spend models.CustomerWalletTransaction
+	if err := tx.Where("order_payment_id = ? AND type = ?", orderPayment.ID, models.CustomerWalletTransactionSpend).
+		First(&spend).Error; err != nil {
+		return nil, errors.New("wallet payment not found")
+	}
+
+	transaction, err := postWalletTransaction(tx, spend.CustomerID, models.CustomerWalletTransaction{
+		Type:           models.CustomerWalletTransactionRefundCredit,
+		Amount:         amount,
+		Reason:         reason,
+		OrderPaymentID: &orderPayment.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PaymentRefundResult{
+		ReferenceID: transaction.Uuid.String(),
+		Status:      PaymentStatusRefunded,
+		Extra:       map[string]interface{}{"wallet_balance": transaction.BalanceAfter},
+	}, nil
+}
+
+func (p *WalletPaymentProvider) VerifyCallback(tx *gorm.DB, callback PaymentCallback) (*PaymentCallbackResult, error) {
+	return nil, ErrPaymentOperationNotSupported
+}
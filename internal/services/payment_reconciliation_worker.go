@@ -0,0 +1,28 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// StartPaymentReconciliationWorker periodically checks pending order payments with their gateway,
+// crediting payments whose notification was lost and expiring abandoned ones.
+// The interval is PAYMENT_RECONCILE_INTERVAL_MINUTES (default 5).
+func StartPaymentReconciliationWorker(orderPaymentService *OrderPaymentService) {
+	minutes, err := strconv.Atoi(os.Getenv("PAYMENT_RECONCILE_INTERVAL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 5
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(minutes) * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := orderPaymentService.ReconcilePendingPayments(); err != nil {
+				log.Printf("Payment reconciliation failed: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"github.com/msyaifudin/pos/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// productUnitCost is the cost of one unit of a product or variant when it is sold. A variant
+// without a cost of its own costs what its product costs, and an fnb_main_product with a recipe
+// costs what its components cost.
+func productUnitCost(tx *gorm.DB, productID *uint, variantID *uint) (float64, error) {
+	if variantID != nil {
+		var variant models.ProductVariant
+		if err := tx.Select("id", "product_id", "cost_price").First(&variant, *variantID).Error; err != nil {
+			return 0, err
+		}
+		if variant.CostPrice > 0 {
+			return variant.CostPrice, nil
+		}
+		productID = &variant.ProductID
+	}
+	if productID == nil {
+		return 0, nil
+	}
+
+	var product models.Product
+	if err := tx.Select("id", "type", "cost_price").First(&product, *productID).Error; err != nil {
+		return 0, err
+	}
+	if product.Type == "fnb_main_product" {
+		var recipes int64
+		if err := tx.Model(&models.Recipe{}).Where("main_product_id = ?", product.ID).Count(&recipes).Error; err != nil {
+			return 0, err
+		}
+		if recipes > 0 {
+			return recipeCost(tx, product.ID)
+		}
+	}
+	return product.CostPrice, nil
+}
+
+// recipeCost rolls the cost of one fnb_main_product up from the current cost of its components.
+func recipeCost(tx *gorm.DB, mainProductID uint) (float64, error) {
+	var cost float64
+	err := tx.Model(&models.Recipe{}).
+		Select("COALESCE(SUM(recipes.quantity * recipes.conversion_factor * products.cost_price), 0)").
+		Joins("JOIN products ON products.id = recipes.component_id").
+		Where("recipes.main_product_id = ?", mainProductID).
+		Scan(&cost).Error
+	return cost, err
+}
+
+// updateMovingAverageCost blends a received quantity at the given unit price into the moving-average
+// cost of a product or variant. It must run before the received quantity is added to stock, since
+// the current cost is weighted by the stock on hand across all outlets.
+func updateMovingAverageCost(tx *gorm.DB, productID *uint, variantID *uint, quantity float64, price float64) error {
+	if quantity <= 0 {
+		return nil
+	}
+
+	var onHand float64
+	stocks := tx.Model(&models.Stock{}).Select("COALESCE(SUM(quantity), 0)").Where("quantity > 0")
+	if variantID != nil {
+		var variant models.ProductVariant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&variant, *variantID).Error; err != nil {
+			return err
+		}
+		if err := stocks.Where("product_variant_id = ?", variant.ID).Scan(&onHand).Error; err != nil {
+			return err
+		}
+		return tx.Model(&variant).Update("cost_price", movingAverage(onHand, variant.CostPrice, quantity, price)).Error
+	}
+	if productID == nil {
+		return nil
+	}
+
+	var product models.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, *productID).Error; err != nil {
+		return err
+	}
+	if err := stocks.Where("product_id = ? AND product_variant_id IS NULL", product.ID).Scan(&onHand).Error; err != nil {
+		return err
+	}
+	return tx.Model(&product).Update("cost_price", movingAverage(onHand, product.CostPrice, quantity, price)).Error
+}
+
+func movingAverage(onHand float64, cost float64, quantity float64, price float64) float64 {
+	if onHand <= 0 {
+		return price
+	}
+	return (onHand*cost + quantity*price) / (onHand + quantity)
+}
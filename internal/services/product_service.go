@@ -46,7 +46,7 @@ func (s *ProductService) GetProductByUuid(Uuid uuid.UUID, userID uint) (*dtos.Pr
 		return nil, err
 	}
 	var product models.Product
-	if err := s.DB.Preload("Variants").Preload("Recipes.Component").Preload("AddOns.AddOn").Where("uuid = ? AND user_id = ?", Uuid, ownerID).First(&product).Error; err != nil {
+	if err := s.DB.Preload("Variants").Preload("Recipes.Component").Preload("AddOns.AddOn").Preload("Units").Where("uuid = ? AND user_id = ?", Uuid, ownerID).First(&product).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("product not found")
 		}
@@ -57,11 +57,12 @@ func (s *ProductService) GetProductByUuid(Uuid uuid.UUID, userID uint) (*dtos.Pr
 	variantResponses := []dtos.ProductVariantResponse{}
 	for _, v := range product.Variants {
 		variantResponses = append(variantResponses, dtos.ProductVariantResponse{
-			ID:    v.ID,
-			Uuid:  v.Uuid,
-			Name:  v.Name,
-			SKU:   v.SKU,
-			Price: v.Price,
+			ID:        v.ID,
+			Uuid:      v.Uuid,
+			Name:      v.Name,
+			SKU:       v.SKU,
+			Price:     v.Price,
+			CostPrice: v.CostPrice,
 		})
 	}
 
@@ -69,11 +70,7 @@ func (s *ProductService) GetProductByUuid(Uuid uuid.UUID, userID uint) (*dtos.Pr
 	if product.Type == "fnb_main_product" {
 		for _, r := range product.Recipes {
 			if r.Component.ID != 0 { // Check if component is loaded
-				recipeResponses = append(recipeResponses, dtos.RecipeResponse{
-					Uuid:        r.Uuid,
-					ComponentName: r.Component.Name,
-					Quantity:    r.Quantity,
-				})
+				recipeResponses = append(recipeResponses, mapRecipeToResponse(r))
 			}
 		}
 	}
@@ -90,17 +87,30 @@ func (s *ProductService) GetProductByUuid(Uuid uuid.UUID, userID uint) (*dtos.Pr
 		}
 	}
 
-	return &dtos.ProductDetailResponse{
+	response := &dtos.ProductDetailResponse{
 		Uuid:        product.Uuid,
 		Name:        product.Name,
 		Description: product.Description,
 		Price:       product.Price,
+		CostPrice:   product.CostPrice,
+		Category:    product.Category,
+		BaseUnit:    product.BaseUnit,
 		SKU:         product.SKU,
 		Type:        product.Type,
 		Variants:    variantResponses,
 		Recipes:     recipeResponses,
 		AddOns:      addOnResponses,
-	}, nil
+		Units:       mapProductUnitsToResponses(product.Units, product.BaseUnit),
+	}
+	if product.Type == "fnb_main_product" {
+		cost, err := recipeCost(s.DB, product.ID)
+		if err != nil {
+			log.Printf("Error calculating recipe cost: %v", err)
+			return nil, errors.New("failed to retrieve product")
+		}
+		response.RecipeCost = &cost
+	}
+	return response, nil
 }
 
 func (s *ProductService) CreateProduct(req *dtos.ProductCreateRequest, userID uint) (*dtos.ProductResponse, error) {
@@ -113,10 +123,16 @@ func (s *ProductService) CreateProduct(req *dtos.ProductCreateRequest, userID ui
 		Name:        req.Name,
 		Description: req.Description,
 		Price:       req.Price,
+		CostPrice:   req.CostPrice,
+		Category:    req.Category,
+		BaseUnit:    req.BaseUnit,
 		SKU:         req.SKU,
 		Type:        req.Type,
 		UserID:      ownerID,
 	}
+	if product.BaseUnit == "" {
+		product.BaseUnit = models.DefaultBaseUnit
+	}
 
 	tx := s.DB.Begin()
 	defer func() {
@@ -139,6 +155,7 @@ func (s *ProductService) CreateProduct(req *dtos.ProductCreateRequest, userID ui
 				Name:      v.Name,
 				SKU:       v.SKU,
 				Price:     v.Price,
+				CostPrice: v.CostPrice,
 				UserID:    ownerID,
 			}
 			if err := tx.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Create(productVariant).Error; err != nil {
@@ -147,11 +164,12 @@ func (s *ProductService) CreateProduct(req *dtos.ProductCreateRequest, userID ui
 				return nil, errors.New("failed to create product variant")
 			}
 			variantResponses = append(variantResponses, dtos.ProductVariantResponse{
-				ID:    productVariant.ID,
-				Uuid:  productVariant.Uuid,
-				Name:  productVariant.Name,
-				SKU:   productVariant.SKU,
-				Price: productVariant.Price,
+				ID:        productVariant.ID,
+				Uuid:      productVariant.Uuid,
+				Name:      productVariant.Name,
+				SKU:       productVariant.SKU,
+				Price:     productVariant.Price,
+				CostPrice: productVariant.CostPrice,
 			})
 		}
 	}
@@ -167,6 +185,9 @@ func (s *ProductService) CreateProduct(req *dtos.ProductCreateRequest, userID ui
 		Name:        product.Name,
 		Description: product.Description,
 		Price:       product.Price,
+		CostPrice:   product.CostPrice,
+		Category:    product.Category,
+		BaseUnit:    product.BaseUnit,
 		SKU:         product.SKU,
 		Type:        product.Type,
 		Variants:    variantResponses,
@@ -200,6 +221,13 @@ func (s *ProductService) UpdateProduct(Uuid uuid.UUID, req *dtos.ProductUpdateRe
 	product.Name = req.Name
 	product.Description = req.Description
 	product.Price = req.Price
+	if req.CostPrice != nil {
+		product.CostPrice = *req.CostPrice
+	}
+	product.Category = req.Category
+	if req.BaseUnit != "" {
+		product.BaseUnit = req.BaseUnit
+	}
 	product.SKU = req.SKU
 	product.Type = req.Type
 
@@ -209,6 +237,18 @@ func (s *ProductService) UpdateProduct(Uuid uuid.UUID, req *dtos.ProductUpdateRe
 		return nil, errors.New("failed to update product")
 	}
 
+	// Variants are recreated below, so keep their moving-average cost by SKU
+	var oldVariants []models.ProductVariant
+	if err := tx.Where("product_id = ?", product.ID).Find(&oldVariants).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error finding old variants: %v", err)
+		return nil, errors.New("failed to update variants")
+	}
+	variantCosts := make(map[string]float64, len(oldVariants))
+	for _, v := range oldVariants {
+		variantCosts[v.SKU] = v.CostPrice
+	}
+
 	//- Hapus varian lama
 	if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductVariant{}).Error; err != nil {
 		tx.Rollback()
@@ -225,19 +265,24 @@ func (s *ProductService) UpdateProduct(Uuid uuid.UUID, req *dtos.ProductUpdateRe
 				Name:      v.Name,
 				SKU:       v.SKU,
 				Price:     v.Price,
+				CostPrice: variantCosts[v.SKU],
 				UserID:    ownerID,
 			}
+			if v.CostPrice != nil {
+				productVariant.CostPrice = *v.CostPrice
+			}
 			if err := tx.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Create(productVariant).Error; err != nil {
 				tx.Rollback()
 				log.Printf("Error creating new variant: %v", err)
 				return nil, errors.New("failed to create new variant")
 			}
 			variantResponses = append(variantResponses, dtos.ProductVariantResponse{
-				ID:    productVariant.ID,
-				Uuid:  productVariant.Uuid,
-				Name:  productVariant.Name,
-				SKU:   productVariant.SKU,
-				Price: productVariant.Price,
+				ID:        productVariant.ID,
+				Uuid:      productVariant.Uuid,
+				Name:      productVariant.Name,
+				SKU:       productVariant.SKU,
+				Price:     productVariant.Price,
+				CostPrice: productVariant.CostPrice,
 			})
 		}
 	}
@@ -253,6 +298,9 @@ func (s *ProductService) UpdateProduct(Uuid uuid.UUID, req *dtos.ProductUpdateRe
 		Name:        product.Name,
 		Description: product.Description,
 		Price:       product.Price,
+		CostPrice:   product.CostPrice,
+		Category:    product.Category,
+		BaseUnit:    product.BaseUnit,
 		SKU:         product.SKU,
 		Type:        product.Type,
 		Variants:    variantResponses,
@@ -290,7 +338,7 @@ func (s *ProductService) GetProductsByOutlet(outletUuid uuid.UUID, userID uint)
 
 	// Join products with stocks to get products available in the outlet
 	if err := s.DB.Table("products").
-		Select("products.uuid as product_uuid, products.name as product_name, products.sku as product_sku, products.price, products.type, stocks.quantity").
+		Select("products.uuid as product_uuid, products.name as product_name, products.sku as product_sku, products.price, products.type, stocks.quantity, stocks.quantity - stocks.reserved_quantity as available_quantity").
 		Joins("JOIN stocks ON products.id = stocks.product_id").
 		Where("stocks.outlet_id = ? AND stocks.quantity > 0 AND products.user_id = ?", outlet.ID, ownerID).
 		Find(&products).Error; err != nil {
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"gorm.io/gorm"
+)
+
+type ProductUnitService struct {
+	DB                 *gorm.DB
+	UserContextService *UserContextService
+}
+
+func NewProductUnitService(db *gorm.DB, userContextService *UserContextService) *ProductUnitService {
+	return &ProductUnitService{DB: db, UserContextService: userContextService}
+}
+
+// CreateProductUnit adds an alternate unit to a product.
+func (s *ProductUnitService) CreateProductUnit(productUuid uuid.UUID, req *dtos.ProductUnitRequest, userID uint) (*dtos.ProductUnitResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var product models.Product
+	if err := s.DB.Where("uuid = ? AND user_id = ?", productUuid, ownerID).First(&product).Error; err != nil {
+		return nil, errors.New("product not found")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := s.checkUnitName(product, name, 0); err != nil {
+		return nil, err
+	}
+
+	unit := models.ProductUnit{
+		ProductID:        product.ID,
+		Name:             name,
+		ConversionFactor: req.ConversionFactor,
+		UserID:           ownerID,
+	}
+	if err := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Create(&unit).Error; err != nil {
+		log.Printf("Error creating product unit: %v", err)
+		return nil, errors.New("failed to create product unit")
+	}
+	return mapProductUnitToResponse(unit, product.BaseUnit), nil
+}
+
+// GetProductUnits lists the alternate units of a product.
+func (s *ProductUnitService) GetProductUnits(productUuid uuid.UUID, userID uint) ([]dtos.ProductUnitResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var product models.Product
+	if err := s.DB.Preload("Units", func(db *gorm.DB) *gorm.DB {
+		return db.Order("conversion_factor")
+	}).Where("uuid = ? AND user_id = ?", productUuid, ownerID).First(&product).Error; err != nil {
+		return nil, errors.New("product not found")
+	}
+	return mapProductUnitsToResponses(product.Units, product.BaseUnit), nil
+}
+
+// UpdateProductUnit renames a unit or changes its conversion factor. Recipe lines in the unit follow
+// the new factor; purchase order items keep the factor they were ordered with.
+func (s *ProductUnitService) UpdateProductUnit(unitUuid uuid.UUID, req *dtos.ProductUnitRequest, userID uint) (*dtos.ProductUnitResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var unit models.ProductUnit
+	if err := tx.Preload("Product").Where("uuid = ? AND user_id = ?", unitUuid, ownerID).First(&unit).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("product unit not found")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := s.checkUnitName(unit.Product, name, unit.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Model(&models.Recipe{}).
+		Where("component_id = ? AND unit = ?", unit.ProductID, unit.Name).
+		Updates(map[string]interface{}{"unit": name, "conversion_factor": req.ConversionFactor}).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error updating recipes for product unit: %v", err)
+		return nil, errors.New("failed to update product unit")
+	}
+
+	unit.Name = name
+	unit.ConversionFactor = req.ConversionFactor
+	if err := tx.Model(&unit).Updates(map[string]interface{}{"name": unit.Name, "conversion_factor": unit.ConversionFactor}).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error updating product unit: %v", err)
+		return nil, errors.New("failed to update product unit")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return mapProductUnitToResponse(unit, unit.Product.BaseUnit), nil
+}
+
+// DeleteProductUnit removes a unit. Recipe lines already in the unit keep its conversion factor.
+func (s *ProductUnitService) DeleteProductUnit(unitUuid uuid.UUID, userID uint) error {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return err
+	}
+
+	var unit models.ProductUnit
+	if err := s.DB.Where("uuid = ? AND user_id = ?", unitUuid, ownerID).First(&unit).Error; err != nil {
+		return errors.New("product unit not found")
+	}
+	if err := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Delete(&unit).Error; err != nil {
+		log.Printf("Error deleting product unit: %v", err)
+		return errors.New("failed to delete product unit")
+	}
+	return nil
+}
+
+// checkUnitName rejects a unit name that is the product's base unit or another of its units.
+func (s *ProductUnitService) checkUnitName(product models.Product, name string, unitID uint) error {
+	if strings.EqualFold(name, product.BaseUnit) {
+		return errors.New("unit name is the product's base unit")
+	}
+	var count int64
+	if err := s.DB.Model(&models.ProductUnit{}).
+		Where("product_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", product.ID, name, unitID).
+		Count(&count).Error; err != nil {
+		log.Printf("Error checking product unit name: %v", err)
+		return errors.New("failed to check product unit")
+	}
+	if count > 0 {
+		return errors.New("product unit already exists")
+	}
+	return nil
+}
+
+// resolveProductUnit looks up a unit of a product by name and returns the name to record with its
+// conversion factor to the base unit. The base unit is recorded as an empty name, so renaming it
+// carries over.
+func resolveProductUnit(tx *gorm.DB, productID uint, name string) (string, float64, error) {
+	var product models.Product
+	if err := tx.Select("id", "base_unit").First(&product, productID).Error; err != nil {
+		return "", 0, errors.New("product not found")
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, product.BaseUnit) {
+		return "", 1, nil
+	}
+
+	var unit models.ProductUnit
+	if err := tx.Where("product_id = ? AND LOWER(name) = LOWER(?)", productID, name).First(&unit).Error; err != nil {
+		return "", 0, errors.New("product unit not found")
+	}
+	return unit.Name, unit.ConversionFactor, nil
+}
+
+func mapProductUnitsToResponses(units []models.ProductUnit, baseUnit string) []dtos.ProductUnitResponse {
+	responses := make([]dtos.ProductUnitResponse, 0, len(units))
+	for _, unit := range units {
+		responses = append(responses, *mapProductUnitToResponse(unit, baseUnit))
+	}
+	return responses
+}
+
+func mapProductUnitToResponse(unit models.ProductUnit, baseUnit string) *dtos.ProductUnitResponse {
+	return &dtos.ProductUnitResponse{
+		Uuid:             unit.Uuid,
+		Name:             unit.Name,
+		ConversionFactor: unit.ConversionFactor,
+		BaseUnit:         baseUnit,
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/msyaifudin/pos/internal/models"
 	"github.com/msyaifudin/pos/internal/models/dtos"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PurchaseOrderService struct {
@@ -64,6 +65,7 @@ func (s *PurchaseOrderService) CreatePurchaseOrder(req dtos.CreatePurchaseOrderR
 		var variant *models.ProductVariant
 		var productID *uint
 		var variantID *uint
+		var unitProductID uint
 
 		if item.ProductVariantUuid != uuid.Nil {
 			if err := tx.Where("uuid = ? AND user_id = ?", item.ProductVariantUuid, ownerID).First(&variant).Error; err != nil {
@@ -71,17 +73,26 @@ func (s *PurchaseOrderService) CreatePurchaseOrder(req dtos.CreatePurchaseOrderR
 				return nil, errors.New("product variant not found")
 			}
 			variantID = &variant.ID
+			unitProductID = variant.ProductID
 		} else if item.ProductUuid != uuid.Nil {
 			if err := tx.Where("uuid = ? AND user_id = ?", item.ProductUuid, ownerID).First(&product).Error; err != nil {
 				tx.Rollback()
 				return nil, errors.New("product not found")
 			}
 			productID = &product.ID
+			unitProductID = product.ID
 		} else {
 			tx.Rollback()
 			return nil, errors.New("product_uuid or product_variant_uuid is required for each item")
 		}
 
+		// Variants are bought in the units of their product
+		unitName, conversionFactor, err := resolveProductUnit(tx, unitProductID, item.Unit)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
 		poItem := models.PurchaseOrderItem{
 			PurchaseOrderID:   po.ID,
 			PurchaseOrderUuid: po.Uuid,
@@ -89,6 +100,8 @@ func (s *PurchaseOrderService) CreatePurchaseOrder(req dtos.CreatePurchaseOrderR
 			ProductVariantID:  variantID,
 			Quantity:          float64(item.Quantity),
 			Price:             item.Price,
+			Unit:              unitName,
+			ConversionFactor:  conversionFactor,
 		}
 
 		if err := tx.Create(&poItem).Error; err != nil {
@@ -182,8 +195,9 @@ func (s *PurchaseOrderService) GetPurchaseOrdersByOutlet(outletUuid uuid.UUID, u
 	return poResponses, nil
 }
 
-// ReceivePurchaseOrder updates stock based on a completed purchase order.
-func (s *PurchaseOrderService) ReceivePurchaseOrder(poUuid uuid.UUID, userID uint) (*dtos.PurchaseOrderResponse, error) {
+// ReceivePurchaseOrder adds the ordered quantities to the outlet's stock and records each item
+// as a stock lot with its cost and, when given, its lot number and expiry date.
+func (s *PurchaseOrderService) ReceivePurchaseOrder(poUuid uuid.UUID, req dtos.ReceivePurchaseOrderRequest, userID uint) (*dtos.PurchaseOrderResponse, error) {
 	ownerID, err := s.UserContextService.GetOwnerID(userID)
 	if err != nil {
 		return nil, err
@@ -201,35 +215,45 @@ func (s *PurchaseOrderService) ReceivePurchaseOrder(poUuid uuid.UUID, userID uin
 		return nil, errors.New("purchase order already received")
 	}
 
-	tx := s.DB.Begin()
+	itemUuids := make(map[uuid.UUID]bool, len(po.PurchaseOrderItems))
+	for _, item := range po.PurchaseOrderItems {
+		itemUuids[item.Uuid] = true
+	}
+	lots := make(map[uuid.UUID]dtos.ReceivePurchaseOrderItemRequest, len(req.Items))
+	for _, lot := range req.Items {
+		if !itemUuids[lot.PurchaseOrderItemUuid] {
+			return nil, errors.New("purchase order item not found")
+		}
+		lots[lot.PurchaseOrderItemUuid] = lot
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
 	if tx.Error != nil {
 		return nil, errors.New("failed to start transaction")
 	}
 
+	var productIDs, variantIDs []uint
 	for _, item := range po.PurchaseOrderItems {
-		// Determine if it's a product or a variant
-		var productUuid uuid.UUID
-		var productVariantUuid uuid.UUID
-
-		if item.ProductVariantID != nil && item.ProductVariant != nil {
-			productVariantUuid = item.ProductVariant.Uuid
-		} else if item.ProductID != nil && item.Product != nil {
-			productUuid = item.Product.Uuid
-		} else {
-			tx.Rollback()
-			return nil, errors.New("purchase order item has no associated product or variant")
+		if item.ProductVariantID != nil {
+			variantIDs = append(variantIDs, *item.ProductVariantID)
+		} else if item.ProductID != nil {
+			productIDs = append(productIDs, *item.ProductID)
 		}
+	}
+	if err := lockStocks(tx, po.OutletID, productIDs, variantIDs); err != nil {
+		tx.Rollback()
+		log.Printf("Error locking stocks for received PO: %v", err)
+		return nil, errors.New("failed to update stock for received purchase order")
+	}
 
-		// Create UpdateStockRequest for the item
-		updateReq := dtos.UpdateStockRequest{
-			ProductUuid:        productUuid,
-			ProductVariantUuid: productVariantUuid,
-			Quantity:           item.Quantity,
+	receivedAt := time.Now()
+	for _, item := range po.PurchaseOrderItems {
+		if item.ProductID == nil && item.ProductVariantID == nil {
+			tx.Rollback()
+			return nil, errors.New("purchase order item has no associated product or variant")
 		}
 
-		// Add stock using StockService.UpdateStock
-		_, err := s.StockService.UpdateStock(updateReq, po.Outlet.Uuid, userID)
-		if err != nil {
+		if err := s.receivePurchaseOrderItem(tx, po, item, lots[item.Uuid], receivedAt); err != nil {
 			tx.Rollback()
 			log.Printf("Error updating stock for received PO: %v", err)
 			return nil, errors.New("failed to update stock for received purchase order")
@@ -237,11 +261,19 @@ func (s *PurchaseOrderService) ReceivePurchaseOrder(poUuid uuid.UUID, userID uin
 	}
 
 	po.Status = "completed"
-	if err := tx.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Save(&po).Error; err != nil {
+	if err := tx.Save(&po).Error; err != nil {
 		tx.Rollback()
 		log.Printf("Error updating purchase order status: %v", err)
 		return nil, errors.New("failed to update purchase order status")
 	}
+	recordWebhookEvent(tx, ownerID, models.WebhookEventPurchaseOrderReceived, map[string]interface{}{
+		"uuid":         po.Uuid,
+		"outlet_uuid":  po.Outlet.Uuid,
+		"supplier_id":  po.SupplierID,
+		"status":       po.Status,
+		"total_amount": po.TotalAmount,
+		"items":        purchaseOrderWebhookItems(po),
+	})
 
 	tx.Commit()
 
@@ -257,3 +289,80 @@ func (s *PurchaseOrderService) ReceivePurchaseOrder(poUuid uuid.UUID, userID uin
 		Status:       po.Status,
 	}, nil
 }
+
+// receivePurchaseOrderItem adds a received item to the outlet's stock, blends its price into the
+// moving-average cost and opens a lot for it. Quantities and prices are converted from the unit the
+// item was ordered in to the base unit stock is kept in.
+func (s *PurchaseOrderService) receivePurchaseOrderItem(tx *gorm.DB, po models.PurchaseOrder, item models.PurchaseOrderItem, lotReq dtos.ReceivePurchaseOrderItemRequest, receivedAt time.Time) error {
+	quantity := item.Quantity * item.ConversionFactor
+	unitCost := item.Price / item.ConversionFactor
+
+	if err := updateMovingAverageCost(tx, item.ProductID, item.ProductVariantID, quantity, unitCost); err != nil {
+		return err
+	}
+
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("outlet_id = ? AND user_id = ?", po.OutletID, po.UserID)
+	if item.ProductVariantID != nil {
+		query = query.Where("product_variant_id = ?", *item.ProductVariantID)
+	} else {
+		query = query.Where("product_id = ?", *item.ProductID)
+	}
+
+	var stock models.Stock
+	if err := query.First(&stock).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		stock = models.Stock{
+			OutletID:         po.OutletID,
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			Quantity:         quantity,
+			UserID:           po.UserID,
+		}
+		if err := tx.Create(&stock).Error; err != nil {
+			return err
+		}
+	} else {
+		stock.Quantity += quantity
+		if err := tx.Model(&stock).Update("quantity", stock.Quantity).Error; err != nil {
+			return err
+		}
+		checkStockLevel(tx, stock)
+	}
+
+	lot := models.StockLot{
+		StockID:          stock.ID,
+		OutletID:         po.OutletID,
+		ProductID:        stock.ProductID,
+		ProductVariantID: stock.ProductVariantID,
+		PurchaseOrderID:  &po.ID,
+		LotNumber:        lotReq.LotNumber,
+		ReceivedAt:       receivedAt,
+		UnitCost:         unitCost,
+		InitialQuantity:  quantity,
+		Quantity:         quantity,
+		UserID:           po.UserID,
+	}
+	if lotReq.ExpiryDate != "" {
+		expiryDate, err := time.Parse("2006-01-02", lotReq.ExpiryDate)
+		if err != nil {
+			return err
+		}
+		lot.ExpiryDate = &expiryDate
+	}
+	if err := tx.Create(&lot).Error; err != nil {
+		return err
+	}
+
+	return s.StockService.StockMovementService.CreateStockMovementWithTx(tx, &models.StockMovement{
+		OutletID:         po.OutletID,
+		ProductID:        stock.ProductID,
+		ProductVariantID: stock.ProductVariantID,
+		QuantityChange:   quantity,
+		MovementType:     models.StockMovementPurchaseOrder,
+		ReferenceType:    models.StockReferencePurchaseOrder,
+		ReferenceID:      &po.ID,
+		Description:      stringPtr("Received purchase order"),
+	})
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/pkg/qris"
+	"gorm.io/gorm"
+)
+
+type QrisService struct {
+	DB                 *gorm.DB
+	UserContextService *UserContextService
+}
+
+func NewQrisService(db *gorm.DB, userContextService *UserContextService) *QrisService {
+	return &QrisService{DB: db, UserContextService: userContextService}
+}
+
+// RegisterQris stores the owner's static QRIS, replacing the one registered before.
+func (s *QrisService) RegisterQris(userID uint, req dtos.QrisRegisterRequest) (*dtos.UserQrisResponse, error) {
+	payload, err := qris.Parse(req.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if payload.IsDynamic() {
+		return nil, errors.New("qris payload must be static")
+	}
+
+	var userQris models.UserQris
+	result := s.DB.Where("user_id = ?", userID).First(&userQris)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, result.Error
+	}
+
+	userQris.UserID = userID
+	userQris.Payload = qris.Encode(payload.Fields)
+	userQris.MerchantName = payload.MerchantName()
+	userQris.MerchantCity = payload.MerchantCity()
+	userQris.NMID = payload.NMID()
+	if err := s.DB.Save(&userQris).Error; err != nil {
+		return nil, err
+	}
+
+	return mapUserQrisToResponse(userQris), nil
+}
+
+func (s *QrisService) GetUserQris(userID uint) (*dtos.UserQrisResponse, error) {
+	var userQris models.UserQris
+	if err := s.DB.Where("user_id = ?", userID).First(&userQris).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user qris data not found")
+		}
+		return nil, err
+	}
+	return mapUserQrisToResponse(userQris), nil
+}
+
+func mapUserQrisToResponse(userQris models.UserQris) *dtos.UserQrisResponse {
+	return &dtos.UserQrisResponse{
+		Payload:      userQris.Payload,
+		MerchantName: userQris.MerchantName,
+		MerchantCity: userQris.MerchantCity,
+		NMID:         userQris.NMID,
+	}
+}
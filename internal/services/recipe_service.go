@@ -35,11 +35,8 @@ func (s *RecipeService) GetRecipeByUuid(recipeUuid uuid.UUID, userID uint) (*dto
 		log.Printf("Error getting recipe by uuid: %v", err)
 		return nil, errors.New("failed to retrieve recipe")
 	}
-	return &dtos.RecipeResponse{
-		Uuid:          recipe.Uuid,
-		ComponentName: recipe.Component.Name,
-		Quantity:      recipe.Quantity,
-	}, nil
+	response := mapRecipeToResponse(recipe)
+	return &response, nil
 }
 
 // GetRecipesByMainProduct retrieves all recipes for a given main product.
@@ -60,17 +57,13 @@ func (s *RecipeService) GetRecipesByMainProduct(mainProductUuid uuid.UUID, userI
 	}
 	var recipeResponses []dtos.RecipeResponse
 	for _, recipe := range recipes {
-		recipeResponses = append(recipeResponses, dtos.RecipeResponse{
-			Uuid:          recipe.Uuid,
-			ComponentName: recipe.Component.Name,
-			Quantity:      recipe.Quantity,
-		})
+		recipeResponses = append(recipeResponses, mapRecipeToResponse(recipe))
 	}
 	return recipeResponses, nil
 }
 
 // CreateRecipe creates a new recipe.
-func (s *RecipeService) CreateRecipe(mainProductUuid uuid.UUID, componentUuid uuid.UUID, quantity float64, userID uint) (*dtos.RecipeResponse, error) {
+func (s *RecipeService) CreateRecipe(mainProductUuid uuid.UUID, componentUuid uuid.UUID, quantity float64, unit string, userID uint) (*dtos.RecipeResponse, error) {
 	ownerID, err := s.UserContextService.GetOwnerID(userID)
 	if err != nil {
 		return nil, err
@@ -91,26 +84,31 @@ func (s *RecipeService) CreateRecipe(mainProductUuid uuid.UUID, componentUuid uu
 		return nil, errors.New("invalid product types for recipe: main product must be 'fnb_main_product' and component must be 'fnb_component'")
 	}
 
+	unitName, conversionFactor, err := resolveProductUnit(s.DB, component.ID, unit)
+	if err != nil {
+		return nil, err
+	}
+
 	recipe := models.Recipe{
-		MainProductID: mainProduct.ID,
-		ComponentID:   component.ID,
-		Quantity:      quantity,
-		UserID:        ownerID,
+		MainProductID:    mainProduct.ID,
+		ComponentID:      component.ID,
+		Quantity:         quantity,
+		Unit:             unitName,
+		ConversionFactor: conversionFactor,
+		UserID:           ownerID,
 	}
 
 	if err := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Create(&recipe).Error; err != nil {
 		log.Printf("Error creating recipe: %v", err)
 		return nil, errors.New("failed to create recipe")
 	}
-	return &dtos.RecipeResponse{
-		Uuid:          recipe.Uuid,
-		ComponentName: component.Name,
-		Quantity:      recipe.Quantity,
-	}, nil
+	recipe.Component = component
+	response := mapRecipeToResponse(recipe)
+	return &response, nil
 }
 
 // UpdateRecipe updates an existing recipe.
-func (s *RecipeService) UpdateRecipe(recipeUuid uuid.UUID, mainProductUuid uuid.UUID, componentUuid uuid.UUID, quantity float64, userID uint) (*dtos.RecipeResponse, error) {
+func (s *RecipeService) UpdateRecipe(recipeUuid uuid.UUID, mainProductUuid uuid.UUID, componentUuid uuid.UUID, quantity float64, unit string, userID uint) (*dtos.RecipeResponse, error) {
 	ownerID, err := s.UserContextService.GetOwnerID(userID)
 	if err != nil {
 		return nil, err
@@ -139,19 +137,24 @@ func (s *RecipeService) UpdateRecipe(recipeUuid uuid.UUID, mainProductUuid uuid.
 		return nil, errors.New("invalid product types for recipe: main product must be 'fnb_main_product' and component must be 'fnb_component'")
 	}
 
+	unitName, conversionFactor, err := resolveProductUnit(s.DB, component.ID, unit)
+	if err != nil {
+		return nil, err
+	}
+
 	recipe.MainProductID = mainProduct.ID
 	recipe.ComponentID = component.ID
 	recipe.Quantity = quantity
+	recipe.Unit = unitName
+	recipe.ConversionFactor = conversionFactor
 
 	if err := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Save(&recipe).Error; err != nil {
 		log.Printf("Error updating recipe: %v", err)
 		return nil, errors.New("failed to update recipe")
 	}
-	return &dtos.RecipeResponse{
-		Uuid:          recipe.Uuid,
-		ComponentName: component.Name,
-		Quantity:      recipe.Quantity,
-	}, nil
+	recipe.Component = component
+	response := mapRecipeToResponse(recipe)
+	return &response, nil
 
 }
 
@@ -170,3 +173,18 @@ func (s *RecipeService) DeleteRecipe(recipeUuid uuid.UUID, userID uint) error {
 	}
 	return nil
 }
+
+// mapRecipeToResponse expects the recipe's component to be loaded.
+func mapRecipeToResponse(recipe models.Recipe) dtos.RecipeResponse {
+	unit := recipe.Unit
+	if unit == "" {
+		unit = recipe.Component.BaseUnit
+	}
+	return dtos.RecipeResponse{
+		Uuid:          recipe.Uuid,
+		ComponentName: recipe.Component.Name,
+		Quantity:      recipe.Quantity,
+		Unit:          unit,
+		BaseQuantity:  recipe.Quantity * recipe.ConversionFactor,
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"log"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -29,6 +30,8 @@ func (s *ReportService) SalesByOutletReport(outletUuid uuid.UUID, startDate, end
 	var orders []models.Order
 	err := s.DB.Preload("OrderItems.Product").
 		Where("outlet_id = ? AND user_id = ? AND created_at BETWEEN ? AND ?", outlet.ID, userID, startDate, endDate.Add(24*time.Hour)).
+		// Wallet top-ups are prepaid balance, not sales
+		Where("NOT EXISTS (SELECT 1 FROM customer_wallet_top_ups WHERE customer_wallet_top_ups.order_id = orders.id)").
 		Find(&orders).Error
 
 	if err != nil {
@@ -98,3 +101,224 @@ func (s *ReportService) StockReport(outletUuid uuid.UUID, userID uint) ([]dtos.S
 
 	return report, nil
 }
+
+// PaymentReconciliationReport compares the iPaymu payments of an outlet paid within a date range
+// with the amounts iPaymu logged and settled, flagging every payment where they disagree.
+func (s *ReportService) PaymentReconciliationReport(outletUuid uuid.UUID, startDate, endDate time.Time, userID uint) (*dtos.PaymentReconciliationReport, error) {
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, userID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	var orderPayments []models.OrderPayment
+	err := s.DB.Preload("Order").Preload("PaymentMethod").
+		Joins("JOIN orders ON orders.id = order_payments.order_id").
+		Joins("JOIN payment_methods ON payment_methods.id = order_payments.payment_method_id").
+		Where("orders.outlet_id = ? AND orders.user_id = ? AND payment_methods.issuer = ?", outlet.ID, userID, "iPaymu").
+		Where("order_payments.status IN ? AND order_payments.paid_at BETWEEN ? AND ?",
+			[]string{models.OrderPaymentStatusPaid, models.OrderPaymentStatusRefunded}, startDate, endDate.Add(24*time.Hour)).
+		Order("order_payments.paid_at").
+		Find(&orderPayments).Error
+	if err != nil {
+		log.Printf("Error generating payment reconciliation report: %v", err)
+		return nil, errors.New("failed to generate report")
+	}
+
+	refIDs := make([]string, 0, len(orderPayments))
+	for _, orderPayment := range orderPayments {
+		refIDs = append(refIDs, orderPayment.Uuid.String())
+	}
+	var ipaymuLogs []models.IpaymuLog
+	if len(refIDs) > 0 {
+		if err := s.DB.Where("service_name = ? AND service_ref_id IN ?", "Order Payment", refIDs).Find(&ipaymuLogs).Error; err != nil {
+			log.Printf("Error loading iPaymu logs for reconciliation report: %v", err)
+			return nil, errors.New("failed to generate report")
+		}
+	}
+	logsByRef := make(map[string]models.IpaymuLog, len(ipaymuLogs))
+	for _, ipaymuLog := range ipaymuLogs {
+		logsByRef[ipaymuLog.ServiceRefID] = ipaymuLog
+	}
+
+	report := &dtos.PaymentReconciliationReport{
+		OutletUuid: outlet.Uuid.String(),
+		StartDate:  startDate.Format("2006-01-02"),
+		EndDate:    endDate.Format("2006-01-02"),
+		Payments:   make([]dtos.PaymentReconciliationRow, 0, len(orderPayments)),
+	}
+
+	for _, orderPayment := range orderPayments {
+		row := dtos.PaymentReconciliationRow{
+			OrderPaymentUuid: orderPayment.Uuid.String(),
+			OrderUuid:        orderPayment.Order.Uuid.String(),
+			PaymentName:      orderPayment.PaymentMethod.Name,
+			ReferenceID:      orderPayment.ReferenceID,
+			PaidAt:           orderPayment.PaidAt,
+			AmountPaid:       orderPayment.AmountPaid,
+			Mismatches:       []string{},
+		}
+
+		ipaymuLog, ok := logsByRef[orderPayment.Uuid.String()]
+		if !ok {
+			row.Mismatches = append(row.Mismatches, "gateway_log_missing")
+		} else {
+			row.GatewayAmount = ipaymuLog.Amount
+			row.Fee = ipaymuLog.Fee
+			row.FeeDirection = ipaymuLog.FeeDirection
+			row.ExpectedNet = ipaymuLog.Amount - ipaymuLog.Fee
+			row.SettledAmount = ipaymuLog.SettledAmount
+			row.SettledAt = ipaymuLog.SettlementAt
+			row.Settled = ipaymuLog.SettlementAt != nil
+
+			// With the fee charged to the buyer iPaymu collects it on top of the payment
+			expectedGatewayAmount := orderPayment.AmountPaid
+			if ipaymuLog.FeeDirection == "BUYER" {
+				expectedGatewayAmount += ipaymuLog.Fee
+			}
+			if !amountMatches(expectedGatewayAmount, ipaymuLog.Amount) {
+				row.Mismatches = append(row.Mismatches, "gateway_amount_mismatch")
+			}
+			if row.Settled && !amountMatches(row.ExpectedNet, ipaymuLog.SettledAmount) {
+				row.Mismatches = append(row.Mismatches, "settled_amount_mismatch")
+			}
+		}
+
+		report.Summary.PaymentCount++
+		report.Summary.GrossAmount += row.AmountPaid
+		report.Summary.GatewayFees += row.Fee
+		if row.Settled {
+			report.Summary.NetSettled += row.SettledAmount
+		} else {
+			report.Summary.UnsettledCount++
+			report.Summary.UnsettledAmount += row.AmountPaid
+		}
+		if len(row.Mismatches) > 0 {
+			report.Summary.MismatchCount++
+		}
+		report.Payments = append(report.Payments, row)
+	}
+
+	return report, nil
+}
+
+// GrossMarginReport sums the revenue and the cost captured at sale of completed orders within a date
+// range, grouped by product, category or outlet. Variants roll up into their product. Add-ons carry
+// no cost of their own and are left out.
+func (s *ReportService) GrossMarginReport(startDate, endDate time.Time, groupBy string, outletUuid *uuid.UUID, userID uint) (*dtos.GrossMarginReport, error) {
+	query := s.DB.Table("order_items").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Joins("JOIN outlets ON outlets.id = orders.outlet_id").
+		Joins("LEFT JOIN product_variants ON product_variants.id = order_items.product_variant_id").
+		Joins("LEFT JOIN products ON products.id = COALESCE(order_items.product_id, product_variants.product_id)").
+		Where("orders.user_id = ? AND orders.status = ? AND orders.created_at BETWEEN ? AND ?", userID, "completed", startDate, endDate.Add(24*time.Hour)).
+		// Wallet top-ups are prepaid balance, not sales
+		Where("NOT EXISTS (SELECT 1 FROM customer_wallet_top_ups WHERE customer_wallet_top_ups.order_id = orders.id)")
+
+	report := &dtos.GrossMarginReport{
+		GroupBy:   groupBy,
+		StartDate: startDate.Format("2006-01-02"),
+		EndDate:   endDate.Format("2006-01-02"),
+		Rows:      []dtos.GrossMarginRow{},
+	}
+
+	if outletUuid != nil {
+		var outlet models.Outlet
+		if err := s.DB.Where("uuid = ? AND user_id = ?", *outletUuid, userID).First(&outlet).Error; err != nil {
+			return nil, errors.New("outlet not found")
+		}
+		query = query.Where("orders.outlet_id = ?", outlet.ID)
+		report.OutletUuid = outlet.Uuid.String()
+	}
+
+	totals := "SUM(order_items.quantity) AS quantity, SUM(order_items.quantity * order_items.price) AS revenue, SUM(order_items.quantity * order_items.cost_price) AS cost"
+	switch groupBy {
+	case "category":
+		query = query.Select("'' AS uuid, COALESCE(products.category, '') AS name, " + totals).
+			Group("COALESCE(products.category, '')")
+	case "outlet":
+		query = query.Select("outlets.uuid::text AS uuid, outlets.name AS name, " + totals).
+			Group("outlets.uuid, outlets.name")
+	default:
+		query = query.Select("COALESCE(products.uuid::text, '') AS uuid, COALESCE(products.name, MIN(order_items.product_name)) AS name, " + totals).
+			Group("products.uuid, products.name")
+	}
+
+	if err := query.Order("revenue DESC").Scan(&report.Rows).Error; err != nil {
+		log.Printf("Error generating gross margin report: %v", err)
+		return nil, errors.New("failed to generate report")
+	}
+
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		row.GrossMargin = row.Revenue - row.Cost
+		row.MarginPercent = marginPercent(row.GrossMargin, row.Revenue)
+
+		report.Summary.Quantity += row.Quantity
+		report.Summary.Revenue += row.Revenue
+		report.Summary.Cost += row.Cost
+	}
+	report.Summary.GrossMargin = report.Summary.Revenue - report.Summary.Cost
+	report.Summary.MarginPercent = marginPercent(report.Summary.GrossMargin, report.Summary.Revenue)
+
+	return report, nil
+}
+
+func marginPercent(margin, revenue float64) float64 {
+	if revenue == 0 {
+		return 0
+	}
+	return math.Round(margin/revenue*10000) / 100
+}
+
+// WasteReport sums the stock written off by applied adjustments within a date range, grouped by
+// reason, product or outlet. Corrections are not waste and are left out.
+func (s *ReportService) WasteReport(startDate, endDate time.Time, groupBy string, outletUuid *uuid.UUID, userID uint) (*dtos.WasteReport, error) {
+	query := s.DB.Table("stock_adjustments").
+		Joins("JOIN outlets ON outlets.id = stock_adjustments.outlet_id").
+		Joins("LEFT JOIN product_variants ON product_variants.id = stock_adjustments.product_variant_id").
+		Joins("LEFT JOIN products ON products.id = COALESCE(stock_adjustments.product_id, product_variants.product_id)").
+		Where("stock_adjustments.user_id = ? AND stock_adjustments.status = ? AND stock_adjustments.reason <> ?", userID, models.StockAdjustmentStatusApplied, models.StockAdjustmentReasonCorrection).
+		Where("stock_adjustments.applied_at BETWEEN ? AND ?", startDate, endDate.Add(24*time.Hour))
+
+	report := &dtos.WasteReport{
+		GroupBy:   groupBy,
+		StartDate: startDate.Format("2006-01-02"),
+		EndDate:   endDate.Format("2006-01-02"),
+		Rows:      []dtos.WasteRow{},
+	}
+
+	if outletUuid != nil {
+		var outlet models.Outlet
+		if err := s.DB.Where("uuid = ? AND user_id = ?", *outletUuid, userID).First(&outlet).Error; err != nil {
+			return nil, errors.New("outlet not found")
+		}
+		query = query.Where("stock_adjustments.outlet_id = ?", outlet.ID)
+		report.OutletUuid = outlet.Uuid.String()
+	}
+
+	totals := "COUNT(*) AS count, SUM(-stock_adjustments.quantity_change) AS quantity, SUM(-stock_adjustments.quantity_change * stock_adjustments.unit_cost) AS value"
+	switch groupBy {
+	case "product":
+		query = query.Select("products.uuid::text AS key, products.name AS name, " + totals).
+			Group("products.uuid, products.name")
+	case "outlet":
+		query = query.Select("outlets.uuid::text AS key, outlets.name AS name, " + totals).
+			Group("outlets.uuid, outlets.name")
+	default:
+		query = query.Select("stock_adjustments.reason AS key, stock_adjustments.reason AS name, " + totals).
+			Group("stock_adjustments.reason")
+	}
+
+	if err := query.Order("value DESC").Scan(&report.Rows).Error; err != nil {
+		log.Printf("Error generating waste report: %v", err)
+		return nil, errors.New("failed to generate report")
+	}
+
+	for _, row := range report.Rows {
+		report.Summary.Count += row.Count
+		report.Summary.Quantity += row.Quantity
+		report.Summary.Value += row.Value
+	}
+
+	return report, nil
+}
@@ -0,0 +1,590 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/redis"
+	"github.com/msyaifudin/pos/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Product types customers may order from a table QR code.
+var selfOrderProductTypes = []string{"retail_item", "fnb_main_product"}
+
+type SelfOrderService struct {
+	DB                  *gorm.DB
+	UserContextService  *UserContextService
+	ProductService      *ProductService
+	OrderService        *OrderService
+	OrderPaymentService *OrderPaymentService
+}
+
+func NewSelfOrderService(db *gorm.DB, userContextService *UserContextService, productService *ProductService, orderService *OrderService, orderPaymentService *OrderPaymentService) *SelfOrderService {
+	return &SelfOrderService{DB: db, UserContextService: userContextService, ProductService: productService, OrderService: orderService, OrderPaymentService: orderPaymentService}
+}
+
+// CreateTable registers a table in an outlet and returns its QR token.
+func (s *SelfOrderService) CreateTable(outletUuid uuid.UUID, req *dtos.CreateOutletTableRequest, userID uint) (*dtos.OutletTableResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	table := models.OutletTable{
+		OutletID:     outlet.ID,
+		Name:         req.Name,
+		TokenVersion: 1,
+		IsActive:     true,
+		UserID:       ownerID,
+	}
+	if err := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Create(&table).Error; err != nil {
+		log.Printf("Error creating outlet table: %v", err)
+		return nil, errors.New("failed to create outlet table")
+	}
+
+	return mapOutletTableToResponse(table), nil
+}
+
+// GetTablesByOutlet lists the tables of an outlet together with their current QR tokens.
+func (s *SelfOrderService) GetTablesByOutlet(outletUuid uuid.UUID, userID uint) ([]dtos.OutletTableResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	var tables []models.OutletTable
+	if err := s.DB.Where("outlet_id = ? AND user_id = ?", outlet.ID, ownerID).Order("name ASC").Find(&tables).Error; err != nil {
+		log.Printf("Error getting outlet tables: %v", err)
+		return nil, errors.New("failed to retrieve outlet tables")
+	}
+
+	responses := []dtos.OutletTableResponse{}
+	for _, table := range tables {
+		responses = append(responses, *mapOutletTableToResponse(table))
+	}
+	return responses, nil
+}
+
+// RotateTableToken invalidates the printed QR code of a table and issues a new token.
+func (s *SelfOrderService) RotateTableToken(tableUuid uuid.UUID, userID uint) (*dtos.OutletTableResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var table models.OutletTable
+	if err := s.DB.Where("uuid = ? AND user_id = ?", tableUuid, ownerID).First(&table).Error; err != nil {
+		return nil, errors.New("outlet table not found")
+	}
+
+	table.TokenVersion++
+	if err := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Save(&table).Error; err != nil {
+		log.Printf("Error rotating outlet table token: %v", err)
+		return nil, errors.New("failed to rotate table token")
+	}
+
+	return mapOutletTableToResponse(table), nil
+}
+
+// resolveTable verifies a table token and loads the active table it belongs to.
+func (s *SelfOrderService) resolveTable(token string) (*models.OutletTable, error) {
+	tableUuid, version, err := utils.ParseTableToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var table models.OutletTable
+	if err := s.DB.Preload("Outlet").Where("uuid = ? AND is_active = ?", tableUuid, true).First(&table).Error; err != nil {
+		return nil, utils.ErrInvalidTableToken
+	}
+
+	// Tokens from a rotated QR code are no longer accepted
+	if table.TokenVersion != version {
+		return nil, utils.ErrInvalidTableToken
+	}
+
+	return &table, nil
+}
+
+// GetPublicMenu returns the orderable products of the outlet a table belongs to.
+func (s *SelfOrderService) GetPublicMenu(token string) (*dtos.PublicMenuResponse, error) {
+	table, err := s.resolveTable(token)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.ProductService.GetProductsByOutlet(table.Outlet.Uuid, table.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	menu := &dtos.PublicMenuResponse{
+		OutletUuid: table.Outlet.Uuid,
+		OutletName: table.Outlet.Name,
+		TableName:  table.Name,
+		Items:      []dtos.PublicMenuItemResponse{},
+	}
+
+	var productUuids []uuid.UUID
+	for _, product := range products {
+		if !isSelfOrderProductType(product.Type) {
+			continue
+		}
+		if product.AvailableQuantity <= 0 {
+			continue // Everything on hand is held by unpaid orders
+		}
+		productUuids = append(productUuids, product.ProductUuid)
+		menu.Items = append(menu.Items, dtos.PublicMenuItemResponse{
+			ProductUuid: product.ProductUuid,
+			ProductName: product.ProductName,
+			Price:       product.Price,
+			Type:        product.Type,
+			Available:   product.AvailableQuantity,
+		})
+	}
+
+	if len(productUuids) == 0 {
+		return menu, nil
+	}
+
+	var productAddOns []models.ProductAddOn
+	if err := s.DB.Preload("Product").Preload("AddOn").
+		Joins("JOIN products ON products.id = product_add_ons.product_id").
+		Where("products.uuid IN ? AND product_add_ons.is_available = ? AND product_add_ons.user_id = ?", productUuids, true, table.UserID).
+		Find(&productAddOns).Error; err != nil {
+		log.Printf("Error getting add-ons for public menu: %v", err)
+		return nil, errors.New("failed to retrieve menu")
+	}
+
+	addOnsByProduct := make(map[uuid.UUID][]dtos.PublicMenuAddOnResponse)
+	for _, pao := range productAddOns {
+		addOnsByProduct[pao.Product.Uuid] = append(addOnsByProduct[pao.Product.Uuid], dtos.PublicMenuAddOnResponse{
+			AddOnUuid: pao.AddOn.Uuid,
+			Name:      pao.AddOn.Name,
+			Price:     pao.AddOn.Price, // Orders are charged at the add-on product's price
+		})
+	}
+	for i := range menu.Items {
+		menu.Items[i].AddOns = addOnsByProduct[menu.Items[i].ProductUuid]
+	}
+
+	return menu, nil
+}
+
+// CreateSelfOrder stores a customer's order for a cashier to confirm. Stock is only
+// checked here; it is deducted once the order is accepted.
+func (s *SelfOrderService) CreateSelfOrder(req *dtos.CreateSelfOrderRequest) (*dtos.SelfOrderResponse, error) {
+	table, err := s.resolveTable(req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowSelfOrder(table.Uuid) {
+		return nil, errors.New("too many self orders for this table, please wait")
+	}
+
+	ownerID := table.UserID
+	selfOrder := models.SelfOrder{
+		OutletID:      table.OutletID,
+		OutletTableID: table.ID,
+		Status:        models.SelfOrderStatusPendingConfirmation,
+		CustomerName:  req.CustomerName,
+		CustomerPhone: req.CustomerPhone,
+		Note:          req.Note,
+		UserID:        ownerID,
+	}
+
+	estimatedTotal := 0.0
+	for _, item := range req.Items {
+		selfOrderItem := models.SelfOrderItem{Quantity: float64(item.Quantity)}
+
+		var stock models.Stock
+		if item.ProductVariantUuid != uuid.Nil {
+			var variant models.ProductVariant
+			if err := s.DB.Preload("Product").Where("uuid = ? AND user_id = ?", item.ProductVariantUuid, ownerID).First(&variant).Error; err != nil || !isSelfOrderProductType(variant.Product.Type) {
+				return nil, errors.New("product variant not found")
+			}
+			if err := s.DB.Where("outlet_id = ? AND product_variant_id = ?", table.OutletID, variant.ID).First(&stock).Error; err != nil {
+				return nil, fmt.Errorf("%s is not available", variant.Name)
+			}
+			selfOrderItem.ProductVariantID = &variant.ID
+			selfOrderItem.ProductName = variant.Name
+			selfOrderItem.Price = variant.Price
+		} else if item.ProductUuid != uuid.Nil {
+			var product models.Product
+			if err := s.DB.Where("uuid = ? AND user_id = ? AND type IN ?", item.ProductUuid, ownerID, selfOrderProductTypes).First(&product).Error; err != nil {
+				return nil, errors.New("product not found")
+			}
+			if err := s.DB.Where("outlet_id = ? AND product_id = ?", table.OutletID, product.ID).First(&stock).Error; err != nil {
+				return nil, fmt.Errorf("%s is not available", product.Name)
+			}
+			selfOrderItem.ProductID = &product.ID
+			selfOrderItem.ProductName = product.Name
+			selfOrderItem.Price = product.Price
+		} else {
+			return nil, errors.New("product_uuid or product_variant_uuid is required for each item")
+		}
+
+		if stock.Quantity-stock.ReservedQuantity < float64(item.Quantity) {
+			return nil, fmt.Errorf("insufficient stock for %s", selfOrderItem.ProductName)
+		}
+
+		for _, addOnReq := range item.AddOns {
+			var addOnProduct models.Product
+			if err := s.DB.Where("uuid = ? AND user_id = ? AND type = ?", addOnReq.AddOnUuid, ownerID, "add_on").First(&addOnProduct).Error; err != nil {
+				return nil, errors.New("add-on product not found or not of type add_on")
+			}
+			estimatedTotal += addOnProduct.Price * float64(addOnReq.Quantity)
+		}
+
+		if len(item.AddOns) > 0 {
+			addOnsJSON, err := json.Marshal(item.AddOns)
+			if err != nil {
+				return nil, errors.New("failed to process add-ons")
+			}
+			selfOrderItem.AddOns = string(addOnsJSON)
+		}
+
+		estimatedTotal += selfOrderItem.Price * selfOrderItem.Quantity
+		selfOrder.Items = append(selfOrder.Items, selfOrderItem)
+	}
+	selfOrder.EstimatedTotal = estimatedTotal
+
+	if err := s.DB.Create(&selfOrder).Error; err != nil {
+		log.Printf("Error creating self order: %v", err)
+		return nil, errors.New("failed to create self order")
+	}
+
+	return s.loadSelfOrderResponse(selfOrder.ID)
+}
+
+// GetPublicSelfOrder lets a customer follow the status of an order placed from their table.
+func (s *SelfOrderService) GetPublicSelfOrder(selfOrderUuid uuid.UUID, token string) (*dtos.SelfOrderResponse, error) {
+	table, err := s.resolveTable(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var selfOrder models.SelfOrder
+	if err := s.DB.Where("uuid = ? AND outlet_table_id = ?", selfOrderUuid, table.ID).First(&selfOrder).Error; err != nil {
+		return nil, errors.New("self order not found")
+	}
+
+	return s.loadSelfOrderResponse(selfOrder.ID)
+}
+
+// GetSelfOrdersByOutlet lists self orders of an outlet, optionally filtered by status.
+func (s *SelfOrderService) GetSelfOrdersByOutlet(outletUuid uuid.UUID, userID uint, status string) ([]dtos.SelfOrderResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	query := s.DB.Preload("Outlet").Preload("OutletTable").Preload("Order").Preload("Items.Product").Preload("Items.ProductVariant").
+		Where("outlet_id = ? AND user_id = ?", outlet.ID, ownerID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var selfOrders []models.SelfOrder
+	if err := query.Order("created_at DESC").Find(&selfOrders).Error; err != nil {
+		log.Printf("Error getting self orders: %v", err)
+		return nil, errors.New("failed to retrieve self orders")
+	}
+
+	responses := []dtos.SelfOrderResponse{}
+	for _, selfOrder := range selfOrders {
+		responses = append(responses, *mapSelfOrderToResponse(selfOrder))
+	}
+	return responses, nil
+}
+
+// AcceptSelfOrder turns a pending self order into a regular order created by the accepting cashier.
+func (s *SelfOrderService) AcceptSelfOrder(selfOrderUuid uuid.UUID, userID uint) (*dtos.SelfOrderResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var selfOrder models.SelfOrder
+	if err := s.DB.Preload("Outlet").Preload("Items.Product").Preload("Items.ProductVariant").Where("uuid = ? AND user_id = ?", selfOrderUuid, ownerID).First(&selfOrder).Error; err != nil {
+		return nil, errors.New("self order not found")
+	}
+
+	orderReq := dtos.CreateOrderRequest{OutletUuid: selfOrder.Outlet.Uuid}
+	for _, item := range selfOrder.Items {
+		orderItem := dtos.OrderItemRequest{Quantity: int(item.Quantity)}
+		if item.ProductVariant != nil {
+			orderItem.ProductVariantUuid = item.ProductVariant.Uuid
+		} else if item.Product != nil {
+			orderItem.ProductUuid = item.Product.Uuid
+		}
+		if item.AddOns != "" {
+			if err := json.Unmarshal([]byte(item.AddOns), &orderItem.AddOns); err != nil {
+				log.Printf("Error unmarshalling self order add-ons: %v", err)
+				return nil, errors.New("failed to process add-ons")
+			}
+		}
+		orderReq.Items = append(orderReq.Items, orderItem)
+	}
+
+	// Claiming the self order, creating its order and linking the two happen in one transaction, so
+	// a failure leaves the self order pending and two cashiers cannot accept it twice
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	claim := tx.Model(&models.SelfOrder{}).
+		Where("id = ? AND status = ?", selfOrder.ID, models.SelfOrderStatusPendingConfirmation).
+		Updates(map[string]interface{}{"status": models.SelfOrderStatusAccepted, "handled_by_id": userID, "handled_at": time.Now()})
+	if claim.Error != nil {
+		tx.Rollback()
+		log.Printf("Error claiming self order: %v", claim.Error)
+		return nil, errors.New("failed to accept self order")
+	}
+	if claim.RowsAffected == 0 {
+		tx.Rollback()
+		return nil, errors.New("self order has already been handled")
+	}
+
+	order, err := s.OrderService.createOrderWithTx(tx, selfOrder.Outlet, orderReq, userID, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Model(&models.SelfOrder{}).Where("id = ?", selfOrder.ID).Update("order_id", order.ID).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error linking order to self order: %v", err)
+		return nil, errors.New("failed to accept self order")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+
+	return s.loadSelfOrderResponse(selfOrder.ID)
+}
+
+// RejectSelfOrder declines a pending self order with a reason shown to the customer.
+func (s *SelfOrderService) RejectSelfOrder(selfOrderUuid uuid.UUID, req *dtos.RejectSelfOrderRequest, userID uint) (*dtos.SelfOrderResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var selfOrder models.SelfOrder
+	if err := s.DB.Where("uuid = ? AND user_id = ?", selfOrderUuid, ownerID).First(&selfOrder).Error; err != nil {
+		return nil, errors.New("self order not found")
+	}
+
+	result := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).
+		Model(&models.SelfOrder{}).
+		Where("id = ? AND status = ?", selfOrder.ID, models.SelfOrderStatusPendingConfirmation).
+		Updates(map[string]interface{}{
+			"status":           models.SelfOrderStatusRejected,
+			"rejection_reason": req.Reason,
+			"handled_by_id":    userID,
+			"handled_at":       time.Now(),
+		})
+	if result.Error != nil {
+		log.Printf("Error rejecting self order: %v", result.Error)
+		return nil, errors.New("failed to reject self order")
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("self order has already been handled")
+	}
+
+	return s.loadSelfOrderResponse(selfOrder.ID)
+}
+
+// PaySelfOrder lets the customer pay the unpaid items of an accepted self order through iPaymu.
+func (s *SelfOrderService) PaySelfOrder(selfOrderUuid uuid.UUID, req *dtos.PaySelfOrderRequest) (*dtos.OrderPaymentResponse, error) {
+	table, err := s.resolveTable(req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	var selfOrder models.SelfOrder
+	if err := s.DB.Preload("Order.OrderItems").Where("uuid = ? AND outlet_table_id = ?", selfOrderUuid, table.ID).First(&selfOrder).Error; err != nil {
+		return nil, errors.New("self order not found")
+	}
+
+	if selfOrder.Status != models.SelfOrderStatusAccepted || selfOrder.Order == nil {
+		return nil, errors.New("self order has not been accepted yet")
+	}
+
+	var paymentMethod models.PaymentMethod
+	if err := s.DB.Where("id = ? AND is_active = ?", req.PaymentMethodID, true).First(&paymentMethod).Error; err != nil {
+		return nil, errors.New("payment method not found or not active")
+	}
+	if paymentMethod.Issuer != "iPaymu" {
+		return nil, errors.New("payment method is not available for self orders")
+	}
+
+	unpaidItemIDs, err := outstandingOrderItemIDs(s.DB, selfOrder.Order.ID)
+	if err != nil {
+		log.Printf("Error checking outstanding items of self order %d: %v", selfOrder.ID, err)
+		return nil, errors.New("failed to process self order payment")
+	}
+
+	if len(unpaidItemIDs) == 0 {
+		// A customer who pays again, e.g. after reloading the page, gets the payment already started
+		var pending models.OrderPayment
+		err := s.DB.Preload("PaymentMethod").
+			Joins("JOIN payment_methods ON payment_methods.id = order_payments.payment_method_id").
+			Where("order_payments.order_id = ? AND order_payments.status = ? AND payment_methods.issuer = ?", selfOrder.Order.ID, models.OrderPaymentStatusPending, "iPaymu").
+			Order("order_payments.created_at DESC").
+			First(&pending).Error
+		if err == nil {
+			return mapOrderPaymentToResponse(pending, selfOrder.Order.Uuid, pending.PaymentMethod.Name), nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Error finding pending payment of self order %d: %v", selfOrder.ID, err)
+			return nil, errors.New("failed to process self order payment")
+		}
+		return nil, errors.New("order is already completed")
+	}
+
+	return s.OrderPaymentService.CreateOrderPayment(dtos.CreateOrderPaymentRequest{
+		OrderUuid:       selfOrder.Order.Uuid,
+		PaymentMethodID: req.PaymentMethodID,
+		OrderItemIDs:    unpaidItemIDs,
+		CustomerName:    req.CustomerName,
+		CustomerEmail:   req.CustomerEmail,
+		CustomerPhone:   req.CustomerPhone,
+	}, table.UserID)
+}
+
+func (s *SelfOrderService) loadSelfOrderResponse(selfOrderID uint) (*dtos.SelfOrderResponse, error) {
+	var selfOrder models.SelfOrder
+	if err := s.DB.Preload("Outlet").Preload("OutletTable").Preload("Order").Preload("Items.Product").Preload("Items.ProductVariant").First(&selfOrder, selfOrderID).Error; err != nil {
+		log.Printf("Error loading self order: %v", err)
+		return nil, errors.New("failed to retrieve self order")
+	}
+	return mapSelfOrderToResponse(selfOrder), nil
+}
+
+// allowSelfOrder applies a per-table rate limit on submitted self orders.
+// Orders are allowed when Redis is unavailable so customers are never blocked by it.
+func allowSelfOrder(tableUuid uuid.UUID) bool {
+	if redis.Rdb == nil {
+		return true
+	}
+
+	limit, err := strconv.Atoi(os.Getenv("SELF_ORDER_RATE_LIMIT"))
+	if err != nil || limit <= 0 {
+		limit = 5
+	}
+	windowMinutes, err := strconv.Atoi(os.Getenv("SELF_ORDER_RATE_WINDOW_MINUTES"))
+	if err != nil || windowMinutes <= 0 {
+		windowMinutes = 10
+	}
+
+	ctx := context.Background()
+	key := fmt.Sprintf("self_order_rate:%s", tableUuid)
+	count, err := redis.Rdb.Incr(ctx, key).Result()
+	if err != nil {
+		log.Printf("Redis error checking self order rate for table %s: %v", tableUuid, err)
+		return true
+	}
+	if count == 1 {
+		redis.Rdb.Expire(ctx, key, time.Duration(windowMinutes)*time.Minute)
+	}
+
+	return count <= int64(limit)
+}
+
+func isSelfOrderProductType(productType string) bool {
+	for _, t := range selfOrderProductTypes {
+		if t == productType {
+			return true
+		}
+	}
+	return false
+}
+
+func mapOutletTableToResponse(table models.OutletTable) *dtos.OutletTableResponse {
+	token := utils.GenerateTableToken(table.Uuid, table.TokenVersion)
+	response := &dtos.OutletTableResponse{
+		Uuid:     table.Uuid,
+		Name:     table.Name,
+		IsActive: table.IsActive,
+		Token:    token,
+	}
+	if baseURL := os.Getenv("SELF_ORDER_BASE_URL"); baseURL != "" {
+		response.OrderURL = fmt.Sprintf("%s?token=%s", strings.TrimRight(baseURL, "/"), token)
+	}
+	return response
+}
+
+func mapSelfOrderToResponse(selfOrder models.SelfOrder) *dtos.SelfOrderResponse {
+	response := &dtos.SelfOrderResponse{
+		Uuid:            selfOrder.Uuid,
+		OutletUuid:      selfOrder.Outlet.Uuid,
+		TableName:       selfOrder.OutletTable.Name,
+		Status:          selfOrder.Status,
+		CustomerName:    selfOrder.CustomerName,
+		CustomerPhone:   selfOrder.CustomerPhone,
+		Note:            selfOrder.Note,
+		EstimatedTotal:  selfOrder.EstimatedTotal,
+		RejectionReason: selfOrder.RejectionReason,
+		CreatedAt:       selfOrder.CreatedAt,
+		HandledAt:       selfOrder.HandledAt,
+		Items:           []dtos.SelfOrderItemResponse{},
+	}
+	if selfOrder.Order != nil {
+		response.OrderUuid = &selfOrder.Order.Uuid
+	}
+
+	for _, item := range selfOrder.Items {
+		itemResponse := dtos.SelfOrderItemResponse{
+			ProductName: item.ProductName,
+			Quantity:    int(item.Quantity),
+			Price:       item.Price,
+		}
+		if item.Product != nil {
+			itemResponse.ProductUuid = item.Product.Uuid
+		}
+		if item.ProductVariant != nil {
+			itemResponse.ProductVariantUuid = item.ProductVariant.Uuid
+		}
+		if item.AddOns != "" {
+			if err := json.Unmarshal([]byte(item.AddOns), &itemResponse.AddOns); err != nil {
+				log.Printf("Error unmarshalling self order add-ons: %v", err)
+			}
+		}
+		response.Items = append(response.Items, itemResponse)
+	}
+	return response
+}
@@ -0,0 +1,427 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/pkg/casbin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockAdjustmentService struct {
+	DB                   *gorm.DB
+	UserContextService   *UserContextService
+	StockMovementService *StockMovementService
+}
+
+func NewStockAdjustmentService(db *gorm.DB, userContextService *UserContextService, stockMovementService *StockMovementService) *StockAdjustmentService {
+	return &StockAdjustmentService{DB: db, UserContextService: userContextService, StockMovementService: stockMovementService}
+}
+
+// stockAdjustmentPhotoDir is where uploaded adjustment photos are stored. They are only served
+// through the API.
+func stockAdjustmentPhotoDir() string {
+	if dir := os.Getenv("STOCK_ADJUSTMENT_PHOTO_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join("storage", "stock-adjustment-photos")
+}
+
+// stockAdjustmentApprovalThreshold is the value, at cost, from which an adjustment waits for a
+// manager's approval. STOCK_ADJUSTMENT_APPROVAL_THRESHOLD defaults to 500000; 0 turns approvals off.
+func stockAdjustmentApprovalThreshold() float64 {
+	threshold, err := strconv.ParseFloat(os.Getenv("STOCK_ADJUSTMENT_APPROVAL_THRESHOLD"), 64)
+	if err != nil || threshold < 0 {
+		return 500000
+	}
+	return threshold
+}
+
+// CreateAdjustment records an adjustment and applies it to stock, unless it is large enough to
+// need approval and the user cannot approve it themselves.
+func (s *StockAdjustmentService) CreateAdjustment(outletUuid uuid.UUID, req *dtos.StockAdjustmentRequest, userID uint) (*dtos.StockAdjustmentResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Reason != models.StockAdjustmentReasonCorrection && req.QuantityChange > 0 {
+		return nil, errors.New("only corrections can add stock")
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	adjustment := models.StockAdjustment{
+		OutletID:       outlet.ID,
+		QuantityChange: req.QuantityChange,
+		Reason:         req.Reason,
+		Note:           req.Note,
+		PhotoURL:       req.PhotoURL,
+		Status:         models.StockAdjustmentStatusApplied,
+		AdjustedBy:     userID,
+		UserID:         ownerID,
+	}
+	if req.ProductVariantUuid != uuid.Nil {
+		var variant models.ProductVariant
+		if err := s.DB.Where("uuid = ? AND user_id = ?", req.ProductVariantUuid, ownerID).First(&variant).Error; err != nil {
+			return nil, errors.New("product variant not found")
+		}
+		adjustment.ProductVariantID = &variant.ID
+	} else if req.ProductUuid != uuid.Nil {
+		var product models.Product
+		if err := s.DB.Where("uuid = ? AND user_id = ?", req.ProductUuid, ownerID).First(&product).Error; err != nil {
+			return nil, errors.New("product not found")
+		}
+		adjustment.ProductID = &product.ID
+	} else {
+		return nil, errors.New("product_uuid or product_variant_uuid is required")
+	}
+
+	adjustment.UnitCost, err = productUnitCost(s.DB, adjustment.ProductID, adjustment.ProductVariantID)
+	if err != nil {
+		log.Printf("Error getting product cost: %v", err)
+		return nil, errors.New("failed to create stock adjustment")
+	}
+
+	threshold := stockAdjustmentApprovalThreshold()
+	if threshold > 0 && math.Abs(adjustment.QuantityChange)*adjustment.UnitCost >= threshold && !s.canApprove(userID) {
+		adjustment.Status = models.StockAdjustmentStatusPending
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if adjustment.Status == models.StockAdjustmentStatusApplied {
+		now := time.Now()
+		adjustment.AppliedAt = &now
+	}
+	if err := tx.Create(&adjustment).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error creating stock adjustment: %v", err)
+		return nil, errors.New("failed to create stock adjustment")
+	}
+	if adjustment.Status == models.StockAdjustmentStatusApplied {
+		if err := s.applyAdjustment(tx, adjustment); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return s.getAdjustment(adjustment.ID)
+}
+
+// GetAdjustmentsByOutlet lists an outlet's adjustments, newest first, optionally of one status or
+// reason.
+func (s *StockAdjustmentService) GetAdjustmentsByOutlet(outletUuid uuid.UUID, status string, reason string, userID uint) ([]dtos.StockAdjustmentResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	query := stockAdjustmentPreloads(s.DB).Where("outlet_id = ? AND user_id = ?", outlet.ID, ownerID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+
+	var adjustments []models.StockAdjustment
+	if err := query.Order("created_at desc").Limit(500).Find(&adjustments).Error; err != nil {
+		log.Printf("Error getting stock adjustments: %v", err)
+		return nil, errors.New("failed to retrieve stock adjustments")
+	}
+
+	responses := make([]dtos.StockAdjustmentResponse, 0, len(adjustments))
+	for _, adjustment := range adjustments {
+		responses = append(responses, *mapStockAdjustmentToResponse(adjustment))
+	}
+	return responses, nil
+}
+
+func (s *StockAdjustmentService) GetAdjustment(adjustmentUuid uuid.UUID, userID uint) (*dtos.StockAdjustmentResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var adjustment models.StockAdjustment
+	if err := stockAdjustmentPreloads(s.DB).Where("uuid = ? AND user_id = ?", adjustmentUuid, ownerID).First(&adjustment).Error; err != nil {
+		return nil, errors.New("stock adjustment not found")
+	}
+	return mapStockAdjustmentToResponse(adjustment), nil
+}
+
+// ApproveAdjustment applies a pending adjustment to stock.
+func (s *StockAdjustmentService) ApproveAdjustment(adjustmentUuid uuid.UUID, req *dtos.ReviewStockAdjustmentRequest, userID uint) (*dtos.StockAdjustmentResponse, error) {
+	return s.reviewAdjustment(adjustmentUuid, req.Note, true, userID)
+}
+
+// RejectAdjustment closes a pending adjustment without touching stock.
+func (s *StockAdjustmentService) RejectAdjustment(adjustmentUuid uuid.UUID, req *dtos.ReviewStockAdjustmentRequest, userID uint) (*dtos.StockAdjustmentResponse, error) {
+	return s.reviewAdjustment(adjustmentUuid, req.Note, false, userID)
+}
+
+func (s *StockAdjustmentService) reviewAdjustment(adjustmentUuid uuid.UUID, note string, approve bool, userID uint) (*dtos.StockAdjustmentResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var adjustment models.StockAdjustment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ? AND user_id = ?", adjustmentUuid, ownerID).First(&adjustment).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("stock adjustment not found")
+	}
+	if adjustment.Status != models.StockAdjustmentStatusPending {
+		tx.Rollback()
+		return nil, errors.New("only pending stock adjustments can be reviewed")
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":      models.StockAdjustmentStatusRejected,
+		"reviewed_by": userID,
+		"reviewed_at": now,
+		"review_note": note,
+	}
+	if approve {
+		if err := s.applyAdjustment(tx, adjustment); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		updates["status"] = models.StockAdjustmentStatusApplied
+		updates["applied_at"] = now
+	}
+	if err := tx.Model(&adjustment).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error reviewing stock adjustment: %v", err)
+		return nil, errors.New("failed to review stock adjustment")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return s.getAdjustment(adjustment.ID)
+}
+
+// UploadPhoto stores a photo of the wasted or damaged stock for an adjustment.
+func (s *StockAdjustmentService) UploadPhoto(adjustmentUuid uuid.UUID, file io.Reader, userID uint) (*dtos.StockAdjustmentResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var adjustment models.StockAdjustment
+	if err := s.DB.Where("uuid = ? AND user_id = ?", adjustmentUuid, ownerID).First(&adjustment).Error; err != nil {
+		return nil, errors.New("stock adjustment not found")
+	}
+
+	// Photos take the same image types and size as proofs of payment
+	data, err := io.ReadAll(io.LimitReader(file, maxPaymentProofSize+1))
+	if err != nil {
+		return nil, errors.New("failed to read photo")
+	}
+	if len(data) > maxPaymentProofSize {
+		return nil, errors.New("photo is too large")
+	}
+	contentType := http.DetectContentType(data)
+	extension, ok := paymentProofExtensions[contentType]
+	if !ok {
+		return nil, errors.New("photo must be a jpeg, png or webp image")
+	}
+
+	dir := stockAdjustmentPhotoDir()
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		log.Printf("Error creating stock adjustment photo directory: %v", err)
+		return nil, errors.New("failed to store photo")
+	}
+	fileName := adjustment.Uuid.String() + extension
+	if err := os.WriteFile(filepath.Join(dir, fileName), data, 0o640); err != nil {
+		log.Printf("Error writing stock adjustment photo: %v", err)
+		return nil, errors.New("failed to store photo")
+	}
+
+	if err := s.DB.Model(&adjustment).Updates(map[string]interface{}{
+		"photo_file_name":    fileName,
+		"photo_content_type": contentType,
+	}).Error; err != nil {
+		log.Printf("Error saving stock adjustment photo: %v", err)
+		return nil, errors.New("failed to store photo")
+	}
+	return s.getAdjustment(adjustment.ID)
+}
+
+// GetPhotoFile returns the path and content type of an adjustment's uploaded photo.
+func (s *StockAdjustmentService) GetPhotoFile(adjustmentUuid uuid.UUID, userID uint) (string, string, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	var adjustment models.StockAdjustment
+	if err := s.DB.Where("uuid = ? AND user_id = ?", adjustmentUuid, ownerID).First(&adjustment).Error; err != nil || adjustment.PhotoFileName == "" {
+		return "", "", errors.New("photo not found")
+	}
+	return filepath.Join(stockAdjustmentPhotoDir(), adjustment.PhotoFileName), adjustment.PhotoContentType, nil
+}
+
+// applyAdjustment posts an adjustment to the outlet's stock. Stock taken out comes out of the
+// lots first-expired-first-out.
+func (s *StockAdjustmentService) applyAdjustment(tx *gorm.DB, adjustment models.StockAdjustment) error {
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("outlet_id = ? AND user_id = ?", adjustment.OutletID, adjustment.UserID)
+	if adjustment.ProductVariantID != nil {
+		query = query.Where("product_variant_id = ?", *adjustment.ProductVariantID)
+	} else {
+		query = query.Where("product_id = ?", *adjustment.ProductID)
+	}
+
+	var stock models.Stock
+	if err := query.First(&stock).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Error finding stock for adjustment: %v", err)
+			return errors.New("failed to apply stock adjustment")
+		}
+		if adjustment.QuantityChange < 0 {
+			return errors.New("adjustment exceeds the available stock")
+		}
+		stock = models.Stock{
+			OutletID:         adjustment.OutletID,
+			ProductID:        adjustment.ProductID,
+			ProductVariantID: adjustment.ProductVariantID,
+			Quantity:         adjustment.QuantityChange,
+			UserID:           adjustment.UserID,
+		}
+		if err := tx.Create(&stock).Error; err != nil {
+			log.Printf("Error creating stock for adjustment: %v", err)
+			return errors.New("failed to apply stock adjustment")
+		}
+	} else {
+		// Stock reserved by unpaid orders cannot be adjusted away
+		if adjustment.QuantityChange < 0 && availableStock(stock)+adjustment.QuantityChange < 0 {
+			return errors.New("adjustment exceeds the available stock")
+		}
+		stock.Quantity += adjustment.QuantityChange
+		if err := tx.Model(&stock).Update("quantity", stock.Quantity).Error; err != nil {
+			log.Printf("Error updating stock for adjustment: %v", err)
+			return errors.New("failed to apply stock adjustment")
+		}
+		if err := consumeStockLots(tx, stock.ID, -adjustment.QuantityChange); err != nil {
+			log.Printf("Error consuming stock lots for adjustment: %v", err)
+			return errors.New("failed to apply stock adjustment")
+		}
+		checkStockLevel(tx, stock)
+	}
+
+	description := adjustment.Reason
+	if adjustment.Note != "" {
+		description += ": " + adjustment.Note
+	}
+	if err := s.StockMovementService.CreateStockMovementWithTx(tx, &models.StockMovement{
+		OutletID:         adjustment.OutletID,
+		ProductID:        adjustment.ProductID,
+		ProductVariantID: adjustment.ProductVariantID,
+		QuantityChange:   adjustment.QuantityChange,
+		MovementType:     models.StockMovementAdjustment,
+		ReferenceType:    models.StockReferenceStockAdjustment,
+		ReferenceID:      &adjustment.ID,
+		Description:      stringPtr(description),
+	}); err != nil {
+		log.Printf("Error recording stock adjustment movement: %v", err)
+		return errors.New("failed to apply stock adjustment")
+	}
+	return nil
+}
+
+// canApprove reports whether the user's role may approve stock adjustments, so their own large
+// adjustments are applied without waiting.
+func (s *StockAdjustmentService) canApprove(userID uint) bool {
+	var user models.User
+	if err := s.DB.Select("id", "role").First(&user, userID).Error; err != nil || casbin.Enforcer == nil {
+		return false
+	}
+	ok, err := casbin.Enforcer.Enforce(user.Role, "stock_adjustments", "approve")
+	return err == nil && ok
+}
+
+func (s *StockAdjustmentService) getAdjustment(id uint) (*dtos.StockAdjustmentResponse, error) {
+	var adjustment models.StockAdjustment
+	if err := stockAdjustmentPreloads(s.DB).First(&adjustment, id).Error; err != nil {
+		return nil, errors.New("stock adjustment not found")
+	}
+	return mapStockAdjustmentToResponse(adjustment), nil
+}
+
+func stockAdjustmentPreloads(db *gorm.DB) *gorm.DB {
+	return db.Preload("Outlet").Preload("Product").Preload("ProductVariant.Product").Preload("Adjuster").Preload("Reviewer")
+}
+
+func mapStockAdjustmentToResponse(adjustment models.StockAdjustment) *dtos.StockAdjustmentResponse {
+	response := &dtos.StockAdjustmentResponse{
+		Uuid:           adjustment.Uuid,
+		OutletUuid:     adjustment.Outlet.Uuid,
+		OutletName:     adjustment.Outlet.Name,
+		QuantityChange: adjustment.QuantityChange,
+		UnitCost:       adjustment.UnitCost,
+		Value:          adjustment.QuantityChange * adjustment.UnitCost,
+		Reason:         adjustment.Reason,
+		Note:           adjustment.Note,
+		PhotoURL:       adjustment.PhotoURL,
+		HasPhoto:       adjustment.PhotoFileName != "",
+		Status:         adjustment.Status,
+		AdjustedBy:     adjustment.Adjuster.Name,
+		AppliedAt:      adjustment.AppliedAt,
+		ReviewedAt:     adjustment.ReviewedAt,
+		ReviewNote:     adjustment.ReviewNote,
+		CreatedAt:      adjustment.CreatedAt.Format(time.RFC3339),
+	}
+	if adjustment.ProductVariant != nil {
+		response.ProductUuid = adjustment.ProductVariant.Product.Uuid
+		response.ProductName = adjustment.ProductVariant.Product.Name
+		response.ProductVariantUuid = &adjustment.ProductVariant.Uuid
+		response.VariantName = adjustment.ProductVariant.Name
+		response.Sku = adjustment.ProductVariant.SKU
+	} else if adjustment.Product != nil {
+		response.ProductUuid = adjustment.Product.Uuid
+		response.ProductName = adjustment.Product.Name
+		response.Sku = adjustment.Product.SKU
+	}
+	if adjustment.Reviewer != nil {
+		response.ReviewedBy = adjustment.Reviewer.Name
+	}
+	return response
+}
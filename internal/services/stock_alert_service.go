@@ -0,0 +1,384 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/internal/redis"
+	"gorm.io/gorm"
+)
+
+type StockAlertService struct {
+	DB                 *gorm.DB
+	UserContextService *UserContextService
+}
+
+func NewStockAlertService(db *gorm.DB, userContextService *UserContextService) *StockAlertService {
+	return &StockAlertService{DB: db, UserContextService: userContextService}
+}
+
+// SetReorderPoint sets the reorder point and par level of a product's stock at an outlet and
+// raises an alert right away when the stock is already low.
+func (s *StockAlertService) SetReorderPoint(req dtos.SetReorderPointRequest, outletUuid uuid.UUID, userID uint) (*dtos.StockLevelResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.ReorderPoint != nil && req.ParLevel != nil && *req.ParLevel < *req.ReorderPoint {
+		return nil, errors.New("par level must not be below the reorder point")
+	}
+
+	db := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID))
+
+	var outlet models.Outlet
+	if err := db.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	query := db.Where("outlet_id = ? AND user_id = ?", outlet.ID, ownerID)
+	if req.ProductVariantUuid != uuid.Nil {
+		query = query.Where("product_variant_id = (SELECT id FROM product_variants WHERE uuid = ? AND user_id = ?)", req.ProductVariantUuid, ownerID)
+	} else {
+		query = query.Where("product_id = (SELECT id FROM products WHERE uuid = ? AND user_id = ?)", req.ProductUuid, ownerID)
+	}
+
+	var stock models.Stock
+	if err := query.First(&stock).Error; err != nil {
+		return nil, errors.New("stock not found")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&stock).Updates(map[string]interface{}{
+			"reorder_point": req.ReorderPoint,
+			"par_level":     req.ParLevel,
+		}).Error; err != nil {
+			return err
+		}
+		stock.ReorderPoint = req.ReorderPoint
+		stock.ParLevel = req.ParLevel
+
+		if stock.ReorderPoint == nil {
+			return resolveStockAlerts(tx, stock)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error setting reorder point: %v", err)
+		return nil, errors.New("failed to set reorder point")
+	}
+	checkStockLevel(db, stock)
+
+	if err := db.Preload("Outlet").Preload("Product").Preload("ProductVariant.Product").First(&stock, stock.ID).Error; err != nil {
+		return nil, errors.New("stock not found")
+	}
+	return mapStockToStockLevelResponse(stock), nil
+}
+
+// GetLowStock lists the owner's stocks that are at or below their reorder point, the lowest first.
+// outletUuid narrows the list to one outlet.
+func (s *StockAlertService) GetLowStock(outletUuid *uuid.UUID, userID uint) ([]dtos.StockLevelResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.DB.Preload("Outlet").Preload("Product").Preload("ProductVariant.Product").
+		Where("user_id = ? AND reorder_point IS NOT NULL AND quantity <= reorder_point", ownerID)
+	if outletUuid != nil {
+		query = query.Where("outlet_id = (SELECT id FROM outlets WHERE uuid = ? AND user_id = ?)", *outletUuid, ownerID)
+	}
+
+	var stocks []models.Stock
+	if err := query.Order("quantity - reorder_point").Find(&stocks).Error; err != nil {
+		log.Printf("Error getting low stock: %v", err)
+		return nil, errors.New("failed to retrieve low stock")
+	}
+
+	responses := make([]dtos.StockLevelResponse, 0, len(stocks))
+	for _, stock := range stocks {
+		responses = append(responses, *mapStockToStockLevelResponse(stock))
+	}
+	return responses, nil
+}
+
+// GetAlerts lists the owner's stock alerts with the given status, or the unresolved ones when no
+// status is given.
+func (s *StockAlertService) GetAlerts(status string, outletUuid *uuid.UUID, userID uint) ([]dtos.StockAlertResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.DB.Preload("Stock.Outlet").Preload("Stock.Product").Preload("Stock.ProductVariant.Product").
+		Where("user_id = ?", ownerID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	} else {
+		query = query.Where("status IN ?", []string{models.StockAlertStatusOpen, models.StockAlertStatusAcknowledged})
+	}
+	if outletUuid != nil {
+		query = query.Where("outlet_id = (SELECT id FROM outlets WHERE uuid = ? AND user_id = ?)", *outletUuid, ownerID)
+	}
+
+	var alerts []models.StockAlert
+	if err := query.Order("created_at desc").Limit(200).Find(&alerts).Error; err != nil {
+		log.Printf("Error getting stock alerts: %v", err)
+		return nil, errors.New("failed to retrieve stock alerts")
+	}
+
+	responses := make([]dtos.StockAlertResponse, 0, len(alerts))
+	for _, alert := range alerts {
+		responses = append(responses, *mapStockAlertToResponse(alert))
+	}
+	return responses, nil
+}
+
+// AcknowledgeAlert marks an alert as seen. It stays on the list until the stock is restocked.
+func (s *StockAlertService) AcknowledgeAlert(alertUuid uuid.UUID, userID uint) (*dtos.StockAlertResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID))
+
+	var alert models.StockAlert
+	if err := db.Where("uuid = ? AND user_id = ?", alertUuid, ownerID).First(&alert).Error; err != nil {
+		return nil, errors.New("stock alert not found")
+	}
+	if alert.Status == models.StockAlertStatusResolved {
+		return nil, errors.New("stock alert is already resolved")
+	}
+
+	if alert.Status == models.StockAlertStatusOpen {
+		now := time.Now()
+		if err := db.Model(&alert).Updates(map[string]interface{}{
+			"status":          models.StockAlertStatusAcknowledged,
+			"acknowledged_at": now,
+			"acknowledged_by": userID,
+		}).Error; err != nil {
+			log.Printf("Error acknowledging stock alert: %v", err)
+			return nil, errors.New("failed to acknowledge stock alert")
+		}
+	}
+
+	if err := db.Preload("Stock.Outlet").Preload("Stock.Product").Preload("Stock.ProductVariant.Product").First(&alert, alert.ID).Error; err != nil {
+		return nil, errors.New("stock alert not found")
+	}
+	return mapStockAlertToResponse(alert), nil
+}
+
+// NotifyPendingAlerts emails every owner one digest of their newly raised alerts. A stock is
+// emailed about at most once per STOCK_ALERT_COOLDOWN_HOURS (default 24), tracked in Redis, so a
+// stock that keeps dipping below its reorder point does not flood the inbox. It is run by the
+// stock alert worker.
+func (s *StockAlertService) NotifyPendingAlerts() error {
+	var alerts []models.StockAlert
+	if err := s.DB.Preload("Stock.Outlet").Preload("Stock.Product").Preload("Stock.ProductVariant.Product").
+		Where("status = ? AND notified_at IS NULL", models.StockAlertStatusOpen).
+		Order("id").
+		Limit(500).
+		Find(&alerts).Error; err != nil {
+		return fmt.Errorf("failed to load pending stock alerts: %w", err)
+	}
+
+	byOwner := make(map[uint][]models.StockAlert)
+	for _, alert := range alerts {
+		byOwner[alert.UserID] = append(byOwner[alert.UserID], alert)
+	}
+
+	for ownerID, ownerAlerts := range byOwner {
+		if err := s.notifyOwner(ownerID, ownerAlerts); err != nil {
+			log.Printf("Error sending stock alerts to owner %d: %v", ownerID, err)
+		}
+	}
+	return nil
+}
+
+func (s *StockAlertService) notifyOwner(ownerID uint, alerts []models.StockAlert) error {
+	var owner models.User
+	if err := s.DB.First(&owner, ownerID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	var claimed []uint
+	data := LowStockAlertEmail{OwnerName: owner.Name}
+	for _, alert := range alerts {
+		if !claimStockAlertNotification(alert.StockID) {
+			continue
+		}
+		claimed = append(claimed, alert.StockID)
+
+		level := mapStockToStockLevelResponse(alert.Stock)
+		name := level.ProductName
+		if level.VariantName != "" {
+			name += " - " + level.VariantName
+		}
+		data.Items = append(data.Items, LowStockAlertEmailItem{
+			OutletName:             level.OutletName,
+			Name:                   name,
+			Quantity:               formatQuantity(alert.Quantity),
+			ReorderPoint:           formatQuantity(alert.ReorderPoint),
+			SuggestedOrderQuantity: formatQuantity(level.SuggestedOrderQuantity),
+		})
+	}
+
+	if len(data.Items) > 0 {
+		if err := SendLowStockAlertEmail(owner.Email, data); err != nil {
+			// Try again on the next run
+			releaseStockAlertNotifications(claimed)
+			return err
+		}
+	}
+
+	ids := make([]uint, 0, len(alerts))
+	for _, alert := range alerts {
+		ids = append(ids, alert.ID)
+	}
+	return s.DB.Model(&models.StockAlert{}).Where("id IN ?", ids).Update("notified_at", time.Now()).Error
+}
+
+// checkStockLevel raises a low stock alert when a stock is at or below its reorder point and
+// resolves its alerts once it is back above it. It runs under a savepoint, so a failure is only
+// logged and never breaks the caller's stock change.
+func checkStockLevel(tx *gorm.DB, stock models.Stock) {
+	if stock.ReorderPoint == nil {
+		return
+	}
+
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		if stock.Quantity > *stock.ReorderPoint {
+			return resolveStockAlerts(tx, stock)
+		}
+		return raiseStockAlert(tx, stock)
+	})
+	if err != nil {
+		log.Printf("Error checking stock level of stock %d: %v", stock.ID, err)
+	}
+}
+
+// raiseStockAlert opens an alert for a low stock unless one is still unresolved.
+func raiseStockAlert(tx *gorm.DB, stock models.Stock) error {
+	var unresolved int64
+	if err := tx.Model(&models.StockAlert{}).
+		Where("stock_id = ? AND status <> ?", stock.ID, models.StockAlertStatusResolved).
+		Count(&unresolved).Error; err != nil {
+		return err
+	}
+	if unresolved > 0 {
+		return nil
+	}
+
+	return tx.Create(&models.StockAlert{
+		StockID:      stock.ID,
+		OutletID:     stock.OutletID,
+		Quantity:     stock.Quantity,
+		ReorderPoint: *stock.ReorderPoint,
+		ParLevel:     stock.ParLevel,
+		Status:       models.StockAlertStatusOpen,
+		UserID:       stock.UserID,
+	}).Error
+}
+
+func resolveStockAlerts(tx *gorm.DB, stock models.Stock) error {
+	return tx.Model(&models.StockAlert{}).
+		Where("stock_id = ? AND status <> ?", stock.ID, models.StockAlertStatusResolved).
+		Updates(map[string]interface{}{"status": models.StockAlertStatusResolved, "resolved_at": time.Now()}).Error
+}
+
+func stockAlertCooldown() time.Duration {
+	hours, err := strconv.Atoi(os.Getenv("STOCK_ALERT_COOLDOWN_HOURS"))
+	if err != nil || hours <= 0 {
+		hours = 24
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// claimStockAlertNotification reports whether a stock may be emailed about now, starting its
+// cooldown when it may. Without Redis every alert is emailed.
+func claimStockAlertNotification(stockID uint) bool {
+	if redis.Rdb == nil {
+		return true
+	}
+	ok, err := redis.Rdb.SetNX(context.Background(), fmt.Sprintf("stock_alert_notified:%d", stockID), "1", stockAlertCooldown()).Result()
+	if err != nil {
+		log.Printf("Redis error checking stock alert cooldown for stock %d: %v", stockID, err)
+		return true
+	}
+	return ok
+}
+
+func releaseStockAlertNotifications(stockIDs []uint) {
+	if redis.Rdb == nil || len(stockIDs) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(stockIDs))
+	for _, id := range stockIDs {
+		keys = append(keys, fmt.Sprintf("stock_alert_notified:%d", id))
+	}
+	if err := redis.Rdb.Del(context.Background(), keys...).Err(); err != nil {
+		log.Printf("Redis error releasing stock alert cooldowns: %v", err)
+	}
+}
+
+// formatQuantity prints a stock quantity without trailing zeros.
+func formatQuantity(quantity float64) string {
+	return strconv.FormatFloat(quantity, 'f', -1, 64)
+}
+
+func mapStockToStockLevelResponse(stock models.Stock) *dtos.StockLevelResponse {
+	response := &dtos.StockLevelResponse{
+		StockUuid:    stock.Uuid,
+		OutletUuid:   stock.Outlet.Uuid,
+		OutletName:   stock.Outlet.Name,
+		Quantity:     stock.Quantity,
+		ReorderPoint: stock.ReorderPoint,
+		ParLevel:     stock.ParLevel,
+	}
+	if stock.ProductVariant != nil {
+		response.ProductUuid = stock.ProductVariant.Product.Uuid
+		response.ProductName = stock.ProductVariant.Product.Name
+		response.ProductVariantUuid = &stock.ProductVariant.Uuid
+		response.VariantName = stock.ProductVariant.Name
+		response.Sku = stock.ProductVariant.SKU
+	} else if stock.Product != nil {
+		response.ProductUuid = stock.Product.Uuid
+		response.ProductName = stock.Product.Name
+		response.Sku = stock.Product.SKU
+	}
+
+	if stock.ReorderPoint != nil {
+		target := *stock.ReorderPoint
+		if stock.ParLevel != nil {
+			target = *stock.ParLevel
+		}
+		if target > stock.Quantity {
+			response.SuggestedOrderQuantity = target - stock.Quantity
+		}
+	}
+	return response
+}
+
+func mapStockAlertToResponse(alert models.StockAlert) *dtos.StockAlertResponse {
+	return &dtos.StockAlertResponse{
+		Uuid:           alert.Uuid,
+		Stock:          *mapStockToStockLevelResponse(alert.Stock),
+		Quantity:       alert.Quantity,
+		ReorderPoint:   alert.ReorderPoint,
+		ParLevel:       alert.ParLevel,
+		Status:         alert.Status,
+		NotifiedAt:     alert.NotifiedAt,
+		AcknowledgedAt: alert.AcknowledgedAt,
+		ResolvedAt:     alert.ResolvedAt,
+		CreatedAt:      alert.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// StartStockAlertWorker emails owners about newly raised low stock alerts.
+// The interval is STOCK_ALERT_INTERVAL_MINUTES (default 5).
+func StartStockAlertWorker(stockAlertService *StockAlertService) {
+	minutes, err := strconv.Atoi(os.Getenv("STOCK_ALERT_INTERVAL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 5
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(minutes) * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := stockAlertService.NotifyPendingAlerts(); err != nil {
+				log.Printf("Stock alert notification failed: %v", err)
+			}
+		}
+	}()
+}
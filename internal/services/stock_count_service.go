@@ -0,0 +1,699 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockCountService struct {
+	DB                   *gorm.DB
+	UserContextService   *UserContextService
+	StockMovementService *StockMovementService
+}
+
+func NewStockCountService(db *gorm.DB, userContextService *UserContextService, stockMovementService *StockMovementService) *StockCountService {
+	return &StockCountService{DB: db, UserContextService: userContextService, StockMovementService: stockMovementService}
+}
+
+// StartCount opens a count at an outlet and snapshots the expected quantity and unit price of
+// every stock there. An outlet has at most one open count.
+func (s *StockCountService) StartCount(outletUuid uuid.UUID, req dtos.StartStockCountRequest, userID uint) (*dtos.StockCountResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var outlet models.Outlet
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("outlet not found")
+	}
+
+	var open int64
+	if err := tx.Model(&models.StockCount{}).
+		Where("outlet_id = ? AND status IN ?", outlet.ID, []string{models.StockCountStatusCounting, models.StockCountStatusSubmitted}).
+		Count(&open).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error checking open stock counts: %v", err)
+		return nil, errors.New("failed to start stock count")
+	}
+	if open > 0 {
+		tx.Rollback()
+		return nil, errors.New("outlet already has an open stock count")
+	}
+
+	count := models.StockCount{
+		OutletID:   outlet.ID,
+		Status:     models.StockCountStatusCounting,
+		BlindCount: req.BlindCount,
+		Note:       req.Note,
+		UserID:     ownerID,
+	}
+	if err := tx.Create(&count).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error creating stock count: %v", err)
+		return nil, errors.New("failed to start stock count")
+	}
+
+	var stocks []models.Stock
+	if err := tx.Preload("Product").Preload("ProductVariant").
+		Where("outlet_id = ? AND user_id = ?", outlet.ID, ownerID).Order("id").Find(&stocks).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error getting stocks to count: %v", err)
+		return nil, errors.New("failed to start stock count")
+	}
+
+	if len(stocks) > 0 {
+		items := make([]models.StockCountItem, 0, len(stocks))
+		for _, stock := range stocks {
+			items = append(items, newStockCountItem(count.ID, stock))
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			tx.Rollback()
+			log.Printf("Error creating stock count items: %v", err)
+			return nil, errors.New("failed to start stock count")
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return s.getCount(count.ID)
+}
+
+// GetCountsByOutlet lists an outlet's counts, newest first, without their items.
+func (s *StockCountService) GetCountsByOutlet(outletUuid uuid.UUID, status string, userID uint) ([]dtos.StockCountResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	query := s.DB.Preload("Outlet").Preload("Items").Where("outlet_id = ? AND user_id = ?", outlet.ID, ownerID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var counts []models.StockCount
+	if err := query.Order("created_at desc").Find(&counts).Error; err != nil {
+		log.Printf("Error getting stock counts: %v", err)
+		return nil, errors.New("failed to retrieve stock counts")
+	}
+
+	responses := make([]dtos.StockCountResponse, 0, len(counts))
+	for _, count := range counts {
+		response := mapStockCountToResponse(count)
+		response.Items = nil
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+func (s *StockCountService) GetCount(countUuid uuid.UUID, userID uint) (*dtos.StockCountResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var count models.StockCount
+	if err := stockCountPreloads(s.DB).Where("uuid = ? AND user_id = ?", countUuid, ownerID).First(&count).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("stock count not found")
+		}
+		log.Printf("Error getting stock count: %v", err)
+		return nil, errors.New("failed to retrieve stock count")
+	}
+	return mapStockCountToResponse(count), nil
+}
+
+// AddEntries records counted quantities. Products without a stock record when the count started
+// are added to the count as they are found.
+func (s *StockCountService) AddEntries(countUuid uuid.UUID, req dtos.AddStockCountEntriesRequest, userID uint) (*dtos.StockCountResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	count, err := lockStockCount(tx, countUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if count.Status != models.StockCountStatusCounting {
+		tx.Rollback()
+		return nil, errors.New("stock count is not open for counting")
+	}
+
+	for _, entryReq := range req.Items {
+		item, err := findOrAddStockCountItem(tx, *count, entryReq)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		entry := models.StockCountEntry{
+			StockCountID:     count.ID,
+			StockCountItemID: item.ID,
+			Quantity:         *entryReq.Quantity,
+			CountedBy:        userID,
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			tx.Rollback()
+			log.Printf("Error creating stock count entry: %v", err)
+			return nil, errors.New("failed to record stock count")
+		}
+		if err := refreshCountedQuantity(tx, item.ID); err != nil {
+			tx.Rollback()
+			log.Printf("Error updating counted quantity: %v", err)
+			return nil, errors.New("failed to record stock count")
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return s.getCount(count.ID)
+}
+
+// DeleteEntry removes a mistaken count entry while the count is still open.
+func (s *StockCountService) DeleteEntry(countUuid uuid.UUID, entryUuid uuid.UUID, userID uint) (*dtos.StockCountResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	count, err := lockStockCount(tx, countUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if count.Status != models.StockCountStatusCounting {
+		tx.Rollback()
+		return nil, errors.New("stock count is not open for counting")
+	}
+
+	var entry models.StockCountEntry
+	if err := tx.Where("uuid = ? AND stock_count_id = ?", entryUuid, count.ID).First(&entry).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("stock count entry not found")
+	}
+	if err := tx.Delete(&entry).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error deleting stock count entry: %v", err)
+		return nil, errors.New("failed to delete stock count entry")
+	}
+	if err := refreshCountedQuantity(tx, entry.StockCountItemID); err != nil {
+		tx.Rollback()
+		log.Printf("Error updating counted quantity: %v", err)
+		return nil, errors.New("failed to delete stock count entry")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return s.getCount(count.ID)
+}
+
+// SubmitCount closes counting and hands the count to a manager for approval. Blind counts show
+// their variances from here on.
+func (s *StockCountService) SubmitCount(countUuid uuid.UUID, userID uint) (*dtos.StockCountResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	count, err := lockStockCount(tx, countUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if count.Status != models.StockCountStatusCounting {
+		tx.Rollback()
+		return nil, errors.New("stock count is not open for counting")
+	}
+
+	var counted int64
+	if err := tx.Model(&models.StockCountItem{}).Where("stock_count_id = ? AND counted_quantity IS NOT NULL", count.ID).Count(&counted).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error checking counted items: %v", err)
+		return nil, errors.New("failed to submit stock count")
+	}
+	if counted == 0 {
+		tx.Rollback()
+		return nil, errors.New("stock count has no counted items")
+	}
+
+	if err := tx.Model(count).Updates(map[string]interface{}{
+		"status":       models.StockCountStatusSubmitted,
+		"submitted_at": time.Now(),
+		"submitted_by": userID,
+	}).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error submitting stock count: %v", err)
+		return nil, errors.New("failed to submit stock count")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return s.getCount(count.ID)
+}
+
+// ApproveCount posts the variance of every counted item as a stock adjustment. Variances are
+// applied on top of the current quantity, so sales made while counting are kept. Items nobody
+// counted are left alone.
+func (s *StockCountService) ApproveCount(countUuid uuid.UUID, userID uint) (*dtos.StockCountResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	count, err := lockStockCount(tx, countUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if count.Status != models.StockCountStatusSubmitted {
+		tx.Rollback()
+		return nil, errors.New("only submitted stock counts can be approved")
+	}
+
+	var items []models.StockCountItem
+	if err := tx.Where("stock_count_id = ? AND counted_quantity IS NOT NULL", count.ID).Order("id").Find(&items).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error getting stock count items: %v", err)
+		return nil, errors.New("failed to approve stock count")
+	}
+
+	var productIDs, variantIDs []uint
+	for _, item := range items {
+		if item.ProductVariantID != nil {
+			variantIDs = append(variantIDs, *item.ProductVariantID)
+		} else if item.ProductID != nil {
+			productIDs = append(productIDs, *item.ProductID)
+		}
+	}
+	if err := lockStocks(tx, count.OutletID, productIDs, variantIDs); err != nil {
+		tx.Rollback()
+		log.Printf("Error locking stocks for stock count: %v", err)
+		return nil, errors.New("failed to approve stock count")
+	}
+
+	for _, item := range items {
+		variance := *item.CountedQuantity - item.ExpectedQuantity
+		if variance == 0 {
+			continue
+		}
+		if err := s.postCountVariance(tx, *count, item, variance); err != nil {
+			tx.Rollback()
+			log.Printf("Error posting stock count variance: %v", err)
+			return nil, errors.New("failed to approve stock count")
+		}
+	}
+
+	if err := tx.Model(count).Updates(map[string]interface{}{
+		"status":      models.StockCountStatusApproved,
+		"approved_at": time.Now(),
+		"approved_by": userID,
+	}).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error approving stock count: %v", err)
+		return nil, errors.New("failed to approve stock count")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return s.getCount(count.ID)
+}
+
+func (s *StockCountService) CancelCount(countUuid uuid.UUID, userID uint) (*dtos.StockCountResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	count, err := lockStockCount(tx, countUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if count.Status != models.StockCountStatusCounting && count.Status != models.StockCountStatusSubmitted {
+		tx.Rollback()
+		return nil, errors.New("stock count can no longer be cancelled")
+	}
+
+	if err := tx.Model(count).Updates(map[string]interface{}{
+		"status":       models.StockCountStatusCancelled,
+		"cancelled_at": time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error cancelling stock count: %v", err)
+		return nil, errors.New("failed to cancel stock count")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return s.getCount(count.ID)
+}
+
+// GetVarianceReport compares every item's counted quantity with its snapshot. A blind count keeps
+// its variances hidden until it is submitted.
+func (s *StockCountService) GetVarianceReport(countUuid uuid.UUID, userID uint) (*dtos.StockCountVarianceReport, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var count models.StockCount
+	if err := stockCountPreloads(s.DB).Where("uuid = ? AND user_id = ?", countUuid, ownerID).First(&count).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("stock count not found")
+		}
+		log.Printf("Error getting stock count: %v", err)
+		return nil, errors.New("failed to retrieve stock count")
+	}
+	if stockCountIsBlind(count) {
+		return nil, errors.New("variances are hidden until the blind count is submitted")
+	}
+
+	report := &dtos.StockCountVarianceReport{
+		StockCountUuid: count.Uuid,
+		OutletName:     count.Outlet.Name,
+		Status:         count.Status,
+		Rows:           make([]dtos.StockCountVarianceRow, 0, len(count.Items)),
+	}
+	for _, item := range count.Items {
+		productName, variantName, sku := stockCountItemProduct(item)
+		row := dtos.StockCountVarianceRow{
+			ProductName:      productName,
+			VariantName:      variantName,
+			Sku:              sku,
+			ExpectedQuantity: item.ExpectedQuantity,
+			CountedQuantity:  item.CountedQuantity,
+			UnitPrice:        item.UnitPrice,
+		}
+		report.Summary.ItemCount++
+		if item.CountedQuantity != nil {
+			report.Summary.CountedCount++
+			row.Variance = *item.CountedQuantity - item.ExpectedQuantity
+			row.VarianceValue = row.Variance * item.UnitPrice
+			if row.Variance != 0 {
+				report.Summary.VarianceCount++
+			}
+			if row.VarianceValue < 0 {
+				report.Summary.ShortageValue -= row.VarianceValue
+			} else {
+				report.Summary.SurplusValue += row.VarianceValue
+			}
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	report.Summary.NetVarianceValue = report.Summary.SurplusValue - report.Summary.ShortageValue
+	return report, nil
+}
+
+func (s *StockCountService) getCount(countID uint) (*dtos.StockCountResponse, error) {
+	var count models.StockCount
+	if err := stockCountPreloads(s.DB).First(&count, countID).Error; err != nil {
+		return nil, errors.New("stock count not found")
+	}
+	return mapStockCountToResponse(count), nil
+}
+
+// postCountVariance adjusts the counted stock by its variance, never below zero, and records the
+// adjustment as a stock movement referencing the count.
+func (s *StockCountService) postCountVariance(tx *gorm.DB, count models.StockCount, item models.StockCountItem, variance float64) error {
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("outlet_id = ? AND user_id = ?", count.OutletID, count.UserID)
+	if item.ProductVariantID != nil {
+		query = query.Where("product_variant_id = ?", *item.ProductVariantID)
+	} else {
+		query = query.Where("product_id = ?", *item.ProductID)
+	}
+
+	var stock models.Stock
+	var change float64
+	if err := query.First(&stock).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if variance < 0 {
+			return nil
+		}
+		stock = models.Stock{
+			OutletID:         count.OutletID,
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			Quantity:         variance,
+			UserID:           count.UserID,
+		}
+		if err := tx.Create(&stock).Error; err != nil {
+			return err
+		}
+		change = variance
+	} else {
+		quantity := stock.Quantity + variance
+		if quantity < 0 {
+			quantity = 0
+		}
+		change = quantity - stock.Quantity
+		stock.Quantity = quantity
+		if err := tx.Model(&stock).Update("quantity", stock.Quantity).Error; err != nil {
+			return err
+		}
+		if err := consumeStockLots(tx, stock.ID, -change); err != nil {
+			return err
+		}
+		checkStockLevel(tx, stock)
+	}
+
+	return s.StockMovementService.CreateStockMovementWithTx(tx, &models.StockMovement{
+		OutletID:         count.OutletID,
+		ProductID:        item.ProductID,
+		ProductVariantID: item.ProductVariantID,
+		QuantityChange:   change,
+		MovementType:     models.StockMovementStockCount,
+		ReferenceType:    models.StockReferenceStockCount,
+		ReferenceID:      &count.ID,
+		Description:      stringPtr("Stock count variance"),
+	})
+}
+
+// lockStockCount loads a count and locks it for the rest of the transaction, so counting,
+// submitting and approving the same count run one at a time.
+func lockStockCount(tx *gorm.DB, countUuid uuid.UUID, ownerID uint) (*models.StockCount, error) {
+	var count models.StockCount
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ? AND user_id = ?", countUuid, ownerID).First(&count).Error; err != nil {
+		return nil, errors.New("stock count not found")
+	}
+	return &count, nil
+}
+
+// findOrAddStockCountItem finds the count's item for a product, adding one when the product was
+// not stocked at the outlet when the count started.
+func findOrAddStockCountItem(tx *gorm.DB, count models.StockCount, req dtos.StockCountEntryRequest) (*models.StockCountItem, error) {
+	stockQuery := tx.Preload("Product").Preload("ProductVariant").Where("outlet_id = ? AND user_id = ?", count.OutletID, count.UserID)
+	item := models.StockCountItem{StockCountID: count.ID}
+
+	if req.ProductVariantUuid != uuid.Nil {
+		var variant models.ProductVariant
+		if err := tx.Where("uuid = ? AND user_id = ?", req.ProductVariantUuid, count.UserID).First(&variant).Error; err != nil {
+			return nil, errors.New("product variant not found")
+		}
+		if err := tx.Where("stock_count_id = ? AND product_variant_id = ?", count.ID, variant.ID).First(&item).Error; err == nil {
+			return &item, nil
+		}
+		item.ProductVariantID = &variant.ID
+		item.UnitPrice = variant.Price
+		stockQuery = stockQuery.Where("product_variant_id = ?", variant.ID)
+	} else {
+		var product models.Product
+		if err := tx.Where("uuid = ? AND user_id = ?", req.ProductUuid, count.UserID).First(&product).Error; err != nil {
+			return nil, errors.New("product not found")
+		}
+		if err := tx.Where("stock_count_id = ? AND product_id = ?", count.ID, product.ID).First(&item).Error; err == nil {
+			return &item, nil
+		}
+		item.ProductID = &product.ID
+		item.UnitPrice = product.Price
+		stockQuery = stockQuery.Where("product_id = ?", product.ID)
+	}
+
+	var stock models.Stock
+	if err := stockQuery.First(&stock).Error; err == nil {
+		item = newStockCountItem(count.ID, stock)
+	}
+	if err := tx.Create(&item).Error; err != nil {
+		log.Printf("Error adding stock count item: %v", err)
+		return nil, errors.New("failed to record stock count")
+	}
+	return &item, nil
+}
+
+// refreshCountedQuantity sets an item's counted quantity to the sum of its entries, or clears it
+// when none are left.
+func refreshCountedQuantity(tx *gorm.DB, itemID uint) error {
+	var result struct {
+		Total   float64
+		Entries int64
+	}
+	if err := tx.Model(&models.StockCountEntry{}).
+		Select("COALESCE(SUM(quantity), 0) AS total, COUNT(*) AS entries").
+		Where("stock_count_item_id = ?", itemID).
+		Scan(&result).Error; err != nil {
+		return err
+	}
+
+	var counted *float64
+	if result.Entries > 0 {
+		counted = &result.Total
+	}
+	return tx.Model(&models.StockCountItem{}).Where("id = ?", itemID).Update("counted_quantity", counted).Error
+}
+
+func newStockCountItem(countID uint, stock models.Stock) models.StockCountItem {
+	item := models.StockCountItem{
+		StockCountID:     countID,
+		StockID:          &stock.ID,
+		ProductID:        stock.ProductID,
+		ProductVariantID: stock.ProductVariantID,
+		ExpectedQuantity: stock.Quantity,
+	}
+	if stock.ProductVariant != nil {
+		item.UnitPrice = stock.ProductVariant.Price
+	} else if stock.Product != nil {
+		item.UnitPrice = stock.Product.Price
+	}
+	return item
+}
+
+func stockCountIsBlind(count models.StockCount) bool {
+	return count.BlindCount && count.Status == models.StockCountStatusCounting
+}
+
+func stockCountItemProduct(item models.StockCountItem) (string, string, string) {
+	if item.ProductVariant != nil {
+		return item.ProductVariant.Product.Name, item.ProductVariant.Name, item.ProductVariant.SKU
+	}
+	if item.Product != nil {
+		return item.Product.Name, "", item.Product.SKU
+	}
+	return "", "", ""
+}
+
+func stockCountPreloads(db *gorm.DB) *gorm.DB {
+	return db.Preload("Outlet").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Product").Preload("Items.ProductVariant.Product").
+		Preload("Items.Entries", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Entries.Counter")
+}
+
+func mapStockCountToResponse(count models.StockCount) *dtos.StockCountResponse {
+	blind := stockCountIsBlind(count)
+	response := &dtos.StockCountResponse{
+		Uuid:        count.Uuid,
+		OutletUuid:  count.Outlet.Uuid,
+		OutletName:  count.Outlet.Name,
+		Status:      count.Status,
+		BlindCount:  count.BlindCount,
+		Note:        count.Note,
+		ItemCount:   len(count.Items),
+		SubmittedAt: count.SubmittedAt,
+		ApprovedAt:  count.ApprovedAt,
+		CancelledAt: count.CancelledAt,
+		Items:       make([]dtos.StockCountItemResponse, 0, len(count.Items)),
+		CreatedAt:   count.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	for _, item := range count.Items {
+		itemResponse := dtos.StockCountItemResponse{
+			Uuid:            item.Uuid,
+			CountedQuantity: item.CountedQuantity,
+			Entries:         make([]dtos.StockCountEntryResponse, 0, len(item.Entries)),
+		}
+		itemResponse.ProductName, itemResponse.VariantName, itemResponse.Sku = stockCountItemProduct(item)
+		if item.ProductVariant != nil {
+			itemResponse.ProductUuid = item.ProductVariant.Product.Uuid
+			itemResponse.ProductVariantUuid = &item.ProductVariant.Uuid
+		} else if item.Product != nil {
+			itemResponse.ProductUuid = item.Product.Uuid
+		}
+		if !blind {
+			expected := item.ExpectedQuantity
+			itemResponse.ExpectedQuantity = &expected
+			if item.CountedQuantity != nil {
+				variance := *item.CountedQuantity - item.ExpectedQuantity
+				itemResponse.Variance = &variance
+			}
+		}
+		for _, entry := range item.Entries {
+			itemResponse.Entries = append(itemResponse.Entries, dtos.StockCountEntryResponse{
+				Uuid:      entry.Uuid,
+				Quantity:  entry.Quantity,
+				CountedBy: entry.Counter.Name,
+				CountedAt: entry.CreatedAt.Format("2006-01-02 15:04:05"),
+			})
+		}
+		if item.CountedQuantity != nil {
+			response.CountedCount++
+		}
+		response.Items = append(response.Items, itemResponse)
+	}
+	return response
+}
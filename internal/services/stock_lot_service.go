@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockLotService struct {
+	DB                   *gorm.DB
+	UserContextService   *UserContextService
+	StockMovementService *StockMovementService
+}
+
+func NewStockLotService(db *gorm.DB, userContextService *UserContextService, stockMovementService *StockMovementService) *StockLotService {
+	return &StockLotService{DB: db, UserContextService: userContextService, StockMovementService: stockMovementService}
+}
+
+// GetLotsByOutlet lists the lots with stock left at an outlet in the order they are consumed.
+func (s *StockLotService) GetLotsByOutlet(outletUuid uuid.UUID, userID uint) ([]dtos.StockLotResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	var lots []models.StockLot
+	if err := stockLotPreloads(s.DB).
+		Where("outlet_id = ? AND user_id = ? AND quantity > 0", outlet.ID, ownerID).
+		Order(stockLotConsumptionOrder).
+		Find(&lots).Error; err != nil {
+		log.Printf("Error getting stock lots: %v", err)
+		return nil, errors.New("failed to retrieve stock lots")
+	}
+	return mapStockLotsToResponses(lots), nil
+}
+
+// GetExpiringLots is the near-expiry report: every lot with stock left that expires within the
+// given number of days, expired lots included, the soonest first.
+func (s *StockLotService) GetExpiringLots(days int, outletUuid *uuid.UUID, userID uint) ([]dtos.StockLotResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := stockLotPreloads(s.DB).
+		Where("user_id = ? AND quantity > 0 AND expiry_date IS NOT NULL AND expiry_date <= ?", ownerID, today().AddDate(0, 0, days))
+	if outletUuid != nil {
+		query = query.Where("outlet_id = (SELECT id FROM outlets WHERE uuid = ? AND user_id = ?)", *outletUuid, ownerID)
+	}
+
+	var lots []models.StockLot
+	if err := query.Order("expiry_date, id").Limit(500).Find(&lots).Error; err != nil {
+		log.Printf("Error getting expiring stock lots: %v", err)
+		return nil, errors.New("failed to retrieve stock lots")
+	}
+	return mapStockLotsToResponses(lots), nil
+}
+
+// WriteOffLot removes spoiled or expired stock of a lot from the outlet's stock.
+func (s *StockLotService) WriteOffLot(lotUuid uuid.UUID, req dtos.WriteOffStockLotRequest, userID uint) (*dtos.StockLotResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var lot models.StockLot
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ? AND user_id = ?", lotUuid, ownerID).First(&lot).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("stock lot not found")
+	}
+	if lot.Quantity <= 0 {
+		tx.Rollback()
+		return nil, errors.New("stock lot is empty")
+	}
+
+	quantity := lot.Quantity
+	if req.Quantity != nil {
+		quantity = *req.Quantity
+	}
+	if quantity > lot.Quantity {
+		tx.Rollback()
+		return nil, errors.New("write-off quantity exceeds the lot quantity")
+	}
+
+	var stock models.Stock
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stock, lot.StockID).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("stock not found")
+	}
+	// Stock reserved by unpaid orders cannot be written off
+	if availableStock(stock) < quantity {
+		tx.Rollback()
+		return nil, errors.New("write-off exceeds the available stock")
+	}
+	stock.Quantity -= quantity
+	if err := tx.Model(&stock).Update("quantity", stock.Quantity).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error updating stock for write-off: %v", err)
+		return nil, errors.New("failed to write off stock lot")
+	}
+	checkStockLevel(tx, stock)
+
+	if err := tx.Model(&lot).Updates(map[string]interface{}{
+		"quantity":             lot.Quantity - quantity,
+		"written_off_quantity": lot.WrittenOffQuantity + quantity,
+	}).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error updating stock lot for write-off: %v", err)
+		return nil, errors.New("failed to write off stock lot")
+	}
+
+	movement := &models.StockMovement{
+		OutletID:         lot.OutletID,
+		ProductID:        lot.ProductID,
+		ProductVariantID: lot.ProductVariantID,
+		QuantityChange:   -quantity,
+		MovementType:     models.StockMovementWriteOff,
+		ReferenceType:    models.StockReferenceStockLot,
+		ReferenceID:      &lot.ID,
+		Description:      stringPtr(req.Reason),
+	}
+	if err := s.StockMovementService.CreateStockMovementWithTx(tx, movement); err != nil {
+		tx.Rollback()
+		log.Printf("Error recording write-off stock movement: %v", err)
+		return nil, errors.New("failed to write off stock lot")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+
+	if err := stockLotPreloads(s.DB).First(&lot, lot.ID).Error; err != nil {
+		return nil, errors.New("stock lot not found")
+	}
+	return mapStockLotToResponse(lot), nil
+}
+
+// NotifyExpiringLots emails every owner one digest of their lots that expire within
+// STOCK_LOT_EXPIRY_ALERT_DAYS (default 3). Each lot is emailed about once. It is run by the stock
+// lot expiry worker.
+func (s *StockLotService) NotifyExpiringLots() error {
+	var lots []models.StockLot
+	if err := stockLotPreloads(s.DB).
+		Where("quantity > 0 AND expiry_date IS NOT NULL AND expiry_date <= ? AND expiry_notified_at IS NULL", today().AddDate(0, 0, stockLotExpiryAlertDays())).
+		Order("expiry_date, id").
+		Limit(500).
+		Find(&lots).Error; err != nil {
+		return fmt.Errorf("failed to load expiring stock lots: %w", err)
+	}
+
+	byOwner := make(map[uint][]models.StockLot)
+	for _, lot := range lots {
+		byOwner[lot.UserID] = append(byOwner[lot.UserID], lot)
+	}
+
+	for ownerID, ownerLots := range byOwner {
+		if err := s.notifyOwner(ownerID, ownerLots); err != nil {
+			log.Printf("Error sending expiring lots to owner %d: %v", ownerID, err)
+		}
+	}
+	return nil
+}
+
+func (s *StockLotService) notifyOwner(ownerID uint, lots []models.StockLot) error {
+	var owner models.User
+	if err := s.DB.First(&owner, ownerID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	data := ExpiringLotsEmail{OwnerName: owner.Name}
+	ids := make([]uint, 0, len(lots))
+	for _, lot := range lots {
+		ids = append(ids, lot.ID)
+		response := mapStockLotToResponse(lot)
+		name := response.ProductName
+		if response.VariantName != "" {
+			name += " - " + response.VariantName
+		}
+		data.Items = append(data.Items, ExpiringLotsEmailItem{
+			OutletName: response.OutletName,
+			Name:       name,
+			LotNumber:  response.LotNumber,
+			ExpiryDate: *response.ExpiryDate,
+			Quantity:   formatQuantity(lot.Quantity),
+		})
+	}
+
+	if err := SendExpiringLotsEmail(owner.Email, data); err != nil {
+		return err
+	}
+	return s.DB.Model(&models.StockLot{}).Where("id IN ?", ids).Update("expiry_notified_at", time.Now()).Error
+}
+
+// stockLotConsumptionOrder is first-expired-first-out. Lots without an expiry date go last, and
+// lots expiring the same day go oldest first.
+const stockLotConsumptionOrder = "expiry_date ASC NULLS LAST, received_at ASC, id ASC"
+
+// consumeStockLots takes a decrease of a stock out of its lots first-expired-first-out. Whatever
+// the lots do not cover came from stock without a lot.
+func consumeStockLots(tx *gorm.DB, stockID uint, quantity float64) error {
+	if quantity <= 0 {
+		return nil
+	}
+
+	var lots []models.StockLot
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("stock_id = ? AND quantity > 0", stockID).
+		Order(stockLotConsumptionOrder).
+		Find(&lots).Error; err != nil {
+		return err
+	}
+
+	remaining := quantity
+	for _, lot := range lots {
+		if remaining <= 0 {
+			break
+		}
+		taken := lot.Quantity
+		if taken > remaining {
+			taken = remaining
+		}
+		if err := tx.Model(&lot).Update("quantity", lot.Quantity-taken).Error; err != nil {
+			return err
+		}
+		remaining -= taken
+	}
+	return nil
+}
+
+func stockLotExpiryAlertDays() int {
+	days, err := strconv.Atoi(os.Getenv("STOCK_LOT_EXPIRY_ALERT_DAYS"))
+	if err != nil || days < 0 {
+		return 3
+	}
+	return days
+}
+
+// today is midnight of the current day, for comparing against expiry dates.
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+func stockLotPreloads(db *gorm.DB) *gorm.DB {
+	return db.Preload("Stock.Outlet").Preload("Product").Preload("ProductVariant.Product")
+}
+
+func mapStockLotsToResponses(lots []models.StockLot) []dtos.StockLotResponse {
+	responses := make([]dtos.StockLotResponse, 0, len(lots))
+	for _, lot := range lots {
+		responses = append(responses, *mapStockLotToResponse(lot))
+	}
+	return responses
+}
+
+func mapStockLotToResponse(lot models.StockLot) *dtos.StockLotResponse {
+	response := &dtos.StockLotResponse{
+		Uuid:               lot.Uuid,
+		OutletUuid:         lot.Stock.Outlet.Uuid,
+		OutletName:         lot.Stock.Outlet.Name,
+		LotNumber:          lot.LotNumber,
+		ReceivedAt:         lot.ReceivedAt,
+		UnitCost:           lot.UnitCost,
+		InitialQuantity:    lot.InitialQuantity,
+		Quantity:           lot.Quantity,
+		WrittenOffQuantity: lot.WrittenOffQuantity,
+	}
+	if lot.ProductVariant != nil {
+		response.ProductUuid = lot.ProductVariant.Product.Uuid
+		response.ProductName = lot.ProductVariant.Product.Name
+		response.ProductVariantUuid = &lot.ProductVariant.Uuid
+		response.VariantName = lot.ProductVariant.Name
+		response.Sku = lot.ProductVariant.SKU
+	} else if lot.Product != nil {
+		response.ProductUuid = lot.Product.Uuid
+		response.ProductName = lot.Product.Name
+		response.Sku = lot.Product.SKU
+	}
+	if lot.ExpiryDate != nil {
+		expiryDate := lot.ExpiryDate.Format("2006-01-02")
+		expiry := time.Date(lot.ExpiryDate.Year(), lot.ExpiryDate.Month(), lot.ExpiryDate.Day(), 0, 0, 0, 0, time.Local)
+		days := int(math.Round(expiry.Sub(today()).Hours() / 24))
+		response.ExpiryDate = &expiryDate
+		response.DaysToExpiry = &days
+		response.Expired = days < 0
+	}
+	return response
+}
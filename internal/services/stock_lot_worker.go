@@ -0,0 +1,27 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// StartStockLotExpiryWorker emails owners about lots that are about to expire.
+// The interval is STOCK_LOT_EXPIRY_INTERVAL_MINUTES (default 60).
+func StartStockLotExpiryWorker(stockLotService *StockLotService) {
+	minutes, err := strconv.Atoi(os.Getenv("STOCK_LOT_EXPIRY_INTERVAL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 60
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(minutes) * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := stockLotService.NotifyExpiringLots(); err != nil {
+				log.Printf("Stock lot expiry notification failed: %v", err)
+			}
+		}
+	}()
+}
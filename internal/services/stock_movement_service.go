@@ -1,6 +1,9 @@
 package services
 
 import (
+	"errors"
+
+	"github.com/msyaifudin/pos/internal/database"
 	"github.com/msyaifudin/pos/internal/models"
 	"gorm.io/gorm"
 )
@@ -14,9 +17,42 @@ func NewStockMovementService(db *gorm.DB) *StockMovementService {
 }
 
 func (s *StockMovementService) CreateStockMovement(movement *models.StockMovement) error {
-	return s.DB.Create(movement).Error
+	return s.CreateStockMovementWithTx(s.DB, movement)
 }
 
+// CreateStockMovementWithTx records a movement after its change has been applied to stock, so the
+// resulting balance can be read back in the same transaction. The user who performed it comes from
+// the transaction context, like CreatedBy on every other model, unless the movement names it.
 func (s *StockMovementService) CreateStockMovementWithTx(tx *gorm.DB, movement *models.StockMovement) error {
+	return recordStockMovement(tx, movement)
+}
+
+// recordStockMovement is CreateStockMovementWithTx for callers without a StockMovementService.
+func recordStockMovement(tx *gorm.DB, movement *models.StockMovement) error {
+	query := tx.Model(&models.Stock{}).Where("outlet_id = ?", movement.OutletID)
+	if movement.ProductVariantID != nil {
+		query = query.Where("product_variant_id = ?", *movement.ProductVariantID)
+	} else if movement.ProductID != nil {
+		query = query.Where("product_id = ? AND product_variant_id IS NULL", *movement.ProductID)
+	} else {
+		return errors.New("product_id or product_variant_id is required for a stock movement")
+	}
+
+	var balances []float64
+	if err := query.Limit(1).Pluck("quantity", &balances).Error; err != nil {
+		return err
+	}
+	if len(balances) > 0 {
+		movement.BalanceAfter = balances[0]
+	}
 	return tx.Create(movement).Error
 }
+
+// movementCreator is the user a movement is recorded for: the one in the transaction context, or
+// userID when the caller passed a transaction without one.
+func movementCreator(tx *gorm.DB, userID uint) *uint {
+	if contextUserID, ok := tx.Statement.Context.Value(database.UserIDContextKey).(uint); ok && contextUserID != 0 {
+		return &contextUserID
+	}
+	return &userID
+}
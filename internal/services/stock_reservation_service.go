@@ -0,0 +1,303 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/msyaifudin/pos/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// stockReservationTTL is how long an unpaid order keeps its reserved stock after its last item was
+// reserved, from STOCK_RESERVATION_TTL_MINUTES (default 30).
+func stockReservationTTL() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("STOCK_RESERVATION_TTL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 30
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// ReserveStockForSale holds stock for an unpaid order instead of deducting it. Only the available
+// quantity, on hand minus what other orders hold, can be reserved. All reservations of an order
+// expire together, so adding an item restarts the order's timeout.
+func (s *StockService) ReserveStockForSale(tx *gorm.DB, outletID uint, productID *uint, productVariantID *uint, quantity float64, userID uint, orderID uint) error {
+	stock, err := lockSaleStock(tx, outletID, productID, productVariantID, userID)
+	if err != nil {
+		return err
+	}
+	if availableStock(stock) < quantity {
+		return errors.New("insufficient stock")
+	}
+	if err := tx.Model(&stock).Update("reserved_quantity", stock.ReservedQuantity+quantity).Error; err != nil {
+		return err
+	}
+
+	var reservation models.StockReservation
+	err = stockReservationQuery(tx, orderID, productID, productVariantID).First(&reservation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		reservation = models.StockReservation{
+			OutletID:         outletID,
+			OrderID:          orderID,
+			ProductID:        productID,
+			ProductVariantID: productVariantID,
+			Quantity:         quantity,
+			Status:           models.StockReservationStatusActive,
+			ExpiresAt:        time.Now().Add(stockReservationTTL()),
+			UserID:           userID,
+		}
+		if err := tx.Create(&reservation).Error; err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else if err := tx.Model(&reservation).Update("quantity", reservation.Quantity+quantity).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.StockReservation{}).
+		Where("order_id = ? AND status = ?", orderID, models.StockReservationStatusActive).
+		Update("expires_at", time.Now().Add(stockReservationTTL())).Error
+}
+
+// ReleaseStockForSale gives back stock an order reserved, when an item is changed or removed. Only
+// what the order actually holds is released, so releasing stock that was never reserved is a no-op.
+func (s *StockService) ReleaseStockForSale(tx *gorm.DB, outletID uint, productID *uint, productVariantID *uint, quantity float64, userID uint, orderID uint) error {
+	var reservation models.StockReservation
+	if err := stockReservationQuery(tx, orderID, productID, productVariantID).First(&reservation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	stock, err := lockSaleStock(tx, outletID, productID, productVariantID, userID)
+	if err != nil {
+		return err
+	}
+	// Read the reservation again now that its stock is locked, so it cannot change underneath
+	if err := tx.First(&reservation, reservation.ID).Error; err != nil {
+		return err
+	}
+
+	released := math.Min(quantity, reservation.Quantity)
+	if err := tx.Model(&stock).Update("reserved_quantity", math.Max(stock.ReservedQuantity-released, 0)).Error; err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{"quantity": reservation.Quantity - released}
+	if reservation.Quantity-released <= 0 {
+		updates["status"] = models.StockReservationStatusReleased
+		updates["released_at"] = time.Now()
+	}
+	return tx.Model(&reservation).Updates(updates).Error
+}
+
+// ReleaseExpiredReservations cancels unpaid orders whose reservations have timed out and releases
+// their stock. Orders that are partly paid or have a payment in progress keep their reservations
+// for another timeout.
+func (s *StockService) ReleaseExpiredReservations() error {
+	var orderIDs []uint
+	if err := s.DB.Model(&models.StockReservation{}).
+		Distinct("order_id").
+		Where("status = ? AND expires_at <= ?", models.StockReservationStatusActive, time.Now()).
+		Limit(100).
+		Pluck("order_id", &orderIDs).Error; err != nil {
+		return err
+	}
+
+	for _, orderID := range orderIDs {
+		if err := s.expireOrderReservations(orderID); err != nil {
+			log.Printf("Error releasing expired stock reservations of order %d: %v", orderID, err)
+		}
+	}
+	return nil
+}
+
+func (s *StockService) expireOrderReservations(orderID uint) error {
+	tx := s.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var pendingPayments int64
+	if err := tx.Model(&models.OrderPayment{}).Where("order_id = ? AND status = ?", order.ID, models.OrderPaymentStatusPending).Count(&pendingPayments).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if order.Status == "pending" && order.PaidAmount == 0 && pendingPayments == 0 {
+		if err := releaseStockReservations(tx, order.ID); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Model(&order).Update("status", "cancelled").Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	} else if err := tx.Model(&models.StockReservation{}).
+		Where("order_id = ? AND status = ?", order.ID, models.StockReservationStatusActive).
+		Update("expires_at", time.Now().Add(stockReservationTTL())).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// convertStockReservations turns the reservations of a completed order into stock deductions.
+func convertStockReservations(tx *gorm.DB, orderID uint) error {
+	reservations, err := lockStockReservations(tx, orderID)
+	if err != nil {
+		return err
+	}
+
+	for _, reservation := range reservations {
+		stock, err := lockSaleStock(tx, reservation.OutletID, reservation.ProductID, reservation.ProductVariantID, reservation.UserID)
+		if err != nil {
+			return err
+		}
+		// The full sale is taken out. A stock count can still leave less on hand than the order
+		// holds; what is missing is put back as a shortfall movement so the ledger shows it.
+		shortfall := math.Max(reservation.Quantity-math.Max(stock.Quantity, 0), 0)
+		previousQuantity := stock.Quantity
+		stock.Quantity -= reservation.Quantity
+		stock.ReservedQuantity = math.Max(stock.ReservedQuantity-reservation.Quantity, 0)
+		if err := tx.Model(&stock).Updates(map[string]interface{}{
+			"quantity":          stock.Quantity,
+			"reserved_quantity": stock.ReservedQuantity,
+		}).Error; err != nil {
+			return err
+		}
+		if err := consumeStockLots(tx, stock.ID, reservation.Quantity-shortfall); err != nil {
+			return err
+		}
+		if err := recordStockMovement(tx, &models.StockMovement{
+			OutletID:         reservation.OutletID,
+			ProductID:        reservation.ProductID,
+			ProductVariantID: reservation.ProductVariantID,
+			QuantityChange:   -reservation.Quantity,
+			MovementType:     models.StockMovementOrder,
+			ReferenceType:    models.StockReferenceOrder,
+			ReferenceID:      &reservation.OrderID,
+			Description:      stringPtr("Deduction for sale"),
+			CreatedBy:        movementCreator(tx, reservation.UserID),
+		}); err != nil {
+			return err
+		}
+
+		if shortfall > 0 {
+			log.Printf("Stock %d was short by %v when order %d was paid", stock.ID, shortfall, reservation.OrderID)
+			stock.Quantity += shortfall
+			if err := tx.Model(&stock).Update("quantity", stock.Quantity).Error; err != nil {
+				return err
+			}
+			if err := recordStockMovement(tx, &models.StockMovement{
+				OutletID:         reservation.OutletID,
+				ProductID:        reservation.ProductID,
+				ProductVariantID: reservation.ProductVariantID,
+				QuantityChange:   shortfall,
+				MovementType:     models.StockMovementShortfall,
+				ReferenceType:    models.StockReferenceOrder,
+				ReferenceID:      &reservation.OrderID,
+				Description:      stringPtr("Sold stock that was not on hand"),
+				CreatedBy:        movementCreator(tx, reservation.UserID),
+			}); err != nil {
+				return err
+			}
+		}
+		recordStockLowEvent(tx, stock, previousQuantity)
+		checkStockLevel(tx, stock)
+
+		if err := tx.Model(&reservation).Updates(map[string]interface{}{
+			"status":       models.StockReservationStatusConverted,
+			"converted_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseStockReservations gives back all stock a cancelled order holds.
+func releaseStockReservations(tx *gorm.DB, orderID uint) error {
+	reservations, err := lockStockReservations(tx, orderID)
+	if err != nil {
+		return err
+	}
+
+	for _, reservation := range reservations {
+		if err := releaseReservedStock(tx, reservation, reservation.Quantity); err != nil {
+			return err
+		}
+		if err := tx.Model(&reservation).Updates(map[string]interface{}{
+			"status":      models.StockReservationStatusReleased,
+			"released_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockStockReservations locks the stock an order holds and then its active reservations, in the
+// same order a sale takes its locks.
+func lockStockReservations(tx *gorm.DB, orderID uint) ([]models.StockReservation, error) {
+	var reservations []models.StockReservation
+	if err := tx.Where("order_id = ? AND status = ?", orderID, models.StockReservationStatusActive).Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+	if len(reservations) == 0 {
+		return nil, nil
+	}
+
+	var productIDs, variantIDs []uint
+	for _, reservation := range reservations {
+		if reservation.ProductVariantID != nil {
+			variantIDs = append(variantIDs, *reservation.ProductVariantID)
+		} else if reservation.ProductID != nil {
+			productIDs = append(productIDs, *reservation.ProductID)
+		}
+	}
+	if err := lockStocks(tx, reservations[0].OutletID, productIDs, variantIDs); err != nil {
+		return nil, err
+	}
+
+	reservations = nil
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status = ?", orderID, models.StockReservationStatusActive).
+		Order("id").
+		Find(&reservations).Error
+	return reservations, err
+}
+
+// releaseReservedStock lowers the reserved quantity of the stock a reservation holds.
+func releaseReservedStock(tx *gorm.DB, reservation models.StockReservation, quantity float64) error {
+	stock, err := lockSaleStock(tx, reservation.OutletID, reservation.ProductID, reservation.ProductVariantID, reservation.UserID)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&stock).Update("reserved_quantity", math.Max(stock.ReservedQuantity-quantity, 0)).Error
+}
+
+// stockReservationQuery finds the active reservation of an order for a product or variant.
+func stockReservationQuery(tx *gorm.DB, orderID uint, productID *uint, productVariantID *uint) *gorm.DB {
+	query := tx.Where("order_id = ? AND status = ?", orderID, models.StockReservationStatusActive)
+	if productVariantID != nil {
+		return query.Where("product_variant_id = ?", *productVariantID)
+	}
+	return query.Where("product_id = ? AND product_variant_id IS NULL", productID)
+}
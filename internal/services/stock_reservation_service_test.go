@@ -0,0 +1,233 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"gorm.io/gorm"
+)
+
+// reservationFixture is an unpaid order for 3 of a product with 10 on hand, at an outlet that
+// reserves stock for unpaid orders.
+type reservationFixture struct {
+	db           *gorm.DB
+	owner        models.User
+	product      models.Product
+	stock        models.Stock
+	order        models.Order
+	orderService *OrderService
+	stockService *StockService
+}
+
+func createReservationFixture(t *testing.T) reservationFixture {
+	t.Helper()
+	db := openTestDB(t)
+	owner, outlet := createTestOwner(t, db, true)
+	product, stock := createTestStock(t, db, outlet, "Reserved item", 10)
+	stockService := newTestStockService(db)
+	orderService := NewOrderService(db, stockService, nil, NewUserContextService(db))
+
+	response, err := orderService.CreateOrder(dtos.CreateOrderRequest{
+		OutletUuid: outlet.Uuid,
+		Items:      []dtos.OrderItemRequest{{ProductUuid: product.Uuid, Quantity: 3}},
+	}, owner.ID)
+	if err != nil {
+		t.Fatalf("failed to create order: %v", err)
+	}
+	var order models.Order
+	if err := db.Where("uuid = ?", response.Uuid).First(&order).Error; err != nil {
+		t.Fatalf("failed to load order: %v", err)
+	}
+	return reservationFixture{db: db, owner: owner, product: product, stock: stock, order: order, orderService: orderService, stockService: stockService}
+}
+
+// assertStock checks the stock of the fixture's product and the number of its ledger rows.
+func (f reservationFixture) assertStock(t *testing.T, quantity, reserved float64, movements int64) {
+	t.Helper()
+	var stock models.Stock
+	if err := f.db.First(&stock, f.stock.ID).Error; err != nil {
+		t.Fatalf("failed to reload stock: %v", err)
+	}
+	if stock.Quantity != quantity || stock.ReservedQuantity != reserved {
+		t.Fatalf("expected %v on hand and %v reserved, got %v and %v", quantity, reserved, stock.Quantity, stock.ReservedQuantity)
+	}
+	var count int64
+	f.db.Model(&models.StockMovement{}).Where("product_id = ?", f.product.ID).Count(&count)
+	if count != movements {
+		t.Fatalf("expected %d stock movements, got %d", movements, count)
+	}
+}
+
+func (f reservationFixture) assertReservation(t *testing.T, status string, quantity float64) {
+	t.Helper()
+	var reservation models.StockReservation
+	if err := f.db.Where("order_id = ?", f.order.ID).First(&reservation).Error; err != nil {
+		t.Fatalf("failed to load reservation: %v", err)
+	}
+	if reservation.Status != status || reservation.Quantity != quantity {
+		t.Fatalf("expected a %s reservation of %v, got %s of %v", status, quantity, reservation.Status, reservation.Quantity)
+	}
+}
+
+func (f reservationFixture) assertOrderStatus(t *testing.T, status string) {
+	t.Helper()
+	var order models.Order
+	if err := f.db.First(&order, f.order.ID).Error; err != nil {
+		t.Fatalf("failed to reload order: %v", err)
+	}
+	if order.Status != status {
+		t.Fatalf("expected the order to be %s, got %s", status, order.Status)
+	}
+}
+
+// payOrder pays the whole order through a pending payment, as a gateway callback would.
+func (f reservationFixture) payOrder(t *testing.T) {
+	t.Helper()
+	paymentMethod := models.PaymentMethod{Name: "Reservation test", Type: "card", Issuer: TsmIssuer, IsActive: true}
+	if err := f.db.Create(&paymentMethod).Error; err != nil {
+		t.Fatalf("failed to create payment method: %v", err)
+	}
+	orderPayment := models.OrderPayment{OrderID: f.order.ID, PaymentMethodID: paymentMethod.ID, AmountPaid: f.order.TotalAmount, Status: models.OrderPaymentStatusPending, Extra: "{}"}
+	if err := f.db.Create(&orderPayment).Error; err != nil {
+		t.Fatalf("failed to create order payment: %v", err)
+	}
+
+	s := NewOrderPaymentService(f.db, NewUserContextService(f.db), NewPaymentProviderRegistry())
+	tx := f.db.Begin()
+	if err := s.UpdateOrderPaymentAndStatus(tx, orderPayment.Uuid.String(), orderPayment.AmountPaid); err != nil {
+		tx.Rollback()
+		t.Fatalf("failed to pay order: %v", err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		t.Fatalf("failed to commit payment: %v", err)
+	}
+}
+
+func TestCreateOrderReservesStock(t *testing.T) {
+	f := createReservationFixture(t)
+
+	// Nothing leaves the shelf until the order is paid
+	f.assertStock(t, 10, 3, 0)
+	f.assertReservation(t, models.StockReservationStatusActive, 3)
+
+	// Reserved stock cannot be sold to anyone else
+	tx := f.db.Begin()
+	defer tx.Rollback()
+	if err := f.stockService.DeductStockForSale(tx, f.order.OutletID, &f.product.ID, nil, 8, f.owner.ID, "", nil); err == nil || err.Error() != "insufficient stock" {
+		t.Fatalf("expected insufficient stock, got %v", err)
+	}
+}
+
+func TestCancelOrderReleasesReservedStock(t *testing.T) {
+	f := createReservationFixture(t)
+
+	if _, err := f.orderService.CancelOrder(f.order.Uuid, f.owner.ID); err != nil {
+		t.Fatalf("failed to cancel order: %v", err)
+	}
+
+	f.assertOrderStatus(t, "cancelled")
+	f.assertStock(t, 10, 0, 0)
+	f.assertReservation(t, models.StockReservationStatusReleased, 3)
+}
+
+func TestReleaseExpiredReservationsCancelsUnpaidOrders(t *testing.T) {
+	f := createReservationFixture(t)
+
+	if err := f.db.Model(&models.StockReservation{}).Where("order_id = ?", f.order.ID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatalf("failed to expire reservation: %v", err)
+	}
+	if err := f.stockService.ReleaseExpiredReservations(); err != nil {
+		t.Fatalf("failed to release expired reservations: %v", err)
+	}
+
+	f.assertOrderStatus(t, "cancelled")
+	f.assertStock(t, 10, 0, 0)
+	f.assertReservation(t, models.StockReservationStatusReleased, 3)
+}
+
+func TestPaymentConvertsReservedStock(t *testing.T) {
+	f := createReservationFixture(t)
+
+	f.payOrder(t)
+
+	f.assertOrderStatus(t, "completed")
+	f.assertStock(t, 7, 0, 1)
+	f.assertReservation(t, models.StockReservationStatusConverted, 3)
+
+	var movement models.StockMovement
+	if err := f.db.Where("product_id = ?", f.product.ID).First(&movement).Error; err != nil {
+		t.Fatalf("failed to load stock movement: %v", err)
+	}
+	if movement.QuantityChange != -3 || movement.BalanceAfter != 7 || movement.MovementType != models.StockMovementOrder {
+		t.Fatalf("unexpected stock movement %+v", movement)
+	}
+	if movement.ReferenceType != models.StockReferenceOrder || movement.ReferenceID == nil || *movement.ReferenceID != f.order.ID {
+		t.Fatalf("expected the movement to reference order %d, got %s %v", f.order.ID, movement.ReferenceType, movement.ReferenceID)
+	}
+	if movement.CreatedBy == nil || *movement.CreatedBy != f.owner.ID {
+		t.Fatalf("expected the movement to be created by %d, got %v", f.owner.ID, movement.CreatedBy)
+	}
+}
+
+func TestPaymentConversionRecordsShortfall(t *testing.T) {
+	f := createReservationFixture(t)
+
+	// A count finds only one unit left while the order still holds three
+	if err := f.db.Model(&models.Stock{}).Where("id = ?", f.stock.ID).Update("quantity", 1).Error; err != nil {
+		t.Fatalf("failed to lower stock: %v", err)
+	}
+
+	f.payOrder(t)
+
+	f.assertStock(t, 0, 0, 2)
+	f.assertReservation(t, models.StockReservationStatusConverted, 3)
+
+	var movements []models.StockMovement
+	if err := f.db.Where("product_id = ?", f.product.ID).Order("id").Find(&movements).Error; err != nil {
+		t.Fatalf("failed to load stock movements: %v", err)
+	}
+	sale, shortfall := movements[0], movements[1]
+	if sale.MovementType != models.StockMovementOrder || sale.QuantityChange != -3 || sale.BalanceAfter != -2 {
+		t.Fatalf("expected the full sale to be recorded, got %+v", sale)
+	}
+	if shortfall.MovementType != models.StockMovementShortfall || shortfall.QuantityChange != 2 || shortfall.BalanceAfter != 0 {
+		t.Fatalf("expected the missing stock to be recorded as a shortfall, got %+v", shortfall)
+	}
+}
+
+func TestReservedStockCannotLeaveTheOutlet(t *testing.T) {
+	f := createReservationFixture(t)
+
+	// 10 on hand, 3 reserved: only 7 can be sent, adjusted or written off
+	lot := models.StockLot{StockID: f.stock.ID, OutletID: f.order.OutletID, ProductID: &f.product.ID, ReceivedAt: time.Now(), InitialQuantity: 10, Quantity: 10, UserID: f.owner.ID}
+	if err := f.db.Create(&lot).Error; err != nil {
+		t.Fatalf("failed to create stock lot: %v", err)
+	}
+	lots := NewStockLotService(f.db, NewUserContextService(f.db), NewStockMovementService(f.db))
+	writeOff := 8.0
+	if _, err := lots.WriteOffLot(lot.Uuid, dtos.WriteOffStockLotRequest{Quantity: &writeOff, Reason: "Spoiled"}, f.owner.ID); err == nil || err.Error() != "write-off exceeds the available stock" {
+		t.Fatalf("expected the write-off to be refused, got %v", err)
+	}
+
+	tx := f.db.Begin()
+	defer tx.Rollback()
+	adjustments := &StockAdjustmentService{DB: f.db, StockMovementService: NewStockMovementService(f.db)}
+	adjustment := models.StockAdjustment{OutletID: f.order.OutletID, ProductID: &f.product.ID, QuantityChange: -8, UserID: f.owner.ID}
+	if err := adjustments.applyAdjustment(tx, adjustment); err == nil || err.Error() != "adjustment exceeds the available stock" {
+		t.Fatalf("expected the adjustment to be refused, got %v", err)
+	}
+
+	transfers := &StockTransferService{DB: f.db, StockMovementService: NewStockMovementService(f.db)}
+	transfer := models.StockTransfer{SourceOutletID: f.order.OutletID, UserID: f.owner.ID}
+	item := models.StockTransferItem{ProductID: &f.product.ID, Quantity: 8}
+	if err := transfers.moveTransferStock(tx, transfer, item, f.order.OutletID, -8, models.StockMovementTransferOut, "Sent on stock transfer"); err != ErrInsufficientTransferStock {
+		t.Fatalf("expected the transfer to be refused, got %v", err)
+	}
+
+	if err := adjustments.applyAdjustment(tx, models.StockAdjustment{OutletID: f.order.OutletID, ProductID: &f.product.ID, QuantityChange: -7, UserID: f.owner.ID}); err != nil {
+		t.Fatalf("expected the available stock to be adjustable, got %v", err)
+	}
+}
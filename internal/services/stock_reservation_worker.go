@@ -0,0 +1,27 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// StartStockReservationWorker releases the stock of unpaid orders whose reservations have timed
+// out. The interval is STOCK_RESERVATION_INTERVAL_MINUTES (default 1).
+func StartStockReservationWorker(stockService *StockService) {
+	minutes, err := strconv.Atoi(os.Getenv("STOCK_RESERVATION_INTERVAL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 1
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(minutes) * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := stockService.ReleaseExpiredReservations(); err != nil {
+				log.Printf("Releasing expired stock reservations failed: %v", err)
+			}
+		}
+	}()
+}
@@ -1,13 +1,17 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"log"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
 	"github.com/msyaifudin/pos/internal/models"
 	"github.com/msyaifudin/pos/internal/models/dtos"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type StockService struct {
@@ -72,16 +76,13 @@ func (s *StockService) GetOutletStocks(outletUuid uuid.UUID, userID uint, produc
 			productDetail.Price = stock.Product.Price
 			productDetail.SKU = stock.Product.SKU
 			productDetail.Type = stock.Product.Type
+			productDetail.BaseUnit = stock.Product.BaseUnit
 
 			// Map recipes
 			if stock.Product.Type == "fnb_main_product" {
 				for _, r := range stock.Product.Recipes {
 					if r.Component.ID != 0 {
-						recipes = append(recipes, dtos.RecipeResponse{
-							Uuid:          r.Uuid,
-							ComponentName: r.Component.Name,
-							Quantity:      r.Quantity,
-						})
+						recipes = append(recipes, mapRecipeToResponse(r))
 					}
 				}
 			}
@@ -113,16 +114,18 @@ func (s *StockService) GetOutletStocks(outletUuid uuid.UUID, userID uint, produc
 			productDetail.Variants = variants
 
 			stockDetailResponses = append(stockDetailResponses, dtos.StockDetailResponse{
-				Uuid:        productDetail.Uuid,
-				Name:        productDetail.Name,
-				Description: productDetail.Description,
-				Price:       productDetail.Price,
-				SKU:         productDetail.SKU,
-				Type:        productDetail.Type,
-				Recipes:     productDetail.Recipes,
-				AddOns:      productDetail.AddOns,
-				Variants:    productDetail.Variants,
-				Quantity:    stock.Quantity,
+				Uuid:              productDetail.Uuid,
+				Name:              productDetail.Name,
+				Description:       productDetail.Description,
+				Price:             productDetail.Price,
+				SKU:               productDetail.SKU,
+				Type:              productDetail.Type,
+				Recipes:           productDetail.Recipes,
+				AddOns:            productDetail.AddOns,
+				Variants:          productDetail.Variants,
+				Quantity:          stock.Quantity,
+				ReservedQuantity:  stock.ReservedQuantity,
+				AvailableQuantity: stock.Quantity - stock.ReservedQuantity,
 			})
 		} else if stock.ProductVariantID != nil && stock.ProductVariant != nil && stock.ProductVariant.Product.ID != 0 {
 			// For product variants, the main product details come from stock.ProductVariant.Product
@@ -132,16 +135,13 @@ func (s *StockService) GetOutletStocks(outletUuid uuid.UUID, userID uint, produc
 			productDetail.Price = stock.ProductVariant.Product.Price
 			productDetail.SKU = stock.ProductVariant.Product.SKU
 			productDetail.Type = stock.ProductVariant.Product.Type
+			productDetail.BaseUnit = stock.ProductVariant.Product.BaseUnit
 
 			// Map recipes for the main product of the variant
 			if stock.ProductVariant.Product.Type == "fnb_main_product" {
 				for _, r := range stock.ProductVariant.Product.Recipes {
 					if r.Component.ID != 0 {
-						recipes = append(recipes, dtos.RecipeResponse{
-							Uuid:          r.Uuid,
-							ComponentName: r.Component.Name,
-							Quantity:      r.Quantity,
-						})
+						recipes = append(recipes, mapRecipeToResponse(r))
 					}
 				}
 			}
@@ -171,16 +171,18 @@ func (s *StockService) GetOutletStocks(outletUuid uuid.UUID, userID uint, produc
 			productDetail.Variants = variants
 
 			stockDetailResponses = append(stockDetailResponses, dtos.StockDetailResponse{
-				Uuid:        productDetail.Uuid,
-				Name:        productDetail.Name,
-				Description: productDetail.Description,
-				Price:       productDetail.Price,
-				SKU:         productDetail.SKU,
-				Type:        productDetail.Type,
-				Recipes:     productDetail.Recipes,
-				AddOns:      productDetail.AddOns,
-				Variants:    productDetail.Variants,
-				Quantity:    stock.Quantity,
+				Uuid:              productDetail.Uuid,
+				Name:              productDetail.Name,
+				Description:       productDetail.Description,
+				Price:             productDetail.Price,
+				SKU:               productDetail.SKU,
+				Type:              productDetail.Type,
+				Recipes:           productDetail.Recipes,
+				AddOns:            productDetail.AddOns,
+				Variants:          productDetail.Variants,
+				Quantity:          stock.Quantity,
+				ReservedQuantity:  stock.ReservedQuantity,
+				AvailableQuantity: stock.Quantity - stock.ReservedQuantity,
 			})
 		}
 	}
@@ -202,24 +204,36 @@ func (s *StockService) UpdateStock(req dtos.UpdateStockRequest, outletUuid uuid.
 	var stock models.Stock
 	var product *models.Product
 	var variant *models.ProductVariant
-	var query *gorm.DB
 
 	if req.ProductVariantUuid != uuid.Nil {
 		// Find variant
 		if err := s.DB.Where("uuid = ? AND user_id = ?", req.ProductVariantUuid, ownerID).First(&variant).Error; err != nil {
 			return nil, errors.New("product variant not found")
 		}
-		query = s.DB.Where("outlet_id = ? AND product_variant_id = ? AND user_id = ?", outlet.ID, variant.ID, ownerID)
 	} else if req.ProductUuid != uuid.Nil {
 		// Find product
 		if err := s.DB.Where("uuid = ? AND user_id = ?", req.ProductUuid, ownerID).First(&product).Error; err != nil {
 			return nil, errors.New("product not found")
 		}
-		query = s.DB.Where("outlet_id = ? AND product_id = ? AND user_id = ?", outlet.ID, product.ID, ownerID)
 	} else {
 		return nil, errors.New("product_uuid or product_variant_uuid is required")
 	}
 
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// The stock row stays locked until the new quantity and its movement are committed
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("outlet_id = ? AND user_id = ?", outlet.ID, ownerID)
+	if variant != nil {
+		query = query.Where("product_variant_id = ?", variant.ID)
+	} else {
+		query = query.Where("product_id = ?", product.ID)
+	}
+
 	oldQuantity := 0.0
 	quantityChange := req.Quantity
 
@@ -237,31 +251,45 @@ func (s *StockService) UpdateStock(req dtos.UpdateStockRequest, outletUuid uuid.
 			} else {
 				stock.ProductID = &product.ID
 			}
-			if err := s.DB.Create(&stock).Error; err != nil {
+			if err := tx.Create(&stock).Error; err != nil {
+				tx.Rollback()
 				log.Printf("Error creating stock: %v", err)
 				return nil, errors.New("failed to create stock")
 			}
 		} else {
+			tx.Rollback()
 			log.Printf("Error finding stock for update: %v", err)
 			return nil, errors.New("failed to retrieve stock for update")
 		}
 	} else {
+		// Stock unpaid orders hold cannot be counted away here
+		if req.Quantity < stock.ReservedQuantity {
+			tx.Rollback()
+			return nil, errors.New("stock cannot be set below the reserved quantity")
+		}
 		// Update existing stock
 		oldQuantity = stock.Quantity
 		stock.Quantity = req.Quantity
 		quantityChange = req.Quantity - oldQuantity
-		if err := s.DB.Save(&stock).Error; err != nil {
+		if err := tx.Save(&stock).Error; err != nil {
+			tx.Rollback()
 			log.Printf("Error updating stock: %v", err)
 			return nil, errors.New("failed to update stock")
 		}
+		if err := consumeStockLots(tx, stock.ID, -quantityChange); err != nil {
+			tx.Rollback()
+			log.Printf("Error consuming stock lots: %v", err)
+			return nil, errors.New("failed to update stock")
+		}
+		checkStockLevel(tx, stock)
 	}
 
 	// Record stock movement
 	if quantityChange != 0 {
 		movement := &models.StockMovement{
 			OutletID:       outlet.ID,
-			QuantityChange: int(quantityChange),
-			MovementType:   "Adjustment",
+			QuantityChange: quantityChange,
+			MovementType:   models.StockMovementAdjustment,
 			Description:    stringPtr("Direct stock update"),
 		}
 		if variant != nil {
@@ -269,11 +297,18 @@ func (s *StockService) UpdateStock(req dtos.UpdateStockRequest, outletUuid uuid.
 		} else if product != nil {
 			movement.ProductID = &product.ID
 		}
-		if err := s.StockMovementService.CreateStockMovement(movement); err != nil {
+		if err := s.StockMovementService.CreateStockMovementWithTx(tx, movement); err != nil {
+			tx.Rollback()
 			log.Printf("Error recording stock movement: %v", err)
+			return nil, errors.New("failed to record stock movement")
 		}
 	}
 
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to commit transaction")
+	}
+
 	// Build response
 	resp := &dtos.StockResponse{Quantity: stock.Quantity}
 	if variant != nil {
@@ -318,23 +353,47 @@ func (s *StockService) ProduceFNBProduct(req dtos.FNBProductionRequest, outletUu
 		return nil, errors.New("F&B main product has no recipes defined")
 	}
 
-	tx := s.DB.Begin()
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
 		}
 	}()
 
+	productIDs := []uint{mainProduct.ID}
+	for _, recipe := range mainProduct.Recipes {
+		productIDs = append(productIDs, recipe.ComponentID)
+	}
+	if err := lockStocks(tx, outlet.ID, productIDs, nil); err != nil {
+		tx.Rollback()
+		log.Printf("Error locking stocks for F&B production: %v", err)
+		return nil, errors.New("failed to complete F&B production")
+	}
+
+	// Every movement of the batch references this run
+	run := models.ProductionRun{
+		OutletID:  outlet.ID,
+		ProductID: mainProduct.ID,
+		Quantity:  req.QuantityToProduce,
+		UserID:    ownerID,
+	}
+	if err := tx.Omit("Outlet", "Product").Create(&run).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error creating production run: %v", err)
+		return nil, errors.New("failed to complete F&B production")
+	}
+
 	// Deduct component stocks
 	for _, recipe := range mainProduct.Recipes {
 		if recipe.Component.ID == 0 { // Ensure component is loaded
 			tx.Rollback()
 			return nil, errors.New("recipe component not found")
 		}
-		requiredQuantity := recipe.Quantity * req.QuantityToProduce
+		// Recipe lines may be in another unit than the component is stocked in
+		requiredQuantity := recipe.Quantity * recipe.ConversionFactor * req.QuantityToProduce
 
 		// Deduct stock for the component
-		err := s.DeductStockForSale(tx, outlet.ID, &recipe.Component.ID, nil, requiredQuantity, userID)
+		err := s.deductStock(tx, outlet.ID, &recipe.Component.ID, nil, requiredQuantity, userID, models.StockMovementProductionUsage, models.StockReferenceProductionRun, &run.ID, "Used in production of "+mainProduct.Name)
 		if err != nil {
 			tx.Rollback()
 			log.Printf("Error deducting stock for component %s: %v", recipe.Component.Name, err)
@@ -357,10 +416,12 @@ func (s *StockService) ProduceFNBProduct(req dtos.FNBProductionRequest, outletUu
 				UserID:    ownerID,
 			}
 			if err := tx.Create(&mainProductStock).Error; err != nil {
+				tx.Rollback()
 				log.Printf("Error creating stock for main F&B product: %v", err)
 				return nil, errors.New("failed to create stock for main F&B product")
 			}
 		} else {
+			tx.Rollback()
 			log.Printf("Error finding stock for main F&B product: %v", err)
 			return nil, errors.New("failed to retrieve stock for main F&B product")
 		}
@@ -368,6 +429,7 @@ func (s *StockService) ProduceFNBProduct(req dtos.FNBProductionRequest, outletUu
 		// Update existing stock for the main product
 		mainProductStock.Quantity += req.QuantityToProduce
 		if err := tx.Save(&mainProductStock).Error; err != nil {
+			tx.Rollback()
 			log.Printf("Error updating stock for main F&B product: %v", err)
 			return nil, errors.New("failed to update stock for main F&B product")
 		}
@@ -377,13 +439,16 @@ func (s *StockService) ProduceFNBProduct(req dtos.FNBProductionRequest, outletUu
 	movement := &models.StockMovement{
 		OutletID:       outlet.ID,
 		ProductID:      &mainProduct.ID,
-		QuantityChange: int(req.QuantityToProduce),
-		MovementType:   "Production",
+		QuantityChange: req.QuantityToProduce,
+		MovementType:   models.StockMovementProduction,
+		ReferenceType:  models.StockReferenceProductionRun,
+		ReferenceID:    &run.ID,
 		Description:    stringPtr("Produced F&B main product"),
 	}
 	if err := s.StockMovementService.CreateStockMovementWithTx(tx, movement); err != nil {
+		tx.Rollback()
 		log.Printf("Error recording production stock movement: %v", err)
-		// Don't rollback for movement logging failure, but log it.
+		return nil, errors.New("failed to complete F&B production")
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -392,6 +457,7 @@ func (s *StockService) ProduceFNBProduct(req dtos.FNBProductionRequest, outletUu
 	}
 
 	return &dtos.FNBProductionResponse{
+		ProductionRunUuid:  run.Uuid,
 		FNBMainProductUuid: mainProduct.Uuid,
 		ProductName:        mainProduct.Name,
 		QuantityProduced:   req.QuantityToProduce,
@@ -399,48 +465,54 @@ func (s *StockService) ProduceFNBProduct(req dtos.FNBProductionRequest, outletUu
 	}, nil
 }
 
-func (s *StockService) DeductStockForSale(tx *gorm.DB, outletID uint, productID *uint, productVariantID *uint, quantity float64, userID uint) error {
-	var stock models.Stock
-	var query *gorm.DB
-
-	if productVariantID != nil {
-		query = tx.Where("outlet_id = ? AND product_variant_id = ? AND user_id = ?", outletID, *productVariantID, userID)
-	} else if productID != nil {
-		query = tx.Where("outlet_id = ? AND product_id = ? AND user_id = ?", outletID, *productID, userID)
-	} else {
-		return errors.New("product_id or product_variant_id is required for stock deduction")
-	}
+// DeductStockForSale takes sold stock out of an outlet. The stock row is locked while it is checked
+// and updated, so two cashiers selling the last unit cannot both succeed. The reference names the
+// order the stock left with, and may be empty.
+func (s *StockService) DeductStockForSale(tx *gorm.DB, outletID uint, productID *uint, productVariantID *uint, quantity float64, userID uint, referenceType string, referenceID *uint) error {
+	return s.deductStock(tx, outletID, productID, productVariantID, quantity, userID, models.StockMovementOrder, referenceType, referenceID, "Deduction for sale")
+}
 
-	if err := query.First(&stock).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("stock not found")
-		}
+// deductStock takes available stock out of an outlet under a row lock and records the movement
+// with the given type and description.
+func (s *StockService) deductStock(tx *gorm.DB, outletID uint, productID *uint, productVariantID *uint, quantity float64, userID uint, movementType string, referenceType string, referenceID *uint, description string) error {
+	stock, err := lockSaleStock(tx, outletID, productID, productVariantID, userID)
+	if err != nil {
 		return err
 	}
 
-	if stock.Quantity < quantity {
+	// Stock reserved by unpaid orders is not available for sale
+	if availableStock(stock) < quantity {
 		return errors.New("insufficient stock")
 	}
 
+	previousQuantity := stock.Quantity
 	stock.Quantity -= quantity
-	if err := tx.Save(&stock).Error; err != nil {
+	if err := tx.Model(&stock).Update("quantity", stock.Quantity).Error; err != nil {
+		return err
+	}
+	if err := consumeStockLots(tx, stock.ID, quantity); err != nil {
 		return err
 	}
+	recordStockLowEvent(tx, stock, previousQuantity)
+	checkStockLevel(tx, stock)
 
 	// Record stock movement
 	movement := &models.StockMovement{
-		OutletID:       outletID,
-		ProductID:      productID,
+		OutletID:         outletID,
+		ProductID:        productID,
 		ProductVariantID: productVariantID,
-		QuantityChange: int(-quantity),
-		MovementType:   "Order",
-		Description:    stringPtr("Deduction for sale"),
+		QuantityChange:   -quantity,
+		MovementType:     movementType,
+		ReferenceType:    referenceType,
+		ReferenceID:      referenceID,
+		Description:      stringPtr(description),
+		CreatedBy:        movementCreator(tx, userID),
 	}
 	return s.StockMovementService.CreateStockMovementWithTx(tx, movement)
 }
 
-// stringPtr is a helper function to return a pointer to a string.
-func (s *StockService) AddStockFromSale(tx *gorm.DB, outletID uint, productID *uint, productVariantID *uint, quantity float64, userID uint) error {
+// AddStockFromSale puts stock back when a sale is changed or cancelled.
+func (s *StockService) AddStockFromSale(tx *gorm.DB, outletID uint, productID *uint, productVariantID *uint, quantity float64, userID uint, referenceType string, referenceID *uint) error {
 	var stock models.Stock
 	var query *gorm.DB
 
@@ -452,7 +524,7 @@ func (s *StockService) AddStockFromSale(tx *gorm.DB, outletID uint, productID *u
 		return errors.New("product_id or product_variant_id is required for stock addition")
 	}
 
-	if err := query.First(&stock).Error; err != nil {
+	if err := query.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stock).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// If stock not found, create a new entry
 			stock = models.Stock{
@@ -473,9 +545,10 @@ func (s *StockService) AddStockFromSale(tx *gorm.DB, outletID uint, productID *u
 		}
 	} else {
 		stock.Quantity += quantity
-		if err := tx.Save(&stock).Error; err != nil {
+		if err := tx.Model(&stock).Update("quantity", stock.Quantity).Error; err != nil {
 			return err
 		}
+		checkStockLevel(tx, stock)
 	}
 
 	// Record stock movement
@@ -483,14 +556,221 @@ func (s *StockService) AddStockFromSale(tx *gorm.DB, outletID uint, productID *u
 		OutletID:         outletID,
 		ProductID:        productID,
 		ProductVariantID: productVariantID,
-		QuantityChange:   int(quantity),
-		MovementType:     "Return",
+		QuantityChange:   quantity,
+		MovementType:     models.StockMovementReturn,
+		ReferenceType:    referenceType,
+		ReferenceID:      referenceID,
 		Description:      stringPtr("Addition from sale return/correction"),
+		CreatedBy:        movementCreator(tx, userID),
 	}
 	return s.StockMovementService.CreateStockMovementWithTx(tx, movement)
 }
 
+// availableStock is the stock that can still leave an outlet: what is on hand less what unpaid
+// orders have reserved.
+func availableStock(stock models.Stock) float64 {
+	return stock.Quantity - stock.ReservedQuantity
+}
+
+// lockSaleStock loads and locks the stock a sale of a product or variant draws from.
+func lockSaleStock(tx *gorm.DB, outletID uint, productID *uint, productVariantID *uint, userID uint) (models.Stock, error) {
+	var stock models.Stock
+	var query *gorm.DB
+	if productVariantID != nil {
+		query = tx.Where("outlet_id = ? AND product_variant_id = ? AND user_id = ?", outletID, *productVariantID, userID)
+	} else if productID != nil {
+		query = tx.Where("outlet_id = ? AND product_id = ? AND user_id = ?", outletID, *productID, userID)
+	} else {
+		return stock, errors.New("product_id or product_variant_id is required for stock deduction")
+	}
+
+	if err := query.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stock).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return stock, errors.New("stock not found")
+		}
+		return stock, err
+	}
+	return stock, nil
+}
+
+// lockStocks locks the stock rows of the given products and variants at an outlet in ID order.
+// Transactions that change more than one stock row call it before changing any, so two of them
+// sharing rows queue up in the same order instead of deadlocking.
+func lockStocks(tx *gorm.DB, outletID uint, productIDs []uint, variantIDs []uint) error {
+	if len(productIDs) == 0 && len(variantIDs) == 0 {
+		return nil
+	}
+
+	var stocks []models.Stock
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("outlet_id = ?", outletID).
+		Where("product_variant_id IN ? OR product_id IN ?", variantIDs, productIDs).
+		Order("id").
+		Find(&stocks).Error
+}
+
 // stringPtr is a helper function to return a pointer to a string.
 func stringPtr(s string) *string {
 	return &s
-}
\ No newline at end of file
+}
+
+// GetStockMovements reads the stock movement ledger of an outlet, newest first.
+func (s *StockService) GetStockMovements(outletUuid uuid.UUID, filter dtos.StockMovementFilter, userID uint) ([]dtos.StockMovementResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	query := s.DB.Preload("Product").Preload("ProductVariant.Product").Preload("Creator").Where("outlet_id = ?", outlet.ID)
+	if filter.ProductVariantUuid != nil {
+		var variant models.ProductVariant
+		if err := s.DB.Where("uuid = ? AND user_id = ?", *filter.ProductVariantUuid, ownerID).First(&variant).Error; err != nil {
+			return nil, errors.New("product variant not found")
+		}
+		query = query.Where("product_variant_id = ?", variant.ID)
+	} else if filter.ProductUuid != nil {
+		var product models.Product
+		if err := s.DB.Where("uuid = ? AND user_id = ?", *filter.ProductUuid, ownerID).First(&product).Error; err != nil {
+			return nil, errors.New("product not found")
+		}
+		query = query.Where("(product_id = ? OR product_variant_id IN (?))", product.ID, s.DB.Model(&models.ProductVariant{}).Select("id").Where("product_id = ?", product.ID))
+	}
+	if filter.MovementType != "" {
+		query = query.Where("movement_type = ?", filter.MovementType)
+	}
+	if filter.ReferenceType != "" {
+		query = query.Where("reference_type = ?", filter.ReferenceType)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("created_at >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("created_at < ?", filter.EndDate.Add(24*time.Hour))
+	}
+
+	var movements []models.StockMovement
+	if err := query.Order("created_at desc, id desc").Limit(500).Find(&movements).Error; err != nil {
+		log.Printf("Error getting stock movements: %v", err)
+		return nil, errors.New("failed to retrieve stock movements")
+	}
+
+	referenceUuids, err := s.stockMovementReferenceUuids(movements)
+	if err != nil {
+		log.Printf("Error getting stock movement references: %v", err)
+		return nil, errors.New("failed to retrieve stock movements")
+	}
+
+	responses := make([]dtos.StockMovementResponse, 0, len(movements))
+	for _, movement := range movements {
+		response := dtos.StockMovementResponse{
+			ID:             movement.ID,
+			QuantityChange: movement.QuantityChange,
+			BalanceAfter:   movement.BalanceAfter,
+			MovementType:   movement.MovementType,
+			ReferenceType:  movement.ReferenceType,
+			CreatedAt:      movement.CreatedAt,
+		}
+		if movement.ProductVariant != nil {
+			response.ProductUuid = &movement.ProductVariant.Product.Uuid
+			response.ProductName = movement.ProductVariant.Product.Name
+			response.ProductVariantUuid = &movement.ProductVariant.Uuid
+			response.VariantName = movement.ProductVariant.Name
+		} else if movement.Product != nil {
+			response.ProductUuid = &movement.Product.Uuid
+			response.ProductName = movement.Product.Name
+		}
+		if movement.ReferenceID != nil {
+			if referenceUuid, ok := referenceUuids[movement.ReferenceType][*movement.ReferenceID]; ok {
+				response.ReferenceUuid = &referenceUuid
+			}
+		}
+		if movement.Description != nil {
+			response.Description = *movement.Description
+		}
+		if movement.Creator != nil {
+			response.PerformedBy = movement.Creator.Name
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// stockMovementReferenceTables maps each reference type to the table its reference IDs point into.
+var stockMovementReferenceTables = map[string]string{
+	models.StockReferenceOrder:           "orders",
+	models.StockReferencePurchaseOrder:   "purchase_orders",
+	models.StockReferenceStockTransfer:   "stock_transfers",
+	models.StockReferenceStockCount:      "stock_counts",
+	models.StockReferenceStockLot:        "stock_lots",
+	models.StockReferenceStockAdjustment: "stock_adjustments",
+}
+
+// stockMovementReferenceUuids looks up the uuids of the records the movements reference, by
+// reference type and ID, so the ledger does not expose internal IDs.
+func (s *StockService) stockMovementReferenceUuids(movements []models.StockMovement) (map[string]map[uint]uuid.UUID, error) {
+	ids := make(map[string][]uint)
+	for _, movement := range movements {
+		if movement.ReferenceID != nil && movement.ReferenceType != "" {
+			ids[movement.ReferenceType] = append(ids[movement.ReferenceType], *movement.ReferenceID)
+		}
+	}
+
+	uuids := make(map[string]map[uint]uuid.UUID, len(ids))
+	for referenceType, referenceIDs := range ids {
+		table, ok := stockMovementReferenceTables[referenceType]
+		if !ok {
+			continue
+		}
+		var rows []struct {
+			ID   uint
+			Uuid uuid.UUID
+		}
+		if err := s.DB.Table(table).Select("id, uuid").Where("id IN ?", referenceIDs).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		uuids[referenceType] = make(map[uint]uuid.UUID, len(rows))
+		for _, row := range rows {
+			uuids[referenceType][row.ID] = row.Uuid
+		}
+	}
+	return uuids, nil
+}
+
+// GetStockAsOf rebuilds the stock of an outlet at the end of a day by summing its movement ledger.
+func (s *StockService) GetStockAsOf(outletUuid uuid.UUID, date time.Time, userID uint) (*dtos.StockAsOfResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	response := &dtos.StockAsOfResponse{
+		OutletUuid: outlet.Uuid,
+		Date:       date.Format("2006-01-02"),
+		Items:      []dtos.StockAsOfItem{},
+	}
+	if err := s.DB.Table("stock_movements").
+		Select("products.uuid AS product_uuid, products.name AS product_name, products.sku AS product_sku, " +
+			"product_variants.uuid AS product_variant_uuid, COALESCE(product_variants.name, '') AS variant_name, COALESCE(product_variants.sku, '') AS variant_sku, " +
+			"SUM(stock_movements.quantity_change) AS quantity").
+		Joins("LEFT JOIN product_variants ON product_variants.id = stock_movements.product_variant_id").
+		Joins("JOIN products ON products.id = COALESCE(product_variants.product_id, stock_movements.product_id)").
+		Where("stock_movements.outlet_id = ? AND stock_movements.created_at < ? AND stock_movements.deleted_at IS NULL", outlet.ID, date.Add(24*time.Hour)).
+		Group("products.uuid, products.name, products.sku, product_variants.uuid, product_variants.name, product_variants.sku").
+		Order("products.name, product_variants.name").
+		Scan(&response.Items).Error; err != nil {
+		log.Printf("Error calculating stock as of %s: %v", response.Date, err)
+		return nil, errors.New("failed to retrieve stock movements")
+	}
+	return response, nil
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"gorm.io/gorm"
+)
+
+func newTestStockService(db *gorm.DB) *StockService {
+	return NewStockService(db, NewUserContextService(db), NewStockMovementService(db))
+}
+
+func TestDeductStockForSaleSellsTheLastUnitOnce(t *testing.T) {
+	db := openTestDB(t)
+	owner, outlet := createTestOwner(t, db, false)
+	product, stock := createTestStock(t, db, outlet, "Last unit", 1)
+	s := newTestStockService(db)
+
+	const sellers = 8
+	start := make(chan struct{})
+	errs := make(chan error, sellers)
+	var wg sync.WaitGroup
+	for i := 0; i < sellers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			tx := db.WithContext(testUserContext(owner.ID)).Begin()
+			if err := s.DeductStockForSale(tx, outlet.ID, &product.ID, nil, 1, owner.ID, "", nil); err != nil {
+				tx.Rollback()
+				errs <- err
+				return
+			}
+			errs <- tx.Commit().Error
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	sold := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			sold++
+		case err.Error() != "insufficient stock":
+			t.Errorf("expected insufficient stock, got %v", err)
+		}
+	}
+	if sold != 1 {
+		t.Fatalf("expected exactly one sale of the last unit, got %d", sold)
+	}
+
+	if err := db.First(&stock, stock.ID).Error; err != nil {
+		t.Fatalf("failed to reload stock: %v", err)
+	}
+	if stock.Quantity != 0 {
+		t.Fatalf("expected no stock left, got %v", stock.Quantity)
+	}
+
+	var movements int64
+	db.Model(&models.StockMovement{}).Where("outlet_id = ? AND product_id = ?", outlet.ID, product.ID).Count(&movements)
+	if movements != 1 {
+		t.Fatalf("expected one stock movement, got %d", movements)
+	}
+}
+
+// Two sales of the same items listed in opposite order take their locks through lockStocks, so
+// they queue on each other instead of deadlocking.
+func TestLockStocksAvoidsDeadlockBetweenOppositeOrders(t *testing.T) {
+	db := openTestDB(t)
+	owner, outlet := createTestOwner(t, db, false)
+	first, firstStock := createTestStock(t, db, outlet, "First item", 10)
+	second, secondStock := createTestStock(t, db, outlet, "Second item", 10)
+	s := newTestStockService(db)
+
+	orders := [][]uint{{first.ID, second.ID}, {second.ID, first.ID}}
+	const rounds = 5
+	for round := 0; round < rounds; round++ {
+		start := make(chan struct{})
+		errs := make(chan error, len(orders))
+		var wg sync.WaitGroup
+		for _, productIDs := range orders {
+			wg.Add(1)
+			go func(productIDs []uint) {
+				defer wg.Done()
+				<-start
+				tx := db.WithContext(testUserContext(owner.ID)).Begin()
+				if err := lockStocks(tx, outlet.ID, productIDs, nil); err != nil {
+					tx.Rollback()
+					errs <- err
+					return
+				}
+				for _, productID := range productIDs {
+					productID := productID
+					// Give the other sale time to reach its own locks
+					time.Sleep(20 * time.Millisecond)
+					if err := s.DeductStockForSale(tx, outlet.ID, &productID, nil, 1, owner.ID, "", nil); err != nil {
+						tx.Rollback()
+						errs <- err
+						return
+					}
+				}
+				errs <- tx.Commit().Error
+			}(productIDs)
+		}
+		close(start)
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if err != nil {
+				t.Fatalf("round %d: expected both sales to succeed, got %v", round+1, err)
+			}
+		}
+	}
+
+	for _, stock := range []models.Stock{firstStock, secondStock} {
+		if err := db.First(&stock, stock.ID).Error; err != nil {
+			t.Fatalf("failed to reload stock: %v", err)
+		}
+		if want := 10.0 - 2*rounds; stock.Quantity != want {
+			t.Fatalf("expected %v left of stock %d, got %v", want, stock.ID, stock.Quantity)
+		}
+	}
+}
+
+func TestUpdateStockRecordsMovementWithTheNewBalance(t *testing.T) {
+	db := openTestDB(t)
+	owner, outlet := createTestOwner(t, db, false)
+	product, stock := createTestStock(t, db, outlet, "Counted item", 10)
+	s := newTestStockService(db)
+
+	if _, err := s.UpdateStock(dtos.UpdateStockRequest{ProductUuid: product.Uuid, Quantity: 6}, outlet.Uuid, owner.ID); err != nil {
+		t.Fatalf("failed to update stock: %v", err)
+	}
+
+	if err := db.First(&stock, stock.ID).Error; err != nil {
+		t.Fatalf("failed to reload stock: %v", err)
+	}
+	if stock.Quantity != 6 {
+		t.Fatalf("expected 6 on hand, got %v", stock.Quantity)
+	}
+	var movement models.StockMovement
+	if err := db.Where("product_id = ?", product.ID).First(&movement).Error; err != nil {
+		t.Fatalf("failed to load stock movement: %v", err)
+	}
+	if movement.QuantityChange != -4 || movement.BalanceAfter != 6 || movement.MovementType != models.StockMovementAdjustment {
+		t.Fatalf("unexpected stock movement %+v", movement)
+	}
+}
+
+func TestProduceFNBProductReferencesTheProductionRun(t *testing.T) {
+	db := openTestDB(t)
+	owner, outlet := createTestOwner(t, db, false)
+	ingredient, _ := createTestStock(t, db, outlet, "Coffee beans", 100)
+	drink := models.Product{Name: "Espresso", Price: 20000, SKU: ingredient.SKU + "-FNB", Type: "fnb_main_product", UserID: owner.ID}
+	if err := db.Create(&drink).Error; err != nil {
+		t.Fatalf("failed to create F&B main product: %v", err)
+	}
+	recipe := models.Recipe{MainProductID: drink.ID, ComponentID: ingredient.ID, Quantity: 18, ConversionFactor: 1, UserID: owner.ID}
+	if err := db.Omit("MainProduct", "Component", "User").Create(&recipe).Error; err != nil {
+		t.Fatalf("failed to create recipe: %v", err)
+	}
+	s := newTestStockService(db)
+
+	response, err := s.ProduceFNBProduct(dtos.FNBProductionRequest{FNBMainProductUuid: drink.Uuid, QuantityToProduce: 2}, outlet.Uuid, owner.ID)
+	if err != nil {
+		t.Fatalf("failed to produce: %v", err)
+	}
+	var run models.ProductionRun
+	if err := db.Where("uuid = ?", response.ProductionRunUuid).First(&run).Error; err != nil {
+		t.Fatalf("failed to load production run: %v", err)
+	}
+
+	var movements []models.StockMovement
+	if err := db.Where("reference_type = ? AND reference_id = ?", models.StockReferenceProductionRun, run.ID).Order("id").Find(&movements).Error; err != nil {
+		t.Fatalf("failed to load stock movements: %v", err)
+	}
+	if len(movements) != 2 {
+		t.Fatalf("expected the ingredient and the product movement, got %d", len(movements))
+	}
+	used, produced := movements[0], movements[1]
+	if used.MovementType != models.StockMovementProductionUsage || *used.ProductID != ingredient.ID || used.QuantityChange != -36 || used.BalanceAfter != 64 {
+		t.Fatalf("unexpected ingredient movement %+v", used)
+	}
+	if produced.MovementType != models.StockMovementProduction || *produced.ProductID != drink.ID || produced.QuantityChange != 2 {
+		t.Fatalf("unexpected product movement %+v", produced)
+	}
+}
@@ -0,0 +1,556 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInsufficientTransferStock = errors.New("insufficient stock for transfer")
+
+type StockTransferService struct {
+	DB                   *gorm.DB
+	UserContextService   *UserContextService
+	StockMovementService *StockMovementService
+}
+
+func NewStockTransferService(db *gorm.DB, userContextService *UserContextService, stockMovementService *StockMovementService) *StockTransferService {
+	return &StockTransferService{DB: db, UserContextService: userContextService, StockMovementService: stockMovementService}
+}
+
+// CreateTransfer creates a draft transfer. No stock moves until the transfer is sent.
+func (s *StockTransferService) CreateTransfer(req dtos.StockTransferRequest, userID uint) (*dtos.StockTransferResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+	db := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID))
+
+	sourceID, destinationID, err := findTransferOutlets(db, req, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := resolveTransferItems(db, req.Items, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := models.StockTransfer{
+		SourceOutletID:      sourceID,
+		DestinationOutletID: destinationID,
+		Status:              models.StockTransferStatusDraft,
+		Note:                req.Note,
+		Items:               items,
+		UserID:              ownerID,
+	}
+	if err := db.Create(&transfer).Error; err != nil {
+		log.Printf("Error creating stock transfer: %v", err)
+		return nil, errors.New("failed to create stock transfer")
+	}
+	return s.getTransfer(transfer.ID)
+}
+
+// UpdateTransfer replaces the outlets, note and items of a draft transfer.
+func (s *StockTransferService) UpdateTransfer(transferUuid uuid.UUID, req dtos.StockTransferRequest, userID uint) (*dtos.StockTransferResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	transfer, err := lockStockTransfer(tx, transferUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if transfer.Status != models.StockTransferStatusDraft {
+		tx.Rollback()
+		return nil, errors.New("only draft stock transfers can be edited")
+	}
+
+	sourceID, destinationID, err := findTransferOutlets(tx, req, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	items, err := resolveTransferItems(tx, req.Items, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for i := range items {
+		items[i].StockTransferID = transfer.ID
+	}
+
+	if err := tx.Model(transfer).Updates(map[string]interface{}{
+		"source_outlet_id":      sourceID,
+		"destination_outlet_id": destinationID,
+		"note":                  req.Note,
+	}).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error updating stock transfer: %v", err)
+		return nil, errors.New("failed to update stock transfer")
+	}
+	if err := tx.Where("stock_transfer_id = ?", transfer.ID).Delete(&models.StockTransferItem{}).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error deleting stock transfer items: %v", err)
+		return nil, errors.New("failed to update stock transfer")
+	}
+	if err := tx.Create(&items).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error creating stock transfer items: %v", err)
+		return nil, errors.New("failed to update stock transfer")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return s.getTransfer(transfer.ID)
+}
+
+// SendTransfer deducts every item from the source outlet. The stock is in transit until the
+// destination outlet receives it.
+func (s *StockTransferService) SendTransfer(transferUuid uuid.UUID, userID uint) (*dtos.StockTransferResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	transfer, err := lockStockTransfer(tx, transferUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if transfer.Status != models.StockTransferStatusDraft {
+		tx.Rollback()
+		return nil, errors.New("only draft stock transfers can be sent")
+	}
+
+	if err := lockTransferStocks(tx, *transfer, transfer.SourceOutletID); err != nil {
+		tx.Rollback()
+		log.Printf("Error locking stocks for transfer %d: %v", transfer.ID, err)
+		return nil, errors.New("failed to update stock")
+	}
+	for _, item := range transfer.Items {
+		if err := s.moveTransferStock(tx, *transfer, item, transfer.SourceOutletID, -item.Quantity, models.StockMovementTransferOut, "Sent on stock transfer"); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Model(transfer).Updates(map[string]interface{}{
+		"status":  models.StockTransferStatusSent,
+		"sent_at": time.Now(),
+		"sent_by": userID,
+	}).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error sending stock transfer: %v", err)
+		return nil, errors.New("failed to send stock transfer")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return s.getTransfer(transfer.ID)
+}
+
+// ReceiveTransfer adds what arrived to the destination outlet and closes the transfer. Items can
+// be received short; the shortfall stays on the transfer with its discrepancy note.
+func (s *StockTransferService) ReceiveTransfer(transferUuid uuid.UUID, req dtos.ReceiveStockTransferRequest, userID uint) (*dtos.StockTransferResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	transfer, err := lockStockTransfer(tx, transferUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if transfer.Status != models.StockTransferStatusSent {
+		tx.Rollback()
+		return nil, errors.New("only sent stock transfers can be received")
+	}
+
+	received := make(map[uuid.UUID]dtos.ReceiveStockTransferItemRequest, len(req.Items))
+	for _, receivedItem := range req.Items {
+		received[receivedItem.ItemUuid] = receivedItem
+	}
+
+	if err := lockTransferStocks(tx, *transfer, transfer.DestinationOutletID); err != nil {
+		tx.Rollback()
+		log.Printf("Error locking stocks for transfer %d: %v", transfer.ID, err)
+		return nil, errors.New("failed to update stock")
+	}
+	for _, item := range transfer.Items {
+		quantity := item.Quantity
+		note := ""
+		if receivedItem, ok := received[item.Uuid]; ok {
+			delete(received, item.Uuid)
+			quantity = *receivedItem.ReceivedQuantity
+			note = receivedItem.DiscrepancyNote
+		}
+		if quantity > item.Quantity {
+			tx.Rollback()
+			return nil, errors.New("received quantity cannot exceed the sent quantity")
+		}
+		if quantity < item.Quantity && note == "" {
+			tx.Rollback()
+			return nil, errors.New("discrepancy note is required when less is received than sent")
+		}
+
+		if err := tx.Model(&item).Updates(map[string]interface{}{
+			"received_quantity": quantity,
+			"discrepancy_note":  note,
+		}).Error; err != nil {
+			tx.Rollback()
+			log.Printf("Error updating stock transfer item: %v", err)
+			return nil, errors.New("failed to receive stock transfer")
+		}
+		if quantity > 0 {
+			if err := s.moveTransferStock(tx, *transfer, item, transfer.DestinationOutletID, quantity, models.StockMovementTransferIn, "Received on stock transfer"); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+	}
+	if len(received) > 0 {
+		tx.Rollback()
+		return nil, errors.New("stock transfer item not found")
+	}
+
+	if err := tx.Model(transfer).Updates(map[string]interface{}{
+		"status":      models.StockTransferStatusReceived,
+		"received_at": time.Now(),
+		"received_by": userID,
+	}).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error receiving stock transfer: %v", err)
+		return nil, errors.New("failed to receive stock transfer")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return s.getTransfer(transfer.ID)
+}
+
+// CancelTransfer cancels a draft or in-transit transfer. Stock already sent goes back to the
+// source outlet.
+func (s *StockTransferService) CancelTransfer(transferUuid uuid.UUID, userID uint) (*dtos.StockTransferResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	transfer, err := lockStockTransfer(tx, transferUuid, ownerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if transfer.Status != models.StockTransferStatusDraft && transfer.Status != models.StockTransferStatusSent {
+		tx.Rollback()
+		return nil, errors.New("stock transfer can no longer be cancelled")
+	}
+
+	if transfer.Status == models.StockTransferStatusSent {
+		if err := lockTransferStocks(tx, *transfer, transfer.SourceOutletID); err != nil {
+			tx.Rollback()
+			log.Printf("Error locking stocks for transfer %d: %v", transfer.ID, err)
+			return nil, errors.New("failed to update stock")
+		}
+		for _, item := range transfer.Items {
+			if err := s.moveTransferStock(tx, *transfer, item, transfer.SourceOutletID, item.Quantity, models.StockMovementTransferReturn, "Returned from cancelled stock transfer"); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Model(transfer).Updates(map[string]interface{}{
+		"status":       models.StockTransferStatusCancelled,
+		"cancelled_at": time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Error cancelling stock transfer: %v", err)
+		return nil, errors.New("failed to cancel stock transfer")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("failed to commit transaction")
+	}
+	return s.getTransfer(transfer.ID)
+}
+
+func (s *StockTransferService) GetTransfer(transferUuid uuid.UUID, userID uint) (*dtos.StockTransferResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var transfer models.StockTransfer
+	if err := stockTransferPreloads(s.DB).Where("uuid = ? AND user_id = ?", transferUuid, ownerID).First(&transfer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("stock transfer not found")
+		}
+		log.Printf("Error getting stock transfer: %v", err)
+		return nil, errors.New("failed to retrieve stock transfer")
+	}
+	return mapStockTransferToResponse(transfer), nil
+}
+
+// GetTransfersByOutlet lists the transfers an outlet sends ("outgoing"), receives ("incoming"),
+// or both when no direction is given.
+func (s *StockTransferService) GetTransfersByOutlet(outletUuid uuid.UUID, direction string, status string, userID uint) ([]dtos.StockTransferResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outlet models.Outlet
+	if err := s.DB.Where("uuid = ? AND user_id = ?", outletUuid, ownerID).First(&outlet).Error; err != nil {
+		return nil, errors.New("outlet not found")
+	}
+
+	query := stockTransferPreloads(s.DB).Where("user_id = ?", ownerID)
+	switch direction {
+	case "outgoing":
+		query = query.Where("source_outlet_id = ?", outlet.ID)
+	case "incoming":
+		query = query.Where("destination_outlet_id = ?", outlet.ID)
+	case "":
+		query = query.Where("source_outlet_id = ? OR destination_outlet_id = ?", outlet.ID, outlet.ID)
+	default:
+		return nil, errors.New("invalid stock transfer direction")
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var transfers []models.StockTransfer
+	if err := query.Order("created_at desc").Find(&transfers).Error; err != nil {
+		log.Printf("Error getting stock transfers: %v", err)
+		return nil, errors.New("failed to retrieve stock transfers")
+	}
+
+	responses := make([]dtos.StockTransferResponse, 0, len(transfers))
+	for _, transfer := range transfers {
+		responses = append(responses, *mapStockTransferToResponse(transfer))
+	}
+	return responses, nil
+}
+
+func (s *StockTransferService) getTransfer(transferID uint) (*dtos.StockTransferResponse, error) {
+	var transfer models.StockTransfer
+	if err := stockTransferPreloads(s.DB).First(&transfer, transferID).Error; err != nil {
+		return nil, errors.New("stock transfer not found")
+	}
+	return mapStockTransferToResponse(transfer), nil
+}
+
+// lockTransferStocks locks the stock of every item of a transfer at one of its outlets.
+func lockTransferStocks(tx *gorm.DB, transfer models.StockTransfer, outletID uint) error {
+	var productIDs, variantIDs []uint
+	for _, item := range transfer.Items {
+		if item.ProductVariantID != nil {
+			variantIDs = append(variantIDs, *item.ProductVariantID)
+		} else if item.ProductID != nil {
+			productIDs = append(productIDs, *item.ProductID)
+		}
+	}
+	return lockStocks(tx, outletID, productIDs, variantIDs)
+}
+
+// moveTransferStock changes the stock of an item at one outlet and writes the matching stock
+// movement. The stock row is locked so concurrent sales cannot oversell it.
+func (s *StockTransferService) moveTransferStock(tx *gorm.DB, transfer models.StockTransfer, item models.StockTransferItem, outletID uint, quantity float64, movementType string, description string) error {
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("outlet_id = ? AND user_id = ?", outletID, transfer.UserID)
+	if item.ProductVariantID != nil {
+		query = query.Where("product_variant_id = ?", *item.ProductVariantID)
+	} else {
+		query = query.Where("product_id = ?", *item.ProductID)
+	}
+
+	var stock models.Stock
+	if err := query.First(&stock).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Error finding stock for transfer %d: %v", transfer.ID, err)
+			return errors.New("failed to update stock")
+		}
+		if quantity < 0 {
+			return ErrInsufficientTransferStock
+		}
+		stock = models.Stock{
+			OutletID:         outletID,
+			ProductID:        item.ProductID,
+			ProductVariantID: item.ProductVariantID,
+			Quantity:         quantity,
+			UserID:           transfer.UserID,
+		}
+		if err := tx.Create(&stock).Error; err != nil {
+			log.Printf("Error creating stock for transfer %d: %v", transfer.ID, err)
+			return errors.New("failed to update stock")
+		}
+	} else {
+		// Stock reserved by unpaid orders cannot be sent away
+		if quantity < 0 && availableStock(stock)+quantity < 0 {
+			return ErrInsufficientTransferStock
+		}
+		stock.Quantity += quantity
+		if err := tx.Model(&stock).Update("quantity", stock.Quantity).Error; err != nil {
+			log.Printf("Error updating stock for transfer %d: %v", transfer.ID, err)
+			return errors.New("failed to update stock")
+		}
+		if err := consumeStockLots(tx, stock.ID, -quantity); err != nil {
+			log.Printf("Error consuming stock lots for transfer %d: %v", transfer.ID, err)
+			return errors.New("failed to update stock")
+		}
+		checkStockLevel(tx, stock)
+	}
+
+	movement := &models.StockMovement{
+		OutletID:         outletID,
+		ProductID:        item.ProductID,
+		ProductVariantID: item.ProductVariantID,
+		QuantityChange:   quantity,
+		MovementType:     movementType,
+		ReferenceType:    models.StockReferenceStockTransfer,
+		ReferenceID:      &transfer.ID,
+		Description:      stringPtr(description),
+	}
+	if err := s.StockMovementService.CreateStockMovementWithTx(tx, movement); err != nil {
+		log.Printf("Error recording stock movement for transfer %d: %v", transfer.ID, err)
+		return errors.New("failed to record stock movement")
+	}
+	return nil
+}
+
+// lockStockTransfer loads a transfer with its items and locks it for the rest of the transaction.
+func lockStockTransfer(tx *gorm.DB, transferUuid uuid.UUID, ownerID uint) (*models.StockTransfer, error) {
+	var transfer models.StockTransfer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ? AND user_id = ?", transferUuid, ownerID).First(&transfer).Error; err != nil {
+		return nil, errors.New("stock transfer not found")
+	}
+	if err := tx.Where("stock_transfer_id = ?", transfer.ID).Order("id").Find(&transfer.Items).Error; err != nil {
+		log.Printf("Error getting stock transfer items: %v", err)
+		return nil, errors.New("failed to retrieve stock transfer")
+	}
+	return &transfer, nil
+}
+
+func findTransferOutlets(db *gorm.DB, req dtos.StockTransferRequest, ownerID uint) (uint, uint, error) {
+	if req.SourceOutletUuid == req.DestinationOutletUuid {
+		return 0, 0, errors.New("source and destination outlets must be different")
+	}
+
+	var source, destination models.Outlet
+	if err := db.Where("uuid = ? AND user_id = ?", req.SourceOutletUuid, ownerID).First(&source).Error; err != nil {
+		return 0, 0, errors.New("outlet not found")
+	}
+	if err := db.Where("uuid = ? AND user_id = ?", req.DestinationOutletUuid, ownerID).First(&destination).Error; err != nil {
+		return 0, 0, errors.New("outlet not found")
+	}
+	return source.ID, destination.ID, nil
+}
+
+func resolveTransferItems(db *gorm.DB, requests []dtos.StockTransferItemRequest, ownerID uint) ([]models.StockTransferItem, error) {
+	items := make([]models.StockTransferItem, 0, len(requests))
+	for _, req := range requests {
+		item := models.StockTransferItem{Quantity: req.Quantity}
+		if req.ProductVariantUuid != uuid.Nil {
+			var variant models.ProductVariant
+			if err := db.Where("uuid = ? AND user_id = ?", req.ProductVariantUuid, ownerID).First(&variant).Error; err != nil {
+				return nil, errors.New("product variant not found")
+			}
+			item.ProductVariantID = &variant.ID
+		} else {
+			var product models.Product
+			if err := db.Where("uuid = ? AND user_id = ?", req.ProductUuid, ownerID).First(&product).Error; err != nil {
+				return nil, errors.New("product not found")
+			}
+			item.ProductID = &product.ID
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func stockTransferPreloads(db *gorm.DB) *gorm.DB {
+	return db.Preload("SourceOutlet").Preload("DestinationOutlet").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Product").Preload("Items.ProductVariant.Product")
+}
+
+func mapStockTransferToResponse(transfer models.StockTransfer) *dtos.StockTransferResponse {
+	response := &dtos.StockTransferResponse{
+		Uuid:                  transfer.Uuid,
+		SourceOutletUuid:      transfer.SourceOutlet.Uuid,
+		SourceOutletName:      transfer.SourceOutlet.Name,
+		DestinationOutletUuid: transfer.DestinationOutlet.Uuid,
+		DestinationOutletName: transfer.DestinationOutlet.Name,
+		Status:                transfer.Status,
+		Note:                  transfer.Note,
+		SentAt:                transfer.SentAt,
+		ReceivedAt:            transfer.ReceivedAt,
+		CancelledAt:           transfer.CancelledAt,
+		Items:                 make([]dtos.StockTransferItemResponse, 0, len(transfer.Items)),
+		CreatedAt:             transfer.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	for _, item := range transfer.Items {
+		itemResponse := dtos.StockTransferItemResponse{
+			Uuid:             item.Uuid,
+			Quantity:         item.Quantity,
+			ReceivedQuantity: item.ReceivedQuantity,
+			DiscrepancyNote:  item.DiscrepancyNote,
+		}
+		if item.ProductVariant != nil {
+			itemResponse.ProductUuid = item.ProductVariant.Product.Uuid
+			itemResponse.ProductName = item.ProductVariant.Product.Name
+			itemResponse.ProductVariantUuid = &item.ProductVariant.Uuid
+			itemResponse.VariantName = item.ProductVariant.Name
+		} else if item.Product != nil {
+			itemResponse.ProductUuid = item.Product.Uuid
+			itemResponse.ProductName = item.Product.Name
+		}
+		if item.ReceivedQuantity != nil {
+			itemResponse.Discrepancy = *item.ReceivedQuantity - item.Quantity
+		}
+		response.Items = append(response.Items, itemResponse)
+	}
+	return response
+}
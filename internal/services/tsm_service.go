@@ -13,29 +13,37 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/msyaifudin/pos/internal/models"
 	"github.com/msyaifudin/pos/internal/models/dtos"
 	"github.com/msyaifudin/pos/pkg/elasticsearch"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrInvalidTsmToken = errors.New("invalid callback token")
+
+// ErrExpiredTsmToken is returned for a correctly signed callback token issued too long ago.
+var ErrExpiredTsmToken = errors.New("callback token has expired")
+
+// tsmTokenClockSkew is how far in the future a callback token may be issued, for clock drift.
+const tsmTokenClockSkew = time.Minute
+
 type TsmService struct {
 	DB                  *gorm.DB
 	UserContextService  *UserContextService
 	UserPaymentService  *UserPaymentService
 	TsmLogService       *TsmLogService
-	OrderPaymentService *OrderPaymentService
 }
 
-func NewTsmService(db *gorm.DB, userContextService *UserContextService, userPaymentService *UserPaymentService, tsmLogService *TsmLogService, orderPaymentService *OrderPaymentService) *TsmService {
+func NewTsmService(db *gorm.DB, userContextService *UserContextService, userPaymentService *UserPaymentService, tsmLogService *TsmLogService) *TsmService {
 	return &TsmService{
 		DB:                  db,
 		UserContextService:  userContextService,
 		UserPaymentService:  userPaymentService,
 		TsmLogService:       tsmLogService,
-		OrderPaymentService: orderPaymentService,
 	}
 }
 
@@ -106,6 +114,17 @@ func (s *TsmService) generateHeader(bodyPart string) (string, error) {
 	headerPart := s.base64urlEncode(jsonHeader)
 
 	headerBodyPart := headerPart + "." + bodyPart
+	signature, err := s.sign(headerBodyPart)
+	if err != nil {
+		return "", err
+	}
+	headerToken := headerPart + "." + bodyPart + "." + signature
+
+	return headerToken, nil
+}
+
+// sign returns the base64url HS256 signature of data using TSM_KEY.
+func (s *TsmService) sign(data string) (string, error) {
 	key := os.Getenv("TSM_KEY")
 
 	if key == "" {
@@ -113,13 +132,42 @@ func (s *TsmService) generateHeader(bodyPart string) (string, error) {
 	}
 
 	h := hmac.New(sha256.New, []byte(key))
-	h.Write([]byte(headerBodyPart))
+	h.Write([]byte(data))
 	barerPart := h.Sum(nil)
 
-	signature := s.base64urlEncode(barerPart)
-	headerToken := headerPart + "." + bodyPart + "." + signature
+	return s.base64urlEncode(barerPart), nil
+}
 
-	return headerToken, nil
+// VerifyToken checks a token built like generateHeader (header.body.signature, HS256 with TSM_KEY)
+// and returns the decoded body.
+func (s *TsmService) VerifyToken(token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidTsmToken
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidTsmToken
+	}
+	var header map[string]string
+	if err := json.Unmarshal(headerJSON, &header); err != nil || header["alg"] != "HS256" {
+		return nil, ErrInvalidTsmToken
+	}
+
+	expected, err := s.sign(parts[0] + "." + parts[1])
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, ErrInvalidTsmToken
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidTsmToken
+	}
+	return body, nil
 }
 
 func (s *TsmService) generateAPPLinkRequest(userID uint, appCode string, amount float64, trxID string, terminalCode string, merchantCode string) (map[string]interface{}, error) {
@@ -240,17 +288,140 @@ func (s *TsmService) GenerateAPPLink(userID uint, req dtos.TsmGenerateApplinkReq
 	return s.generateAPPLinkRequest(userID, req.AppCode, req.Amount, req.TrxID, req.TerminalCode, req.MerchantCode)
 }
 
-func (s *TsmService) HandleCallback(req dtos.TsmCallbackRequest) error {
-	tx := s.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+// VerifyCallback authenticates a TSM callback and checks it against the order payment it refers to:
+// the Token must be signed with TSM_KEY over the same callback and recently issued, the payment must
+// be a TSM payment, the merchant, terminal and app codes must match the owner's UserTsm, and a PAID
+// amount must match the payment. The order payment row is locked for the rest of the transaction;
+// the returned flag is true when it was already paid.
+func (s *TsmService) VerifyCallback(tx *gorm.DB, req dtos.TsmCallbackRequest, rawBody []byte, token string) (*models.OrderPayment, bool, error) {
+	if len(rawBody) == 0 {
+		rawBody, _ = json.Marshal(req)
+	}
+	if err := s.verifyCallbackToken(token, rawBody, time.Now()); err != nil {
+		return nil, false, err
+	}
+
+	var orderPayment models.OrderPayment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ?", req.PartnerTrxID).First(&orderPayment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, errors.New("order payment not found")
+		}
+		return nil, false, err
+	}
+
+	// A TSM signature only vouches for TSM payments, never for the owner's other gateways
+	var paymentMethod models.PaymentMethod
+	if err := tx.Where("id = ?", orderPayment.PaymentMethodID).First(&paymentMethod).Error; err != nil {
+		return nil, false, errors.New("payment method not found")
+	}
+	if paymentMethod.Issuer != TsmIssuer {
+		return nil, false, errors.New("order payment is not a TSM payment")
+	}
+
+	var order models.Order
+	if err := tx.Where("id = ?", orderPayment.OrderID).First(&order).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to find order for TSM callback: %w", err)
+	}
+
+	var userTsm models.UserTsm
+	if err := tx.Where("user_id = ?", order.UserID).First(&userTsm).Error; err != nil {
+		return nil, false, errors.New("user tsm data not found")
+	}
+	if req.MerchantCode != userTsm.MerchantCode || req.TerminalCode != userTsm.TerminalCode || req.AppCode != userTsm.AppCode {
+		return nil, false, errors.New("callback merchant does not match")
+	}
+
+	if orderPayment.IsPaid {
+		return &orderPayment, true, nil
+	}
+	if req.Status == "PAID" && !amountMatches(orderPayment.AmountPaid, req.Amount) {
+		return nil, false, errors.New("payment amount mismatch")
+	}
+
+	if err := s.RecordCallback(tx, req); err != nil {
+		return nil, false, err
+	}
+	return &orderPayment, false, nil
+}
+
+// verifyCallbackToken checks that token is signed with TSM_KEY over the posted callback and was
+// issued within TSM_CALLBACK_MAX_AGE_MINUTES (default 10) of now.
+func (s *TsmService) verifyCallbackToken(token string, rawBody []byte, now time.Time) error {
+	signedBody, err := s.VerifyToken(token)
+	if err != nil {
+		return err
+	}
+	if !tsmCallbackMatches(signedBody, rawBody) {
+		return ErrInvalidTsmToken
+	}
+	if !tsmTokenIsFresh(signedBody, now) {
+		return ErrExpiredTsmToken
+	}
+	return nil
+}
+
+// tsmTokenIsFresh checks the issue time of a signed callback body. TSM stamps its bodies with a
+// millisecond "timestamp", as generateAPPLinkRequest does; "iat" and "exp" claims are honoured too.
+// A body without an issue time is refused, since it could be replayed at any time.
+func tsmTokenIsFresh(signedBody []byte, now time.Time) bool {
+	var claims map[string]interface{}
+	if err := json.Unmarshal(signedBody, &claims); err != nil {
+		return false
+	}
+
+	var issuedAt time.Time
+	if iat, err := strconv.ParseFloat(tsmFieldString(claims["iat"]), 64); err == nil {
+		issuedAt = time.Unix(int64(iat), 0)
+	} else if timestamp, err := strconv.ParseFloat(tsmFieldString(claims["timestamp"]), 64); err == nil {
+		issuedAt = time.UnixMilli(int64(timestamp))
+	} else {
+		return false
+	}
+	if exp, err := strconv.ParseFloat(tsmFieldString(claims["exp"]), 64); err == nil && !now.Before(time.Unix(int64(exp), 0)) {
+		return false
+	}
+
+	maxAge := 10
+	if minutes, err := strconv.Atoi(os.Getenv("TSM_CALLBACK_MAX_AGE_MINUTES")); err == nil && minutes > 0 {
+		maxAge = minutes
+	}
+	return issuedAt.After(now.Add(-time.Duration(maxAge)*time.Minute)) && issuedAt.Before(now.Add(tsmTokenClockSkew))
+}
+
+// tsmCallbackMatches compares the fields that decide a payment between the signed token body and
+// the posted callback. Amounts may be sent as numbers or strings.
+func tsmCallbackMatches(signedBody, postedBody []byte) bool {
+	var signed, posted map[string]interface{}
+	if err := json.Unmarshal(signedBody, &signed); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(postedBody, &posted); err != nil {
+		return false
+	}
+
+	for _, key := range []string{"partner_trx_id", "status", "amount", "merchant_code", "terminal_code", "app_code"} {
+		if tsmFieldString(signed[key]) != tsmFieldString(posted[key]) {
+			return false
 		}
-	}()
+	}
+	return true
+}
 
+func tsmFieldString(value interface{}) string {
+	if f, ok := value.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// RecordCallback stores a TSM callback in the TsmLog. Crediting the related payment is left
+// to the caller, inside the same transaction.
+func (s *TsmService) RecordCallback(tx *gorm.DB, req dtos.TsmCallbackRequest) error {
 	jsonReq, err := json.Marshal(req)
 	if err != nil {
-		tx.Rollback()
 		return fmt.Errorf("failed to marshal TSM callback request: %w", err)
 	}
 
@@ -266,12 +437,10 @@ func (s *TsmService) HandleCallback(req dtos.TsmCallbackRequest) error {
 			// If no existing log found, try to get UserID from OrderPayment
 			var orderPayment models.OrderPayment
 			if err := tx.Where("uuid = ?", req.PartnerTrxID).First(&orderPayment).Error; err != nil {
-				tx.Rollback()
 				return fmt.Errorf("failed to find order payment for TSM callback: %w", err)
 			}
 			var order models.Order
 			if err := tx.Where("id = ?", orderPayment.OrderID).First(&order).Error; err != nil {
-				tx.Rollback()
 				return fmt.Errorf("failed to find order for TSM callback: %w", err)
 			}
 			currentUserID = order.UserID // Assign UserID from the associated Order
@@ -289,7 +458,6 @@ func (s *TsmService) HandleCallback(req dtos.TsmCallbackRequest) error {
 				log.Printf("Failed to save new TSM callback log: %v", logErr)
 			}
 		} else {
-			tx.Rollback()
 			return fmt.Errorf("failed to find TSM log for callback: %w", findErr)
 		}
 	} else {
@@ -299,17 +467,9 @@ func (s *TsmService) HandleCallback(req dtos.TsmCallbackRequest) error {
 		tsmLog.CallbackAt = &now
 		currentUserID = tsmLog.UserID // Get UserID from the existing log
 		if logErr := tx.Save(&tsmLog).Error; logErr != nil {
-			tx.Rollback()
 			return fmt.Errorf("failed to update TSM log for callback: %w", logErr)
 		}
 	}
 
-	if req.Status == "PAID" {
-		if err := s.OrderPaymentService.UpdateOrderPaymentAndStatus(tx, req.PartnerTrxID, req.Amount); err != nil {
-			tx.Rollback()
-			return err
-		}
-	}
-
-	return tx.Commit().Error
+	return nil
 }
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+)
+
+const testTsmKey = "tsm-test-key"
+
+// signTsmCallback signs a callback body with key the way TSM signs its callback tokens.
+func signTsmCallback(t *testing.T, key string, body map[string]interface{}) (string, []byte) {
+	t.Helper()
+	t.Setenv("TSM_KEY", key)
+	rawBody, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("failed to marshal callback: %v", err)
+	}
+	s := &TsmService{}
+	token, err := s.generateHeader(s.base64urlEncode(rawBody))
+	if err != nil {
+		t.Fatalf("failed to sign callback: %v", err)
+	}
+	return token, rawBody
+}
+
+func tsmCallbackFixture(partnerTrxID string, issuedAt time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"partner_trx_id": partnerTrxID,
+		"merchant_code":  "M001",
+		"app_code":       "A001",
+		"terminal_code":  "T001",
+		"amount":         "50000",
+		"status":         "PAID",
+		"timestamp":      issuedAt.UnixMilli(),
+	}
+}
+
+func TestVerifyCallbackTokenAcceptsSignedCallback(t *testing.T) {
+	now := time.Now()
+	token, rawBody := signTsmCallback(t, testTsmKey, tsmCallbackFixture("trx-1", now))
+
+	s := &TsmService{}
+	if err := s.verifyCallbackToken(token, rawBody, now); err != nil {
+		t.Fatalf("expected a valid token, got %v", err)
+	}
+}
+
+func TestVerifyCallbackTokenRejectsTamperedBody(t *testing.T) {
+	now := time.Now()
+	token, _ := signTsmCallback(t, testTsmKey, tsmCallbackFixture("trx-1", now))
+
+	tampered := tsmCallbackFixture("trx-1", now)
+	tampered["amount"] = "5000000"
+	postedBody, _ := json.Marshal(tampered)
+
+	s := &TsmService{}
+	if err := s.verifyCallbackToken(token, postedBody, now); !errors.Is(err, ErrInvalidTsmToken) {
+		t.Fatalf("expected %v, got %v", ErrInvalidTsmToken, err)
+	}
+}
+
+func TestVerifyCallbackTokenRejectsWrongKey(t *testing.T) {
+	now := time.Now()
+	token, rawBody := signTsmCallback(t, "another-key", tsmCallbackFixture("trx-1", now))
+	t.Setenv("TSM_KEY", testTsmKey)
+
+	s := &TsmService{}
+	if err := s.verifyCallbackToken(token, rawBody, now); !errors.Is(err, ErrInvalidTsmToken) {
+		t.Fatalf("expected %v, got %v", ErrInvalidTsmToken, err)
+	}
+}
+
+func TestVerifyCallbackTokenRejectsExpiredToken(t *testing.T) {
+	now := time.Now()
+	t.Setenv("TSM_CALLBACK_MAX_AGE_MINUTES", "10")
+	s := &TsmService{}
+
+	token, rawBody := signTsmCallback(t, testTsmKey, tsmCallbackFixture("trx-1", now.Add(-11*time.Minute)))
+	if err := s.verifyCallbackToken(token, rawBody, now); !errors.Is(err, ErrExpiredTsmToken) {
+		t.Fatalf("expected %v for an old token, got %v", ErrExpiredTsmToken, err)
+	}
+
+	withoutIssueTime := tsmCallbackFixture("trx-1", now)
+	delete(withoutIssueTime, "timestamp")
+	token, rawBody = signTsmCallback(t, testTsmKey, withoutIssueTime)
+	if err := s.verifyCallbackToken(token, rawBody, now); !errors.Is(err, ErrExpiredTsmToken) {
+		t.Fatalf("expected %v for a token without an issue time, got %v", ErrExpiredTsmToken, err)
+	}
+
+	withExpiry := tsmCallbackFixture("trx-1", now)
+	withExpiry["exp"] = now.Add(-time.Second).Unix()
+	token, rawBody = signTsmCallback(t, testTsmKey, withExpiry)
+	if err := s.verifyCallbackToken(token, rawBody, now); !errors.Is(err, ErrExpiredTsmToken) {
+		t.Fatalf("expected %v for a token past exp, got %v", ErrExpiredTsmToken, err)
+	}
+}
+
+// createTestTsmPayment creates a pending 50000 order payment with the given issuer for an owner
+// registered with the codes of tsmCallbackFixture.
+func createTestTsmPayment(t *testing.T, issuer string) (*OrderPaymentService, models.OrderPayment) {
+	t.Helper()
+	db := openTestDB(t)
+	owner, outlet := createTestOwner(t, db, false)
+
+	if err := db.Create(&models.UserTsm{UserID: owner.ID, AppCode: "A001", MerchantCode: "M001", TerminalCode: "T001"}).Error; err != nil {
+		t.Fatalf("failed to create user tsm: %v", err)
+	}
+	paymentMethod := models.PaymentMethod{Name: issuer + " test", Type: "card", Issuer: issuer, IsActive: true}
+	if err := db.Create(&paymentMethod).Error; err != nil {
+		t.Fatalf("failed to create payment method: %v", err)
+	}
+	order := models.Order{OutletID: outlet.ID, UserID: owner.ID, TotalAmount: 50000, Status: "pending"}
+	if err := db.Create(&order).Error; err != nil {
+		t.Fatalf("failed to create order: %v", err)
+	}
+	orderPayment := models.OrderPayment{OrderID: order.ID, PaymentMethodID: paymentMethod.ID, AmountPaid: 50000, Status: models.OrderPaymentStatusPending, Extra: "{}"}
+	if err := db.Create(&orderPayment).Error; err != nil {
+		t.Fatalf("failed to create order payment: %v", err)
+	}
+
+	tsmService := &TsmService{DB: db, TsmLogService: NewTsmLogService(db)}
+	registry := NewPaymentProviderRegistry()
+	registry.Register(NewTsmPaymentProvider(db, tsmService))
+	return NewOrderPaymentService(db, NewUserContextService(db), registry), orderPayment
+}
+
+func postTsmCallback(t *testing.T, s *OrderPaymentService, orderPayment models.OrderPayment) error {
+	t.Helper()
+	token, rawBody := signTsmCallback(t, testTsmKey, tsmCallbackFixture(orderPayment.Uuid.String(), time.Now()))
+	req := dtos.TsmCallbackRequest{
+		PartnerTrxID: orderPayment.Uuid.String(),
+		MerchantCode: "M001",
+		AppCode:      "A001",
+		TerminalCode: "T001",
+		Amount:       50000,
+		Status:       "PAID",
+	}
+	headers := http.Header{}
+	headers.Set("Token", token)
+	return s.HandleProviderCallback(TsmIssuer, PaymentCallback{Payload: &req, RawBody: rawBody, Headers: headers})
+}
+
+func TestTsmCallbackDuplicateCreditsOnce(t *testing.T) {
+	s, orderPayment := createTestTsmPayment(t, TsmIssuer)
+
+	for i := 0; i < 2; i++ {
+		if err := postTsmCallback(t, s, orderPayment); err != nil {
+			t.Fatalf("callback %d failed: %v", i+1, err)
+		}
+	}
+
+	var order models.Order
+	if err := s.DB.First(&order, orderPayment.OrderID).Error; err != nil {
+		t.Fatalf("failed to load order: %v", err)
+	}
+	if order.PaidAmount != 50000 {
+		t.Fatalf("expected the order to be credited once with 50000, got %v", order.PaidAmount)
+	}
+	if order.Status != "completed" {
+		t.Fatalf("expected the order to be completed, got %q", order.Status)
+	}
+
+	var duplicates int64
+	s.DB.Model(&models.PaymentCallbackLog{}).
+		Where("service_ref_id = ? AND status = ?", orderPayment.Uuid.String(), models.PaymentCallbackStatusDuplicate).
+		Count(&duplicates)
+	if duplicates != 1 {
+		t.Fatalf("expected the second callback to be logged as a duplicate, got %d", duplicates)
+	}
+}
+
+func TestTsmCallbackRejectsOtherIssuers(t *testing.T) {
+	s, orderPayment := createTestTsmPayment(t, "iPaymu")
+
+	if err := postTsmCallback(t, s, orderPayment); err == nil || err.Error() != "order payment is not a TSM payment" {
+		t.Fatalf("expected the callback to be rejected, got %v", err)
+	}
+
+	var reloaded models.OrderPayment
+	if err := s.DB.First(&reloaded, orderPayment.ID).Error; err != nil {
+		t.Fatalf("failed to load order payment: %v", err)
+	}
+	if reloaded.IsPaid || reloaded.Status != models.OrderPaymentStatusPending {
+		t.Fatalf("expected the payment to stay pending, got %q", reloaded.Status)
+	}
+}
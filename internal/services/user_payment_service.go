@@ -11,6 +11,7 @@ import (
 var (
 	ErrIpaymuRegistrationRequired = errors.New("iPaymu registration required")
 	ErrTsmRegistrationRequired    = errors.New("TSM registration required")
+	ErrQrisRegistrationRequired   = errors.New("QRIS registration required")
 )
 
 type UserPaymentService struct {
@@ -55,6 +56,15 @@ func (s *UserPaymentService) ActivateUserPayment(userID uint, paymentMethodID ui
 			}
 			return err
 		}
+	case "QRIS static":
+		// Check if UserQris entry exists for this user
+		var userQris models.UserQris
+		if err := s.DB.Where("user_id = ?", userID).First(&userQris).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrQrisRegistrationRequired
+			}
+			return err
+		}
 	}
 
 	var userPayment models.UserPayment
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/models"
+	"gorm.io/gorm"
+)
+
+// webhookPayload is the body of every webhook delivery.
+type webhookPayload struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+func newWebhookEvent(ownerID uint, eventType string, data interface{}) (*models.WebhookEvent, error) {
+	event := &models.WebhookEvent{EventType: eventType, UserID: ownerID}
+	event.Uuid = uuid.New()
+
+	payload, err := json.Marshal(webhookPayload{ID: event.Uuid, Type: eventType, CreatedAt: time.Now(), Data: data})
+	if err != nil {
+		return nil, err
+	}
+	event.Payload = string(payload)
+	return event, nil
+}
+
+// recordWebhookEvent queues an event for the owner's webhook endpoints as part of tx; the webhook
+// worker delivers it after the transaction commits. Nothing is written when no active endpoint
+// subscribes to the event. The event is written under a savepoint, so a failure is only logged and
+// never breaks the caller's transaction.
+func recordWebhookEvent(tx *gorm.DB, ownerID uint, eventType string, data interface{}) {
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		var subscribers int64
+		if err := subscribedWebhookEndpoints(tx, ownerID, eventType).Count(&subscribers).Error; err != nil {
+			return err
+		}
+		if subscribers == 0 {
+			return nil
+		}
+
+		event, err := newWebhookEvent(ownerID, eventType, data)
+		if err != nil {
+			return err
+		}
+		return tx.Create(event).Error
+	})
+	if err != nil {
+		log.Printf("Error recording %s webhook event: %v", eventType, err)
+	}
+}
+
+func orderWebhookData(order models.Order) map[string]interface{} {
+	return map[string]interface{}{
+		"uuid":         order.Uuid,
+		"outlet_id":    order.OutletID,
+		"status":       order.Status,
+		"total_amount": order.TotalAmount,
+		"paid_amount":  order.PaidAmount,
+		"created_at":   order.CreatedAt,
+	}
+}
+
+func paymentWebhookData(tx *gorm.DB, orderPayment models.OrderPayment) map[string]interface{} {
+	var orderUuid uuid.UUID
+	tx.Model(&models.Order{}).Where("id = ?", orderPayment.OrderID).Pluck("uuid", &orderUuid)
+
+	return map[string]interface{}{
+		"uuid":              orderPayment.Uuid,
+		"order_uuid":        orderUuid,
+		"payment_method_id": orderPayment.PaymentMethodID,
+		"amount_paid":       orderPayment.AmountPaid,
+		"surcharge_amount":  orderPayment.SurchargeAmount,
+		"refunded_amount":   orderPayment.RefundedAmount,
+		"status":            orderPayment.Status,
+		"reference_id":      orderPayment.ReferenceID,
+		"paid_at":           orderPayment.PaidAt,
+	}
+}
+
+// webhookStockLowThreshold is the quantity at or below which a stock without a reorder point sends
+// stock.low, from WEBHOOK_STOCK_LOW_THRESHOLD (default 0, i.e. out of stock).
+func webhookStockLowThreshold() float64 {
+	threshold, err := strconv.ParseFloat(os.Getenv("WEBHOOK_STOCK_LOW_THRESHOLD"), 64)
+	if err != nil || threshold < 0 {
+		return 0
+	}
+	return threshold
+}
+
+// recordStockLowEvent sends stock.low when a deduction takes a stock from above its reorder point,
+// or the default threshold, to or below it, so each shortage is only reported once.
+func recordStockLowEvent(tx *gorm.DB, stock models.Stock, previousQuantity float64) {
+	threshold := webhookStockLowThreshold()
+	if stock.ReorderPoint != nil {
+		threshold = *stock.ReorderPoint
+	}
+	if previousQuantity <= threshold || stock.Quantity > threshold {
+		return
+	}
+
+	data := map[string]interface{}{
+		"stock_uuid": stock.Uuid,
+		"outlet_id":  stock.OutletID,
+		"quantity":   stock.Quantity,
+		"threshold":  threshold,
+		"par_level":  stock.ParLevel,
+	}
+	if stock.ProductVariantID != nil {
+		var variant models.ProductVariant
+		if err := tx.Select("uuid", "name", "sku").First(&variant, *stock.ProductVariantID).Error; err == nil {
+			data["product_variant_uuid"] = variant.Uuid
+			data["name"] = variant.Name
+			data["sku"] = variant.SKU
+		}
+	} else if stock.ProductID != nil {
+		var product models.Product
+		if err := tx.Select("uuid", "name", "sku").First(&product, *stock.ProductID).Error; err == nil {
+			data["product_uuid"] = product.Uuid
+			data["name"] = product.Name
+			data["sku"] = product.SKU
+		}
+	}
+	recordWebhookEvent(tx, stock.UserID, models.WebhookEventStockLow, data)
+}
+
+func purchaseOrderWebhookItems(po models.PurchaseOrder) []map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(po.PurchaseOrderItems))
+	for _, item := range po.PurchaseOrderItems {
+		data := map[string]interface{}{
+			"quantity": item.Quantity,
+			"price":    item.Price,
+		}
+		if item.ProductVariant != nil {
+			data["product_variant_uuid"] = item.ProductVariant.Uuid
+			data["name"] = item.ProductVariant.Name
+		} else if item.Product != nil {
+			data["product_uuid"] = item.Product.Uuid
+			data["name"] = item.Product.Name
+		}
+		items = append(items, data)
+	}
+	return items
+}
@@ -0,0 +1,570 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/database"
+	"github.com/msyaifudin/pos/internal/models"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+	"github.com/msyaifudin/pos/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// webhookResponseBodyLimit is how much of an endpoint's response is kept on the delivery.
+	webhookResponseBodyLimit = 1000
+	// webhookDeliveryLease keeps a claimed delivery from being picked up again while it is being sent.
+	webhookDeliveryLease = 2 * time.Minute
+	// webhookMaxRetryDelay caps the exponential backoff between attempts.
+	webhookMaxRetryDelay = 6 * time.Hour
+	// webhookDeliveryBatch is how many events or deliveries the worker handles per run.
+	webhookDeliveryBatch = 100
+)
+
+type WebhookService struct {
+	DB                 *gorm.DB
+	UserContextService *UserContextService
+	HTTPClient         *http.Client
+}
+
+func NewWebhookService(db *gorm.DB, userContextService *UserContextService) *WebhookService {
+	return &WebhookService{DB: db, UserContextService: userContextService, HTTPClient: newWebhookHTTPClient()}
+}
+
+// errWebhookAddressNotAllowed is returned when an endpoint URL resolves to an internal address.
+var errWebhookAddressNotAllowed = errors.New("webhook endpoint does not resolve to a public address")
+
+// nonPublicPrefixes are ranges that netip does not flag itself but that never belong to a public endpoint.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// newWebhookHTTPClient returns the client used for deliveries. Endpoint URLs are chosen by tenants, so
+// every connection, including those made to follow a redirect, is checked after DNS resolution and
+// refused unless it goes to a public address.
+func newWebhookHTTPClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: webhookDialControl}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialled in place of the endpoint and bypass the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
+
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(addrPort.Addr()) {
+		return errWebhookAddressNotAllowed
+	}
+	return nil
+}
+
+// isPublicAddr reports whether addr can be reached on the internet, ruling out loopback, private,
+// link-local, multicast, unspecified and other reserved addresses.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// webhookMaxAttempts is how often a delivery is tried before it is given up, from
+// WEBHOOK_MAX_ATTEMPTS (default 8).
+func webhookMaxAttempts() int {
+	attempts, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS"))
+	if err != nil || attempts <= 0 {
+		attempts = 8
+	}
+	return attempts
+}
+
+// webhookRetryDelay is the wait after a failed attempt: 30 seconds, doubled after every further
+// failure, up to webhookMaxRetryDelay.
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := 30 * time.Second
+	for i := 1; i < attempts && delay < webhookMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > webhookMaxRetryDelay {
+		delay = webhookMaxRetryDelay
+	}
+	return delay
+}
+
+// subscribedWebhookEndpoints scopes a query to the owner's active endpoints subscribed to eventType.
+func subscribedWebhookEndpoints(db *gorm.DB, ownerID uint, eventType string) *gorm.DB {
+	return db.Model(&models.WebhookEndpoint{}).
+		Where("user_id = ? AND is_active = ? AND ',' || event_types || ',' LIKE ?", ownerID, true, "%,"+eventType+",%")
+}
+
+func (s *WebhookService) CreateEndpoint(req *dtos.WebhookEndpointRequest, userID uint) (*dtos.WebhookEndpointResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		log.Printf("Error generating webhook secret: %v", err)
+		return nil, errors.New("failed to create webhook endpoint")
+	}
+
+	endpoint := models.WebhookEndpoint{
+		URL:         req.URL,
+		Secret:      secret,
+		EventTypes:  joinWebhookEventTypes(req.EventTypes),
+		Description: req.Description,
+		IsActive:    true,
+		UserID:      ownerID,
+	}
+	if err := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Create(&endpoint).Error; err != nil {
+		log.Printf("Error creating webhook endpoint: %v", err)
+		return nil, errors.New("failed to create webhook endpoint")
+	}
+	// is_active has a column default, so GORM skips a false value on insert
+	if req.IsActive != nil && !*req.IsActive {
+		endpoint.IsActive = false
+		if err := s.DB.Model(&endpoint).Update("is_active", false).Error; err != nil {
+			return nil, errors.New("failed to create webhook endpoint")
+		}
+	}
+
+	response := mapWebhookEndpointToResponse(endpoint)
+	response.Secret = endpoint.Secret
+	return response, nil
+}
+
+func (s *WebhookService) GetEndpoints(userID uint) ([]dtos.WebhookEndpointResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []models.WebhookEndpoint
+	if err := s.DB.Where("user_id = ?", ownerID).Order("id").Find(&endpoints).Error; err != nil {
+		return nil, errors.New("failed to retrieve webhook endpoints")
+	}
+
+	responses := make([]dtos.WebhookEndpointResponse, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		responses = append(responses, *mapWebhookEndpointToResponse(endpoint))
+	}
+	return responses, nil
+}
+
+func (s *WebhookService) UpdateEndpoint(endpointUuid uuid.UUID, req *dtos.WebhookEndpointRequest, userID uint) (*dtos.WebhookEndpointResponse, error) {
+	endpoint, err := s.findOwnedEndpoint(endpointUuid, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint.URL = req.URL
+	endpoint.EventTypes = joinWebhookEventTypes(req.EventTypes)
+	endpoint.Description = req.Description
+	if req.IsActive != nil {
+		endpoint.IsActive = *req.IsActive
+	}
+	if err := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Save(endpoint).Error; err != nil {
+		return nil, errors.New("failed to update webhook endpoint")
+	}
+	return mapWebhookEndpointToResponse(*endpoint), nil
+}
+
+// DeleteEndpoint removes an endpoint together with its delivery log.
+func (s *WebhookService) DeleteEndpoint(endpointUuid uuid.UUID, userID uint) error {
+	endpoint, err := s.findOwnedEndpoint(endpointUuid, userID)
+	if err != nil {
+		return err
+	}
+
+	tx := s.DB.WithContext(context.WithValue(context.Background(), database.UserIDContextKey, userID)).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Where("webhook_endpoint_id = ?", endpoint.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to delete webhook endpoint")
+	}
+	if err := tx.Delete(endpoint).Error; err != nil {
+		tx.Rollback()
+		return errors.New("failed to delete webhook endpoint")
+	}
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("failed to commit transaction")
+	}
+	return nil
+}
+
+// PingEndpoint sends a ping event to an endpoint right away and returns the outcome. Pings are not retried.
+func (s *WebhookService) PingEndpoint(endpointUuid uuid.UUID, userID uint) (*dtos.WebhookDeliveryResponse, error) {
+	endpoint, err := s.findOwnedEndpoint(endpointUuid, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	event, err := newWebhookEvent(endpoint.UserID, models.WebhookEventPing, map[string]interface{}{
+		"endpoint_uuid": endpoint.Uuid,
+	})
+	if err != nil {
+		return nil, errors.New("failed to send webhook ping")
+	}
+	event.DispatchedAt = &now
+	if err := s.DB.Create(event).Error; err != nil {
+		return nil, errors.New("failed to send webhook ping")
+	}
+
+	delivery := models.WebhookDelivery{
+		WebhookEndpointID: endpoint.ID,
+		WebhookEventID:    event.ID,
+		EventType:         event.EventType,
+		Status:            models.WebhookDeliveryStatusPending,
+	}
+	if err := s.DB.Create(&delivery).Error; err != nil {
+		return nil, errors.New("failed to send webhook ping")
+	}
+	delivery.WebhookEndpoint = *endpoint
+	delivery.WebhookEvent = *event
+
+	s.attemptDelivery(&delivery)
+	return mapWebhookDeliveryToResponse(delivery), nil
+}
+
+// GetDeliveries lists the latest deliveries of an endpoint, optionally only those with the given status.
+func (s *WebhookService) GetDeliveries(endpointUuid uuid.UUID, status string, userID uint) ([]dtos.WebhookDeliveryResponse, error) {
+	endpoint, err := s.findOwnedEndpoint(endpointUuid, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.DB.Preload("WebhookEvent").Where("webhook_endpoint_id = ?", endpoint.ID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("created_at desc").Limit(webhookDeliveryBatch).Find(&deliveries).Error; err != nil {
+		return nil, errors.New("failed to retrieve webhook deliveries")
+	}
+
+	responses := make([]dtos.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		delivery.WebhookEndpoint = *endpoint
+		responses = append(responses, *mapWebhookDeliveryToResponse(delivery))
+	}
+	return responses, nil
+}
+
+// RedeliverDelivery sends the event of a past delivery to its endpoint again. The new attempt is
+// logged as a delivery of its own and retried like any other.
+func (s *WebhookService) RedeliverDelivery(deliveryUuid uuid.UUID, userID uint) (*dtos.WebhookDeliveryResponse, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var original models.WebhookDelivery
+	if err := s.DB.Preload("WebhookEndpoint").Preload("WebhookEvent").
+		Joins("JOIN webhook_endpoints ON webhook_endpoints.id = webhook_deliveries.webhook_endpoint_id").
+		Where("webhook_deliveries.uuid = ? AND webhook_endpoints.user_id = ?", deliveryUuid, ownerID).
+		First(&original).Error; err != nil {
+		return nil, errors.New("webhook delivery not found")
+	}
+	if !original.WebhookEndpoint.IsActive {
+		return nil, errors.New("webhook endpoint is inactive")
+	}
+
+	delivery := models.WebhookDelivery{
+		WebhookEndpointID: original.WebhookEndpointID,
+		WebhookEventID:    original.WebhookEventID,
+		EventType:         original.EventType,
+		Status:            models.WebhookDeliveryStatusPending,
+	}
+	if err := s.DB.Create(&delivery).Error; err != nil {
+		return nil, errors.New("failed to redeliver webhook")
+	}
+	delivery.WebhookEndpoint = original.WebhookEndpoint
+	delivery.WebhookEvent = original.WebhookEvent
+
+	s.attemptDelivery(&delivery)
+	return mapWebhookDeliveryToResponse(delivery), nil
+}
+
+// DispatchPendingEvents creates a delivery for every endpoint subscribed to each event not yet
+// dispatched. It is run by the webhook worker.
+func (s *WebhookService) DispatchPendingEvents() error {
+	var eventIDs []uint
+	if err := s.DB.Model(&models.WebhookEvent{}).
+		Where("dispatched_at IS NULL").
+		Order("id").
+		Limit(webhookDeliveryBatch).
+		Pluck("id", &eventIDs).Error; err != nil {
+		return fmt.Errorf("failed to load pending webhook events: %w", err)
+	}
+
+	for _, id := range eventIDs {
+		if err := s.dispatchEvent(id); err != nil {
+			log.Printf("Error dispatching webhook event %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func (s *WebhookService) dispatchEvent(eventID uint) error {
+	tx := s.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var event models.WebhookEvent
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("id = ? AND dispatched_at IS NULL", eventID).
+		First(&event).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Another worker has it
+			return nil
+		}
+		return err
+	}
+
+	var endpoints []models.WebhookEndpoint
+	if err := subscribedWebhookEndpoints(tx, event.UserID, event.EventType).Find(&endpoints).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	now := time.Now()
+	for _, endpoint := range endpoints {
+		delivery := models.WebhookDelivery{
+			WebhookEndpointID: endpoint.ID,
+			WebhookEventID:    event.ID,
+			EventType:         event.EventType,
+			Status:            models.WebhookDeliveryStatusPending,
+			NextAttemptAt:     &now,
+		}
+		if err := tx.Create(&delivery).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Model(&event).Update("dispatched_at", now).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// DeliverDueWebhooks sends every pending delivery whose next attempt is due. Deliveries are claimed
+// with a lease first, so several workers never send the same delivery at once.
+func (s *WebhookService) DeliverDueWebhooks() error {
+	now := time.Now()
+	var deliveries []models.WebhookDelivery
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryStatusPending, now).
+			Order("next_attempt_at").
+			Limit(webhookDeliveryBatch).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(webhookDeliveryLease)).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	for i := range deliveries {
+		if err := s.DB.Preload("WebhookEndpoint").Preload("WebhookEvent").First(&deliveries[i], deliveries[i].ID).Error; err != nil {
+			log.Printf("Error loading webhook delivery %d: %v", deliveries[i].ID, err)
+			continue
+		}
+		s.attemptDelivery(&deliveries[i])
+	}
+	return nil
+}
+
+// attemptDelivery sends a delivery once and records the outcome. A failed delivery is scheduled
+// again with exponential backoff until webhookMaxAttempts is reached.
+func (s *WebhookService) attemptDelivery(delivery *models.WebhookDelivery) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = 0
+	delivery.ResponseBody = ""
+	delivery.Error = ""
+
+	var sendErr error
+	if !delivery.WebhookEndpoint.IsActive {
+		sendErr = errors.New("webhook endpoint is inactive")
+	} else {
+		delivery.ResponseStatus, delivery.ResponseBody, sendErr = s.send(delivery)
+	}
+
+	switch {
+	case sendErr == nil:
+		delivery.Status = models.WebhookDeliveryStatusSucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	case delivery.EventType == models.WebhookEventPing, !delivery.WebhookEndpoint.IsActive, delivery.Attempts >= webhookMaxAttempts():
+		delivery.Status = models.WebhookDeliveryStatusFailed
+		delivery.Error = truncateString(sendErr.Error(), 500)
+		delivery.NextAttemptAt = nil
+	default:
+		next := now.Add(webhookRetryDelay(delivery.Attempts))
+		delivery.Status = models.WebhookDeliveryStatusPending
+		delivery.Error = truncateString(sendErr.Error(), 500)
+		delivery.NextAttemptAt = &next
+	}
+
+	if err := s.DB.Omit(clause.Associations).Save(delivery).Error; err != nil {
+		log.Printf("Error recording webhook delivery %s: %v", delivery.Uuid, err)
+	}
+}
+
+// send posts the event payload to the endpoint. The X-Webhook-Signature header is the hex
+// HMAC-SHA256, keyed with the endpoint secret, of the X-Webhook-Timestamp value, a dot and the body.
+func (s *WebhookService) send(delivery *models.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.WebhookEvent.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := utils.SignHMACSHA256(delivery.WebhookEndpoint.Secret, append([]byte(timestamp+"."), body...))
+
+	req, err := http.NewRequest(http.MethodPost, delivery.WebhookEndpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "POS-Webhook/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", delivery.Uuid.String())
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+signature)
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseBodyLimit))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(responseBody), fmt.Errorf("endpoint responded with status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(responseBody), nil
+}
+
+func (s *WebhookService) findOwnedEndpoint(endpointUuid uuid.UUID, userID uint) (*models.WebhookEndpoint, error) {
+	ownerID, err := s.UserContextService.GetOwnerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoint models.WebhookEndpoint
+	if err := s.DB.Where("uuid = ? AND user_id = ?", endpointUuid, ownerID).First(&endpoint).Error; err != nil {
+		return nil, errors.New("webhook endpoint not found")
+	}
+	return &endpoint, nil
+}
+
+// joinWebhookEventTypes stores subscribed event types as a comma separated list without duplicates.
+func joinWebhookEventTypes(eventTypes []string) string {
+	seen := make(map[string]bool, len(eventTypes))
+	unique := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if !seen[eventType] {
+			seen[eventType] = true
+			unique = append(unique, eventType)
+		}
+	}
+	return strings.Join(unique, ",")
+}
+
+func truncateString(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	return value[:limit]
+}
+
+func mapWebhookEndpointToResponse(endpoint models.WebhookEndpoint) *dtos.WebhookEndpointResponse {
+	eventTypes := []string{}
+	if endpoint.EventTypes != "" {
+		eventTypes = strings.Split(endpoint.EventTypes, ",")
+	}
+	return &dtos.WebhookEndpointResponse{
+		Uuid:        endpoint.Uuid,
+		URL:         endpoint.URL,
+		EventTypes:  eventTypes,
+		Description: endpoint.Description,
+		IsActive:    endpoint.IsActive,
+		CreatedAt:   endpoint.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func mapWebhookDeliveryToResponse(delivery models.WebhookDelivery) *dtos.WebhookDeliveryResponse {
+	var payload interface{}
+	if delivery.WebhookEvent.Payload != "" {
+		json.Unmarshal([]byte(delivery.WebhookEvent.Payload), &payload)
+	}
+
+	return &dtos.WebhookDeliveryResponse{
+		Uuid:           delivery.Uuid,
+		EndpointUuid:   delivery.WebhookEndpoint.Uuid,
+		EventUuid:      delivery.WebhookEvent.Uuid,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   delivery.ResponseBody,
+		Error:          delivery.Error,
+		DeliveredAt:    delivery.DeliveredAt,
+		Payload:        payload,
+		CreatedAt:      delivery.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/models"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr   string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := isPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.public {
+			t.Errorf("%s: expected public %v, got %v", tt.addr, tt.public, got)
+		}
+	}
+}
+
+func TestWebhookClientRefusesInternalAddresses(t *testing.T) {
+	reached := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.Write([]byte("internal secret"))
+	}))
+	defer server.Close()
+
+	s := &WebhookService{HTTPClient: newWebhookHTTPClient()}
+	delivery := &models.WebhookDelivery{
+		EventType:       models.WebhookEventPing,
+		WebhookEndpoint: models.WebhookEndpoint{URL: server.URL, Secret: "secret"},
+		WebhookEvent:    models.WebhookEvent{Payload: "{}"},
+	}
+	delivery.Uuid = uuid.New()
+
+	status, body, err := s.send(delivery)
+	if !errors.Is(err, errWebhookAddressNotAllowed) {
+		t.Fatalf("expected %v, got %v", errWebhookAddressNotAllowed, err)
+	}
+	if reached || status != 0 || body != "" {
+		t.Fatalf("expected the loopback server not to be reached, got status %d and body %q", status, body)
+	}
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// StartWebhookWorker fans recorded events out to the subscribed webhook endpoints and sends due
+// deliveries, retrying failed ones with backoff. Requests never wait on partner endpoints.
+// The interval is WEBHOOK_WORKER_INTERVAL_SECONDS (default 5).
+func StartWebhookWorker(webhookService *WebhookService) {
+	seconds, err := strconv.Atoi(os.Getenv("WEBHOOK_WORKER_INTERVAL_SECONDS"))
+	if err != nil || seconds <= 0 {
+		seconds = 5
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(seconds) * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := webhookService.DispatchPendingEvents(); err != nil {
+				log.Printf("Webhook dispatch failed: %v", err)
+			}
+			if err := webhookService.DeliverDueWebhooks(); err != nil {
+				log.Printf("Webhook delivery failed: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,67 @@
+package validators
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+)
+
+var customerValidator = validator.New()
+
+func ValidateCustomer(req *dtos.CustomerRequest) []string {
+	err := customerValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"Name":  "customer_name_required",
+		"Phone": "customer_phone_too_long",
+		"Email": "email_invalid",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func ValidateAdjustCustomerWallet(req *dtos.AdjustCustomerWalletRequest) []string {
+	err := customerValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"Amount": "wallet_adjustment_amount_required",
+		"Reason": "wallet_adjustment_reason_required",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func ValidateTopUpCustomerWallet(req *dtos.TopUpCustomerWalletRequest) []string {
+	err := customerValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"OutletUuid":      "outlet_uuid_required",
+		"Amount":          "wallet_top_up_amount_invalid",
+		"PaymentMethodID": "payment_method_id_required",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
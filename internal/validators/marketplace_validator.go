@@ -0,0 +1,92 @@
+package validators
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+)
+
+var marketplaceValidator = validator.New()
+
+func ValidateCreateMarketplaceChannel(req *dtos.CreateMarketplaceChannelRequest) []string {
+	err := marketplaceValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"Provider":          "marketplace_provider_required",
+		"Name":              "marketplace_channel_name_required",
+		"CommissionPercent": "commission_percent_invalid",
+		"StatusCallbackURL": "status_callback_url_invalid",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func ValidateCreateMarketplaceItemMapping(req *dtos.CreateMarketplaceItemMappingRequest) []string {
+	var messages []string
+	if err := marketplaceValidator.Struct(req); err != nil {
+		fieldToMessage := map[string]string{
+			"ExternalItemID": "external_item_id_required",
+		}
+		for _, err := range err.(validator.ValidationErrors) {
+			if msg, ok := fieldToMessage[err.Field()]; ok {
+				messages = append(messages, msg)
+			}
+		}
+	}
+
+	// Custom validation logic for product_uuid or product_variant_uuid
+	if (req.ProductUuid == uuid.Nil && req.ProductVariantUuid == uuid.Nil) || (req.ProductUuid != uuid.Nil && req.ProductVariantUuid != uuid.Nil) {
+		messages = append(messages, "either_product_uuid_or_product_variant_uuid_is_required")
+	}
+
+	return messages
+}
+
+func ValidateMarketplaceWebhook(req *dtos.MarketplaceWebhookRequest) []string {
+	err := marketplaceValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"Event":           "marketplace_event_invalid",
+		"ExternalOrderID": "external_order_id_required",
+		"Items":           "order_items_required",
+		"ExternalItemID":  "external_item_id_required",
+		"Quantity":        "quantity_required",
+		"Price":           "price_invalid",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func ValidateUpdateMarketplaceOrderStatus(req *dtos.UpdateMarketplaceOrderStatusRequest) []string {
+	err := marketplaceValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"Status": "marketplace_order_status_invalid",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
@@ -9,3 +9,38 @@ func ValidateCreateOrderPayment(req *dtos.CreateOrderPaymentRequest) error {
 	validate := validator.New()
 	return validate.Struct(req)
 }
+
+func ValidateRetryOrderPayment(req *dtos.RetryOrderPaymentRequest) error {
+	validate := validator.New()
+	return validate.Struct(req)
+}
+
+func ValidateCreateOrderPaymentRefund(req *dtos.CreateOrderPaymentRefundRequest) error {
+	validate := validator.New()
+	return validate.Struct(req)
+}
+
+func ValidateFakePaymentCallback(req *dtos.FakePaymentCallbackRequest) error {
+	validate := validator.New()
+	return validate.Struct(req)
+}
+
+func ValidateConfirmTransfer(req *dtos.ConfirmTransferRequest) error {
+	validate := validator.New()
+	return validate.Struct(req)
+}
+
+func ValidateRejectTransfer(req *dtos.RejectTransferRequest) error {
+	validate := validator.New()
+	return validate.Struct(req)
+}
+
+func ValidateCreatePaymentLink(req *dtos.CreatePaymentLinkRequest) error {
+	validate := validator.New()
+	return validate.Struct(req)
+}
+
+func ValidateSendPaymentLink(req *dtos.SendPaymentLinkRequest) error {
+	validate := validator.New()
+	return validate.Struct(req)
+}
@@ -0,0 +1,51 @@
+package validators
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+)
+
+var paymentMethodValidator = validator.New()
+
+func ValidatePaymentMethod(req *dtos.PaymentMethodRequest) []string {
+	err := paymentMethodValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"Name":           "payment_method_name_required",
+		"Type":           "payment_method_type_required",
+		"PaymentMethod":  "payment_method_too_long",
+		"PaymentChannel": "payment_channel_too_long",
+		"Issuer":         "payment_method_issuer_required",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func ValidateSetOutletPaymentMethods(req *dtos.SetOutletPaymentMethodsRequest) []string {
+	err := paymentMethodValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"PaymentMethodID": "payment_method_id_required",
+		"DisplayOrder":    "display_order_invalid",
+		"SurchargeType":   "surcharge_type_invalid",
+		"SurchargeValue":  "surcharge_value_invalid",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
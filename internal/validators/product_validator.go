@@ -18,6 +18,9 @@ func ValidateCreateProduct(req *dtos.ProductCreateRequest) []string {
 		"Name":        "product_name_required",
 		"Description": "product_description_required",
 		"Price":       "product_price_required",
+		"CostPrice":   "product_cost_price_invalid",
+		"Category":    "product_category_too_long",
+		"BaseUnit":    "unit_name_too_long",
 		"SKU":         "product_sku_required",
 		"Type":        "product_type_required",
 	}
@@ -40,6 +43,9 @@ func ValidateUpdateProduct(req *dtos.ProductUpdateRequest) []string {
 		"Name":        "product_name_required",
 		"Description": "product_description_required",
 		"Price":       "product_price_required",
+		"CostPrice":   "product_cost_price_invalid",
+		"Category":    "product_category_too_long",
+		"BaseUnit":    "unit_name_too_long",
 		"SKU":         "product_sku_required",
 		"Type":        "product_type_required",
 	}
@@ -49,4 +55,22 @@ func ValidateUpdateProduct(req *dtos.ProductUpdateRequest) []string {
 		}
 	}
 	return messages
-}
\ No newline at end of file
+}
+func ValidateProductUnit(req *dtos.ProductUnitRequest) []string {
+	err := productValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"Name":             "unit_name_required",
+		"ConversionFactor": "conversion_factor_must_be_greater_than_zero",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
@@ -19,6 +19,7 @@ func ValidateCreatePurchaseOrder(req *dtos.CreatePurchaseOrderRequest) []string
 		"SupplierUuid": "supplier_uuid_required",
 		"OutletUuid":   "outlet_uuid_required",
 		"Items":        "purchase_items_required",
+		"Unit":         "unit_name_too_long",
 	}
 	for _, err := range err.(validator.ValidationErrors) {
 		if msg, ok := fieldToMessage[err.Field()]; ok {
@@ -43,6 +44,7 @@ func ValidatePurchaseItem(req *dtos.PurchaseItemRequest) []string {
 	fieldToMessage := map[string]string{
 		"Quantity":    "quantity_required",
 		"Price":       "price_required",
+		"Unit":        "unit_name_too_long",
 	}
 	for _, err := range err.(validator.ValidationErrors) {
 		if msg, ok := fieldToMessage[err.Field()]; ok {
@@ -50,4 +52,23 @@ func ValidatePurchaseItem(req *dtos.PurchaseItemRequest) []string {
 		}
 	}
 	return messages
-}
\ No newline at end of file
+}
+func ValidateReceivePurchaseOrder(req *dtos.ReceivePurchaseOrderRequest) []string {
+	err := purchaseOrderValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"PurchaseOrderItemUuid": "purchase_order_item_uuid_required",
+		"LotNumber":             "lot_number_too_long",
+		"ExpiryDate":            "invalid_expiry_date_format",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
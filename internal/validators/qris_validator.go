@@ -0,0 +1,29 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+)
+
+func ValidateQrisRegister(req *dtos.QrisRegisterRequest) []string {
+	if err := validate.Struct(req); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			errMsgs := make([]string, 0, len(ve))
+			for _, fe := range ve {
+				msgKey := fmt.Sprintf("validation_%s_%s", strings.ToLower(fe.Field()), strings.ToLower(fe.Tag()))
+				if !isSpecificMessageKeyDefined(msgKey) {
+					msgKey = "validation_generic_field_failed"
+				}
+				errMsgs = append(errMsgs, msgKey)
+			}
+			return errMsgs
+		}
+		return []string{"validation_generic_error"}
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ func ValidateCreateRecipe(req *dtos.CreateRecipeRequest) []string {
 		"MainProductUuid": "main_product_uuid_required",
 		"ComponentUuid":   "component_uuid_required",
 		"Quantity":        "quantity_required",
+		"Unit":            "unit_name_too_long",
 	}
 	for _, err := range err.(validator.ValidationErrors) {
 		if msg, ok := fieldToMessage[err.Field()]; ok {
@@ -38,6 +39,7 @@ func ValidateUpdateRecipe(req *dtos.UpdateRecipeRequest) []string {
 		"MainProductUuid": "main_product_uuid_required",
 		"ComponentUuid":   "component_uuid_required",
 		"Quantity":        "quantity_required",
+		"Unit":            "unit_name_too_long",
 	}
 	for _, err := range err.(validator.ValidationErrors) {
 		if msg, ok := fieldToMessage[err.Field()]; ok {
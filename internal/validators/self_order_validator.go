@@ -0,0 +1,95 @@
+package validators
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+)
+
+var selfOrderValidator = validator.New()
+
+func ValidateCreateOutletTable(req *dtos.CreateOutletTableRequest) []string {
+	err := selfOrderValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"Name": "table_name_required",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func ValidateCreateSelfOrder(req *dtos.CreateSelfOrderRequest) []string {
+	var messages []string
+	if err := selfOrderValidator.Struct(req); err != nil {
+		fieldToMessage := map[string]string{
+			"Token":        "invalid_table_token",
+			"CustomerName": "CustomerName_required",
+			"Items":        "order_items_required",
+			"Quantity":     "quantity_required",
+			"AddOnUuid":    "add_on_uuid_required",
+		}
+		for _, err := range err.(validator.ValidationErrors) {
+			if msg, ok := fieldToMessage[err.Field()]; ok {
+				messages = append(messages, msg)
+			}
+		}
+	}
+
+	// Custom validation logic for product_uuid or product_variant_uuid
+	for _, item := range req.Items {
+		if (item.ProductUuid == uuid.Nil && item.ProductVariantUuid == uuid.Nil) || (item.ProductUuid != uuid.Nil && item.ProductVariantUuid != uuid.Nil) {
+			messages = append(messages, "either_product_uuid_or_product_variant_uuid_is_required_for_order_item")
+			break
+		}
+	}
+
+	return messages
+}
+
+func ValidateRejectSelfOrder(req *dtos.RejectSelfOrderRequest) []string {
+	err := selfOrderValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"Reason": "rejection_reason_required",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func ValidatePaySelfOrder(req *dtos.PaySelfOrderRequest) []string {
+	err := selfOrderValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"Token":           "invalid_table_token",
+		"PaymentMethodID": "payment_method_id_required",
+		"CustomerName":    "CustomerName_required",
+		"CustomerEmail":   "CustomerEmail_required",
+		"CustomerPhone":   "CustomerPhone_required",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
@@ -30,3 +30,175 @@ func ValidateUpdateStock(req *dtos.UpdateStockRequest) []string {
 	}
 	return messages
 }
+
+func ValidateSetReorderPoint(req *dtos.SetReorderPointRequest) []string {
+	if (req.ProductUuid == uuid.Nil && req.ProductVariantUuid == uuid.Nil) || (req.ProductUuid != uuid.Nil && req.ProductVariantUuid != uuid.Nil) {
+		return []string{"either_product_uuid_or_product_variant_uuid_is_required"}
+	}
+
+	err := stockValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"ReorderPoint": "reorder_point_invalid",
+		"ParLevel":     "par_level_invalid",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func ValidateStockTransfer(req *dtos.StockTransferRequest) []string {
+	for _, item := range req.Items {
+		if (item.ProductUuid == uuid.Nil && item.ProductVariantUuid == uuid.Nil) || (item.ProductUuid != uuid.Nil && item.ProductVariantUuid != uuid.Nil) {
+			return []string{"either_product_uuid_or_product_variant_uuid_is_required"}
+		}
+	}
+
+	err := stockValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"SourceOutletUuid":      "source_outlet_uuid_required",
+		"DestinationOutletUuid": "destination_outlet_uuid_required",
+		"Note":                  "stock_transfer_note_too_long",
+		"Items":                 "stock_transfer_items_required",
+		"Quantity":              "quantity_must_be_greater_than_zero",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func ValidateReceiveStockTransfer(req *dtos.ReceiveStockTransferRequest) []string {
+	err := stockValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"ItemUuid":         "stock_transfer_item_uuid_required",
+		"ReceivedQuantity": "received_quantity_invalid",
+		"DiscrepancyNote":  "discrepancy_note_too_long",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func ValidateStartStockCount(req *dtos.StartStockCountRequest) []string {
+	err := stockValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"Note": "stock_count_note_too_long",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func ValidateAddStockCountEntries(req *dtos.AddStockCountEntriesRequest) []string {
+	for _, item := range req.Items {
+		if (item.ProductUuid == uuid.Nil && item.ProductVariantUuid == uuid.Nil) || (item.ProductUuid != uuid.Nil && item.ProductVariantUuid != uuid.Nil) {
+			return []string{"either_product_uuid_or_product_variant_uuid_is_required"}
+		}
+	}
+
+	err := stockValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"Items":    "stock_count_items_required",
+		"Quantity": "counted_quantity_invalid",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func ValidateWriteOffStockLot(req *dtos.WriteOffStockLotRequest) []string {
+	err := stockValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"Quantity": "quantity_must_be_greater_than_zero",
+		"Reason":   "write_off_reason_required",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func ValidateStockAdjustment(req *dtos.StockAdjustmentRequest) []string {
+	err := stockValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"QuantityChange": "quantity_change_required",
+		"Reason":         "invalid_stock_adjustment_reason",
+		"Note":           "note_too_long",
+		"PhotoURL":       "invalid_photo_url",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func ValidateReviewStockAdjustment(req *dtos.ReviewStockAdjustmentRequest) []string {
+	err := stockValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"Note": "note_too_long",
+	}
+	for _, err := range err.(validator.ValidationErrors) {
+		if msg, ok := fieldToMessage[err.Field()]; ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
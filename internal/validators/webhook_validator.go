@@ -0,0 +1,34 @@
+package validators
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/msyaifudin/pos/internal/models/dtos"
+)
+
+var webhookValidator = validator.New()
+
+func ValidateWebhookEndpoint(req *dtos.WebhookEndpointRequest) []string {
+	err := webhookValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	fieldToMessage := map[string]string{
+		"URL":         "webhook_url_invalid",
+		"EventTypes":  "webhook_event_types_invalid",
+		"Description": "webhook_description_too_long",
+	}
+	seen := make(map[string]bool)
+	for _, err := range err.(validator.ValidationErrors) {
+		// Errors on an event type are reported on the list, e.g. EventTypes[1]
+		field := strings.SplitN(err.Field(), "[", 2)[0]
+		if msg, ok := fieldToMessage[field]; ok && !seen[msg] {
+			seen[msg] = true
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
@@ -299,6 +299,814 @@ var messages = map[string]map[string]string{
 		"en": "Failed to unmarshal response JSON: invalid character '<' looking for beginning of value",
 		"id": "Gagal mengurai JSON respons: karakter '<' tidak valid saat mencari awal nilai.",
 	},
+	"outlet_table_created_successfully": {
+		"en": "Outlet table created successfully.",
+		"id": "Meja outlet berhasil dibuat.",
+	},
+	"outlet_tables_retrieved_successfully": {
+		"en": "Outlet tables retrieved successfully.",
+		"id": "Meja outlet berhasil diambil.",
+	},
+	"outlet_table_token_rotated_successfully": {
+		"en": "Table QR code renewed successfully.",
+		"id": "Kode QR meja berhasil diperbarui.",
+	},
+	"table_name_required": {
+		"en": "Table name is required.",
+		"id": "Nama meja wajib diisi.",
+	},
+	"public_menu_retrieved_successfully": {
+		"en": "Menu retrieved successfully.",
+		"id": "Menu berhasil diambil.",
+	},
+	"invalid table token": {
+		"en": "Invalid or expired table QR code.",
+		"id": "Kode QR meja tidak valid atau sudah kedaluwarsa.",
+	},
+	"invalid_table_token": {
+		"en": "Invalid or expired table QR code.",
+		"id": "Kode QR meja tidak valid atau sudah kedaluwarsa.",
+	},
+	"too many self orders for this table, please wait": {
+		"en": "Too many orders from this table, please wait a moment.",
+		"id": "Terlalu banyak pesanan dari meja ini, mohon tunggu sebentar.",
+	},
+	"self_order_created_successfully": {
+		"en": "Order submitted and waiting for confirmation.",
+		"id": "Pesanan terkirim dan menunggu konfirmasi.",
+	},
+	"self_order_retrieved_successfully": {
+		"en": "Self order retrieved successfully.",
+		"id": "Pesanan mandiri berhasil diambil.",
+	},
+	"self_orders_retrieved_successfully": {
+		"en": "Self orders retrieved successfully.",
+		"id": "Pesanan mandiri berhasil diambil.",
+	},
+	"self_order_accepted_successfully": {
+		"en": "Self order accepted successfully.",
+		"id": "Pesanan mandiri berhasil diterima.",
+	},
+	"self_order_rejected_successfully": {
+		"en": "Self order rejected successfully.",
+		"id": "Pesanan mandiri berhasil ditolak.",
+	},
+	"rejection_reason_required": {
+		"en": "Rejection reason is required.",
+		"id": "Alasan penolakan wajib diisi.",
+	},
+	"add_on_uuid_required": {
+		"en": "Add-on UUID is required.",
+		"id": "UUID add-on wajib diisi.",
+	},
+	"payment_method_id_required": {
+		"en": "Payment method is required.",
+		"id": "Metode pembayaran wajib diisi.",
+	},
+	"marketplace_channel_created_successfully": {
+		"en": "Marketplace channel created successfully.",
+		"id": "Kanal marketplace berhasil dibuat.",
+	},
+	"marketplace_channels_retrieved_successfully": {
+		"en": "Marketplace channels retrieved successfully.",
+		"id": "Kanal marketplace berhasil diambil.",
+	},
+	"marketplace_item_mapping_created_successfully": {
+		"en": "Marketplace item mapping created successfully.",
+		"id": "Pemetaan item marketplace berhasil dibuat.",
+	},
+	"marketplace_item_mappings_retrieved_successfully": {
+		"en": "Marketplace item mappings retrieved successfully.",
+		"id": "Pemetaan item marketplace berhasil diambil.",
+	},
+	"marketplace_item_mapping_deleted_successfully": {
+		"en": "Marketplace item mapping deleted successfully.",
+		"id": "Pemetaan item marketplace berhasil dihapus.",
+	},
+	"marketplace_webhook_processed_successfully": {
+		"en": "Marketplace webhook processed successfully.",
+		"id": "Webhook marketplace berhasil diproses.",
+	},
+	"marketplace_orders_retrieved_successfully": {
+		"en": "Marketplace orders retrieved successfully.",
+		"id": "Pesanan marketplace berhasil diambil.",
+	},
+	"marketplace_order_status_updated_successfully": {
+		"en": "Marketplace order status updated successfully.",
+		"id": "Status pesanan marketplace berhasil diperbarui.",
+	},
+	"marketplace_provider_required": {
+		"en": "Marketplace provider is required.",
+		"id": "Penyedia marketplace wajib diisi.",
+	},
+	"marketplace_channel_name_required": {
+		"en": "Channel name is required.",
+		"id": "Nama kanal wajib diisi.",
+	},
+	"commission_percent_invalid": {
+		"en": "Commission percent must be between 0 and 100.",
+		"id": "Persentase komisi harus antara 0 dan 100.",
+	},
+	"status_callback_url_invalid": {
+		"en": "Status callback URL must be a valid URL.",
+		"id": "URL callback status harus berupa URL yang valid.",
+	},
+	"external_item_id_required": {
+		"en": "External item ID is required.",
+		"id": "ID item eksternal wajib diisi.",
+	},
+	"external_order_id_required": {
+		"en": "External order ID is required.",
+		"id": "ID pesanan eksternal wajib diisi.",
+	},
+	"marketplace_event_invalid": {
+		"en": "Event must be order.created or order.cancelled.",
+		"id": "Event harus order.created atau order.cancelled.",
+	},
+	"marketplace_order_status_invalid": {
+		"en": "Status must be one of accepted, preparing, ready, completed or cancelled.",
+		"id": "Status harus salah satu dari accepted, preparing, ready, completed atau cancelled.",
+	},
+	"price_invalid": {
+		"en": "Price must not be negative.",
+		"id": "Harga tidak boleh negatif.",
+	},
+	"invalid signature": {
+		"en": "Invalid signature.",
+		"id": "Tanda tangan tidak valid.",
+	},
+	"payment provider not found": {
+		"en": "No payment provider is registered for this payment method.",
+		"id": "Tidak ada penyedia pembayaran untuk metode pembayaran ini.",
+	},
+	"payment operation not supported by provider": {
+		"en": "This operation is not supported by the payment provider.",
+		"id": "Operasi ini tidak didukung oleh penyedia pembayaran.",
+	},
+	"order payment not found": {
+		"en": "Order payment not found.",
+		"id": "Pembayaran pesanan tidak ditemukan.",
+	},
+	"callback_processed_successfully": {
+		"en": "Callback processed successfully.",
+		"id": "Callback berhasil diproses.",
+	},
+	"notification source not allowed": {
+		"en": "Notification source is not allowed.",
+		"id": "Sumber notifikasi tidak diizinkan.",
+	},
+	"ipaymu transaction not found": {
+		"en": "iPaymu transaction not found.",
+		"id": "Transaksi iPaymu tidak ditemukan.",
+	},
+	"payment not confirmed by ipaymu": {
+		"en": "The payment has not been confirmed by iPaymu.",
+		"id": "Pembayaran belum dikonfirmasi oleh iPaymu.",
+	},
+	"payment amount mismatch": {
+		"en": "The paid amount does not match the payment.",
+		"id": "Jumlah yang dibayar tidak sesuai dengan pembayaran.",
+	},
+	"invalid callback token": {
+		"en": "Invalid callback token.",
+		"id": "Token callback tidak valid.",
+	},
+	"callback merchant does not match": {
+		"en": "The callback merchant, terminal or app code does not match.",
+		"id": "Kode merchant, terminal atau aplikasi pada callback tidak sesuai.",
+	},
+	"order_payment_status_checked_successfully": {
+		"en": "Order payment status checked successfully.",
+		"id": "Status pembayaran pesanan berhasil diperiksa.",
+	},
+	"order_payment_cancelled_successfully": {
+		"en": "Order payment cancelled successfully.",
+		"id": "Pembayaran pesanan berhasil dibatalkan.",
+	},
+	"only pending payments can be cancelled": {
+		"en": "Only pending payments can be cancelled.",
+		"id": "Hanya pembayaran yang masih menunggu yang dapat dibatalkan.",
+	},
+	"order payment cannot be retried": {
+		"en": "A paid or refunded payment cannot be retried.",
+		"id": "Pembayaran yang sudah dibayar atau dikembalikan tidak dapat diulang.",
+	},
+	"order_payment_refund_created_successfully": {
+		"en": "Refund created successfully.",
+		"id": "Pengembalian dana berhasil dibuat.",
+	},
+	"order_payment_refunds_retrieved_successfully": {
+		"en": "Refunds retrieved successfully.",
+		"id": "Pengembalian dana berhasil diambil.",
+	},
+	"only paid payments can be refunded": {
+		"en": "Only paid payments can be refunded.",
+		"id": "Hanya pembayaran yang sudah dibayar yang dapat dikembalikan.",
+	},
+	"refund amount exceeds the refundable amount": {
+		"en": "The refund amount exceeds the amount that can still be refunded.",
+		"id": "Jumlah pengembalian melebihi jumlah yang masih dapat dikembalikan.",
+	},
+	"payment_reconciliation_report_generated_successfully": {
+		"en": "Payment reconciliation report generated successfully.",
+		"id": "Laporan rekonsiliasi pembayaran berhasil dibuat.",
+	},
+	"failed_to_generate_csv": {
+		"en": "Failed to generate the CSV file.",
+		"id": "Gagal membuat file CSV.",
+	},
+	"qris_registered_successfully": {
+		"en": "QRIS registered successfully.",
+		"id": "QRIS berhasil didaftarkan.",
+	},
+	"qris_retrieved_successfully": {
+		"en": "QRIS retrieved successfully.",
+		"id": "QRIS berhasil diambil.",
+	},
+	"order_payment_confirmed_successfully": {
+		"en": "Order payment confirmed successfully.",
+		"id": "Pembayaran pesanan berhasil dikonfirmasi.",
+	},
+	"user qris data not found": {
+		"en": "QRIS has not been registered for this account.",
+		"id": "QRIS belum didaftarkan untuk akun ini.",
+	},
+	"qris payload must be static": {
+		"en": "The QRIS must be the static QRIS from your bank.",
+		"id": "QRIS harus berupa QRIS statis dari bank Anda.",
+	},
+	"invalid qris checksum": {
+		"en": "The QRIS checksum is invalid.",
+		"id": "Checksum QRIS tidak valid.",
+	},
+	"payment method does not allow manual confirmation": {
+		"en": "This payment method cannot be confirmed manually.",
+		"id": "Metode pembayaran ini tidak dapat dikonfirmasi secara manual.",
+	},
+	"only pending payments can be confirmed": {
+		"en": "Only pending payments can be confirmed.",
+		"id": "Hanya pembayaran yang masih menunggu yang dapat dikonfirmasi.",
+	},
+	"payment_methods_retrieved_successfully": {
+		"en": "Payment methods retrieved successfully.",
+		"id": "Metode pembayaran berhasil diambil.",
+	},
+	"payment_method_created_successfully": {
+		"en": "Payment method created successfully.",
+		"id": "Metode pembayaran berhasil dibuat.",
+	},
+	"payment_method_updated_successfully": {
+		"en": "Payment method updated successfully.",
+		"id": "Metode pembayaran berhasil diperbarui.",
+	},
+	"outlet_payment_methods_retrieved_successfully": {
+		"en": "Outlet payment methods retrieved successfully.",
+		"id": "Metode pembayaran outlet berhasil diambil.",
+	},
+	"outlet_payment_methods_updated_successfully": {
+		"en": "Outlet payment methods updated successfully.",
+		"id": "Metode pembayaran outlet berhasil diperbarui.",
+	},
+	"invalid_payment_method_id": {
+		"en": "Invalid payment method ID.",
+		"id": "ID metode pembayaran tidak valid.",
+	},
+	"payment_method_name_required": {
+		"en": "Payment method name is required.",
+		"id": "Nama metode pembayaran wajib diisi.",
+	},
+	"payment_method_type_required": {
+		"en": "Payment method type is required.",
+		"id": "Tipe metode pembayaran wajib diisi.",
+	},
+	"payment_method_too_long": {
+		"en": "Payment method must be at most 255 characters.",
+		"id": "Metode pembayaran maksimal 255 karakter.",
+	},
+	"payment_channel_too_long": {
+		"en": "Payment channel must be at most 255 characters.",
+		"id": "Kanal pembayaran maksimal 255 karakter.",
+	},
+	"payment_method_issuer_required": {
+		"en": "Payment method issuer is required.",
+		"id": "Penerbit metode pembayaran wajib diisi.",
+	},
+	"display_order_invalid": {
+		"en": "Display order must not be negative.",
+		"id": "Urutan tampilan tidak boleh negatif.",
+	},
+	"surcharge_type_invalid": {
+		"en": "Surcharge type must be fixed or percentage.",
+		"id": "Tipe biaya tambahan harus fixed atau percentage.",
+	},
+	"surcharge_value_invalid": {
+		"en": "Surcharge value must not be negative.",
+		"id": "Nilai biaya tambahan tidak boleh negatif.",
+	},
+	"payment method not found": {
+		"en": "Payment method not found.",
+		"id": "Metode pembayaran tidak ditemukan.",
+	},
+	"unknown payment issuer": {
+		"en": "The payment issuer is not supported.",
+		"id": "Penerbit pembayaran tidak didukung.",
+	},
+	"duplicate payment method": {
+		"en": "A payment method is listed more than once.",
+		"id": "Metode pembayaran tercantum lebih dari sekali.",
+	},
+	"payment method not enabled for this outlet": {
+		"en": "This payment method is not enabled for the outlet.",
+		"id": "Metode pembayaran ini tidak diaktifkan untuk outlet.",
+	},
+	"payment_proof_attached_successfully": {
+		"en": "Proof of payment attached successfully.",
+		"id": "Bukti pembayaran berhasil dilampirkan.",
+	},
+	"payment_proofs_retrieved_successfully": {
+		"en": "Proofs of payment retrieved successfully.",
+		"id": "Bukti pembayaran berhasil diambil.",
+	},
+	"transfer_confirmed_successfully": {
+		"en": "Transfer confirmed successfully.",
+		"id": "Transfer berhasil dikonfirmasi.",
+	},
+	"transfer_rejected_successfully": {
+		"en": "Transfer rejected successfully.",
+		"id": "Transfer berhasil ditolak.",
+	},
+	"pending_transfers_retrieved_successfully": {
+		"en": "Pending transfers retrieved successfully.",
+		"id": "Transfer yang menunggu konfirmasi berhasil diambil.",
+	},
+	"proof_file_required": {
+		"en": "A proof of payment file is required.",
+		"id": "File bukti pembayaran wajib diunggah.",
+	},
+	"proof_note_too_long": {
+		"en": "The note must be at most 500 characters.",
+		"id": "Catatan maksimal 500 karakter.",
+	},
+	"proof of payment not found": {
+		"en": "Proof of payment not found.",
+		"id": "Bukti pembayaran tidak ditemukan.",
+	},
+	"order payment is not a manual transfer": {
+		"en": "This order payment is not a manual transfer.",
+		"id": "Pembayaran pesanan ini bukan transfer manual.",
+	},
+	"proof of payment is too large": {
+		"en": "The proof of payment must be at most 5 MB.",
+		"id": "Bukti pembayaran maksimal 5 MB.",
+	},
+	"proof of payment must be a jpeg, png or webp image": {
+		"en": "The proof of payment must be a JPEG, PNG or WebP image.",
+		"id": "Bukti pembayaran harus berupa gambar JPEG, PNG, atau WebP.",
+	},
+	"proof of payment required": {
+		"en": "Attach a proof of payment before confirming the transfer.",
+		"id": "Lampirkan bukti pembayaran sebelum mengonfirmasi transfer.",
+	},
+	"only pending transfers accept a proof of payment": {
+		"en": "Only pending transfers accept a proof of payment.",
+		"id": "Hanya transfer yang masih menunggu yang dapat menerima bukti pembayaran.",
+	},
+	"only pending transfers can be reviewed": {
+		"en": "Only pending transfers can be confirmed or rejected.",
+		"id": "Hanya transfer yang masih menunggu yang dapat dikonfirmasi atau ditolak.",
+	},
+	"payment_link_created_successfully": {
+		"en": "Payment link created successfully",
+		"id": "Tautan pembayaran berhasil dibuat",
+	},
+	"payment_links_retrieved_successfully": {
+		"en": "Payment links retrieved successfully",
+		"id": "Tautan pembayaran berhasil diambil",
+	},
+	"payment_link_sent_successfully": {
+		"en": "Payment link sent successfully",
+		"id": "Tautan pembayaran berhasil dikirim",
+	},
+	"payment_link_cancelled_successfully": {
+		"en": "Payment link cancelled successfully",
+		"id": "Tautan pembayaran berhasil dibatalkan",
+	},
+	"payment link not found": {
+		"en": "Payment link not found",
+		"id": "Tautan pembayaran tidak ditemukan",
+	},
+	"payment link has expired": {
+		"en": "Payment link has expired",
+		"id": "Tautan pembayaran sudah kedaluwarsa",
+	},
+	"payment link has already been paid": {
+		"en": "Payment link has already been paid",
+		"id": "Tautan pembayaran sudah dibayar",
+	},
+	"payment link is no longer active": {
+		"en": "Payment link is no longer active",
+		"id": "Tautan pembayaran sudah tidak aktif",
+	},
+	"payment method does not support payment links": {
+		"en": "Payment method does not support payment links",
+		"id": "Metode pembayaran tidak mendukung tautan pembayaran",
+	},
+	"order has no outstanding balance": {
+		"en": "Order has no outstanding balance",
+		"id": "Pesanan tidak memiliki sisa tagihan",
+	},
+	"recipient email is required": {
+		"en": "Recipient email is required",
+		"id": "Email penerima wajib diisi",
+	},
+	"failed to send payment link email": {
+		"en": "Failed to send payment link email",
+		"id": "Gagal mengirim email tautan pembayaran",
+	},
+	"failed to create payment link": {
+		"en": "Failed to create payment link",
+		"id": "Gagal membuat tautan pembayaran",
+	},
+	"email rate limited": {
+		"en": "Please wait before sending another email",
+		"id": "Harap tunggu sebelum mengirim email lagi",
+	},
+	"payment reference mismatch": {
+		"en": "Payment reference mismatch",
+		"id": "Referensi pembayaran tidak cocok",
+	},
+	"iPaymu hosted payment failed": {
+		"en": "iPaymu hosted payment failed",
+		"id": "Pembayaran halaman iPaymu gagal",
+	},
+	"webhook_endpoint_created_successfully": {
+		"en": "Webhook endpoint created successfully. Store the signing secret now; it is not shown again.",
+		"id": "Endpoint webhook berhasil dibuat. Simpan secret penandatanganan sekarang; secret tidak akan ditampilkan lagi.",
+	},
+	"webhook_endpoints_retrieved_successfully": {
+		"en": "Webhook endpoints retrieved successfully.",
+		"id": "Endpoint webhook berhasil diambil.",
+	},
+	"webhook_endpoint_updated_successfully": {
+		"en": "Webhook endpoint updated successfully.",
+		"id": "Endpoint webhook berhasil diperbarui.",
+	},
+	"webhook_endpoint_deleted_successfully": {
+		"en": "Webhook endpoint deleted successfully.",
+		"id": "Endpoint webhook berhasil dihapus.",
+	},
+	"webhook_ping_sent_successfully": {
+		"en": "Webhook ping sent.",
+		"id": "Ping webhook telah dikirim.",
+	},
+	"webhook_deliveries_retrieved_successfully": {
+		"en": "Webhook deliveries retrieved successfully.",
+		"id": "Riwayat pengiriman webhook berhasil diambil.",
+	},
+	"webhook_redelivered_successfully": {
+		"en": "Webhook redelivered.",
+		"id": "Webhook telah dikirim ulang.",
+	},
+	"webhook_url_invalid": {
+		"en": "Webhook URL must be a valid URL of at most 500 characters.",
+		"id": "URL webhook harus berupa URL yang valid dengan maksimal 500 karakter.",
+	},
+	"webhook_event_types_invalid": {
+		"en": "Choose at least one supported webhook event type.",
+		"id": "Pilih minimal satu jenis event webhook yang didukung.",
+	},
+	"webhook_description_too_long": {
+		"en": "Webhook description must be at most 255 characters.",
+		"id": "Deskripsi webhook maksimal 255 karakter.",
+	},
+	"customers_retrieved_successfully": {
+		"en": "Customers retrieved successfully.",
+		"id": "Pelanggan berhasil diambil.",
+	},
+	"customer_retrieved_successfully": {
+		"en": "Customer retrieved successfully.",
+		"id": "Pelanggan berhasil diambil.",
+	},
+	"customer_created_successfully": {
+		"en": "Customer created successfully.",
+		"id": "Pelanggan berhasil dibuat.",
+	},
+	"customer_updated_successfully": {
+		"en": "Customer updated successfully.",
+		"id": "Pelanggan berhasil diperbarui.",
+	},
+	"wallet_transactions_retrieved_successfully": {
+		"en": "Wallet transactions retrieved successfully.",
+		"id": "Riwayat saldo berhasil diambil.",
+	},
+	"wallet_adjusted_successfully": {
+		"en": "Wallet balance adjusted successfully.",
+		"id": "Saldo berhasil disesuaikan.",
+	},
+	"wallet_top_up_created_successfully": {
+		"en": "Wallet top-up created successfully.",
+		"id": "Isi ulang saldo berhasil dibuat.",
+	},
+	"customer_name_required": {
+		"en": "Customer name is required and must be at most 255 characters.",
+		"id": "Nama pelanggan wajib diisi dan maksimal 255 karakter.",
+	},
+	"customer_phone_too_long": {
+		"en": "Customer phone must be at most 50 characters.",
+		"id": "Nomor telepon pelanggan maksimal 50 karakter.",
+	},
+	"wallet_adjustment_amount_required": {
+		"en": "Adjustment amount is required and cannot be zero.",
+		"id": "Jumlah penyesuaian wajib diisi dan tidak boleh nol.",
+	},
+	"wallet_adjustment_reason_required": {
+		"en": "Adjustment reason is required and must be at most 255 characters.",
+		"id": "Alasan penyesuaian wajib diisi dan maksimal 255 karakter.",
+	},
+	"wallet_top_up_amount_invalid": {
+		"en": "Top-up amount must be greater than zero.",
+		"id": "Jumlah isi ulang harus lebih dari nol.",
+	},
+	"reorder_point_updated_successfully": {
+		"en": "Reorder point updated successfully.",
+		"id": "Titik pemesanan ulang berhasil diperbarui.",
+	},
+	"low_stock_retrieved_successfully": {
+		"en": "Low stock retrieved successfully.",
+		"id": "Daftar stok menipis berhasil diambil.",
+	},
+	"stock_alerts_retrieved_successfully": {
+		"en": "Stock alerts retrieved successfully.",
+		"id": "Peringatan stok berhasil diambil.",
+	},
+	"stock_alert_acknowledged_successfully": {
+		"en": "Stock alert acknowledged.",
+		"id": "Peringatan stok telah ditandai dibaca.",
+	},
+	"reorder_point_invalid": {
+		"en": "Reorder point must be zero or greater.",
+		"id": "Titik pemesanan ulang minimal nol.",
+	},
+	"par_level_invalid": {
+		"en": "Par level must be zero or greater.",
+		"id": "Par level minimal nol.",
+	},
+	"stock_transfer_created_successfully": {
+		"en": "Stock transfer created successfully.",
+		"id": "Transfer stok berhasil dibuat.",
+	},
+	"stock_transfer_updated_successfully": {
+		"en": "Stock transfer updated successfully.",
+		"id": "Transfer stok berhasil diperbarui.",
+	},
+	"stock_transfer_retrieved_successfully": {
+		"en": "Stock transfer retrieved successfully.",
+		"id": "Transfer stok berhasil diambil.",
+	},
+	"stock_transfers_retrieved_successfully": {
+		"en": "Stock transfers retrieved successfully.",
+		"id": "Daftar transfer stok berhasil diambil.",
+	},
+	"stock_transfer_sent_successfully": {
+		"en": "Stock transfer sent successfully.",
+		"id": "Transfer stok berhasil dikirim.",
+	},
+	"stock_transfer_received_successfully": {
+		"en": "Stock transfer received successfully.",
+		"id": "Transfer stok berhasil diterima.",
+	},
+	"stock_transfer_cancelled_successfully": {
+		"en": "Stock transfer cancelled successfully.",
+		"id": "Transfer stok berhasil dibatalkan.",
+	},
+	"source_outlet_uuid_required": {
+		"en": "Source outlet UUID is required.",
+		"id": "UUID outlet asal wajib diisi.",
+	},
+	"destination_outlet_uuid_required": {
+		"en": "Destination outlet UUID is required.",
+		"id": "UUID outlet tujuan wajib diisi.",
+	},
+	"stock_transfer_note_too_long": {
+		"en": "Note must not exceed 1000 characters.",
+		"id": "Catatan tidak boleh lebih dari 1000 karakter.",
+	},
+	"stock_transfer_items_required": {
+		"en": "At least one item is required.",
+		"id": "Minimal satu item wajib diisi.",
+	},
+	"quantity_must_be_greater_than_zero": {
+		"en": "Quantity must be greater than zero.",
+		"id": "Jumlah harus lebih dari nol.",
+	},
+	"stock_transfer_item_uuid_required": {
+		"en": "Stock transfer item UUID is required.",
+		"id": "UUID item transfer stok wajib diisi.",
+	},
+	"received_quantity_invalid": {
+		"en": "Received quantity is required and must not be negative.",
+		"id": "Jumlah diterima wajib diisi dan tidak boleh negatif.",
+	},
+	"discrepancy_note_too_long": {
+		"en": "Discrepancy note must not exceed 1000 characters.",
+		"id": "Catatan selisih tidak boleh lebih dari 1000 karakter.",
+	},
+	"stock_count_started_successfully": {
+		"en": "Stock count started successfully.",
+		"id": "Stok opname berhasil dimulai.",
+	},
+	"stock_counts_retrieved_successfully": {
+		"en": "Stock counts retrieved successfully.",
+		"id": "Daftar stok opname berhasil diambil.",
+	},
+	"stock_count_retrieved_successfully": {
+		"en": "Stock count retrieved successfully.",
+		"id": "Stok opname berhasil diambil.",
+	},
+	"stock_count_recorded_successfully": {
+		"en": "Counted quantities recorded successfully.",
+		"id": "Jumlah hitungan berhasil dicatat.",
+	},
+	"stock_count_entry_deleted_successfully": {
+		"en": "Count entry deleted successfully.",
+		"id": "Entri hitungan berhasil dihapus.",
+	},
+	"stock_count_submitted_successfully": {
+		"en": "Stock count submitted successfully.",
+		"id": "Stok opname berhasil diajukan.",
+	},
+	"stock_count_approved_successfully": {
+		"en": "Stock count approved successfully.",
+		"id": "Stok opname berhasil disetujui.",
+	},
+	"stock_count_cancelled_successfully": {
+		"en": "Stock count cancelled successfully.",
+		"id": "Stok opname berhasil dibatalkan.",
+	},
+	"stock_count_variance_report_generated_successfully": {
+		"en": "Stock count variance report generated successfully.",
+		"id": "Laporan selisih stok opname berhasil dibuat.",
+	},
+	"stock_count_note_too_long": {
+		"en": "Note must not exceed 1000 characters.",
+		"id": "Catatan tidak boleh lebih dari 1000 karakter.",
+	},
+	"stock_count_items_required": {
+		"en": "At least one counted item is required.",
+		"id": "Minimal satu item hitungan wajib diisi.",
+	},
+	"counted_quantity_invalid": {
+		"en": "Counted quantity is required and must not be negative.",
+		"id": "Jumlah hitungan wajib diisi dan tidak boleh negatif.",
+	},
+	"stock_lots_retrieved_successfully": {
+		"en": "Stock lots retrieved successfully.",
+		"id": "Daftar lot stok berhasil diambil.",
+	},
+	"expiring_stock_lots_retrieved_successfully": {
+		"en": "Expiring stock lots retrieved successfully.",
+		"id": "Daftar lot stok yang akan kedaluwarsa berhasil diambil.",
+	},
+	"stock_lot_written_off_successfully": {
+		"en": "Stock lot written off successfully.",
+		"id": "Lot stok berhasil dihapusbukukan.",
+	},
+	"invalid_days_parameter": {
+		"en": "Days must be a whole number of zero or more.",
+		"id": "Jumlah hari harus berupa bilangan bulat nol atau lebih.",
+	},
+	"write_off_reason_required": {
+		"en": "A write-off reason of at most 255 characters is required.",
+		"id": "Alasan penghapusan wajib diisi, maksimal 255 karakter.",
+	},
+	"purchase_order_item_uuid_required": {
+		"en": "Purchase order item UUID is required.",
+		"id": "UUID item pesanan pembelian wajib diisi.",
+	},
+	"lot_number_too_long": {
+		"en": "Lot number must not exceed 100 characters.",
+		"id": "Nomor lot tidak boleh lebih dari 100 karakter.",
+	},
+	"invalid_expiry_date_format": {
+		"en": "Expiry date must use the YYYY-MM-DD format.",
+		"id": "Tanggal kedaluwarsa harus berformat YYYY-MM-DD.",
+	},
+	"gross_margin_report_generated_successfully": {
+		"en": "Gross margin report generated successfully.",
+		"id": "Laporan margin kotor berhasil dibuat.",
+	},
+	"invalid_group_by_parameter": {
+		"en": "Invalid group_by parameter. Use product, category or outlet.",
+		"id": "Parameter group_by tidak valid. Gunakan product, category atau outlet.",
+	},
+	"product_cost_price_invalid": {
+		"en": "Cost price cannot be negative.",
+		"id": "Harga pokok tidak boleh negatif.",
+	},
+	"product_category_too_long": {
+		"en": "Category must be at most 100 characters.",
+		"id": "Kategori maksimal 100 karakter.",
+	},
+	"product_unit_created_successfully": {
+		"en": "Product unit created successfully.",
+		"id": "Satuan produk berhasil dibuat.",
+	},
+	"product_units_retrieved_successfully": {
+		"en": "Product units retrieved successfully.",
+		"id": "Satuan produk berhasil diambil.",
+	},
+	"product_unit_updated_successfully": {
+		"en": "Product unit updated successfully.",
+		"id": "Satuan produk berhasil diperbarui.",
+	},
+	"product_unit_deleted_successfully": {
+		"en": "Product unit deleted successfully.",
+		"id": "Satuan produk berhasil dihapus.",
+	},
+	"unit_name_required": {
+		"en": "Unit name is required and must be at most 20 characters.",
+		"id": "Nama satuan wajib diisi dan maksimal 20 karakter.",
+	},
+	"unit_name_too_long": {
+		"en": "Unit name must be at most 20 characters.",
+		"id": "Nama satuan maksimal 20 karakter.",
+	},
+	"conversion_factor_must_be_greater_than_zero": {
+		"en": "Conversion factor must be greater than zero.",
+		"id": "Faktor konversi harus lebih besar dari nol.",
+	},
+	"stock_adjustment_created_successfully": {
+		"en": "Stock adjustment recorded successfully.",
+		"id": "Penyesuaian stok berhasil dicatat.",
+	},
+	"stock_adjustments_retrieved_successfully": {
+		"en": "Stock adjustments retrieved successfully.",
+		"id": "Penyesuaian stok berhasil diambil.",
+	},
+	"stock_adjustment_retrieved_successfully": {
+		"en": "Stock adjustment retrieved successfully.",
+		"id": "Penyesuaian stok berhasil diambil.",
+	},
+	"stock_adjustment_approved_successfully": {
+		"en": "Stock adjustment approved and applied successfully.",
+		"id": "Penyesuaian stok berhasil disetujui dan diterapkan.",
+	},
+	"stock_adjustment_rejected_successfully": {
+		"en": "Stock adjustment rejected successfully.",
+		"id": "Penyesuaian stok berhasil ditolak.",
+	},
+	"stock_adjustment_photo_uploaded_successfully": {
+		"en": "Stock adjustment photo uploaded successfully.",
+		"id": "Foto penyesuaian stok berhasil diunggah.",
+	},
+	"waste_report_generated_successfully": {
+		"en": "Waste report generated successfully.",
+		"id": "Laporan waste berhasil dibuat.",
+	},
+	"invalid_waste_group_by_parameter": {
+		"en": "Invalid group_by parameter. Use reason, product or outlet.",
+		"id": "Parameter group_by tidak valid. Gunakan reason, product atau outlet.",
+	},
+	"photo_file_required": {
+		"en": "Photo file is required.",
+		"id": "File foto wajib diisi.",
+	},
+	"quantity_change_required": {
+		"en": "Quantity change is required and must not be zero.",
+		"id": "Perubahan kuantitas wajib diisi dan tidak boleh nol.",
+	},
+	"invalid_stock_adjustment_reason": {
+		"en": "Invalid reason. Use waste, spoilage, breakage, theft, staff_meal, sample or correction.",
+		"id": "Alasan tidak valid. Gunakan waste, spoilage, breakage, theft, staff_meal, sample atau correction.",
+	},
+	"note_too_long": {
+		"en": "Note must not exceed 500 characters.",
+		"id": "Catatan tidak boleh lebih dari 500 karakter.",
+	},
+	"invalid_photo_url": {
+		"en": "Photo URL must be a valid URL of at most 500 characters.",
+		"id": "URL foto harus berupa URL yang valid dengan maksimal 500 karakter.",
+	},
+	"stock_movements_retrieved_successfully": {
+		"en": "Stock movements retrieved successfully.",
+		"id": "Pergerakan stok berhasil diambil.",
+	},
+	"stock_as_of_retrieved_successfully": {
+		"en": "Stock as of the given date retrieved successfully.",
+		"id": "Stok per tanggal yang diminta berhasil diambil.",
+	},
+	"invalid_date_format": {
+		"en": "Invalid date format. Use YYYY-MM-DD.",
+		"id": "Format tanggal tidak valid. Gunakan YYYY-MM-DD.",
+	},
+	"order_cancelled_successfully": {
+		"en": "Order cancelled successfully.",
+		"id": "Pesanan berhasil dibatalkan.",
+	},
+	"a refund of this payment is still pending": {
+		"en": "A refund of this payment is still being processed.",
+		"id": "Pengembalian dana untuk pembayaran ini masih diproses.",
+	},
 }
 
 func GetLocalizedMessage(messageKey, lang string) string {
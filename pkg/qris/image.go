@@ -0,0 +1,10 @@
+package qris
+
+import (
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// PNG renders a payload as a QR code image of size by size pixels.
+func PNG(payload string, size int) ([]byte, error) {
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
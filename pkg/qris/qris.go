@@ -0,0 +1,281 @@
+// Package qris reads and writes QRIS merchant presented (MPM) payloads, the EMVCo QR code
+// format used by Indonesian payment QR codes.
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Tags of the EMVCo MPM data objects used by the package.
+const (
+	TagPayloadFormat       = "00"
+	TagPointOfInitiation   = "01"
+	TagMerchantCategory    = "52"
+	TagCurrency            = "53"
+	TagAmount              = "54"
+	TagTipIndicator        = "55"
+	TagConvenienceFeeFixed = "56"
+	TagConvenienceFeeRate  = "57"
+	TagCountryCode         = "58"
+	TagMerchantName        = "59"
+	TagMerchantCity        = "60"
+	TagPostalCode          = "61"
+	TagAdditionalData      = "62"
+	TagCRC                 = "63"
+)
+
+// Point of initiation values: a static code is reused, a dynamic one carries the amount of a single payment.
+const (
+	PointOfInitiationStatic  = "11"
+	PointOfInitiationDynamic = "12"
+)
+
+// qrisGlobalID identifies the national QRIS merchant account template, whose sub tag 02 is the NMID.
+const qrisGlobalID = "ID.CO.QRIS.WWW"
+
+var (
+	ErrInvalidPayload  = errors.New("invalid qris payload")
+	ErrInvalidChecksum = errors.New("invalid qris checksum")
+	ErrInvalidAmount   = errors.New("invalid qris amount")
+)
+
+// Field is one TLV data object of a payload.
+type Field struct {
+	Tag   string
+	Value string
+}
+
+// Payload is a parsed QRIS payload. Fields keep the order they were read in.
+type Payload struct {
+	Fields []Field
+}
+
+// Decode splits TLV encoded data into its fields. Each field is a two digit tag, a two digit
+// length and the value.
+func Decode(data string) ([]Field, error) {
+	var fields []Field
+	for pos := 0; pos < len(data); {
+		if pos+4 > len(data) {
+			return nil, fmt.Errorf("%w: truncated field at position %d", ErrInvalidPayload, pos)
+		}
+		tag := data[pos : pos+2]
+		if !isDigits(tag) {
+			return nil, fmt.Errorf("%w: invalid tag %q at position %d", ErrInvalidPayload, tag, pos)
+		}
+		length, err := strconv.Atoi(data[pos+2 : pos+4])
+		if err != nil || !isDigits(data[pos+2:pos+4]) {
+			return nil, fmt.Errorf("%w: invalid length for tag %s", ErrInvalidPayload, tag)
+		}
+		pos += 4
+		if pos+length > len(data) {
+			return nil, fmt.Errorf("%w: value of tag %s is truncated", ErrInvalidPayload, tag)
+		}
+		fields = append(fields, Field{Tag: tag, Value: data[pos : pos+length]})
+		pos += length
+	}
+	return fields, nil
+}
+
+// Encode writes fields as TLV data in the given order.
+func Encode(fields []Field) string {
+	var b strings.Builder
+	for _, field := range fields {
+		b.WriteString(field.Tag)
+		b.WriteString(fmt.Sprintf("%02d", len(field.Value)))
+		b.WriteString(field.Value)
+	}
+	return b.String()
+}
+
+// CRC16 returns the CRC-16/CCITT-FALSE checksum of data as four uppercase hex digits, as used by tag 63.
+func CRC16(data string) string {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%04X", crc)
+}
+
+// Parse decodes a payload and validates its structure and checksum.
+func Parse(data string) (*Payload, error) {
+	data = strings.TrimSpace(data)
+	fields, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	payload := &Payload{Fields: fields}
+	if err := payload.validate(data); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func (p *Payload) validate(data string) error {
+	if len(p.Fields) == 0 || p.Fields[0].Tag != TagPayloadFormat || p.Fields[0].Value != "01" {
+		return fmt.Errorf("%w: payload must start with format indicator 01", ErrInvalidPayload)
+	}
+
+	last := p.Fields[len(p.Fields)-1]
+	if last.Tag != TagCRC || len(last.Value) != 4 {
+		return fmt.Errorf("%w: payload must end with a checksum", ErrInvalidPayload)
+	}
+	if !strings.EqualFold(CRC16(data[:len(data)-4]), last.Value) {
+		return ErrInvalidChecksum
+	}
+
+	seen := make(map[string]bool, len(p.Fields))
+	hasMerchantAccount := false
+	for _, field := range p.Fields {
+		if seen[field.Tag] {
+			return fmt.Errorf("%w: tag %s appears more than once", ErrInvalidPayload, field.Tag)
+		}
+		seen[field.Tag] = true
+		if isMerchantAccountTag(field.Tag) {
+			if _, err := Decode(field.Value); err != nil {
+				return fmt.Errorf("%w: merchant account %s is malformed", ErrInvalidPayload, field.Tag)
+			}
+			hasMerchantAccount = true
+		}
+	}
+	if !hasMerchantAccount {
+		return fmt.Errorf("%w: no merchant account information", ErrInvalidPayload)
+	}
+
+	for _, tag := range []string{TagMerchantCategory, TagCurrency, TagCountryCode, TagMerchantName, TagMerchantCity} {
+		if !seen[tag] {
+			return fmt.Errorf("%w: missing tag %s", ErrInvalidPayload, tag)
+		}
+	}
+
+	if poi, ok := p.Get(TagPointOfInitiation); ok && poi != PointOfInitiationStatic && poi != PointOfInitiationDynamic {
+		return fmt.Errorf("%w: unknown point of initiation %s", ErrInvalidPayload, poi)
+	}
+	if amount, ok := p.Get(TagAmount); ok {
+		if value, err := strconv.ParseFloat(amount, 64); err != nil || value <= 0 {
+			return fmt.Errorf("%w: %s", ErrInvalidAmount, amount)
+		}
+	}
+	if additional, ok := p.Get(TagAdditionalData); ok {
+		if _, err := Decode(additional); err != nil {
+			return fmt.Errorf("%w: additional data is malformed", ErrInvalidPayload)
+		}
+	}
+	return nil
+}
+
+// Get returns the value of a top level tag.
+func (p *Payload) Get(tag string) (string, bool) {
+	for _, field := range p.Fields {
+		if field.Tag == tag {
+			return field.Value, true
+		}
+	}
+	return "", false
+}
+
+// IsDynamic reports whether the payload is a single use code.
+func (p *Payload) IsDynamic() bool {
+	poi, _ := p.Get(TagPointOfInitiation)
+	return poi == PointOfInitiationDynamic
+}
+
+func (p *Payload) MerchantName() string {
+	name, _ := p.Get(TagMerchantName)
+	return name
+}
+
+func (p *Payload) MerchantCity() string {
+	city, _ := p.Get(TagMerchantCity)
+	return city
+}
+
+// NMID returns the national merchant ID from the QRIS merchant account template, if present.
+func (p *Payload) NMID() string {
+	for _, field := range p.Fields {
+		if !isMerchantAccountTag(field.Tag) {
+			continue
+		}
+		subFields, err := Decode(field.Value)
+		if err != nil {
+			continue
+		}
+		template := Payload{Fields: subFields}
+		if globalID, _ := template.Get("00"); globalID == qrisGlobalID {
+			nmid, _ := template.Get("02")
+			return nmid
+		}
+	}
+	return ""
+}
+
+// ToDynamic turns a static payload into a dynamic one for a single payment of amount. Fields are kept
+// in tag order, any amount already present is replaced and the checksum is recomputed.
+func ToDynamic(static string, amount float64) (string, error) {
+	if amount <= 0 || math.IsInf(amount, 0) || math.IsNaN(amount) {
+		return "", ErrInvalidAmount
+	}
+	formattedAmount := FormatAmount(amount)
+	if len(formattedAmount) > 13 {
+		return "", ErrInvalidAmount
+	}
+
+	payload, err := Parse(static)
+	if err != nil {
+		return "", err
+	}
+
+	fields := make([]Field, 0, len(payload.Fields)+1)
+	amountWritten := false
+	for _, field := range payload.Fields {
+		switch {
+		case field.Tag == TagCRC, field.Tag == TagAmount:
+			continue
+		case field.Tag == TagPointOfInitiation:
+			field.Value = PointOfInitiationDynamic
+		case !amountWritten && field.Tag > TagAmount:
+			fields = append(fields, Field{Tag: TagAmount, Value: formattedAmount})
+			amountWritten = true
+		}
+		fields = append(fields, field)
+	}
+	if !amountWritten {
+		fields = append(fields, Field{Tag: TagAmount, Value: formattedAmount})
+	}
+	if _, ok := payload.Get(TagPointOfInitiation); !ok {
+		// The indicator follows the payload format, which Parse guarantees is the first field
+		fields = append(fields[:1], append([]Field{{Tag: TagPointOfInitiation, Value: PointOfInitiationDynamic}}, fields[1:]...)...)
+	}
+
+	data := Encode(fields) + TagCRC + "04"
+	return data + CRC16(data), nil
+}
+
+// FormatAmount writes amount the way tag 54 expects it: no thousands separator, a dot before
+// the decimals, and no decimals for whole amounts.
+func FormatAmount(amount float64) string {
+	return strconv.FormatFloat(math.Round(amount*100)/100, 'f', -1, 64)
+}
+
+func isMerchantAccountTag(tag string) bool {
+	return tag >= "02" && tag <= "51"
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
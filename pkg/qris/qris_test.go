@@ -0,0 +1,118 @@
+package qris
+
+import (
+	"errors"
+	"testing"
+)
+
+// staticSample is a published static QRIS code of a DANA merchant.
+const staticSample = "00020101021126570011ID.DANA.WWW011893600915302259148102090225914810303UMI51440014ID.CO.QRIS.WWW0215ID10200176114730303UMI5204581253033605802ID5922Warung Sayur Bu Sugeng6010Kab. Demak610559567630458C7"
+
+func TestCRC16CheckValue(t *testing.T) {
+	if got := CRC16("123456789"); got != "29B1" {
+		t.Fatalf("expected the CRC-16/CCITT-FALSE check value 29B1, got %s", got)
+	}
+}
+
+func TestParseStaticSample(t *testing.T) {
+	payload, err := Parse(staticSample)
+	if err != nil {
+		t.Fatalf("failed to parse sample: %v", err)
+	}
+	if payload.IsDynamic() {
+		t.Fatal("expected a static payload")
+	}
+	if name := payload.MerchantName(); name != "Warung Sayur Bu Sugeng" {
+		t.Fatalf("unexpected merchant name %q", name)
+	}
+	if city := payload.MerchantCity(); city != "Kab. Demak" {
+		t.Fatalf("unexpected merchant city %q", city)
+	}
+	if nmid := payload.NMID(); nmid != "ID1020017611473" {
+		t.Fatalf("unexpected NMID %q", nmid)
+	}
+	if _, ok := payload.Get(TagAmount); ok {
+		t.Fatal("expected no amount on a static payload")
+	}
+}
+
+func TestToDynamic(t *testing.T) {
+	// Tag 54 goes between 53 and 58, the point of initiation becomes 12 and the checksum is recomputed
+	want := "00020101021226570011ID.DANA.WWW011893600915302259148102090225914810303UMI51440014ID.CO.QRIS.WWW0215ID10200176114730303UMI5204581253033605405150005802ID5922Warung Sayur Bu Sugeng6010Kab. Demak61055956763041A02"
+
+	got, err := ToDynamic(staticSample, 15000)
+	if err != nil {
+		t.Fatalf("failed to make the payload dynamic: %v", err)
+	}
+	if got != want {
+		t.Fatalf("unexpected dynamic payload\n got: %s\nwant: %s", got, want)
+	}
+
+	payload, err := Parse(got)
+	if err != nil {
+		t.Fatalf("dynamic payload does not parse: %v", err)
+	}
+	if !payload.IsDynamic() {
+		t.Fatal("expected a dynamic payload")
+	}
+
+	// Making it dynamic again replaces the amount instead of adding a second one
+	again, err := ToDynamic(got, 2500.5)
+	if err != nil {
+		t.Fatalf("failed to replace the amount: %v", err)
+	}
+	payload, err = Parse(again)
+	if err != nil {
+		t.Fatalf("payload with a replaced amount does not parse: %v", err)
+	}
+	if amount, _ := payload.Get(TagAmount); amount != "2500.5" {
+		t.Fatalf("expected amount 2500.5, got %q", amount)
+	}
+}
+
+func TestToDynamicRejectsInvalidAmounts(t *testing.T) {
+	for _, amount := range []float64{0, -1, 1e13} {
+		if _, err := ToDynamic(staticSample, amount); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("amount %v: expected %v, got %v", amount, ErrInvalidAmount, err)
+		}
+	}
+}
+
+func TestParseRejectsBadChecksum(t *testing.T) {
+	tampered := staticSample[:len(staticSample)-4] + "58C8"
+	if _, err := Parse(tampered); !errors.Is(err, ErrInvalidChecksum) {
+		t.Fatalf("expected %v, got %v", ErrInvalidChecksum, err)
+	}
+
+	// A changed merchant name no longer matches the original checksum
+	renamed := "00020101021126570011ID.DANA.WWW011893600915302259148102090225914810303UMI51440014ID.CO.QRIS.WWW0215ID10200176114730303UMI5204581253033605802ID5922Warung Sayur Bu Sugeny6010Kab. Demak610559567630458C7"
+	if _, err := Parse(renamed); !errors.Is(err, ErrInvalidChecksum) {
+		t.Fatalf("expected %v, got %v", ErrInvalidChecksum, err)
+	}
+}
+
+func TestParseRejectsMalformedFields(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"truncated header", staticSample[:len(staticSample)-6]},
+		{"truncated value", staticSample[:len(staticSample)-2]},
+		{"length past the end", "000201" + "5999Warung"},
+		{"non numeric tag", "0002015A04Toko6304ABCD"},
+		{"non numeric length", "00020159A4Toko6304ABCD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.data); !errors.Is(err, ErrInvalidPayload) {
+				t.Fatalf("expected %v, got %v", ErrInvalidPayload, err)
+			}
+		})
+	}
+}
+
+func TestDecodeRejectsOverlongValue(t *testing.T) {
+	if _, err := Decode("0002015910Toko"); !errors.Is(err, ErrInvalidPayload) {
+		t.Fatalf("expected %v, got %v", ErrInvalidPayload, err)
+	}
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignHMACSHA256 returns the hex encoded HMAC-SHA256 of body using secret.
+func SignHMACSHA256(secret string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifyHMACSHA256 checks a hex encoded HMAC-SHA256 signature in constant time.
+// An optional "sha256=" prefix, as sent by several webhook providers, is accepted.
+func VerifyHMACSHA256(secret string, body []byte, signature string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	signature = strings.TrimPrefix(strings.TrimSpace(signature), "sha256=")
+	expected := SignHMACSHA256(secret, body)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
+// GenerateSecureToken returns a hex encoded cryptographically random token of n bytes.
+func GenerateSecureToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
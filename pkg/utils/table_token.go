@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidTableToken = errors.New("invalid table token")
+
+func tableTokenSecret() []byte {
+	secret := os.Getenv("QR_TOKEN_SECRET")
+	if secret == "" {
+		secret = string(jwtSecret) // Fall back to the JWT secret so tokens are never unsigned
+	}
+	return []byte(secret)
+}
+
+func signTableToken(payload string) string {
+	h := hmac.New(sha256.New, tableTokenSecret())
+	h.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// GenerateTableToken signs the table UUID and token version for use in a table QR code.
+func GenerateTableToken(tableUuid uuid.UUID, version int) string {
+	payload := fmt.Sprintf("%s:%d", tableUuid.String(), version)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + signTableToken(payload)
+}
+
+// ParseTableToken verifies a table token and returns the table UUID and token version it was issued for.
+func ParseTableToken(token string) (uuid.UUID, int, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return uuid.Nil, 0, ErrInvalidTableToken
+	}
+
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return uuid.Nil, 0, ErrInvalidTableToken
+	}
+	payload := string(payloadBytes)
+
+	if !hmac.Equal([]byte(signTableToken(payload)), []byte(parts[1])) {
+		return uuid.Nil, 0, ErrInvalidTableToken
+	}
+
+	fields := strings.Split(payload, ":")
+	if len(fields) != 2 {
+		return uuid.Nil, 0, ErrInvalidTableToken
+	}
+	tableUuid, err := uuid.Parse(fields[0])
+	if err != nil {
+		return uuid.Nil, 0, ErrInvalidTableToken
+	}
+	version, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return uuid.Nil, 0, ErrInvalidTableToken
+	}
+	return tableUuid, version, nil
+}